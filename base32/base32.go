@@ -0,0 +1,39 @@
+// Package base32 contains Base32 encoding/decoding functions
+package base32
+
+import (
+	b32 "encoding/base32"
+	"fmt"
+	"strings"
+)
+
+// Encode - Encode data in the standard base32 format (RFC 4648). Trailing
+// padding is omitted when pad is false.
+func Encode(in []byte, pad bool) string {
+	enc := b32.StdEncoding
+	if !pad {
+		enc = enc.WithPadding(b32.NoPadding)
+	}
+
+	return enc.EncodeToString(in)
+}
+
+// Decode - Decode a base32-encoded string. Input may be lowercase, and may
+// omit the trailing padding.
+func Decode(in string) ([]byte, error) {
+	s := strings.TrimRight(strings.ToUpper(strings.TrimSpace(in)), "=")
+
+	// unpadded input can't end with a partial block of 1, 3, or 6 characters,
+	// but the decoder silently drops them
+	switch len(s) % 8 {
+	case 1, 3, 6:
+		return nil, fmt.Errorf("invalid base32 input: illegal length %d", len(s))
+	}
+
+	o, err := b32.StdEncoding.WithPadding(b32.NoPadding).DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base32 input: %w", err)
+	}
+
+	return o, nil
+}
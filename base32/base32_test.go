@@ -0,0 +1,45 @@
+package base32
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncode(t *testing.T) {
+	testdata := []struct {
+		in, padded, unpadded string
+	}{
+		{"", "", ""},
+		{"f", "MY======", "MY"},
+		{"fo", "MZXQ====", "MZXQ"},
+		{"foo", "MZXW6===", "MZXW6"},
+		{"foob", "MZXW6YQ=", "MZXW6YQ"},
+		{"fooba", "MZXW6YTB", "MZXW6YTB"},
+		{"foobar", "MZXW6YTBOI======", "MZXW6YTBOI"},
+	}
+
+	for _, d := range testdata {
+		assert.Equal(t, d.padded, Encode([]byte(d.in), true))
+		assert.Equal(t, d.unpadded, Encode([]byte(d.in), false))
+	}
+}
+
+func TestDecode(t *testing.T) {
+	for _, in := range []string{"MZXW6YTBOI======", "MZXW6YTBOI", "mzxw6ytboi", "mzxw6ytboi======", " MZXW6YTBOI\n"} {
+		out, err := Decode(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, []byte("foobar"), out, in)
+	}
+
+	out, err := Decode("")
+	require.NoError(t, err)
+	assert.Empty(t, out)
+
+	_, err = Decode("MZXW1")
+	require.ErrorContains(t, err, "invalid base32 input")
+
+	_, err = Decode("M")
+	require.ErrorContains(t, err, "invalid base32 input")
+}
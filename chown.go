@@ -0,0 +1,157 @@
+package gomplate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os/user"
+	"strconv"
+
+	"github.com/hack-pad/hackpadfs"
+	"github.com/hairyhenderson/gomplate/v4/internal/datafs"
+	"github.com/hairyhenderson/gomplate/v4/internal/iohelpers"
+)
+
+// outOwner is the owner to set on output files - as with os.Chown, -1 leaves
+// the uid or gid unchanged
+type outOwner struct {
+	uid, gid int
+}
+
+type outOwnerCtxKey struct{}
+
+func contextWithOutOwner(ctx context.Context, o *outOwner) context.Context {
+	return context.WithValue(ctx, outOwnerCtxKey{}, o)
+}
+
+// outOwnerFromContext returns the output files' owner, or nil if none is set
+func outOwnerFromContext(ctx context.Context) *outOwner {
+	o, _ := ctx.Value(outOwnerCtxKey{}).(*outOwner)
+	return o
+}
+
+// newOutOwner resolves the user and group (names or numeric IDs) to set as
+// the owner of output files. When ownership can't be changed (i.e. when not
+// running as root), a warning is logged and nil is returned.
+func newOutOwner(ctx context.Context, userName, groupName string) (*outOwner, error) {
+	if err := chownSupported(); err != nil {
+		slog.WarnContext(ctx, "output file ownership won't be changed",
+			"user", userName, "group", groupName, "reason", err)
+		return nil, nil
+	}
+
+	o := &outOwner{uid: -1, gid: -1}
+
+	if userName != "" {
+		uid, err := lookupID(userName, func(name string) (string, error) {
+			u, err := user.Lookup(name)
+			if err != nil {
+				return "", err
+			}
+			return u.Uid, nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("unknown output file user %q: %w", userName, err)
+		}
+		o.uid = uid
+	}
+
+	if groupName != "" {
+		gid, err := lookupID(groupName, func(name string) (string, error) {
+			g, err := user.LookupGroup(name)
+			if err != nil {
+				return "", err
+			}
+			return g.Gid, nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("unknown output file group %q: %w", groupName, err)
+		}
+		o.gid = gid
+	}
+
+	slog.DebugContext(ctx, "output file ownership will be changed",
+		"user", userName, "uid", o.uid, "group", groupName, "gid", o.gid)
+
+	return o, nil
+}
+
+// lookupID returns numeric IDs as-is, and looks up names with lookup
+func lookupID(name string, lookup func(string) (string, error)) (int, error) {
+	if id, err := strconv.Atoi(name); err == nil {
+		if id < 0 {
+			return 0, errors.New("must not be negative")
+		}
+		return id, nil
+	}
+
+	id, err := lookup(name)
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.Atoi(id)
+}
+
+// withOutOwner wraps the writer for the named output file so that its owner
+// is changed once the file has been closed, and so moved into place. Nothing
+// is changed in dry-run mode, since no files are written.
+func withOutOwner(ctx context.Context, filename string, w io.WriteCloser) io.WriteCloser {
+	o := outOwnerFromContext(ctx)
+	if o == nil || dryRunReportFromContext(ctx) != nil {
+		return w
+	}
+
+	return &ownerWriter{WriteCloser: w, ctx: ctx, owner: o, name: filename}
+}
+
+type ownerWriter struct {
+	io.WriteCloser
+	ctx   context.Context
+	owner *outOwner
+	name  string
+}
+
+var _ iohelpers.Aborter = (*ownerWriter)(nil)
+
+func (w *ownerWriter) Close() error {
+	if err := w.WriteCloser.Close(); err != nil {
+		return err
+	}
+
+	fsys, err := datafs.FSysForPath(w.ctx, w.name)
+	if err != nil {
+		return fmt.Errorf("fsysForPath: %w", err)
+	}
+
+	// the file may have been deleted because the output was empty
+	fi, err := hackpadfs.Stat(fsys, w.name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to stat output file %q: %w", w.name, err)
+	}
+
+	// as with chmod, only chown when the owner actually changes, since even a
+	// no-op chown updates the file's ctime
+	if uid, gid, ok := fileOwner(fi); ok &&
+		(w.owner.uid == -1 || w.owner.uid == uid) && (w.owner.gid == -1 || w.owner.gid == gid) {
+		return nil
+	}
+
+	if err := hackpadfs.Chown(fsys, w.name, w.owner.uid, w.owner.gid); err != nil {
+		return fmt.Errorf("failed to chown output file %q to %d:%d: %w", w.name, w.owner.uid, w.owner.gid, err)
+	}
+
+	return nil
+}
+
+// Abort - implements iohelpers.Aborter. The owner isn't changed for failed
+// renders.
+func (w *ownerWriter) Abort() error {
+	return iohelpers.Abort(w.WriteCloser)
+}
@@ -0,0 +1,87 @@
+package gomplate
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/hack-pad/hackpadfs"
+	"github.com/hack-pad/hackpadfs/mem"
+	"github.com/hairyhenderson/gomplate/v4/internal/datafs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookupID(t *testing.T) {
+	lookup := func(name string) (string, error) {
+		if name == "www-data" {
+			return "33", nil
+		}
+		return "", os.ErrNotExist
+	}
+
+	id, err := lookupID("1000", lookup)
+	require.NoError(t, err)
+	assert.Equal(t, 1000, id)
+
+	id, err = lookupID("www-data", lookup)
+	require.NoError(t, err)
+	assert.Equal(t, 33, id)
+
+	_, err = lookupID("nobody", lookup)
+	require.Error(t, err)
+
+	_, err = lookupID("-1", lookup)
+	require.Error(t, err)
+}
+
+// chownRecorder is a filesystem that records chown calls
+type chownRecorder struct {
+	hackpadfs.FS
+	chowned map[string][2]int
+}
+
+func (f *chownRecorder) Chown(name string, uid, gid int) error {
+	f.chowned[name] = [2]int{uid, gid}
+	return nil
+}
+
+func (f *chownRecorder) Stat(name string) (os.FileInfo, error) {
+	return hackpadfs.Stat(f.FS, name)
+}
+
+func TestWithOutOwner(t *testing.T) {
+	memfs, _ := mem.NewFS()
+	fsys := &chownRecorder{FS: memfs, chowned: map[string][2]int{}}
+	require.NoError(t, hackpadfs.WriteFullFile(fsys.FS, "out", []byte("hi"), 0o644))
+
+	ctx := datafs.ContextWithFSProvider(context.Background(),
+		datafs.WrappedFSProvider(fsys, "file", ""))
+
+	w := &closeRecorder{}
+	assert.Same(t, w, withOutOwner(ctx, "out", w))
+
+	ctx = contextWithOutOwner(ctx, &outOwner{uid: 1000, gid: -1})
+
+	// nothing is changed in dry-run mode
+	assert.Same(t, w, withOutOwner(contextWithDryRunReport(ctx, newDryRunReport()), "out", w))
+
+	// the owner isn't changed when the output is aborted
+	wc := withOutOwner(ctx, "out", w)
+	require.NoError(t, wc.(*ownerWriter).Abort())
+	assert.True(t, w.aborted)
+	assert.False(t, w.closed)
+	assert.Empty(t, fsys.chowned)
+
+	w = &closeRecorder{}
+	wc = withOutOwner(ctx, "out", w)
+	require.NoError(t, wc.Close())
+	assert.True(t, w.closed)
+	assert.Equal(t, map[string][2]int{"out": {1000, -1}}, fsys.chowned)
+
+	// deleted (empty) output files are skipped
+	w = &closeRecorder{}
+	wc = withOutOwner(ctx, "missing", w)
+	require.NoError(t, wc.Close())
+	assert.NotContains(t, fsys.chowned, "missing")
+}
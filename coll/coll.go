@@ -6,9 +6,13 @@
 package coll
 
 import (
+	"cmp"
 	"fmt"
+	"math"
 	"reflect"
 	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/hairyhenderson/gomplate/v4/conv"
 	iconv "github.com/hairyhenderson/gomplate/v4/internal/conv"
@@ -110,7 +114,12 @@ func Append(v interface{}, list interface{}) ([]interface{}, error) {
 		return nil, err
 	}
 
-	return append(l, v), nil
+	// l may be the caller's own slice, so copy it to avoid writing into its
+	// backing array
+	out := make([]interface{}, len(l), len(l)+1)
+	copy(out, l)
+
+	return append(out, v), nil
 }
 
 // Prepend v to the beginning of list. No matter what type of input slice or array list is, a new []interface{} is always returned.
@@ -123,7 +132,13 @@ func Prepend(v interface{}, list interface{}) ([]interface{}, error) {
 	return append([]interface{}{v}, l...), nil
 }
 
-// Uniq finds the unique values within list. No matter what type of input slice or array list is, a new []interface{} is always returned.
+// Uniq finds the unique values within list, keeping the first occurrence of
+// each. No matter what type of input slice or array list is, a new
+// []interface{} is always returned.
+//
+// When all values have the same type they're compared directly, but values
+// of mixed types are compared by their string form, so 1 and "1" are
+// considered duplicates.
 func Uniq(list interface{}) ([]interface{}, error) {
 	l, err := iconv.InterfaceSlice(list)
 	if err != nil {
@@ -131,14 +146,40 @@ func Uniq(list interface{}) ([]interface{}, error) {
 	}
 
 	out := []interface{}{}
+
+	if !mixedTypes(l) {
+		for _, v := range l {
+			if !Has(out, v) {
+				out = append(out, v)
+			}
+		}
+
+		return out, nil
+	}
+
+	seen := map[string]struct{}{}
 	for _, v := range l {
-		if !Has(out, v) {
+		s := conv.ToString(v)
+		if _, ok := seen[s]; !ok {
+			seen[s] = struct{}{}
 			out = append(out, v)
 		}
 	}
+
 	return out, nil
 }
 
+// mixedTypes reports whether the values in l don't all have the same type
+func mixedTypes(l []interface{}) bool {
+	for i := 1; i < len(l); i++ {
+		if reflect.TypeOf(l[i]) != reflect.TypeOf(l[0]) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // Reverse the list. No matter what type of input slice or array list is, a new []interface{} is always returned.
 func Reverse(list interface{}) ([]interface{}, error) {
 	l, err := iconv.InterfaceSlice(list)
@@ -153,6 +194,89 @@ func Reverse(list interface{}) ([]interface{}, error) {
 	return l, nil
 }
 
+// SubSlice returns the elements of list from index start up to (but not
+// including) end. As in Python, negative indexes count back from the end of the
+// list, and indexes out of range are clamped, so an empty slice is returned
+// rather than an error.
+func SubSlice(list interface{}, start, end int) ([]interface{}, error) {
+	l, err := iconv.InterfaceSlice(list)
+	if err != nil {
+		return nil, err
+	}
+
+	start = clampIndex(start, len(l))
+	end = clampIndex(end, len(l))
+
+	if start >= end {
+		return []interface{}{}, nil
+	}
+
+	out := make([]interface{}, end-start)
+	copy(out, l[start:end])
+
+	return out, nil
+}
+
+// clampIndex converts a (possibly negative) index into a slice of length n to
+// an index in the range [0, n]
+func clampIndex(i, n int) int {
+	if i < 0 {
+		i += n
+	}
+
+	return min(max(i, 0), n)
+}
+
+// maxRange is the most integers Range will return, so that a large range can't
+// exhaust memory
+const maxRange = 1 << 20
+
+// Range returns the integers from start up to (but not including) end, in
+// increments of step, as with Python's range. The step may be negative, for a
+// descending range. An empty slice is returned when end can't be reached from
+// start, and a step of 0 is an error, as is a range of more than 1048576
+// integers.
+func Range(start, end, step int64) ([]int64, error) {
+	if step == 0 {
+		return nil, fmt.Errorf("range step must not be 0")
+	}
+
+	n := rangeLen(start, end, step)
+	if n > maxRange {
+		return nil, fmt.Errorf("range from %d to %d in steps of %d is too large: at most %d integers may be returned", start, end, step, maxRange)
+	}
+
+	out := make([]int64, 0, n)
+	for i := start; (step > 0 && i < end) || (step < 0 && i > end); i += step {
+		out = append(out, i)
+
+		// stop before overflowing
+		if (step > 0 && i > math.MaxInt64-step) || (step < 0 && i < math.MinInt64-step) {
+			break
+		}
+	}
+
+	return out, nil
+}
+
+// rangeLen returns the number of integers Range will return. The arithmetic
+// is unsigned, so that there's no overflow even for the widest ranges.
+func rangeLen(start, end, step int64) uint64 {
+	var diff, inc uint64
+
+	switch {
+	case step > 0 && start < end:
+		diff, inc = uint64(end)-uint64(start), uint64(step)
+	case step < 0 && start > end:
+		// -step would overflow for math.MinInt64
+		diff, inc = uint64(start)-uint64(end), uint64(-(step+1))+1
+	default:
+		return 0
+	}
+
+	return (diff-1)/inc + 1
+}
+
 // Merge source maps (srcs) into dst. Precedence is in left-to-right order, with
 // the left-most values taking precedence over the right-most.
 func Merge(dst map[string]interface{}, srcs ...map[string]interface{}) (map[string]interface{}, error) {
@@ -237,8 +361,25 @@ func mergeValues(d map[string]interface{}, o map[string]interface{}) map[string]
 // non-empty key is given and the list elements are maps, this will attempt to
 // sort by the values of those entries.
 //
+// Numbers (and strings that look like numbers) are sorted numerically, and
+// before any other values, which are sorted lexically. Elements without the
+// key are sorted last. The sort is stable.
+//
 // Does not modify the input list.
 func Sort(key string, list interface{}) (out []interface{}, err error) {
+	return sortList(key, list, false)
+}
+
+// SortDesc is like Sort, but sorts in descending order. Elements without the
+// key are still sorted last, and the sort is stable, so equal elements retain
+// their original order.
+//
+// Does not modify the input list.
+func SortDesc(key string, list interface{}) (out []interface{}, err error) {
+	return sortList(key, list, true)
+}
+
+func sortList(key string, list interface{}, desc bool) ([]interface{}, error) {
 	if list == nil {
 		return nil, nil
 	}
@@ -253,49 +394,159 @@ func Sort(key string, list interface{}) (out []interface{}, err error) {
 		// make a copy so the original is unmodified
 		copy(s, ia)
 		sort.SliceStable(s, func(i, j int) bool {
-			return lessThan(key)(s[i], s[j])
+			return lessThan(key, desc)(s[i], s[j])
 		})
 		return s, nil
 	}
 	return ia, nil
 }
 
-// lessThan - compare two values of the same type
-func lessThan(key string) func(left, right interface{}) bool {
+// GroupBy groups the elements of list by the value of the given key, returning
+// a map of the stringified values to the elements that have them. Elements
+// that are not maps, or that don't have the key (or have a nil value for it),
+// are grouped under the empty string. The order of the elements within each
+// group is preserved.
+//
+// Does not modify the input list.
+func GroupBy(key string, list interface{}) (map[string][]interface{}, error) {
+	l, err := iconv.InterfaceSlice(list)
+	if err != nil {
+		return nil, err
+	}
+
+	out := map[string][]interface{}{}
+	for _, v := range l {
+		k := groupKey(key, v)
+		out[k] = append(out[k], v)
+	}
+
+	return out, nil
+}
+
+// groupKey returns the stringified value of the key in v, or "" if v isn't a
+// map with a string key type, or doesn't contain a non-nil value for the key
+func groupKey(key string, v interface{}) string {
+	val := reflect.Indirect(reflect.ValueOf(v))
+	if val.Kind() != reflect.Map || val.Type().Key().Kind() != reflect.String {
+		return ""
+	}
+
+	kv := val.MapIndex(reflect.ValueOf(key).Convert(val.Type().Key()))
+	if !kv.IsValid() {
+		return ""
+	}
+
+	i := kv.Interface()
+	if i == nil {
+		return ""
+	}
+
+	return conv.ToString(i)
+}
+
+// lessThan - compare two values of the same type, or the values of the given
+// key or field when they're maps or structs
+func lessThan(key string, desc bool) func(left, right interface{}) bool {
 	return func(left, right interface{}) bool {
-		val := reflect.Indirect(reflect.ValueOf(left))
-		rval := reflect.Indirect(reflect.ValueOf(right))
-		switch val.Kind() {
-		case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int:
-			return val.Int() < rval.Int()
-		case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint, reflect.Uint64:
-			return val.Uint() < rval.Uint()
-		case reflect.Float32, reflect.Float64:
-			return val.Float() < rval.Float()
-		case reflect.String:
-			return val.String() < rval.String()
-		case reflect.MapOf(
-			reflect.TypeOf(reflect.String),
-			reflect.TypeOf(reflect.Interface),
-		).Kind():
-			kval := reflect.ValueOf(key)
-			if !val.MapIndex(kval).IsValid() {
-				return false
-			}
-			newleft := val.MapIndex(kval).Interface()
-			newright := rval.MapIndex(kval).Interface()
-			return lessThan("")(newleft, newright)
-		case reflect.Struct:
-			if !val.FieldByName(key).IsValid() {
-				return false
-			}
-			newleft := val.FieldByName(key).Interface()
-			newright := rval.FieldByName(key).Interface()
-			return lessThan("")(newleft, newright)
-		default:
-			// it's not really comparable, so...
-			return false
+		lval, lok := sortValue(key, left)
+		rval, rok := sortValue(key, right)
+
+		// missing values always sort last
+		if !lok || !rok {
+			return lok && !rok
+		}
+
+		c := compareValues(lval, rval)
+		if desc {
+			return c > 0
+		}
+		return c < 0
+	}
+}
+
+// sortValue returns the value to sort v by - the value of the key or field
+// for maps and structs, or v itself otherwise. Returns false when a map or
+// struct doesn't have the key, or v is nil.
+func sortValue(key string, v interface{}) (reflect.Value, bool) {
+	val := reflect.Indirect(reflect.ValueOf(v))
+	switch val.Kind() {
+	case reflect.Invalid:
+		return val, false
+	case reflect.Map:
+		if val.Type().Key().Kind() != reflect.String {
+			return val, false
+		}
+		val = val.MapIndex(reflect.ValueOf(key).Convert(val.Type().Key()))
+	case reflect.Struct:
+		val = val.FieldByName(key)
+	default:
+		return val, true
+	}
+
+	if !val.IsValid() {
+		return val, false
+	}
+
+	val = reflect.Indirect(val)
+	if val.Kind() == reflect.Interface {
+		val = val.Elem()
+	}
+
+	return val, val.IsValid()
+}
+
+// compareValues compares two values, returning -1, 0, or 1. Numbers (and
+// numeric strings) are compared numerically and are less than all other
+// values, which are compared lexically by their string representations.
+func compareValues(left, right reflect.Value) int {
+	lf, lnum := sortNumber(left)
+	rf, rnum := sortNumber(right)
+
+	switch {
+	case lnum && rnum:
+		return cmp.Compare(lf, rf)
+	case lnum:
+		return -1
+	case rnum:
+		return 1
+	}
+
+	// other types (maps, slices, etc) aren't really comparable, so compare
+	// them all as equal
+	if !sortable(left.Kind()) || !sortable(right.Kind()) {
+		return 0
+	}
+
+	return strings.Compare(conv.ToString(left.Interface()), conv.ToString(right.Interface()))
+}
+
+func sortable(k reflect.Kind) bool {
+	switch k {
+	case reflect.String, reflect.Bool:
+		return true
+	default:
+		return false
+	}
+}
+
+// sortNumber returns the value as a float64, if it's a number or a string that
+// can be parsed as one
+func sortNumber(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int:
+		return float64(v.Int()), true
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	case reflect.String:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v.String()), 64)
+		if err != nil || math.IsNaN(f) {
+			return 0, false
 		}
+		return f, true
+	default:
+		return 0, false
 	}
 }
 
@@ -2,6 +2,7 @@ package coll
 
 import (
 	"fmt"
+	"math"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -133,6 +134,30 @@ func TestValues(t *testing.T) {
 	assert.EqualValues(t, expected, values)
 }
 
+func TestKeysValues_Deterministic(t *testing.T) {
+	// map iteration order is randomized, so a large map read many times would
+	// expose any dependence on it
+	in := map[string]interface{}{}
+	expectedKeys := make([]string, 100)
+	expectedValues := make([]interface{}, 100)
+	for i := range 100 {
+		k := fmt.Sprintf("k%03d", i)
+		in[k] = i
+		expectedKeys[i] = k
+		expectedValues[i] = i
+	}
+
+	for range 50 {
+		keys, err := Keys(in)
+		require.NoError(t, err)
+		require.Equal(t, expectedKeys, keys)
+
+		values, err := Values(in)
+		require.NoError(t, err)
+		require.Equal(t, expectedValues, values)
+	}
+}
+
 func TestAppend(t *testing.T) {
 	out, err := Append(42, []interface{}{})
 	require.NoError(t, err)
@@ -150,6 +175,17 @@ func TestAppend(t *testing.T) {
 	out, err = Append("baz", []string{"foo", "bar"})
 	require.NoError(t, err)
 	assert.EqualValues(t, []interface{}{"foo", "bar", "baz"}, out)
+
+	// the input isn't modified, even when it has spare capacity
+	in := make([]interface{}, 1, 2)
+	in[0] = "foo"
+	a, err := Append("bar", in)
+	require.NoError(t, err)
+	b, err := Append("baz", in)
+	require.NoError(t, err)
+	assert.EqualValues(t, []interface{}{"foo", "bar"}, a)
+	assert.EqualValues(t, []interface{}{"foo", "baz"}, b)
+	assert.Len(t, in, 1)
 }
 
 func TestPrepend(t *testing.T) {
@@ -172,13 +208,36 @@ func TestPrepend(t *testing.T) {
 }
 
 func TestUniq(t *testing.T) {
-	out, err := Uniq([]interface{}{1, 2, 3, 1, true, false, true, "1", 2})
+	out, err := Uniq([]interface{}{1, 2, 3, 1, 2})
 	require.NoError(t, err)
-	assert.EqualValues(t, []interface{}{1, 2, 3, true, false, "1"}, out)
+	assert.EqualValues(t, []interface{}{1, 2, 3}, out)
 
 	out, err = Uniq([]string{"one", "two", "one", "three"})
 	require.NoError(t, err)
 	assert.EqualValues(t, []interface{}{"one", "two", "three"}, out)
+
+	out, err = Uniq([]interface{}{})
+	require.NoError(t, err)
+	assert.EqualValues(t, []interface{}{}, out)
+
+	// values of different types are compared by their string form
+	out, err = Uniq([]interface{}{1, 2, 3, 1, true, false, "true", "1", 2.0, 2})
+	require.NoError(t, err)
+	assert.EqualValues(t, []interface{}{1, 2, 3, true, false}, out)
+
+	out, err = Uniq([]interface{}{
+		map[string]interface{}{"a": 1},
+		map[string]interface{}{"a": 1},
+		[]interface{}{"b"},
+	})
+	require.NoError(t, err)
+	assert.EqualValues(t, []interface{}{map[string]interface{}{"a": 1}, []interface{}{"b"}}, out)
+
+	// the input isn't modified
+	in := []interface{}{"a", "b", "a"}
+	_, err = Uniq(in)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"a", "b", "a"}, in)
 }
 
 func TestReverse(t *testing.T) {
@@ -199,6 +258,112 @@ func TestReverse(t *testing.T) {
 	assert.EqualValues(t, []interface{}{4, 3, 2, 1}, out)
 }
 
+func TestSubSlice(t *testing.T) {
+	in := []int{0, 1, 2, 3, 4, 5}
+
+	testdata := []struct {
+		expected   []interface{}
+		start, end int
+	}{
+		{[]interface{}{1, 2}, 1, 3},
+		{[]interface{}{0, 1, 2, 3, 4, 5}, 0, 6},
+		{[]interface{}{3, 4}, -3, -1},
+		{[]interface{}{4, 5}, -2, 6},
+		{[]interface{}{0, 1, 2, 3, 4, 5}, -10, 10},
+		{[]interface{}{}, 3, 3},
+		{[]interface{}{}, 4, 2},
+		{[]interface{}{}, 7, 10},
+	}
+
+	for _, d := range testdata {
+		t.Run(fmt.Sprintf("%d:%d", d.start, d.end), func(t *testing.T) {
+			out, err := SubSlice(in, d.start, d.end)
+			require.NoError(t, err)
+			assert.Equal(t, d.expected, out)
+		})
+	}
+
+	// the result doesn't share the input's backing array
+	l := []interface{}{"a", "b", "c"}
+	out, err := SubSlice(l, 0, 2)
+	require.NoError(t, err)
+	out[0] = "z"
+	assert.Equal(t, "a", l[0])
+
+	_, err = SubSlice("not a list", 0, 1)
+	require.Error(t, err)
+}
+
+func TestRange(t *testing.T) {
+	testdata := []struct {
+		expected         []int64
+		start, end, step int64
+	}{
+		{[]int64{0, 2, 4, 6, 8}, 0, 10, 2},
+		{[]int64{0, 1, 2}, 0, 3, 1},
+		{[]int64{10, 8, 6, 4, 2}, 10, 0, -2},
+		{[]int64{-1, -2}, -1, -3, -1},
+		{[]int64{}, 0, 0, 1},
+		{[]int64{}, 10, 0, 1},
+		{[]int64{}, 0, 10, -1},
+		{[]int64{math.MaxInt64 - 1}, math.MaxInt64 - 1, math.MaxInt64, 2},
+		{[]int64{math.MinInt64 + 1}, math.MinInt64 + 1, math.MinInt64, -2},
+	}
+
+	for _, d := range testdata {
+		t.Run(fmt.Sprintf("%d:%d:%d", d.start, d.end, d.step), func(t *testing.T) {
+			out, err := Range(d.start, d.end, d.step)
+			require.NoError(t, err)
+			assert.Equal(t, d.expected, out)
+		})
+	}
+
+	_, err := Range(0, 10, 0)
+	require.ErrorContains(t, err, "step must not be 0")
+
+	out, err := Range(0, maxRange, 1)
+	require.NoError(t, err)
+	assert.Len(t, out, maxRange)
+
+	// large ranges are an error, rather than exhausting memory
+	_, err = Range(0, maxRange+1, 1)
+	require.ErrorContains(t, err, "too large")
+
+	_, err = Range(0, 9e18, 1)
+	require.ErrorContains(t, err, "too large")
+
+	_, err = Range(math.MaxInt64, math.MinInt64, -1)
+	require.ErrorContains(t, err, "too large")
+
+	// but a large step can make a wide range small
+	out, err = Range(math.MinInt64, math.MaxInt64, math.MaxInt64)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{math.MinInt64, -1, math.MaxInt64 - 1}, out)
+}
+
+func TestRangeLen(t *testing.T) {
+	testdata := []struct {
+		start, end, step int64
+	}{
+		{0, 10, 2},
+		{0, 10, 3},
+		{10, 0, -3},
+		{0, 0, 1},
+		{10, 0, 1},
+		{0, 10, -1},
+		{math.MaxInt64 - 1, math.MaxInt64, 2},
+		{math.MinInt64 + 1, math.MinInt64, -2},
+		{math.MinInt64, math.MaxInt64, math.MaxInt64},
+		{math.MaxInt64, math.MinInt64, math.MinInt64},
+	}
+
+	for _, d := range testdata {
+		out, err := Range(d.start, d.end, d.step)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(len(out)), rangeLen(d.start, d.end, d.step), "%d:%d:%d", d.start, d.end, d.step)
+	}
+}
+
 func TestMerge(t *testing.T) {
 	dst := map[string]interface{}{}
 	src := map[string]interface{}{}
@@ -282,6 +447,54 @@ func TestMerge(t *testing.T) {
 	assert.EqualValues(t, expected, out)
 }
 
+func TestMergeLayered(t *testing.T) {
+	base := map[string]interface{}{
+		"name": "app",
+		"db": map[string]interface{}{
+			"host": "localhost",
+			"port": 5432,
+			"opts": map[string]interface{}{"ssl": false, "timeout": "5s"},
+		},
+		"hosts":  []interface{}{"a", "b", "c"},
+		"limits": map[string]interface{}{"cpu": 1},
+		"tags":   "none",
+	}
+	prod := map[string]interface{}{
+		"db": map[string]interface{}{
+			"host": "db.example.com",
+			"opts": map[string]interface{}{"ssl": true},
+		},
+		// slices are replaced, not merged
+		"hosts": []interface{}{"x"},
+	}
+	local := map[string]interface{}{
+		"db": map[string]interface{}{"port": 6543},
+		// when types conflict, the value with the highest precedence wins,
+		// whether or not it's a map
+		"limits": "unlimited",
+		"tags":   map[string]interface{}{"env": "local"},
+	}
+
+	out, err := Merge(local, prod, base)
+	require.NoError(t, err)
+	assert.EqualValues(t, map[string]interface{}{
+		"name": "app",
+		"db": map[string]interface{}{
+			"host": "db.example.com",
+			"port": 6543,
+			"opts": map[string]interface{}{"ssl": true, "timeout": "5s"},
+		},
+		"hosts":  []interface{}{"x"},
+		"limits": "unlimited",
+		"tags":   map[string]interface{}{"env": "local"},
+	}, out)
+
+	// the inputs aren't modified
+	assert.Equal(t, map[string]interface{}{"ssl": false, "timeout": "5s"},
+		base["db"].(map[string]interface{})["opts"])
+	assert.Equal(t, map[string]interface{}{"port": 6543}, local["db"])
+}
+
 type coords struct {
 	X, Y int
 }
@@ -340,12 +553,33 @@ func TestLessThan(t *testing.T) {
 		{key: "Y", left: &coords{1, 1}, right: &coords{-1, 2}, out: true},
 		{left: &coords{1, 1}, right: &coords{-1, 2}},
 		{key: "foo", left: &coords{1, 1}, right: &coords{-1, 2}},
+		{left: "10", right: "9"},
+		{left: "9", right: "10", out: true},
+		{left: "10", right: "a", out: true},
+		{left: "a", right: "10"},
+		{
+			key:   "foo",
+			left:  map[string]interface{}{"foo": 1},
+			right: map[string]interface{}{"foo": 2.5},
+			out:   true,
+		},
+		{
+			key:   "foo",
+			left:  map[string]interface{}{"foo": 1},
+			right: map[string]interface{}{"bar": 2},
+			out:   true,
+		},
+		{
+			key:   "foo",
+			left:  map[string]interface{}{"bar": 1},
+			right: map[string]interface{}{"foo": 2},
+		},
 	}
 
 	for _, d := range data {
 		d := d
 		t.Run(fmt.Sprintf(`LessThan("%s")(<%T>%#v,%#v)==%v`, d.key, d.left, d.left, d.right, d.out), func(t *testing.T) {
-			assert.Equal(t, d.out, lessThan(d.key)(d.left, d.right))
+			assert.Equal(t, d.out, lessThan(d.key, false)(d.left, d.right))
 		})
 	}
 }
@@ -471,6 +705,79 @@ func TestSort(t *testing.T) {
 	}
 }
 
+func TestSort_NumericAndMissing(t *testing.T) {
+	out, err := Sort("", []string{"10", "9", "b", "1.5", "a"})
+	require.NoError(t, err)
+	assert.EqualValues(t, []interface{}{"1.5", "9", "10", "a", "b"}, out)
+
+	// mixed numeric types, and missing keys sorted last
+	in := []interface{}{
+		map[string]interface{}{"name": "a", "size": 10},
+		map[string]interface{}{"name": "b"},
+		map[string]interface{}{"name": "c", "size": 2.5},
+		map[string]interface{}{"name": "d", "size": "3"},
+		map[string]interface{}{"name": "e", "size": 10},
+	}
+
+	out, err = Sort("size", in)
+	require.NoError(t, err)
+	assert.EqualValues(t, []interface{}{in[2], in[3], in[0], in[4], in[1]}, out)
+
+	// descending order is stable too
+	out, err = SortDesc("size", in)
+	require.NoError(t, err)
+	assert.EqualValues(t, []interface{}{in[0], in[4], in[3], in[2], in[1]}, out)
+
+	out, err = SortDesc("", []interface{}{"b", "c", "a"})
+	require.NoError(t, err)
+	assert.EqualValues(t, []interface{}{"c", "b", "a"}, out)
+
+	out, err = SortDesc("", nil)
+	require.NoError(t, err)
+	assert.Nil(t, out)
+}
+
+func TestGroupBy(t *testing.T) {
+	_, err := GroupBy("region", 42)
+	require.Error(t, err)
+
+	out, err := GroupBy("region", []interface{}{})
+	require.NoError(t, err)
+	assert.Empty(t, out)
+
+	in := []interface{}{
+		map[string]interface{}{"name": "a", "region": "us-east"},
+		map[string]interface{}{"name": "b", "region": "eu-west"},
+		map[string]interface{}{"name": "c"},
+		map[string]interface{}{"name": "d", "region": "us-east"},
+		map[string]interface{}{"name": "e", "region": nil},
+		map[string]interface{}{"name": "f", "region": 42},
+		"not a map",
+		map[string]interface{}{"name": "g", "region": "eu-west"},
+	}
+
+	out, err = GroupBy("region", in)
+	require.NoError(t, err)
+	assert.EqualValues(t, map[string][]interface{}{
+		"us-east": {in[0], in[3]},
+		"eu-west": {in[1], in[7]},
+		"42":      {in[5]},
+		"":        {in[2], in[4], in[6]},
+	}, out)
+
+	// other map types are supported
+	out, err = GroupBy("y", []map[string]int{
+		{"x": 1, "y": 2},
+		{"x": 2, "y": 1},
+		{"x": 3, "y": 2},
+	})
+	require.NoError(t, err)
+	assert.EqualValues(t, map[string][]interface{}{
+		"1": {map[string]int{"x": 2, "y": 1}},
+		"2": {map[string]int{"x": 1, "y": 2}, map[string]int{"x": 3, "y": 2}},
+	}, out)
+}
+
 func TestFlatten(t *testing.T) {
 	data := []struct {
 		in       interface{}
@@ -45,12 +45,17 @@ func JQ(ctx context.Context, jqExpr string, in interface{}) (interface{}, error)
 }
 
 // jqConvertType converts the input to a map[string]interface{}, []interface{},
-// or other supported primitive JSON types.
+// or other supported primitive JSON types. Maps and slices are converted
+// recursively, since gojq doesn't support typed containers (like []string)
+// anywhere in its input.
 func jqConvertType(in interface{}) (interface{}, error) {
 	// if it's already a supported type, pass it through
-	switch in.(type) {
-	case map[string]interface{}, []interface{},
-		string, []byte,
+	switch v := in.(type) {
+	case map[string]interface{}:
+		return jqConvertMap(reflect.ValueOf(v))
+	case []interface{}:
+		return jqConvertSlice(reflect.ValueOf(v))
+	case string, []byte,
 		nil, bool,
 		int, int8, int16, int32, int64,
 		uint, uint8, uint16, uint32, uint64,
@@ -63,22 +68,27 @@ func jqConvertType(in interface{}) (interface{}, error) {
 
 	// pointers need to be dereferenced first
 	if inType.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return nil, nil
+		}
+
 		inType = inType.Elem()
 		value = value.Elem()
 	}
 
-	mapType := reflect.TypeOf(map[string]interface{}{})
-	sliceType := reflect.TypeOf([]interface{}{})
-	// if it can be converted to a map or slice, do that
-	if inType.ConvertibleTo(mapType) {
-		return value.Convert(mapType).Interface(), nil
-	} else if inType.ConvertibleTo(sliceType) {
-		return value.Convert(sliceType).Interface(), nil
-	}
-
-	// if it's a struct, the simplest (though not necessarily most efficient)
-	// is to JSON marshal/unmarshal it
-	if inType.Kind() == reflect.Struct {
+	switch inType.Kind() {
+	case reflect.Map:
+		if inType.Key().Kind() == reflect.String {
+			return jqConvertMap(value)
+		}
+	case reflect.Slice, reflect.Array:
+		// byte slices are passed through as strings
+		if inType.Elem().Kind() != reflect.Uint8 {
+			return jqConvertSlice(value)
+		}
+	case reflect.Struct:
+		// the simplest (though not necessarily most efficient) way to convert
+		// a struct is to JSON marshal/unmarshal it
 		b, err := json.Marshal(in)
 		if err != nil {
 			return nil, fmt.Errorf("json marshal struct: %w", err)
@@ -94,3 +104,37 @@ func jqConvertType(in interface{}) (interface{}, error) {
 	// we maybe don't need to convert the value, so return it as-is
 	return in, nil
 }
+
+// jqConvertMap converts a map with string keys to a map[string]interface{},
+// converting each value
+func jqConvertMap(value reflect.Value) (interface{}, error) {
+	m := make(map[string]interface{}, value.Len())
+
+	iter := value.MapRange()
+	for iter.Next() {
+		v, err := jqConvertType(iter.Value().Interface())
+		if err != nil {
+			return nil, err
+		}
+
+		m[iter.Key().String()] = v
+	}
+
+	return m, nil
+}
+
+// jqConvertSlice converts a slice or array to a []interface{}, converting each
+// element
+func jqConvertSlice(value reflect.Value) (interface{}, error) {
+	a := make([]interface{}, value.Len())
+	for i := range a {
+		v, err := jqConvertType(value.Index(i).Interface())
+		if err != nil {
+			return nil, err
+		}
+
+		a[i] = v
+	}
+
+	return a, nil
+}
@@ -206,6 +206,29 @@ func TestJQ_typeConversions(t *testing.T) {
 	out, err = JQ(ctx, ".", byteArrayType("hello"))
 	require.NoError(t, err)
 	assert.EqualValues(t, "hello", out)
+
+	// typed slices and maps, including nested ones
+	out, err = JQ(ctx, ".[1]", []string{"a", "b", "c"})
+	require.NoError(t, err)
+	assert.Equal(t, "b", out)
+
+	out, err = JQ(ctx, ".[] | select(. > 1)", [3]int{1, 2, 3})
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{2, 3}, out)
+
+	out, err = JQ(ctx, ".a.b | length", map[string]interface{}{
+		"a": map[string][]string{"b": {"x", "y"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, out)
+
+	out, err = JQ(ctx, "keys", map[string]string{"z": "1", "y": "2"})
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"y", "z"}, out)
+
+	out, err = JQ(ctx, ".", []interface{}{[]string(nil), (*bicycleType)(nil)})
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{[]interface{}{}, nil}, out)
 }
 
 func TestJQConvertType_passthroughTypes(t *testing.T) {
@@ -0,0 +1,128 @@
+package coll
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// JSONPointer - returns the value in the given object referenced by the JSON
+// Pointer p, as defined in RFC 6901
+func JSONPointer(p string, in interface{}) (interface{}, error) {
+	tokens, err := parsePointer(p)
+	if err != nil {
+		return nil, err
+	}
+
+	v := reflect.ValueOf(in)
+	for i, tok := range tokens {
+		// the pointer to the value being indexed, for error messages
+		parent := "/" + strings.Join(escapeTokens(tokens[:i]), "/")
+		if i == 0 {
+			parent = ""
+		}
+
+		v = indirectValue(v)
+		if !v.IsValid() {
+			return nil, fmt.Errorf("JSON pointer %q: can't index into null value at %q", p, parent)
+		}
+
+		switch v.Kind() {
+		case reflect.Map:
+			if v.Type().Key().Kind() != reflect.String {
+				return nil, fmt.Errorf("JSON pointer %q: can't index into map with %s keys at %q", p, v.Type().Key(), parent)
+			}
+
+			v = v.MapIndex(reflect.ValueOf(tok).Convert(v.Type().Key()))
+			if !v.IsValid() {
+				return nil, fmt.Errorf("JSON pointer %q: key %q not found", p, tok)
+			}
+		case reflect.Slice, reflect.Array:
+			idx, err := arrayIndex(tok)
+			if err != nil {
+				return nil, fmt.Errorf("JSON pointer %q: %w", p, err)
+			}
+
+			if idx >= v.Len() {
+				return nil, fmt.Errorf("JSON pointer %q: index %d out of range (length %d)", p, idx, v.Len())
+			}
+
+			v = v.Index(idx)
+		default:
+			return nil, fmt.Errorf("JSON pointer %q: can't index into %s value at %q", p, v.Kind(), parent)
+		}
+	}
+
+	v = indirectValue(v)
+	if !v.IsValid() {
+		return nil, nil
+	}
+
+	return v.Interface(), nil
+}
+
+// parsePointer splits a JSON Pointer into its unescaped reference tokens
+func parsePointer(p string) ([]string, error) {
+	if p == "" {
+		return nil, nil
+	}
+
+	if p[0] != '/' {
+		return nil, fmt.Errorf("invalid JSON pointer %q: must be empty or start with '/'", p)
+	}
+
+	tokens := strings.Split(p[1:], "/")
+	for i, tok := range tokens {
+		for j := 0; j < len(tok); j++ {
+			if tok[j] == '~' && (j == len(tok)-1 || (tok[j+1] != '0' && tok[j+1] != '1')) {
+				return nil, fmt.Errorf("invalid JSON pointer %q: '~' must be followed by '0' or '1'", p)
+			}
+		}
+
+		// ~1 must be replaced before ~0, so that "~01" becomes "~1", not "/"
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(tok, "~1", "/"), "~0", "~")
+	}
+
+	return tokens, nil
+}
+
+func escapeTokens(tokens []string) []string {
+	out := make([]string, len(tokens))
+	for i, tok := range tokens {
+		out[i] = strings.ReplaceAll(strings.ReplaceAll(tok, "~", "~0"), "/", "~1")
+	}
+
+	return out
+}
+
+// arrayIndex parses an array index token - only non-negative decimal numbers
+// without leading zeros are allowed
+func arrayIndex(tok string) (int, error) {
+	if tok == "-" {
+		return 0, fmt.Errorf("index '-' refers to a nonexistent element past the end of the array")
+	}
+
+	if tok == "" || (len(tok) > 1 && tok[0] == '0') || strings.TrimLeft(tok, "0123456789") != "" {
+		return 0, fmt.Errorf("invalid array index %q", tok)
+	}
+
+	idx, err := strconv.Atoi(tok)
+	if err != nil {
+		return 0, fmt.Errorf("invalid array index %q: %w", tok, err)
+	}
+
+	return idx, nil
+}
+
+func indirectValue(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Interface || v.Kind() == reflect.Pointer) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+
+		v = v.Elem()
+	}
+
+	return v
+}
@@ -0,0 +1,113 @@
+package coll
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONPointer(t *testing.T) {
+	// the example document from RFC 6901, section 5
+	in := m{
+		"foo":  ar{"bar", "baz"},
+		"":     0,
+		"a/b":  1,
+		"c%d":  2,
+		"e^f":  3,
+		"g|h":  4,
+		"i\\j": 5,
+		"k\"l": 6,
+		" ":    7,
+		"m~n":  8,
+	}
+
+	testdata := []struct {
+		p        string
+		expected interface{}
+	}{
+		{"", in},
+		{"/foo", ar{"bar", "baz"}},
+		{"/foo/0", "bar"},
+		{"/", 0},
+		{"/a~1b", 1},
+		{"/c%d", 2},
+		{"/e^f", 3},
+		{"/g|h", 4},
+		{"/i\\j", 5},
+		{"/k\"l", 6},
+		{"/ ", 7},
+		{"/m~0n", 8},
+	}
+
+	for _, d := range testdata {
+		t.Run(d.p, func(t *testing.T) {
+			out, err := JSONPointer(d.p, in)
+			require.NoError(t, err)
+			assert.Equal(t, d.expected, out)
+		})
+	}
+
+	nested := m{
+		"a": m{
+			"b.c": []map[string]int{{"d": 1}, {"d": 2}},
+			"~01": "tilde",
+			"nil": nil,
+		},
+	}
+
+	out, err := JSONPointer("/a/b.c/1/d", nested)
+	require.NoError(t, err)
+	assert.Equal(t, 2, out)
+
+	out, err = JSONPointer("/a/~001", nested)
+	require.NoError(t, err)
+	assert.Equal(t, "tilde", out)
+
+	out, err = JSONPointer("/a/nil", nested)
+	require.NoError(t, err)
+	assert.Nil(t, out)
+
+	out, err = JSONPointer("/0", [2]string{"x", "y"})
+	require.NoError(t, err)
+	assert.Equal(t, "x", out)
+}
+
+func TestJSONPointer_Errors(t *testing.T) {
+	in := m{
+		"a": m{
+			"b":   ar{"x", "y"},
+			"s":   "str",
+			"nil": nil,
+			"int": map[int]string{1: "one"},
+		},
+	}
+
+	testdata := []struct {
+		p   string
+		err string
+	}{
+		{"a/b", `invalid JSON pointer "a/b": must be empty or start with '/'`},
+		{"/a~2", `invalid JSON pointer "/a~2": '~' must be followed by '0' or '1'`},
+		{"/a~", `invalid JSON pointer "/a~": '~' must be followed by '0' or '1'`},
+		{"/missing", `JSON pointer "/missing": key "missing" not found`},
+		{"/a/b/2", `JSON pointer "/a/b/2": index 2 out of range (length 2)`},
+		{"/a/b/-", `JSON pointer "/a/b/-": index '-' refers to a nonexistent element past the end of the array`},
+		{"/a/b/01", `JSON pointer "/a/b/01": invalid array index "01"`},
+		{"/a/b/-1", `JSON pointer "/a/b/-1": invalid array index "-1"`},
+		{"/a/b/x", `JSON pointer "/a/b/x": invalid array index "x"`},
+		{"/a/s/0", `JSON pointer "/a/s/0": can't index into string value at "/a/s"`},
+		{"/a/nil/0", `JSON pointer "/a/nil/0": can't index into null value at "/a/nil"`},
+		{"/a/int/1", `JSON pointer "/a/int/1": can't index into map with int keys at "/a/int"`},
+	}
+
+	for _, d := range testdata {
+		t.Run(d.p, func(t *testing.T) {
+			_, err := JSONPointer(d.p, in)
+			require.EqualError(t, err, d.err)
+		})
+	}
+
+	_, err := JSONPointer("/0", nil)
+	require.EqualError(t, err, `JSON pointer "/0": can't index into null value at ""`)
+}
@@ -5,6 +5,8 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"path"
+	"path/filepath"
 	"slices"
 	"strconv"
 	"strings"
@@ -55,6 +57,7 @@ type Config struct {
 	Plugins     map[string]PluginConfig `yaml:"plugins,omitempty"`
 
 	Input                 string   `yaml:"in,omitempty"`
+	InputURL              string   `yaml:"inURL,omitempty"`
 	InputDir              string   `yaml:"inputDir,omitempty"`
 	InputFiles            []string `yaml:"inputFiles,omitempty,flow"`
 	ExcludeGlob           []string `yaml:"excludes,omitempty"`
@@ -65,6 +68,16 @@ type Config struct {
 	OutputFiles []string `yaml:"outputFiles,omitempty,flow"`
 	OutMode     string   `yaml:"chmod,omitempty"`
 
+	// OutModeGlobs - per-glob output file modes. The first glob that matches
+	// an output path determines its mode, falling back to OutMode.
+	OutModeGlobs []OutModeGlob `yaml:"chmodGlobs,omitempty"`
+
+	// OutUser, OutGroup - the user and group (names or numeric IDs) to set as
+	// the owner of output files, after they're written. Ownership can only be
+	// changed when running as root - otherwise a warning is logged.
+	OutUser  string `yaml:"chownUser,omitempty"`
+	OutGroup string `yaml:"chownGroup,omitempty"`
+
 	LDelim string `yaml:"leftDelim,omitempty"`
 	RDelim string `yaml:"rightDelim,omitempty"`
 
@@ -72,10 +85,77 @@ type Config struct {
 
 	PostExec []string `yaml:"postExec,omitempty,flow"`
 
+	// PostExecEach - a command to run after each output file is written. The
+	// file's name replaces any "{}" arguments, or is appended when there are
+	// none.
+	PostExecEach []string `yaml:"postExecEach,omitempty,flow"`
+
 	PluginTimeout time.Duration `yaml:"pluginTimeout,omitempty"`
 
+	// TemplateTimeout - how long to wait for a remote nested template (see
+	// Templates) to be read. Defaults to 30 seconds.
+	TemplateTimeout time.Duration `yaml:"templateTimeout,omitempty"`
+
+	// Parallelism - the maximum number of templates to render concurrently.
+	// Defaults to GOMAXPROCS when zero.
+	Parallelism int `yaml:"parallelism,omitempty"`
+
 	ExecPipe     bool `yaml:"execPipe,omitempty"`
 	Experimental bool `yaml:"experimental,omitempty"`
+
+	// Watch - when true, keep running after rendering, and re-render whenever
+	// an input file or local file datasource changes
+	Watch bool `yaml:"watch,omitempty"`
+
+	// DryRun - when true, output files aren't written. Instead, a diff against
+	// each file's current content and a summary are written to Stdout.
+	DryRun bool `yaml:"dryRun,omitempty"`
+	// FailOnChange - when true in dry-run mode, fail if any output file would
+	// be created or changed
+	FailOnChange bool `yaml:"failOnChange,omitempty"`
+
+	// Force - when true, output files are always written (and chmodded),
+	// even when their content is unchanged
+	Force bool `yaml:"force,omitempty"`
+
+	// Stream - when true, output is written directly to its destination as
+	// it's rendered, instead of to a temporary file that's moved into place
+	// once rendering succeeds. Can't be used with DryRun.
+	Stream bool `yaml:"stream,omitempty"`
+
+	// DeleteEmpty - when true, an existing output file is deleted when the
+	// template renders to empty (whitespace-only) output, instead of being left
+	// untouched. Can't be used with Stream.
+	DeleteEmpty bool `yaml:"deleteEmpty,omitempty"`
+
+	// FrontMatter - when true, a YAML front matter block at the top of each
+	// template file (delimited by "---" lines) is parsed, and can override the
+	// template's output file, output mode, and datasources
+	FrontMatter bool `yaml:"frontMatter,omitempty"`
+
+	// Combine - when true, the InputFiles are parsed as one set of templates
+	// and rendered once, to a single output file. The last file is the
+	// entrypoint, and the others are only parsed for the templates they
+	// define.
+	Combine bool `yaml:"combine,omitempty"`
+
+	// MetricsJSON - when true, the render metrics are written to Stderr as a
+	// line of JSON after rendering
+	MetricsJSON bool `yaml:"metricsJSON,omitempty"`
+
+	// NoCache - when true, datasources are read every time they're used,
+	// instead of each unique datasource URL being read at most once per run
+	NoCache bool `yaml:"noCache,omitempty"`
+
+	// ErrorFormat - the format errors are reported in. Either "text" (the
+	// default), or "json" to write the error to Stderr as a line of JSON,
+	// with the failed template's name, line, and function as separate fields.
+	ErrorFormat string `yaml:"errorFormat,omitempty"`
+
+	// Compress - the compression to apply to output files. Only "gzip" is
+	// supported, which also adds a ".gz" suffix to the output file names.
+	// Output is not compressed when empty.
+	Compress string `yaml:"compress,omitempty"`
 }
 
 // TODO: remove when we remove the deprecated array format for templates
@@ -86,6 +166,7 @@ type rawConfig struct {
 	Plugins     map[string]PluginConfig `yaml:"plugins,omitempty"`
 
 	Input                 string   `yaml:"in,omitempty"`
+	InputURL              string   `yaml:"inURL,omitempty"`
 	InputDir              string   `yaml:"inputDir,omitempty"`
 	InputFiles            []string `yaml:"inputFiles,omitempty,flow"`
 	ExcludeGlob           []string `yaml:"excludes,omitempty"`
@@ -96,17 +177,39 @@ type rawConfig struct {
 	OutputFiles []string `yaml:"outputFiles,omitempty,flow"`
 	OutMode     string   `yaml:"chmod,omitempty"`
 
+	OutModeGlobs []OutModeGlob `yaml:"chmodGlobs,omitempty"`
+
+	OutUser  string `yaml:"chownUser,omitempty"`
+	OutGroup string `yaml:"chownGroup,omitempty"`
+
 	LDelim string `yaml:"leftDelim,omitempty"`
 	RDelim string `yaml:"rightDelim,omitempty"`
 
 	MissingKey string `yaml:"missingKey,omitempty"`
 
-	PostExec []string `yaml:"postExec,omitempty,flow"`
+	PostExec     []string `yaml:"postExec,omitempty,flow"`
+	PostExecEach []string `yaml:"postExecEach,omitempty,flow"`
 
-	PluginTimeout time.Duration `yaml:"pluginTimeout,omitempty"`
+	PluginTimeout   time.Duration `yaml:"pluginTimeout,omitempty"`
+	TemplateTimeout time.Duration `yaml:"templateTimeout,omitempty"`
+
+	Parallelism int `yaml:"parallelism,omitempty"`
 
 	ExecPipe     bool `yaml:"execPipe,omitempty"`
 	Experimental bool `yaml:"experimental,omitempty"`
+	Watch        bool `yaml:"watch,omitempty"`
+	DryRun       bool `yaml:"dryRun,omitempty"`
+	FailOnChange bool `yaml:"failOnChange,omitempty"`
+	Force        bool `yaml:"force,omitempty"`
+	Stream       bool `yaml:"stream,omitempty"`
+	DeleteEmpty  bool `yaml:"deleteEmpty,omitempty"`
+	FrontMatter  bool `yaml:"frontMatter,omitempty"`
+	Combine      bool `yaml:"combine,omitempty"`
+	MetricsJSON  bool `yaml:"metricsJSON,omitempty"`
+	NoCache      bool `yaml:"noCache,omitempty"`
+
+	ErrorFormat string `yaml:"errorFormat,omitempty"`
+	Compress    string `yaml:"compress,omitempty"`
 }
 
 // TODO: remove when we remove the deprecated array format for templates
@@ -125,6 +228,7 @@ func (c *Config) UnmarshalYAML(value *yaml.Node) error {
 		Templates:             r.Templates,
 		Plugins:               r.Plugins,
 		Input:                 r.Input,
+		InputURL:              r.InputURL,
 		InputDir:              r.InputDir,
 		InputFiles:            r.InputFiles,
 		ExcludeGlob:           r.ExcludeGlob,
@@ -133,13 +237,31 @@ func (c *Config) UnmarshalYAML(value *yaml.Node) error {
 		OutputMap:             r.OutputMap,
 		OutputFiles:           r.OutputFiles,
 		OutMode:               r.OutMode,
+		OutModeGlobs:          r.OutModeGlobs,
+		OutUser:               r.OutUser,
+		OutGroup:              r.OutGroup,
 		LDelim:                r.LDelim,
 		RDelim:                r.RDelim,
 		MissingKey:            r.MissingKey,
 		PostExec:              r.PostExec,
+		PostExecEach:          r.PostExecEach,
 		PluginTimeout:         r.PluginTimeout,
+		TemplateTimeout:       r.TemplateTimeout,
+		Parallelism:           r.Parallelism,
 		ExecPipe:              r.ExecPipe,
 		Experimental:          r.Experimental,
+		Watch:                 r.Watch,
+		DryRun:                r.DryRun,
+		FailOnChange:          r.FailOnChange,
+		Force:                 r.Force,
+		Stream:                r.Stream,
+		DeleteEmpty:           r.DeleteEmpty,
+		FrontMatter:           r.FrontMatter,
+		Combine:               r.Combine,
+		MetricsJSON:           r.MetricsJSON,
+		NoCache:               r.NoCache,
+		ErrorFormat:           r.ErrorFormat,
+		Compress:              r.Compress,
 	}
 
 	return nil
@@ -155,6 +277,7 @@ func (c Config) MarshalYAML() (interface{}, error) {
 		Templates:             c.Templates,
 		Plugins:               c.Plugins,
 		Input:                 c.Input,
+		InputURL:              c.InputURL,
 		InputDir:              c.InputDir,
 		InputFiles:            c.InputFiles,
 		ExcludeGlob:           c.ExcludeGlob,
@@ -163,13 +286,31 @@ func (c Config) MarshalYAML() (interface{}, error) {
 		OutputMap:             c.OutputMap,
 		OutputFiles:           c.OutputFiles,
 		OutMode:               c.OutMode,
+		OutModeGlobs:          c.OutModeGlobs,
+		OutUser:               c.OutUser,
+		OutGroup:              c.OutGroup,
 		LDelim:                c.LDelim,
 		RDelim:                c.RDelim,
 		MissingKey:            c.MissingKey,
 		PostExec:              c.PostExec,
+		PostExecEach:          c.PostExecEach,
 		PluginTimeout:         c.PluginTimeout,
+		TemplateTimeout:       c.TemplateTimeout,
+		Parallelism:           c.Parallelism,
 		ExecPipe:              c.ExecPipe,
 		Experimental:          c.Experimental,
+		Watch:                 c.Watch,
+		DryRun:                c.DryRun,
+		FailOnChange:          c.FailOnChange,
+		Force:                 c.Force,
+		Stream:                c.Stream,
+		DeleteEmpty:           c.DeleteEmpty,
+		FrontMatter:           c.FrontMatter,
+		Combine:               c.Combine,
+		MetricsJSON:           c.MetricsJSON,
+		NoCache:               c.NoCache,
+		ErrorFormat:           c.ErrorFormat,
+		Compress:              c.Compress,
 	}
 
 	return aux, nil
@@ -252,22 +393,31 @@ func (p *PluginConfig) UnmarshalYAML(value *yaml.Node) error {
 // MergeFrom - use this Config as the defaults, and override it with any
 // non-zero values from the other Config
 //
-// Note that Input/InputDir/InputFiles will override each other, as well as
-// OutputDir/OutputFiles.
+// Note that Input/InputURL/InputDir/InputFiles will override each other, as
+// well as OutputDir/OutputFiles.
 func (c *Config) MergeFrom(o *Config) *Config {
 	switch {
 	case !isZero(o.Input):
 		c.Input = o.Input
+		c.InputURL = ""
+		c.InputDir = ""
+		c.InputFiles = nil
+		c.OutputDir = ""
+	case !isZero(o.InputURL):
+		c.Input = ""
+		c.InputURL = o.InputURL
 		c.InputDir = ""
 		c.InputFiles = nil
 		c.OutputDir = ""
 	case !isZero(o.InputDir):
 		c.Input = ""
+		c.InputURL = ""
 		c.InputDir = o.InputDir
 		c.InputFiles = nil
 	case !isZero(o.InputFiles):
 		if !(len(o.InputFiles) == 1 && o.InputFiles[0] == "-") {
 			c.Input = ""
+			c.InputURL = ""
 			c.InputFiles = o.InputFiles
 			c.InputDir = ""
 			c.OutputDir = ""
@@ -294,6 +444,9 @@ func (c *Config) MergeFrom(o *Config) *Config {
 		c.PostExec = o.PostExec
 		c.OutputFiles = o.OutputFiles
 	}
+	if len(o.PostExecEach) > 0 {
+		c.PostExecEach = o.PostExecEach
+	}
 	if !isZero(o.ExcludeGlob) {
 		c.ExcludeGlob = o.ExcludeGlob
 	}
@@ -303,12 +456,60 @@ func (c *Config) MergeFrom(o *Config) *Config {
 	if !isZero(o.OutMode) {
 		c.OutMode = o.OutMode
 	}
+	if !isZero(o.OutModeGlobs) {
+		c.OutModeGlobs = o.OutModeGlobs
+	}
+	if !isZero(o.OutUser) {
+		c.OutUser = o.OutUser
+	}
+	if !isZero(o.OutGroup) {
+		c.OutGroup = o.OutGroup
+	}
 	if !isZero(o.LDelim) {
 		c.LDelim = o.LDelim
 	}
 	if !isZero(o.RDelim) {
 		c.RDelim = o.RDelim
 	}
+	if o.Parallelism != 0 {
+		c.Parallelism = o.Parallelism
+	}
+	if o.Watch {
+		c.Watch = o.Watch
+	}
+	if o.DryRun {
+		c.DryRun = o.DryRun
+	}
+	if o.FailOnChange {
+		c.FailOnChange = o.FailOnChange
+	}
+	if o.Force {
+		c.Force = o.Force
+	}
+	if o.Stream {
+		c.Stream = o.Stream
+	}
+	if o.DeleteEmpty {
+		c.DeleteEmpty = o.DeleteEmpty
+	}
+	if o.FrontMatter {
+		c.FrontMatter = o.FrontMatter
+	}
+	if o.Combine {
+		c.Combine = o.Combine
+	}
+	if o.MetricsJSON {
+		c.MetricsJSON = o.MetricsJSON
+	}
+	if o.NoCache {
+		c.NoCache = o.NoCache
+	}
+	if !isZero(o.ErrorFormat) {
+		c.ErrorFormat = o.ErrorFormat
+	}
+	if !isZero(o.Compress) {
+		c.Compress = o.Compress
+	}
 	if c.Templates == nil {
 		c.Templates = o.Templates
 	} else {
@@ -336,18 +537,24 @@ func (c *Config) MergeFrom(o *Config) *Config {
 // validate the Config
 func (c Config) validate() (err error) {
 	err = notTogether(
-		[]string{"in", "inputFiles", "inputDir"},
-		c.Input, c.InputFiles, c.InputDir)
+		[]string{"in", "inURL", "inputFiles", "inputDir"},
+		c.Input, c.InputURL, c.InputFiles, c.InputDir)
+	// outputDir and outputMap can be used together - the map's paths are then
+	// relative to (and contained in) the output directory
+	if err == nil {
+		err = notTogether([]string{"outputFiles", "outputDir"}, c.OutputFiles, c.OutputDir)
+	}
+
 	if err == nil {
-		err = notTogether(
-			[]string{"outputFiles", "outputDir", "outputMap"},
-			c.OutputFiles, c.OutputDir, c.OutputMap)
+		err = notTogether([]string{"outputFiles", "outputMap"}, c.OutputFiles, c.OutputMap)
 	}
 
 	if err == nil {
-		err = notTogether(
-			[]string{"outputDir", "outputMap", "execPipe"},
-			c.OutputDir, c.OutputMap, c.ExecPipe)
+		err = notTogether([]string{"outputDir", "execPipe"}, c.OutputDir, c.ExecPipe)
+	}
+
+	if err == nil {
+		err = notTogether([]string{"outputMap", "execPipe"}, c.OutputMap, c.ExecPipe)
 	}
 
 	if err == nil {
@@ -360,14 +567,25 @@ func (c Config) validate() (err error) {
 			c.OutputMap, c.InputDir)
 	}
 
+	if err == nil {
+		err = mustTogether("combine", "inputFiles", c.Combine, c.InputFiles)
+	}
+
 	if err == nil {
 		f := len(c.InputFiles)
-		if f == 0 && c.Input != "" {
+		if f == 0 && (c.Input != "" || c.InputURL != "") {
+			f = 1
+		}
+		// combined input files are rendered to a single output
+		if c.Combine {
 			f = 1
 		}
 		o := len(c.OutputFiles)
 		if f != o && !c.ExecPipe {
 			err = fmt.Errorf("must provide same number of 'outputFiles' (%d) as 'in' or 'inputFiles' (%d) options", o, f)
+			if c.Combine {
+				err = fmt.Errorf("must provide exactly one 'outputFiles' entry when using 'combine', got %d", o)
+			}
 		}
 	}
 
@@ -383,6 +601,53 @@ func (c Config) validate() (err error) {
 		}
 	}
 
+	if err == nil && slices.Contains(c.InputFiles, "-") {
+		if alias := stdinDataSource(c); alias != "" {
+			err = fmt.Errorf("the input template and datasource %q can't both be read from stdin", alias)
+		}
+	}
+
+	if err == nil && c.InputURL != "" {
+		err = c.validateInputURLAlias()
+	}
+
+	if err == nil {
+		err = mustTogether("failOnChange", "dryRun",
+			c.FailOnChange, c.DryRun)
+	}
+
+	if err == nil {
+		err = notTogether([]string{"stream", "dryRun"}, c.Stream, c.DryRun)
+	}
+
+	if err == nil {
+		err = notTogether([]string{"stream", "deleteEmpty"}, c.Stream, c.DeleteEmpty)
+	}
+
+	if err == nil && c.Watch {
+		err = c.validateWatch()
+	}
+
+	if err == nil {
+		for _, g := range c.OutModeGlobs {
+			if err = g.validate(); err != nil {
+				break
+			}
+		}
+	}
+
+	if err == nil && c.Parallelism < 0 {
+		err = fmt.Errorf("parallelism must not be negative, got %d", c.Parallelism)
+	}
+
+	if err == nil && !slices.Contains([]string{"", "text", "json"}, c.ErrorFormat) {
+		err = fmt.Errorf("unsupported error format %q, must be one of: text, json", c.ErrorFormat)
+	}
+
+	if err == nil && !slices.Contains([]string{"", "gzip"}, c.Compress) {
+		err = fmt.Errorf("unsupported compression %q, must be gzip", c.Compress)
+	}
+
 	if err == nil {
 		missingKeyValues := []string{"", "error", "zero", "default", "invalid"}
 		if !slices.Contains(missingKeyValues, c.MissingKey) {
@@ -393,6 +658,52 @@ func (c Config) validate() (err error) {
 	return err
 }
 
+// validateWatch checks that the config can be used in watch mode
+func (c Config) validateWatch() error {
+	if slices.Contains(c.InputFiles, "-") {
+		return fmt.Errorf("watch can't be used when the input template is read from stdin")
+	}
+
+	if len(c.PostExec) > 0 {
+		return fmt.Errorf("watch can't be used with postExec")
+	}
+
+	return nil
+}
+
+// validateInputURLAlias ensures that no datasource, context, or nested template
+// uses the alias that headers for the input URL are given with, since the
+// headers would apply to both
+func (c Config) validateInputURLAlias() error {
+	for _, m := range []map[string]DataSource{c.DataSources, c.Context, c.Templates} {
+		if _, ok := m[inputURLAlias]; ok {
+			return fmt.Errorf("the alias %q is reserved for headers sent with inURL, so it can't be used for a datasource", inputURLAlias)
+		}
+	}
+
+	return nil
+}
+
+// stdinDataSource returns the alias of the first (in sorted order) datasource,
+// context, or nested template that reads from stdin, or "" if there are none
+func stdinDataSource(c Config) string {
+	aliases := []string{}
+	for _, m := range []map[string]DataSource{c.DataSources, c.Context, c.Templates} {
+		for alias, ds := range m {
+			if ds.URL != nil && ds.URL.Scheme == "stdin" {
+				aliases = append(aliases, alias)
+			}
+		}
+	}
+
+	if len(aliases) == 0 {
+		return ""
+	}
+
+	slices.Sort(aliases)
+	return aliases[0]
+}
+
 func notTogether(names []string, values ...interface{}) error {
 	found := ""
 	for i, value := range values {
@@ -446,7 +757,7 @@ func (c *Config) applyDefaults() {
 	if c.InputDir != "" && c.OutputDir == "" && c.OutputMap == "" {
 		c.OutputDir = "."
 	}
-	if c.Input == "" && c.InputDir == "" && len(c.InputFiles) == 0 {
+	if c.Input == "" && c.InputURL == "" && c.InputDir == "" && len(c.InputFiles) == 0 {
 		c.InputFiles = []string{"-"}
 	}
 	if c.OutputDir == "" && c.OutputMap == "" && len(c.OutputFiles) == 0 {
@@ -467,20 +778,88 @@ func (c *Config) applyDefaults() {
 	}
 }
 
-// getMode - parse an os.FileMode out of the string, and let us know if it's an override or not...
-func (c *Config) getMode() (os.FileMode, bool, error) {
-	modeOverride := c.OutMode != ""
-	m, err := strconv.ParseUint("0"+c.OutMode, 8, 32)
+// outputName returns the name output is actually written to: with
+// compression, ".gz" is added (unless the name already has that extension)
+func (c *Config) outputName(name string) string {
+	if c.Compress != "" && name != "-" && !strings.HasSuffix(name, ".gz") {
+		return name + ".gz"
+	}
+	return name
+}
+
+// getMode - parse an os.FileMode for the given output path out of the
+// OutModeGlobs or OutMode, and let us know if it's an override or not...
+func (c *Config) getMode(outPath string) (os.FileMode, bool, error) {
+	outMode := c.OutMode
+	for _, g := range c.OutModeGlobs {
+		if g.matches(outPath) {
+			outMode = g.Mode
+			break
+		}
+	}
+
+	modeOverride := outMode != ""
+	m, err := strconv.ParseUint("0"+outMode, 8, 32)
 	if err != nil {
 		return 0, false, err
 	}
 	mode := iohelpers.NormalizeFileMode(os.FileMode(m))
-	if mode == 0 && c.Input != "" {
+	if mode == 0 && (c.Input != "" || c.InputURL != "") {
 		mode = iohelpers.NormalizeFileMode(0o644)
 	}
 	return mode, modeOverride, nil
 }
 
+// OutModeGlob - a mode to set on output files with paths matching a glob
+type OutModeGlob struct {
+	// Glob - a glob matched against the end of the output path. For example
+	// '*.sh' matches any file ending in '.sh', and 'bin/*' matches any file in
+	// a directory named 'bin'.
+	Glob string `yaml:"glob"`
+	// Mode - the octal file mode, in the same format as OutMode
+	Mode string `yaml:"mode"`
+}
+
+// ParseOutModeGlob parses a glob=mode pair, such as '*.sh=0755'
+func ParseOutModeGlob(value string) (OutModeGlob, error) {
+	glob, mode, ok := strings.Cut(value, "=")
+	if !ok {
+		return OutModeGlob{}, fmt.Errorf("invalid chmod glob %q, must be in glob=mode form", value)
+	}
+
+	g := OutModeGlob{Glob: glob, Mode: mode}
+	if err := g.validate(); err != nil {
+		return OutModeGlob{}, err
+	}
+
+	return g, nil
+}
+
+func (g OutModeGlob) validate() error {
+	if _, err := path.Match(g.Glob, ""); err != nil || g.Glob == "" {
+		return fmt.Errorf("invalid chmod glob %q", g.Glob)
+	}
+
+	if _, err := strconv.ParseUint(g.Mode, 8, 32); err != nil {
+		return fmt.Errorf("invalid mode %q for chmod glob %q", g.Mode, g.Glob)
+	}
+
+	return nil
+}
+
+// matches reports whether the glob matches the end of the output path, at a
+// path separator boundary - i.e. "*.sh" and "bin/*" both match "out/bin/x.sh"
+func (g OutModeGlob) matches(outPath string) bool {
+	parts := strings.Split(filepath.ToSlash(outPath), "/")
+	n := strings.Count(g.Glob, "/") + 1
+	if n > len(parts) {
+		return false
+	}
+
+	ok, _ := path.Match(g.Glob, strings.Join(parts[len(parts)-n:], "/"))
+	return ok
+}
+
 // String -
 func (c *Config) String() string {
 	out := &strings.Builder{}
@@ -3,6 +3,7 @@ package gomplate
 import (
 	"net/http"
 	"net/url"
+	"os"
 	"strings"
 	"testing"
 	"time"
@@ -44,6 +45,8 @@ templates:
 	in = `in: hello world
 outputFiles: [out.txt]
 chmod: 644
+chownUser: www-data
+chownGroup: "33"
 
 datasources:
   data:
@@ -87,7 +90,9 @@ pluginTimeout: 2s
 				URL: mustURL("file:///data.json"),
 			},
 		},
-		OutMode: "644",
+		OutMode:  "644",
+		OutUser:  "www-data",
+		OutGroup: "33",
 		Plugins: map[string]PluginConfig{
 			"foo": {Cmd: "echo", Pipe: true},
 		},
@@ -115,6 +120,126 @@ func TestValidate(t *testing.T) {
 
 	require.Error(t, validateConfig(`in: foo
 inputFiles: [bar]
+`))
+	require.Error(t, validateConfig(`inURL: https://example.com/foo.tmpl
+inputFiles: [bar]
+`))
+	require.Error(t, validateConfig(`inURL: https://example.com/foo.tmpl
+inputDir: bar
+`))
+	require.NoError(t, validateConfig(`inURL: https://example.com/foo.tmpl
+outputFiles: [out]
+`))
+	require.EqualError(t, validateConfig(`inURL: https://example.com/foo.tmpl
+outputFiles: [out]
+datasources:
+  in:
+    url: in.json
+`), `the alias "in" is reserved for headers sent with inURL, so it can't be used for a datasource`)
+	require.Error(t, validateConfig(`inURL: https://example.com/foo.tmpl
+outputFiles: [out]
+templates:
+  in:
+    url: in.t
+`))
+	// without inURL, "in" is an ordinary alias
+	require.NoError(t, validateConfig(`in: hello
+outputFiles: [out]
+context:
+  in:
+    url: in.json
+`))
+	require.Error(t, validateConfig(`parallelism: -1
+`))
+
+	require.Error(t, validateConfig(`inputFiles: ['-']
+outputFiles: ['-']
+datasources:
+  data:
+    url: stdin:///foo.yaml
+`))
+	require.NoError(t, validateConfig(`in: foo
+outputFiles: ['-']
+datasources:
+  data:
+    url: stdin:///foo.yaml
+`))
+
+	require.Error(t, validateConfig(`inputFiles: ['-']
+outputFiles: ['-']
+watch: true
+`))
+	require.Error(t, validateConfig(`in: foo
+outputFiles: ['-']
+postExec: [echo]
+watch: true
+`))
+	require.NoError(t, validateConfig(`inputFiles: [foo]
+outputFiles: [bar]
+watch: true
+`))
+
+	require.Error(t, validateConfig(`in: foo
+outputFiles: [bar]
+failOnChange: true
+`))
+
+	require.Error(t, validateConfig(`in: foo
+outputFiles: [bar]
+stream: true
+dryRun: true
+`))
+	require.NoError(t, validateConfig(`in: foo
+outputFiles: [bar]
+stream: true
+`))
+
+	require.Error(t, validateConfig(`in: foo
+outputFiles: [bar]
+stream: true
+deleteEmpty: true
+`))
+	require.NoError(t, validateConfig(`in: foo
+outputFiles: [bar]
+deleteEmpty: true
+`))
+
+	require.NoError(t, validateConfig(`inputFiles: [defs.tmpl, main.tmpl]
+outputFiles: [out]
+combine: true
+`))
+	require.EqualError(t, validateConfig(`inputFiles: [defs.tmpl, main.tmpl]
+outputFiles: [out1, out2]
+combine: true
+`), "must provide exactly one 'outputFiles' entry when using 'combine', got 2")
+	require.Error(t, validateConfig(`in: foo
+outputFiles: [bar]
+combine: true
+`))
+
+	require.NoError(t, validateConfig(`in: foo
+outputFiles: [bar]
+errorFormat: json
+`))
+	require.EqualError(t, validateConfig(`in: foo
+outputFiles: [bar]
+errorFormat: xml
+`), `unsupported error format "xml", must be one of: text, json`)
+
+	require.NoError(t, validateConfig(`in: foo
+outputFiles: [bar]
+compress: gzip
+`))
+	require.EqualError(t, validateConfig(`in: foo
+outputFiles: [bar]
+compress: zip
+`), `unsupported compression "zip", must be gzip`)
+
+	require.Error(t, validateConfig(`in: foo
+outputFiles: [bar]
+chmodGlobs:
+  - glob: '*.sh'
+    mode: rwx
 `))
 	require.Error(t, validateConfig(`inputDir: foo
 inputFiles: [bar]
@@ -149,9 +274,15 @@ outputFiles: [bar]
 outputFiles: [bar]
 `))
 
-	require.Error(t, validateConfig(`inputDir: foo
+	require.NoError(t, validateConfig(`inputDir: foo
 outputDir: bar
 outputMap: bar
+`))
+
+	require.Error(t, validateConfig(`inputDir: foo
+outputMap: bar
+execPipe: true
+postExec: [echo]
 `))
 
 	require.Error(t, validateConfig(`execPipe: true
@@ -286,6 +417,20 @@ func TestMergeFrom(t *testing.T) {
 
 	assert.EqualValues(t, expected, cfg.MergeFrom(other))
 
+	cfg = &Config{
+		InputFiles:  []string{"in.tmpl"},
+		OutputFiles: []string{"out"},
+	}
+	other = &Config{
+		InputURL: "https://example.com/in.tmpl",
+	}
+	expected = &Config{
+		InputURL:    "https://example.com/in.tmpl",
+		OutputFiles: []string{"out"},
+	}
+
+	assert.EqualValues(t, expected, cfg.MergeFrom(other))
+
 	cfg = &Config{
 		Input:       "hello world",
 		OutputFiles: []string{"out", "out2"},
@@ -630,25 +775,84 @@ func TestApplyDefaults(t *testing.T) {
 
 func TestGetMode(t *testing.T) {
 	c := &Config{}
-	m, o, err := c.getMode()
+	m, o, err := c.getMode("out")
 	require.NoError(t, err)
 	assert.Equal(t, iohelpers.NormalizeFileMode(0), m)
 	assert.False(t, o)
 
 	c = &Config{OutMode: "755"}
-	m, o, err = c.getMode()
+	m, o, err = c.getMode("out")
 	require.NoError(t, err)
 	assert.Equal(t, iohelpers.NormalizeFileMode(0o755), m)
 	assert.True(t, o)
 
 	c = &Config{OutMode: "0755"}
-	m, o, err = c.getMode()
+	m, o, err = c.getMode("out")
 	require.NoError(t, err)
 	assert.Equal(t, iohelpers.NormalizeFileMode(0o755), m)
 	assert.True(t, o)
 
 	c = &Config{OutMode: "foo"}
-	_, _, err = c.getMode()
+	_, _, err = c.getMode("out")
+	require.Error(t, err)
+
+	c = &Config{
+		OutMode: "600",
+		OutModeGlobs: []OutModeGlob{
+			{Glob: "*.sh", Mode: "0755"},
+			{Glob: "bin/*", Mode: "0750"},
+			{Glob: "*", Mode: "0644"},
+		},
+	}
+	testdata := []struct {
+		path string
+		mode os.FileMode
+	}{
+		{"out/run.sh", 0o755},
+		{"run.sh", 0o755},
+		{"bin/run.sh", 0o755},
+		{"bin/run", 0o750},
+		{"out/bin/run", 0o750},
+		{"out/bin/sub/run", 0o644},
+		{"config.yaml", 0o644},
+	}
+	for _, d := range testdata {
+		m, o, err = c.getMode(d.path)
+		require.NoError(t, err)
+		assert.Equal(t, iohelpers.NormalizeFileMode(d.mode), m, d.path)
+		assert.True(t, o)
+	}
+
+	// falls back to OutMode when no glob matches
+	c.OutModeGlobs = c.OutModeGlobs[:2]
+	m, o, err = c.getMode("config.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, iohelpers.NormalizeFileMode(0o600), m)
+	assert.True(t, o)
+
+	// and isn't an override at all when neither is set
+	c.OutMode = ""
+	m, o, err = c.getMode("config.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, iohelpers.NormalizeFileMode(0), m)
+	assert.False(t, o)
+}
+
+func TestParseOutModeGlob(t *testing.T) {
+	g, err := ParseOutModeGlob("*.sh=0755")
+	require.NoError(t, err)
+	assert.Equal(t, OutModeGlob{Glob: "*.sh", Mode: "0755"}, g)
+
+	_, err = ParseOutModeGlob("0755")
+	require.Error(t, err)
+
+	_, err = ParseOutModeGlob("=0755")
+	require.Error(t, err)
+
+	_, err = ParseOutModeGlob("[=0755")
+	require.Error(t, err)
+
+	_, err = ParseOutModeGlob("*.sh=rwx")
 	require.Error(t, err)
 }
 
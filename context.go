@@ -2,6 +2,7 @@ package gomplate
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"strings"
 
@@ -22,12 +23,18 @@ func (c *tmplctx) Env() map[string]string {
 	return env
 }
 
-// createTmplContext reads the datasources for the given aliases
+// createTmplContext reads the datasources for the given aliases. The special
+// alias "." sets the root context - when other aliases are also given, its
+// top-level keys are merged into the root context alongside them.
 func createTmplContext(
 	ctx context.Context, aliases []string,
 	sr datafs.DataSourceReader,
 ) (interface{}, error) {
 	tctx := &tmplctx{}
+
+	var root interface{}
+	hasRoot := false
+
 	for _, a := range aliases {
 		ct, b, err := sr.ReadSource(ctx, a)
 		if err != nil {
@@ -40,10 +47,33 @@ func createTmplContext(
 		}
 
 		if a == "." {
-			return content, nil
+			root, hasRoot = content, true
+			continue
 		}
 
 		(*tctx)[a] = content
 	}
+
+	if !hasRoot {
+		return tctx, nil
+	}
+
+	if len(*tctx) == 0 {
+		return root, nil
+	}
+
+	m, ok := root.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("root context (.) must be a map to be combined with other contexts, but was %T", root)
+	}
+
+	for k, v := range m {
+		if _, exists := (*tctx)[k]; exists {
+			return nil, fmt.Errorf("context %q conflicts with a top-level key of the same name in the root context (.)", k)
+		}
+
+		(*tctx)[k] = v
+	}
+
 	return tctx, nil
 }
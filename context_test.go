@@ -62,4 +62,20 @@ func TestCreateContext(t *testing.T) {
 	assert.IsType(t, map[string]interface{}{}, c)
 	ds = c.(map[string]interface{})
 	assert.Equal(t, "baz", ds["bar"])
+
+	// the root context is merged with other contexts
+	c, err = createTmplContext(ctx, []string{".", "foo"}, sr)
+	require.NoError(t, err)
+	tctx = c.(*tmplctx)
+	assert.Equal(t, "baz", (*tctx)["bar"])
+	assert.Equal(t, map[string]interface{}{"foo": "bar"}, (*tctx)["foo"])
+
+	// content is cached, so a new reader is needed to see changes
+	t.Setenv("bar", "foo: baz")
+	_, err = createTmplContext(ctx, []string{"foo", "."}, datafs.NewSourceReader(reg))
+	require.EqualError(t, err, `context "foo" conflicts with a top-level key of the same name in the root context (.)`)
+
+	t.Setenv("bar", "[1, 2]")
+	_, err = createTmplContext(ctx, []string{"foo", "."}, datafs.NewSourceReader(reg))
+	require.ErrorContains(t, err, "root context (.) must be a map")
 }
@@ -238,7 +238,7 @@ func ToInt64s(in ...interface{}) ([]int64, error) {
 	for i, v := range in {
 		n, err := ToInt64(v)
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("element %d: %w", i, err)
 		}
 
 		out[i] = n
@@ -253,7 +253,7 @@ func ToInts(in ...interface{}) ([]int, error) {
 	for i, v := range in {
 		n, err := ToInt(v)
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("element %d: %w", i, err)
 		}
 
 		out[i] = n
@@ -336,7 +336,7 @@ func ToFloat64s(in ...interface{}) ([]float64, error) {
 	for i, v := range in {
 		f, err := ToFloat64(v)
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("element %d: %w", i, err)
 		}
 		out[i] = f
 	}
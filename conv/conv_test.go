@@ -293,6 +293,9 @@ func TestToInt64s(t *testing.T) {
 
 		_, err = ToInt64s(nil, false, "", true, 1, 2.0, uint8(3), int64(5), float32(8), "13", "-1,000")
 		require.Error(t, err)
+
+		_, err = ToInt64s("1", "2", "three")
+		require.ErrorContains(t, err, "element 2: ")
 	})
 }
 
@@ -319,6 +322,9 @@ func TestToInts(t *testing.T) {
 
 		_, err = ToInts(nil, false, "", true, 1, 2.0, uint8(3), int64(5), float32(8), "13", "42,000")
 		require.Error(t, err)
+
+		_, err = ToInts("1", "two")
+		require.ErrorContains(t, err, "element 1: ")
 	})
 }
 
@@ -364,6 +370,9 @@ func TestToFloat64s(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, []float64{1.0, 2.0, math.Pi, 4.0}, actual)
 
+	_, err = ToFloat64s(nil, true, "2", math.Pi, uint8(4))
+	require.ErrorContains(t, err, "element 0: ")
+
 	_, err = ToFloat64s(nil, true, "2", math.Pi, uint8(4))
 	require.Error(t, err)
 }
@@ -0,0 +1,35 @@
+package crypto
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+// HKDF - Run the HMAC-based Extract-and-Expand Key Derivation Function as
+// defined in RFC 5869. The salt and info may be empty, but the secret may not.
+// At most 255 times the hash's size can be derived.
+func HKDF(secret, salt, info []byte, keylen int, hashFunc crypto.Hash) ([]byte, error) {
+	h, ok := hashFuncs[hashFunc]
+	if !ok {
+		return nil, fmt.Errorf("hashFunc not supported: %v", hashFunc)
+	}
+
+	if len(secret) == 0 {
+		return nil, errors.New("secret must not be empty")
+	}
+
+	if maxLen := 255 * hashFunc.Size(); keylen < 1 || keylen > maxLen {
+		return nil, fmt.Errorf("key length must be between 1 and %d for %v, got %d", maxLen, hashFunc, keylen)
+	}
+
+	dk := make([]byte, keylen)
+	if _, err := io.ReadFull(hkdf.New(h, secret, salt, info), dk); err != nil {
+		return nil, fmt.Errorf("hkdf: %w", err)
+	}
+
+	return dk, nil
+}
@@ -0,0 +1,61 @@
+package crypto
+
+import (
+	"crypto"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mustDecodeHex(t *testing.T, s string) []byte {
+	t.Helper()
+
+	b, err := hex.DecodeString(s)
+	require.NoError(t, err)
+
+	return b
+}
+
+func TestHKDF(t *testing.T) {
+	t.Parallel()
+
+	// RFC 5869 test vectors
+	ikm := mustDecodeHex(t, "0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b")
+
+	dk, err := HKDF(ikm,
+		mustDecodeHex(t, "000102030405060708090a0b0c"),
+		mustDecodeHex(t, "f0f1f2f3f4f5f6f7f8f9"),
+		42, crypto.SHA256)
+	require.NoError(t, err)
+	assert.Equal(t, "3cb25f25faacd57a90434f64d0362f2a2d2d0a90cf1a5a4c5db02d56ecc4c5bf34007208d5b887185865", hex.EncodeToString(dk))
+
+	// no salt or info
+	dk, err = HKDF(ikm, nil, nil, 42, crypto.SHA256)
+	require.NoError(t, err)
+	assert.Equal(t, "8da4e775a563c18f715f802a063c5a31b8a11f5c5ee1879ec3454e5f3c738d2d9d201395faa4b61a96c8", hex.EncodeToString(dk))
+
+	dk, err = HKDF(mustDecodeHex(t, "0b0b0b0b0b0b0b0b0b0b0b"),
+		mustDecodeHex(t, "000102030405060708090a0b0c"),
+		mustDecodeHex(t, "f0f1f2f3f4f5f6f7f8f9"),
+		42, crypto.SHA1)
+	require.NoError(t, err)
+	assert.Equal(t, "085a01ea1b10f36933068b56efa5ad81a4f14b822f5b091568a9cdd4f155fda2c22e422478d305f3f896", hex.EncodeToString(dk))
+
+	dk, err = HKDF([]byte("secret"), nil, []byte("info"), 255*64, crypto.SHA512)
+	require.NoError(t, err)
+	assert.Len(t, dk, 255*64)
+
+	_, err = HKDF(ikm, nil, nil, 255*32+1, crypto.SHA256)
+	require.ErrorContains(t, err, "key length must be between 1 and 8160 for SHA-256, got 8161")
+
+	_, err = HKDF(ikm, nil, nil, 0, crypto.SHA256)
+	require.Error(t, err)
+
+	_, err = HKDF(nil, []byte("salt"), nil, 32, crypto.SHA256)
+	require.ErrorContains(t, err, "secret must not be empty")
+
+	_, err = HKDF(ikm, nil, nil, 32, crypto.MD5)
+	require.Error(t, err)
+}
@@ -5,8 +5,10 @@ import (
 	"crypto/sha1" //nolint: gosec
 	"crypto/sha256"
 	"crypto/sha512"
+	"errors"
 	"fmt"
 	"hash"
+	"strings"
 	"sync"
 
 	"golang.org/x/crypto/pbkdf2"
@@ -25,9 +27,9 @@ var hashFuncs = sync.OnceValue[map[crypto.Hash]func() hash.Hash](func() map[cryp
 	return h
 })()
 
-// StrToHash - find a hash given a certain string
+// StrToHash - find a hash given a certain string (case-insensitive)
 func StrToHash(hash string) (crypto.Hash, error) {
-	switch hash {
+	switch strings.ToUpper(hash) {
 	case "SHA1", "SHA-1":
 		return crypto.SHA1, nil
 	case "SHA224", "SHA-224":
@@ -53,5 +55,18 @@ func PBKDF2(password, salt []byte, iter, keylen int, hashFunc crypto.Hash) ([]by
 	if !ok {
 		return nil, fmt.Errorf("hashFunc not supported: %v", hashFunc)
 	}
+
+	if len(password) == 0 {
+		return nil, errors.New("password must not be empty")
+	}
+
+	if iter < 1 {
+		return nil, fmt.Errorf("iteration count must be at least 1, got %d", iter)
+	}
+
+	if keylen < 1 {
+		return nil, fmt.Errorf("key length must be at least 1, got %d", keylen)
+	}
+
 	return pbkdf2.Key(password, salt, iter, keylen, h), nil
 }
@@ -43,6 +43,15 @@ func TestPBKDF2(t *testing.T) {
 	}, dk)
 	require.NoError(t, err)
 
+	_, err = PBKDF2([]byte("password"), []byte("salt"), 0, 32, crypto.SHA256)
+	require.ErrorContains(t, err, "iteration count must be at least 1, got 0")
+
+	_, err = PBKDF2([]byte("password"), []byte("salt"), 4096, -1, crypto.SHA256)
+	require.ErrorContains(t, err, "key length must be at least 1, got -1")
+
+	_, err = PBKDF2(nil, []byte("salt"), 4096, 32, crypto.SHA256)
+	require.ErrorContains(t, err, "password must not be empty")
+
 	// some longer hash functions
 	dk, err = PBKDF2([]byte("password"), []byte("IEEE"), 4096, 64, crypto.SHA512)
 	assert.EqualValues(t, []byte{
@@ -85,4 +94,7 @@ func TestStrToHash(t *testing.T) {
 	h, err = StrToHash("SHA512/256")
 	assert.Equal(t, crypto.SHA512_256, h)
 	require.NoError(t, err)
+	h, err = StrToHash("sha256")
+	assert.Equal(t, crypto.SHA256, h)
+	require.NoError(t, err)
 }
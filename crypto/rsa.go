@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"crypto/rand"
 	"crypto/rsa"
+	"crypto/sha256"
 	"crypto/x509"
 	"encoding/pem"
 	"fmt"
@@ -15,45 +16,56 @@ import (
 // beginning with "PUBLIC KEY". PKCS#1 format is also supported as a fallback.
 // The output will not be encoded, so consider base64-encoding it for display.
 func RSAEncrypt(key string, in []byte) ([]byte, error) {
-	block, _ := pem.Decode([]byte(key))
-	if block == nil {
-		return nil, fmt.Errorf("failed to read key %q: no key found", key)
-	}
-
-	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	pubKey, err := parsePublicKey(key)
 	if err != nil {
-		if strings.Contains(err.Error(), "use ParsePKCS1PublicKey instead") {
-			pub, err = x509.ParsePKCS1PublicKey(block.Bytes)
-		}
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse public key: %w", err)
-		}
-	}
-	pubKey, ok := pub.(*rsa.PublicKey)
-	if !ok {
-		return nil, fmt.Errorf("public key in wrong format, was %T", pub)
+		return nil, err
 	}
 
 	out, err := rsa.EncryptPKCS1v15(rand.Reader, pubKey, in)
 	return out, err
 }
 
+// RSAEncryptOAEP - like RSAEncrypt, but uses RSA-OAEP with SHA-256 instead of
+// PKCS#1 v1.5 padding.
+func RSAEncryptOAEP(key string, in []byte) ([]byte, error) {
+	pubKey, err := parsePublicKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, pubKey, in, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt: %w", err)
+	}
+	return out, nil
+}
+
 // RSADecrypt - decrypt the ciphertext with the given private key. The key
 // must be a PEM-encoded RSA private key in PKCS#1, ASN.1 DER form, typically
-// beginning with "RSA PRIVATE KEY". The input text must be plain ciphertext,
-// not base64-encoded.
+// beginning with "RSA PRIVATE KEY". PKCS#8 format is also supported as a
+// fallback. The input text must be plain ciphertext, not base64-encoded.
 func RSADecrypt(key string, in []byte) ([]byte, error) {
-	block, _ := pem.Decode([]byte(key))
-	if block == nil {
-		return nil, fmt.Errorf("failed to read key %q: no key found", key)
+	priv, err := parsePrivateKey([]byte(key))
+	if err != nil {
+		return nil, err
 	}
 
-	priv, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	out, err := priv.Decrypt(nil, in, nil)
 	if err != nil {
-		return nil, fmt.Errorf("invalid private key: %w", err)
+		return nil, fmt.Errorf("failed to decrypt: %w", err)
 	}
+	return out, nil
+}
 
-	out, err := priv.Decrypt(nil, in, nil)
+// RSADecryptOAEP - like RSADecrypt, but for ciphertext encrypted with RSA-OAEP
+// with SHA-256 (see RSAEncryptOAEP).
+func RSADecryptOAEP(key string, in []byte) ([]byte, error) {
+	priv, err := parsePrivateKey([]byte(key))
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := rsa.DecryptOAEP(sha256.New(), nil, priv, in, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to decrypt: %w", err)
 	}
@@ -85,14 +97,9 @@ func RSAGenerateKey(bits int) ([]byte, error) {
 
 // RSADerivePublicKey -
 func RSADerivePublicKey(privateKey []byte) ([]byte, error) {
-	block, _ := pem.Decode(privateKey)
-	if block == nil {
-		return nil, fmt.Errorf("failed to read key: no key found")
-	}
-
-	priv, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	priv, err := parsePrivateKey(privateKey)
 	if err != nil {
-		return nil, fmt.Errorf("invalid private key: %w", err)
+		return nil, err
 	}
 
 	b, err := x509.MarshalPKIXPublicKey(&priv.PublicKey)
@@ -100,10 +107,61 @@ func RSADerivePublicKey(privateKey []byte) ([]byte, error) {
 		return nil, fmt.Errorf("failed to marshal PKIX public key: %w", err)
 	}
 
-	block = &pem.Block{
+	block := &pem.Block{
 		Type:  "PUBLIC KEY",
 		Bytes: b,
 	}
 
 	return pem.EncodeToMemory(block), nil
 }
+
+// parsePublicKey parses a PEM-encoded RSA public key, in PKIX or PKCS#1 form
+func parsePublicKey(key string) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode([]byte(key))
+	if block == nil {
+		return nil, fmt.Errorf("failed to read key: no PEM-encoded key found")
+	}
+
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		if strings.Contains(err.Error(), "use ParsePKCS1PublicKey instead") {
+			pub, err = x509.ParsePKCS1PublicKey(block.Bytes)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse public key: %w", err)
+		}
+	}
+	pubKey, ok := pub.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("public key in wrong format, was %T", pub)
+	}
+
+	return pubKey, nil
+}
+
+// parsePrivateKey parses a PEM-encoded RSA private key, in PKCS#1 or PKCS#8
+// form
+func parsePrivateKey(key []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(key)
+	if block == nil {
+		return nil, fmt.Errorf("failed to read key: no PEM-encoded key found")
+	}
+
+	priv, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err == nil {
+		return priv, nil
+	}
+
+	k, perr := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if perr != nil {
+		// report the PKCS#1 error, since that's the expected format
+		return nil, fmt.Errorf("invalid private key: %w", err)
+	}
+
+	priv, ok := k.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("invalid private key: expected an RSA key, was %T", k)
+	}
+
+	return priv, nil
+}
@@ -103,6 +103,68 @@ func TestRSACrypt(t *testing.T) {
 	})
 }
 
+func TestRSACryptOAEP(t *testing.T) {
+	t.Parallel()
+
+	priv, testPrivKey := genPKCS1PrivKey()
+
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(priv)
+	require.NoError(t, err)
+	pkcs8PrivKey := string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8}))
+
+	testdata := []struct {
+		name   string
+		encKey string
+		decKey string
+	}{
+		{"pkix key", derivePKIXPubKey(priv), testPrivKey},
+		{"pkcs1 key", derivePKCS1PubKey(priv), testPrivKey},
+		{"pkcs8 private key", derivePKIXPubKey(priv), pkcs8PrivKey},
+	}
+
+	in := []byte("hello world")
+
+	for _, d := range testdata {
+		d := d
+		t.Run(d.name, func(t *testing.T) {
+			t.Parallel()
+
+			enc, err := RSAEncryptOAEP(d.encKey, in)
+			require.NoError(t, err)
+
+			dec, err := RSADecryptOAEP(d.decKey, enc)
+			require.NoError(t, err)
+			assert.Equal(t, in, dec)
+
+			// PKCS#1 v1.5 ciphertext can't be decrypted as OAEP
+			enc, err = RSAEncrypt(d.encKey, in)
+			require.NoError(t, err)
+
+			_, err = RSADecryptOAEP(d.decKey, enc)
+			require.Error(t, err)
+		})
+	}
+
+	t.Run("bad keys", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := RSAEncryptOAEP("bad key", in)
+		require.ErrorContains(t, err, "no PEM-encoded key found")
+		assert.NotContains(t, err.Error(), "bad key")
+
+		_, err = RSADecryptOAEP("", in)
+		require.ErrorContains(t, err, "no PEM-encoded key found")
+
+		// a public key where a private key is expected
+		_, err = RSADecryptOAEP(derivePKIXPubKey(priv), in)
+		require.ErrorContains(t, err, "invalid private key")
+
+		// a private key where a public key is expected
+		_, err = RSAEncryptOAEP(testPrivKey, in)
+		require.ErrorContains(t, err, "failed to parse public key")
+	})
+}
+
 func TestRSAGenerateKey(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,187 @@
+package gomplate
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// dryRunStatus describes what would happen to an output file
+type dryRunStatus string
+
+const (
+	dryRunCreated   dryRunStatus = "created"
+	dryRunChanged   dryRunStatus = "changed"
+	dryRunUnchanged dryRunStatus = "unchanged"
+)
+
+// dryRunReport collects the outputs that would have been written in dry-run
+// mode. It's safe for concurrent use.
+type dryRunReport struct {
+	files map[string]dryRunFile
+	mu    sync.Mutex
+}
+
+type dryRunFile struct {
+	status dryRunStatus
+	diff   string
+}
+
+type dryRunReportCtxKey struct{}
+
+func contextWithDryRunReport(ctx context.Context, r *dryRunReport) context.Context {
+	return context.WithValue(ctx, dryRunReportCtxKey{}, r)
+}
+
+// dryRunReportFromContext returns the dry-run report, or nil if this isn't a
+// dry run
+func dryRunReportFromContext(ctx context.Context) *dryRunReport {
+	r, _ := ctx.Value(dryRunReportCtxKey{}).(*dryRunReport)
+	return r
+}
+
+func newDryRunReport() *dryRunReport {
+	return &dryRunReport{files: map[string]dryRunFile{}}
+}
+
+// writer returns a writer that buffers the output, and compares it to the
+// current content of the named file when closed, instead of writing it. When
+// compressed is set, the current content is decompressed first.
+func (r *dryRunReport) writer(fsys fs.FS, filename string, compressed bool) io.WriteCloser {
+	return &dryRunWriter{report: r, fsys: fsys, name: filename, compressed: compressed}
+}
+
+func (r *dryRunReport) add(name string, f dryRunFile) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.files[name] = f
+}
+
+// changes returns the number of files that would be created or changed
+func (r *dryRunReport) changes() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	n := 0
+	for _, f := range r.files {
+		if f.status != dryRunUnchanged {
+			n++
+		}
+	}
+	return n
+}
+
+// print writes the diffs of all created and changed files, followed by a
+// summary, to w
+func (r *dryRunReport) print(w io.Writer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names := make([]string, 0, len(r.files))
+	for name := range r.files {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	counts := map[dryRunStatus]int{}
+	for _, name := range names {
+		f := r.files[name]
+		counts[f.status]++
+
+		if _, err := io.WriteString(w, f.diff); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range names {
+		if _, err := fmt.Fprintf(w, "%-9s %s\n", r.files[name].status, name); err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "dry run: %d created, %d changed, %d unchanged\n",
+		counts[dryRunCreated], counts[dryRunChanged], counts[dryRunUnchanged])
+	return err
+}
+
+type dryRunWriter struct {
+	report     *dryRunReport
+	fsys       fs.FS
+	name       string
+	buf        bytes.Buffer
+	compressed bool
+}
+
+func (d *dryRunWriter) Write(p []byte) (int, error) {
+	return d.buf.Write(p)
+}
+
+// Abort - implements iohelpers.Aborter. Output from failed renders isn't
+// reported.
+func (d *dryRunWriter) Abort() error {
+	d.buf.Reset()
+	return nil
+}
+
+// Close - implements io.Closer, recording the result in the report
+func (d *dryRunWriter) Close() error {
+	from := "a/" + d.name
+	status := dryRunChanged
+
+	current, err := fs.ReadFile(d.fsys, d.name)
+	if err == nil && d.compressed {
+		current = gunzipOrNil(current)
+	}
+
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		from = "/dev/null"
+		status = dryRunCreated
+	case err != nil:
+		return fmt.Errorf("failed to read %q for comparison: %w", d.name, err)
+	case bytes.Equal(current, d.buf.Bytes()):
+		d.report.add(d.name, dryRunFile{status: dryRunUnchanged})
+		return nil
+	}
+
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        diffLines(string(current)),
+		B:        diffLines(d.buf.String()),
+		FromFile: from,
+		ToFile:   "b/" + d.name,
+		Context:  3,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to diff %q: %w", d.name, err)
+	}
+
+	d.report.add(d.name, dryRunFile{status: status, diff: diff})
+
+	return nil
+}
+
+// diffLines splits s into lines for diffing, each ending in a newline (one is
+// added to the last line if missing)
+func diffLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+
+	lines := strings.SplitAfter(s, "\n")
+	if last := len(lines) - 1; lines[last] == "" {
+		lines = lines[:last]
+	} else {
+		lines[last] += "\n"
+	}
+
+	return lines
+}
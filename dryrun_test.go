@@ -0,0 +1,76 @@
+package gomplate
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/hack-pad/hackpadfs"
+	"github.com/hack-pad/hackpadfs/mem"
+	"github.com/hairyhenderson/gomplate/v4/internal/iohelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDryRunReport(t *testing.T) {
+	fsys, _ := mem.NewFS()
+	require.NoError(t, hackpadfs.WriteFullFile(fsys, "changed", []byte("one\ntwo\nthree\n"), 0o644))
+	require.NoError(t, hackpadfs.WriteFullFile(fsys, "same", []byte("same\n"), 0o644))
+
+	r := newDryRunReport()
+
+	outputs := map[string]string{
+		"changed": "one\n2\nthree\n",
+		"same":    "same\n",
+		"created": "new\n",
+	}
+	for name, content := range outputs {
+		w := r.writer(fsys, name, false)
+		_, err := w.Write([]byte(content))
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+	}
+
+	// aborted output isn't reported
+	w := r.writer(fsys, "failed", false)
+	_, err := w.Write([]byte("partial"))
+	require.NoError(t, err)
+	require.NoError(t, iohelpers.Abort(w))
+
+	assert.Equal(t, 2, r.changes())
+
+	out := &bytes.Buffer{}
+	require.NoError(t, r.print(out))
+	assert.Equal(t, `--- a/changed
++++ b/changed
+@@ -1,3 +1,3 @@
+ one
+-two
++2
+ three
+--- /dev/null
++++ b/created
+@@ -0,0 +1 @@
++new
+changed   changed
+created   created
+unchanged same
+dry run: 1 created, 1 changed, 1 unchanged
+`, out.String())
+
+	// nothing was written
+	_, err = hackpadfs.Stat(fsys, "created")
+	require.Error(t, err)
+	b, err := hackpadfs.ReadFile(fsys, "changed")
+	require.NoError(t, err)
+	assert.Equal(t, "one\ntwo\nthree\n", string(b))
+}
+
+func TestDryRunReportFromContext(t *testing.T) {
+	ctx := context.Background()
+	assert.Nil(t, dryRunReportFromContext(ctx))
+
+	r := newDryRunReport()
+	ctx = contextWithDryRunReport(ctx, r)
+	assert.Same(t, r, dryRunReportFromContext(ctx))
+}
@@ -0,0 +1,143 @@
+package gomplate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// TemplateError is returned when a template fails to parse or render. The
+// line, column, and failing function are taken from the underlying
+// text/template error, when it has them.
+type TemplateError struct {
+	// Err is the underlying error
+	Err error
+	// Template is the name of the template that failed - usually its path,
+	// or "<arg>" for a template given inline
+	Template string
+	// Source is the nested template the error occurred in, when it isn't
+	// Template itself. Line and Column are relative to it.
+	Source string
+	// Func is the name of the function that failed, if any
+	Func string
+	// Op is either "parse" or "render"
+	Op     string
+	Line   int
+	Column int
+}
+
+func (e *TemplateError) Error() string {
+	msg := "failed to render template " + e.Template
+	if e.Op == "parse" {
+		msg = "parse template " + e.Template
+	}
+
+	loc := []string{}
+	if e.Line > 0 {
+		l := "line " + strconv.Itoa(e.Line)
+		if e.Source != "" {
+			l += " of " + strconv.Quote(e.Source)
+		}
+
+		loc = append(loc, l)
+	}
+
+	if e.Func != "" {
+		loc = append(loc, "function "+strconv.Quote(e.Func))
+	}
+
+	if len(loc) > 0 {
+		msg += " (" + strings.Join(loc, ", ") + ")"
+	}
+
+	return msg + ": " + e.Err.Error()
+}
+
+func (e *TemplateError) Unwrap() error {
+	return e.Err
+}
+
+//nolint:gochecknoglobals
+var (
+	// the location prefix of text/template errors, as "template: name:line: "
+	// for parse errors, or "template: name:line:col: " for execution errors
+	tmplErrLocation = regexp.MustCompile(`template: (.+?):(\d+)(?::(\d+))?: `)
+
+	tmplErrNode      = regexp.MustCompile(`executing ".*?" at <([^>\s]+)`)
+	tmplErrCall      = regexp.MustCompile(`error calling ([^:\s]+): `)
+	tmplErrUndefined = regexp.MustCompile(`function "([^"]+)" not defined`)
+)
+
+// newTemplateError wraps err, which came from parsing or executing the named
+// template. Only the outermost location is used, when errors from templates
+// rendered with tmpl.Exec or tpl are nested inside err.
+func newTemplateError(op, name string, err error) *TemplateError {
+	te := &TemplateError{Err: err, Template: name, Op: op}
+
+	msg := err.Error()
+
+	if m := tmplErrLocation.FindStringSubmatch(msg); m != nil {
+		if m[1] != name {
+			te.Source = m[1]
+		}
+
+		te.Line, _ = strconv.Atoi(m[2])
+		te.Column, _ = strconv.Atoi(m[3])
+	}
+
+	if m := tmplErrCall.FindStringSubmatch(msg); m != nil {
+		te.Func = m[1]
+
+		// namespaced functions are only reported by their method name, but
+		// the node being executed has the full name
+		if n := tmplErrNode.FindStringSubmatch(msg); n != nil && strings.HasSuffix(n[1], "."+m[1]) &&
+			!strings.HasPrefix(n[1], ".") && !strings.HasPrefix(n[1], "$") {
+			te.Func = n[1]
+		}
+	} else if m := tmplErrUndefined.FindStringSubmatch(msg); m != nil {
+		te.Func = m[1]
+	}
+
+	return te
+}
+
+type errorJSON struct {
+	Error    string `json:"error"`
+	Template string `json:"template,omitempty"`
+	Source   string `json:"source,omitempty"`
+	Op       string `json:"op,omitempty"`
+	Func     string `json:"function,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+}
+
+// writeErrorJSON writes err to w as a line of JSON, including the template
+// details when err is (or wraps) a TemplateError
+func writeErrorJSON(w io.Writer, err error) error {
+	out := errorJSON{Error: err.Error()}
+
+	var te *TemplateError
+	if errors.As(err, &te) {
+		out.Template = te.Template
+		out.Source = te.Source
+		out.Op = te.Op
+		out.Func = te.Func
+		out.Line = te.Line
+		out.Column = te.Column
+	}
+
+	// json.Encoder terminates the output with a newline, and template names
+	// like "<arg>" shouldn't be escaped
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+
+	if err := enc.Encode(out); err != nil {
+		return fmt.Errorf("failed to write error: %w", err)
+	}
+
+	return nil
+}
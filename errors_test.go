@@ -0,0 +1,152 @@
+package gomplate
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplateError(t *testing.T) {
+	tr := NewRenderer(RenderOptions{})
+
+	testdata := []struct {
+		in       string
+		expected TemplateError
+		msg      string
+	}{
+		{
+			"hello\n{{ fail \"boom\" }}",
+			TemplateError{Op: "render", Func: "fail", Line: 2, Column: 3},
+			`failed to render template foo.tmpl (line 2, function "fail"): template: foo.tmpl:2:3: ` +
+				`executing "foo.tmpl" at <fail "boom">: error calling fail: template generation failed: boom`,
+		},
+		{
+			`{{ "a" | strings.Repeat -1 }}`,
+			TemplateError{Op: "render", Func: "strings.Repeat", Line: 1, Column: 16},
+			`failed to render template foo.tmpl (line 1, function "strings.Repeat"): `,
+		},
+		{
+			"\n\n{{ nope }}",
+			TemplateError{Op: "parse", Func: "nope", Line: 3},
+			`parse template foo.tmpl (line 3, function "nope"): template: foo.tmpl:3: function "nope" not defined`,
+		},
+		{
+			`{{ .foo }}`,
+			TemplateError{Op: "render", Line: 1, Column: 3},
+			`failed to render template foo.tmpl (line 1): template: foo.tmpl:1:3: `,
+		},
+		{
+			"{{ define \"inner\" }}\n{{ fail }}{{ end }}{{ template \"inner\" }}",
+			TemplateError{Op: "render", Func: "fail", Line: 2, Column: 3},
+			`failed to render template foo.tmpl (line 2, function "fail"): `,
+		},
+		{
+			// only the outermost failure is reported
+			`{{ tmpl.Inline "{{ fail }}" }}`,
+			TemplateError{Op: "render", Func: "tmpl.Inline", Line: 1, Column: 7},
+			`failed to render template foo.tmpl (line 1, function "tmpl.Inline"): `,
+		},
+	}
+
+	for _, d := range testdata {
+		t.Run(d.in, func(t *testing.T) {
+			err := tr.Render(context.Background(), "foo.tmpl", d.in, &bytes.Buffer{})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), d.msg)
+
+			var te *TemplateError
+			require.ErrorAs(t, err, &te)
+
+			d.expected.Template = "foo.tmpl"
+			d.expected.Err = te.Err
+			assert.Equal(t, d.expected, *te)
+		})
+	}
+
+	t.Run("nested templates", func(t *testing.T) {
+		te := newTemplateError("render", "main.tmpl",
+			errors.New(`template: inner.t:4:2: executing "inner.t" at <fail>: error calling fail: oops`))
+		assert.Equal(t, "inner.t", te.Source)
+		assert.Equal(t, 4, te.Line)
+		assert.Equal(t, `failed to render template main.tmpl (line 4 of "inner.t", function "fail"): `+
+			`template: inner.t:4:2: executing "inner.t" at <fail>: error calling fail: oops`, te.Error())
+	})
+
+	t.Run("other errors", func(t *testing.T) {
+		te := newTemplateError("render", "main.tmpl", errors.New("write failed"))
+		assert.Equal(t, "failed to render template main.tmpl: write failed", te.Error())
+		assert.Zero(t, te.Line)
+		assert.Empty(t, te.Func)
+	})
+}
+
+func TestWriteErrorJSON(t *testing.T) {
+	out := &bytes.Buffer{}
+
+	err := fmt.Errorf("renderTemplate: %w", &TemplateError{
+		Err:      errors.New("oops"),
+		Template: "<arg>",
+		Op:       "render",
+		Func:     "fail",
+		Line:     2,
+		Column:   3,
+	})
+	require.NoError(t, writeErrorJSON(out, err))
+
+	assert.JSONEq(t, `{
+		"error": "renderTemplate: failed to render template <arg> (line 2, function \"fail\"): oops",
+		"template": "<arg>",
+		"op": "render",
+		"function": "fail",
+		"line": 2,
+		"column": 3
+	}`, out.String())
+
+	// a single line, without HTML escaping
+	assert.Equal(t, 1, strings.Count(out.String(), "\n"))
+	assert.Contains(t, out.String(), `"template":"<arg>"`)
+
+	out.Reset()
+	require.NoError(t, writeErrorJSON(out, errors.New("failed to gather templates")))
+	assert.JSONEq(t, `{"error": "failed to gather templates"}`, out.String())
+}
+
+func TestRun_ErrorFormatJSON(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+
+	cfg := &Config{
+		Input:       "hello\n{{ fail }}",
+		Stdout:      stdout,
+		Stderr:      stderr,
+		ErrorFormat: "json",
+	}
+	require.Error(t, Run(context.Background(), cfg))
+	assert.Contains(t, stderr.String(), `"template":"<arg>","op":"render","function":"fail","line":2`)
+
+	// nothing is written when rendering succeeds
+	stderr.Reset()
+	cfg = &Config{
+		Input:       "hello",
+		Stdout:      stdout,
+		Stderr:      stderr,
+		ErrorFormat: "json",
+	}
+	require.NoError(t, Run(context.Background(), cfg))
+	assert.Empty(t, stderr.String())
+
+	// errors are only written as JSON when asked
+	cfg = &Config{
+		Input:  "{{ fail }}",
+		Stdout: stdout,
+		Stderr: stderr,
+	}
+	require.Error(t, Run(context.Background(), cfg))
+	assert.Empty(t, stderr.String())
+}
@@ -0,0 +1,160 @@
+package gomplate
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/hairyhenderson/gomplate/v4/internal/iohelpers"
+	"github.com/hairyhenderson/yaml"
+)
+
+// frontMatter holds the settings a template file can declare in a YAML block
+// at the top of the file, delimited by "---" lines
+type frontMatter struct {
+	DataSources map[string]DataSource `yaml:"datasources"`
+	Out         string                `yaml:"out"`
+	Chmod       string                `yaml:"chmod"`
+	LDelim      string                `yaml:"leftDelim"`
+	RDelim      string                `yaml:"rightDelim"`
+}
+
+// frontMatterKeys are the keys recognized in front matter - others are
+// ignored with a warning
+//
+//nolint:gochecknoglobals
+var frontMatterKeys = []string{"chmod", "datasources", "leftDelim", "out", "rightDelim"}
+
+// parseFrontMatter splits the front matter (if any) from the template text.
+// So that line numbers in error messages still match the file, the front
+// matter is replaced with a template comment spanning the same number of
+// lines.
+func parseFrontMatter(ctx context.Context, name, text, lDelim, rDelim string) (*frontMatter, string, error) {
+	first, _, ok := strings.Cut(text, "\n")
+	if !ok || strings.TrimSuffix(first, "\r") != "---" {
+		return nil, text, nil
+	}
+
+	end := -1
+	offset := len(first) + 1
+	for offset < len(text) {
+		line, _, _ := strings.Cut(text[offset:], "\n")
+		if strings.TrimSuffix(line, "\r") == "---" {
+			end = offset + len(line)
+			break
+		}
+		offset += len(line) + 1
+	}
+
+	if end < 0 {
+		return nil, "", fmt.Errorf("front matter in %s isn't closed with a '---' line", name)
+	}
+
+	raw := text[len(first)+1 : offset]
+
+	node := yaml.Node{}
+	if err := yaml.Unmarshal([]byte(raw), &node); err != nil {
+		return nil, "", fmt.Errorf("invalid front matter in %s: %w", name, err)
+	}
+
+	fm := &frontMatter{}
+	if len(node.Content) > 0 {
+		m := node.Content[0]
+		if m.Kind != yaml.MappingNode {
+			return nil, "", fmt.Errorf("invalid front matter in %s: must be a map", name)
+		}
+
+		for i := 0; i < len(m.Content); i += 2 {
+			if k := m.Content[i].Value; !slices.Contains(frontMatterKeys, k) {
+				slog.WarnContext(ctx, "ignoring unknown front matter key",
+					"template", name, "key", k)
+			}
+		}
+
+		if err := m.Decode(fm); err != nil {
+			return nil, "", fmt.Errorf("invalid front matter in %s: %w", name, err)
+		}
+	}
+
+	// skip the closing delimiter's line ending too
+	if end < len(text) {
+		end++
+	}
+
+	// the comment must use the template's own delimiters
+	if fm.LDelim != "" {
+		lDelim = fm.LDelim
+	}
+	if fm.RDelim != "" {
+		rDelim = fm.RDelim
+	}
+
+	lines := strings.Count(text[:end], "\n")
+	body := lDelim + "/*" + strings.Repeat("\n", lines) + "*/" + rDelim + text[end:]
+
+	return fm, body, nil
+}
+
+// apply returns the output file and mode for the template, as overridden by
+// the front matter
+func (fm *frontMatter) apply(ctx context.Context, cfg *Config, inFile, outFile string, mode os.FileMode, modeOverride bool) (string, os.FileMode, bool, error) {
+	if out := cfg.outputName(fm.outFile(outFile)); out != outFile {
+		slog.DebugContext(ctx, "output file set by front matter",
+			"template", inFile, "out", out)
+
+		outFile = out
+
+		// chmod globs may match the new path
+		var err error
+		mode, modeOverride, err = cfg.getMode(outFile)
+		if err != nil {
+			return "", 0, false, err
+		}
+	}
+
+	m, override, err := fm.mode()
+	if err != nil {
+		return "", 0, false, fmt.Errorf("%s: %w", inFile, err)
+	}
+
+	if override {
+		mode, modeOverride = m, override
+	}
+
+	return outFile, mode, modeOverride, nil
+}
+
+// outFile returns the output file declared in the front matter, or the
+// default if none is declared. Relative paths are relative to the default
+// output file's directory, so that in an output directory they stay inside
+// it.
+func (fm *frontMatter) outFile(defaultOut string) string {
+	if fm.Out == "" {
+		return defaultOut
+	}
+
+	if fm.Out == "-" || filepath.IsAbs(fm.Out) || defaultOut == "-" {
+		return fm.Out
+	}
+
+	return filepath.Join(filepath.Dir(defaultOut), fm.Out)
+}
+
+// mode returns the output mode declared in the front matter, if any
+func (fm *frontMatter) mode() (os.FileMode, bool, error) {
+	if fm.Chmod == "" {
+		return 0, false, nil
+	}
+
+	m, err := strconv.ParseUint(fm.Chmod, 8, 32)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid chmod %q in front matter: %w", fm.Chmod, err)
+	}
+
+	return iohelpers.NormalizeFileMode(os.FileMode(m)), true, nil
+}
@@ -0,0 +1,282 @@
+package gomplate
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/url"
+	"os"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hack-pad/hackpadfs"
+	"github.com/hack-pad/hackpadfs/mem"
+	"github.com/hairyhenderson/go-fsimpl"
+	"github.com/hairyhenderson/gomplate/v4/internal/datafs"
+	"github.com/hairyhenderson/gomplate/v4/internal/iohelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFrontMatter(t *testing.T) {
+	ctx := context.Background()
+
+	// no front matter
+	for _, in := range []string{"", "hello", "---", "--- \nfoo\n---\n", "foo\n---\nbar\n---\n"} {
+		fm, body, err := parseFrontMatter(ctx, "t", in, "{{", "}}")
+		require.NoError(t, err)
+		assert.Nil(t, fm)
+		assert.Equal(t, in, body)
+	}
+
+	fm, body, err := parseFrontMatter(ctx, "t", "---\nout: foo.txt\nchmod: '0755'\n"+
+		"datasources:\n  cfg:\n    url: config.json\nunknown: true\n---\nhello\n", "{{", "}}")
+	require.NoError(t, err)
+	require.NotNil(t, fm)
+	assert.Equal(t, "foo.txt", fm.Out)
+	assert.Equal(t, "0755", fm.Chmod)
+	require.Contains(t, fm.DataSources, "cfg")
+	assert.Equal(t, "config.json", fm.DataSources["cfg"].URL.Path)
+
+	// the front matter is replaced by a comment with the same number of lines
+	assert.Equal(t, "{{/*\n\n\n\n\n\n\n\n*/}}hello\n", body)
+
+	fm, body, err = parseFrontMatter(ctx, "t", "---\r\n---\r\nhello", "<<", ">>")
+	require.NoError(t, err)
+	assert.Equal(t, &frontMatter{}, fm)
+	assert.Equal(t, "<</*\n\n*/>>hello", body)
+
+	// the comment uses the template's own delimiters
+	fm, body, err = parseFrontMatter(ctx, "t", "---\nleftDelim: '<%'\nrightDelim: '%>'\n---\n{ <% .x %> }", "{{", "}}")
+	require.NoError(t, err)
+	assert.Equal(t, &frontMatter{LDelim: "<%", RDelim: "%>"}, fm)
+	assert.Equal(t, "<%/*\n\n\n\n*/%>{ <% .x %> }", body)
+
+	_, body, err = parseFrontMatter(ctx, "t", "---\nout: foo\n---", "{{", "}}")
+	require.NoError(t, err)
+	assert.Equal(t, "{{/*\n\n*/}}", body)
+
+	_, _, err = parseFrontMatter(ctx, "t", "---\nout: foo\nhello\n", "{{", "}}")
+	require.EqualError(t, err, "front matter in t isn't closed with a '---' line")
+
+	_, _, err = parseFrontMatter(ctx, "t", "---\n- foo\n---\n", "{{", "}}")
+	require.EqualError(t, err, "invalid front matter in t: must be a map")
+
+	_, _, err = parseFrontMatter(ctx, "t", "---\nout: [foo\n---\n", "{{", "}}")
+	require.ErrorContains(t, err, "invalid front matter in t:")
+}
+
+func TestFrontMatter_OutFile(t *testing.T) {
+	fm := &frontMatter{}
+	assert.Equal(t, "out/a.txt", fm.outFile("out/a.txt"))
+
+	fm.Out = "b.txt"
+	assert.Equal(t, "out/b.txt", fm.outFile("out/a.txt"))
+	assert.Equal(t, "b.txt", fm.outFile("-"))
+
+	fm.Out = "../b.txt"
+	assert.Equal(t, "b.txt", fm.outFile("out/a.txt"))
+
+	fm.Out = "/tmp/b.txt"
+	assert.Equal(t, "/tmp/b.txt", fm.outFile("out/a.txt"))
+
+	fm.Out = "-"
+	assert.Equal(t, "-", fm.outFile("out/a.txt"))
+}
+
+func TestFrontMatter_Mode(t *testing.T) {
+	fm := &frontMatter{}
+	mode, override, err := fm.mode()
+	require.NoError(t, err)
+	assert.False(t, override)
+	assert.Equal(t, os.FileMode(0), mode)
+
+	fm.Chmod = "755"
+	mode, override, err = fm.mode()
+	require.NoError(t, err)
+	assert.True(t, override)
+	assert.Equal(t, iohelpers.NormalizeFileMode(0o755), mode)
+
+	fm.Chmod = "rwx"
+	_, _, err = fm.mode()
+	require.ErrorContains(t, err, `invalid chmod "rwx" in front matter`)
+}
+
+func TestGatherTemplates_FrontMatter(t *testing.T) {
+	wd, _ := os.Getwd()
+	t.Cleanup(func() {
+		_ = os.Chdir(wd)
+	})
+	_ = os.Chdir("/")
+
+	fsys, _ := mem.NewFS()
+
+	_ = hackpadfs.Mkdir(fsys, "in", 0o777)
+	_ = hackpadfs.WriteFullFile(fsys, "in/a", []byte("---\nout: sub/renamed.sh\nchmod: '0755'\n---\nfoo"), 0o644)
+	_ = hackpadfs.WriteFullFile(fsys, "in/b", []byte("---\nout: c\n---\nbar"), 0o644)
+	_ = hackpadfs.WriteFullFile(fsys, "in/c", []byte("baz"), 0o644)
+
+	ctx := datafs.ContextWithFSProvider(context.Background(), datafs.WrappedFSProvider(fsys, "file"))
+
+	cfg := &Config{
+		InputDir:    "in",
+		OutputDir:   "out",
+		FrontMatter: true,
+	}
+	cfg.applyDefaults()
+
+	// in/b's front matter sends it to the same output as in/c
+	_, err := gatherTemplates(ctx, cfg, simpleNamer("out"))
+	require.ErrorContains(t, err, `input files "in/b" and "in/c" would both be written to "out/c"`)
+
+	_ = hackpadfs.Remove(fsys, "in/c")
+
+	templates, err := gatherTemplates(ctx, cfg, simpleNamer("out"))
+	require.NoError(t, err)
+	require.Len(t, templates, 2)
+	assert.Equal(t, "{{/*\n\n\n\n*/}}foo", templates[0].Text)
+
+	for _, tpl := range templates {
+		_, err = tpl.Writer.Write([]byte("hello"))
+		require.NoError(t, err)
+		require.NoError(t, tpl.Writer.(io.Closer).Close())
+	}
+
+	info, err := hackpadfs.Stat(fsys, "out/sub/renamed.sh")
+	require.NoError(t, err)
+	assert.Equal(t, iohelpers.NormalizeFileMode(0o755), info.Mode())
+
+	info, err = hackpadfs.Stat(fsys, "out/c")
+	require.NoError(t, err)
+	assert.Equal(t, iohelpers.NormalizeFileMode(0o644), info.Mode())
+
+	// front matter is only parsed when enabled
+	_ = hackpadfs.RemoveAll(fsys, "out")
+	cfg.FrontMatter = false
+
+	templates, err = gatherTemplates(ctx, cfg, simpleNamer("out"))
+	require.NoError(t, err)
+	require.Len(t, templates, 2)
+	assert.Equal(t, "---\nout: sub/renamed.sh\nchmod: '0755'\n---\nfoo", templates[0].Text)
+}
+
+func TestRenderTemplate_DataSources(t *testing.T) {
+	t.Setenv("FOO", "foo")
+	t.Setenv("BAR", "bar")
+
+	ctx := datafs.ContextWithFSProvider(context.Background(), DefaultFSProvider)
+
+	fu, _ := url.Parse("env:FOO")
+	bu, _ := url.Parse("env:BAR")
+
+	tr := newRenderer(RenderOptions{
+		Datasources: map[string]DataSource{"foo": {URL: fu}},
+	})
+
+	a := &bytes.Buffer{}
+	b := &bytes.Buffer{}
+	err := tr.RenderTemplates(ctx, []Template{
+		{
+			Name:        "a",
+			Text:        `{{ ds "foo" }} {{ ds "bar" }} {{ listDatasources }}`,
+			Writer:      a,
+			DataSources: map[string]DataSource{"bar": {URL: bu}},
+		},
+		{
+			Name:   "b",
+			Text:   `{{ ds "foo" }} {{ listDatasources }}`,
+			Writer: b,
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "foo bar [bar foo]", a.String())
+
+	// the datasource is only available to the template that declares it
+	assert.Equal(t, "foo [foo]", b.String())
+}
+
+func TestRenderTemplate_DataSourcesDefine(t *testing.T) {
+	t.Setenv("FOO", "foo")
+	t.Setenv("BAR", "bar")
+
+	ctx := datafs.ContextWithFSProvider(context.Background(), DefaultFSProvider)
+
+	bu, _ := url.Parse("env:BAR")
+
+	tr := newRenderer(RenderOptions{})
+
+	a := &bytes.Buffer{}
+	b := &bytes.Buffer{}
+	err := tr.RenderTemplates(ctx, []Template{
+		{
+			Name:        "a",
+			Text:        `{{ defineDatasource "foo" "env:FOO" }}{{ ds "foo" }} {{ ds "bar" }}`,
+			Writer:      a,
+			DataSources: map[string]DataSource{"bar": {URL: bu}},
+		},
+		{
+			Name:   "b",
+			Text:   `{{ ds "foo" }} {{ listDatasources }}`,
+			Writer: b,
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "foo bar", a.String())
+
+	// datasources defined by a template with front matter are still global
+	assert.Equal(t, "foo [foo]", b.String())
+
+	// so redefining them elsewhere is still an error
+	err = tr.RenderTemplates(ctx, []Template{
+		{
+			Name:        "a",
+			Text:        `{{ defineDatasource "baz" "env:FOO" }}`,
+			Writer:      &bytes.Buffer{},
+			DataSources: map[string]DataSource{"bar": {URL: bu}},
+		},
+		{
+			Name:   "b",
+			Text:   `{{ defineDatasource "baz" "env:BAR" }}`,
+			Writer: &bytes.Buffer{},
+		},
+	})
+	require.ErrorContains(t, err, `datasource "baz" is already defined`)
+}
+
+func TestRenderTemplate_Delims(t *testing.T) {
+	wd, _ := os.Getwd()
+	t.Cleanup(func() {
+		_ = os.Chdir(wd)
+	})
+	_ = os.Chdir("/")
+
+	fsys := fstest.MapFS{
+		"nested.tmpl": &fstest.MapFile{Data: []byte(`[[ . | toUpper ]]`)},
+	}
+	fsp := fsimpl.NewMux()
+	fsp.Add(datafs.WrappedFSProvider(fsys, "file", ""))
+	ctx := datafs.ContextWithFSProvider(context.Background(), fsp)
+
+	nu, _ := url.Parse("nested.tmpl")
+
+	tr := newRenderer(RenderOptions{
+		Templates: map[string]DataSource{"nested": {URL: nu}},
+		LDelim:    "[[",
+		RDelim:    "]]",
+	})
+
+	a := &bytes.Buffer{}
+	b := &bytes.Buffer{}
+	c := &bytes.Buffer{}
+	err := tr.RenderTemplates(ctx, []Template{
+		{Name: "a", Text: `{{ [[ "a" ]] }}`, Writer: a},
+		// nested templates keep the renderer's delimiters
+		{Name: "b", Text: `{ <% template "nested" "b" %> }`, Writer: b, LDelim: "<%", RDelim: "%>"},
+		// unset delimiters fall back to the renderer's
+		{Name: "c", Text: `[[ "c" %>`, Writer: c, RDelim: "%>"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "{{ a }}", a.String())
+	assert.Equal(t, "{ B }", b.String())
+	assert.Equal(t, "c", c.String())
+}
@@ -15,6 +15,7 @@ func CreateFuncs(ctx context.Context) template.FuncMap {
 	addToMap(f, funcs.CreateAWSFuncs(ctx))
 	addToMap(f, funcs.CreateGCPFuncs(ctx))
 	addToMap(f, funcs.CreateBase64Funcs(ctx))
+	addToMap(f, funcs.CreateBase32Funcs(ctx))
 	addToMap(f, funcs.CreateNetFuncs(ctx))
 	addToMap(f, funcs.CreateReFuncs(ctx))
 	addToMap(f, funcs.CreateStringFuncs(ctx))
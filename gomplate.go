@@ -16,13 +16,25 @@ import (
 )
 
 // Run all gomplate templates specified by the given configuration
-func Run(ctx context.Context, cfg *Config) error {
+func Run(ctx context.Context, cfg *Config) (err error) {
 	Metrics = newMetrics()
 
 	// apply defaults before validation
 	cfg.applyDefaults()
 
-	err := cfg.validate()
+	if cfg.ErrorFormat == "json" {
+		defer func() {
+			if err == nil {
+				return
+			}
+
+			if werr := writeErrorJSON(cfg.Stderr, err); werr != nil {
+				slog.WarnContext(ctx, "couldn't report error", "err", werr)
+			}
+		}()
+	}
+
+	err = cfg.validate()
 	if err != nil {
 		return fmt.Errorf("failed to validate config: %w\n%+v", err, cfg)
 	}
@@ -41,6 +53,13 @@ func Run(ctx context.Context, cfg *Config) error {
 		return err
 	}
 
+	// close any datasource connections that were kept open between reads
+	defer func() {
+		if cerr := datafs.Cleanup(); cerr != nil {
+			slog.WarnContext(ctx, "couldn't close datasource connections", "err", cerr)
+		}
+	}()
+
 	// if a custom Stdin is set in the config, inject it into the context now
 	ctx = datafs.ContextWithStdin(ctx, cfg.Stdin)
 
@@ -51,6 +70,69 @@ func Run(ctx context.Context, cfg *Config) error {
 		ctx = datafs.ContextWithFSProvider(ctx, DefaultFSProvider)
 	}
 
+	err = runTemplatesWithMetrics(ctx, cfg, funcMap, nil)
+	if !cfg.Watch {
+		return err
+	}
+
+	// like later renders, a failed first render shouldn't stop the watch,
+	// since the fix is usually only a save away
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to render templates", slog.Any("err", err))
+	}
+
+	return watch(ctx, cfg, func(ctx context.Context, changed []string) error {
+		// each re-render is reported separately
+		Metrics = newMetrics()
+
+		return runTemplatesWithMetrics(ctx, cfg, funcMap, changed)
+	})
+}
+
+// runTemplatesWithMetrics runs the templates, and then writes the metrics to
+// Stderr as JSON if MetricsJSON is set - even when rendering fails
+func runTemplatesWithMetrics(ctx context.Context, cfg *Config, funcMap template.FuncMap, changed []string) error {
+	err := runTemplates(ctx, cfg, funcMap, changed)
+
+	if cfg.MetricsJSON {
+		if merr := Metrics.WriteJSON(cfg.Stderr); merr != nil {
+			slog.WarnContext(ctx, "couldn't report metrics", "err", merr)
+		}
+	}
+
+	return err
+}
+
+// runTemplates gathers and renders all templates specified by the given
+// configuration. A new renderer is created on each call, so datasources are
+// always read fresh. When changed lists the (absolute) paths of files that
+// have changed, only the templates that may be affected are rendered - see
+// changedTemplates.
+func runTemplates(ctx context.Context, cfg *Config, funcMap template.FuncMap, changed []string) error {
+	var report *dryRunReport
+	if cfg.DryRun {
+		report = newDryRunReport()
+		ctx = contextWithDryRunReport(ctx, report)
+	}
+
+	if cfg.OutUser != "" || cfg.OutGroup != "" {
+		owner, err := newOutOwner(ctx, cfg.OutUser, cfg.OutGroup)
+		if err != nil {
+			return err
+		}
+		if owner != nil {
+			ctx = contextWithOutOwner(ctx, owner)
+		}
+	}
+
+	if len(cfg.PostExecEach) > 0 {
+		ctx = contextWithPostExecEach(ctx, &postExecEach{
+			args:   cfg.PostExecEach,
+			stdout: cfg.Stdout,
+			stderr: cfg.Stderr,
+		})
+	}
+
 	// extract the rendering options from the config
 	opts := optionsFromConfig(cfg)
 	opts.Funcs = funcMap
@@ -71,11 +153,58 @@ func Run(ctx context.Context, cfg *Config) error {
 	}
 	Metrics.TemplatesGathered = len(tmpl)
 
-	err = tr.RenderTemplates(ctx, tmpl)
+	err = tr.RenderTemplates(ctx, changedTemplates(tmpl, changed))
 	if err != nil {
 		return err
 	}
 
+	if report != nil {
+		return reportDryRun(cfg, report)
+	}
+
+	return nil
+}
+
+// changedTemplates returns the templates read from the changed files. When
+// nothing is known to have changed, or when any of the changed files isn't one
+// of the templates (a datasource, nested template, or archive, for example),
+// all templates are returned, since any of them could depend on it.
+func changedTemplates(tmpl []Template, changed []string) []Template {
+	if len(changed) == 0 {
+		return tmpl
+	}
+
+	byPath := make(map[string]Template, len(tmpl))
+	for _, t := range tmpl {
+		if p, err := filepath.Abs(t.Name); err == nil {
+			byPath[p] = t
+		}
+	}
+
+	out := make([]Template, 0, len(changed))
+	for _, p := range changed {
+		t, ok := byPath[p]
+		if !ok {
+			return tmpl
+		}
+
+		out = append(out, t)
+	}
+
+	return out
+}
+
+// reportDryRun prints the dry-run report, and returns an error if any files
+// would change and FailOnChange is set
+func reportDryRun(cfg *Config, report *dryRunReport) error {
+	if err := report.print(cfg.Stdout); err != nil {
+		return fmt.Errorf("failed to print dry-run report: %w", err)
+	}
+
+	if n := report.changes(); cfg.FailOnChange && n > 0 {
+		return fmt.Errorf("dry run: %d output file(s) would be created or changed", n)
+	}
+
 	return nil
 }
 
@@ -91,10 +220,20 @@ func (f outputNamerFunc) Name(ctx context.Context, inPath string) (string, error
 }
 
 func chooseNamer(cfg *Config, tr *renderer) outputNamer {
+	namer := mappingNamer(cfg.OutputMap, cfg.OutputDir, tr)
 	if cfg.OutputMap == "" {
-		return simpleNamer(cfg.OutputDir)
+		namer = simpleNamer(cfg.OutputDir)
 	}
-	return mappingNamer(cfg.OutputMap, tr)
+
+	// output names must be final before they're checked for collisions or
+	// matched against chmod globs
+	return outputNamerFunc(func(ctx context.Context, inPath string) (string, error) {
+		out, err := namer.Name(ctx, inPath)
+		if err != nil {
+			return "", err
+		}
+		return cfg.outputName(out), nil
+	})
 }
 
 func simpleNamer(outDir string) outputNamer {
@@ -104,7 +243,10 @@ func simpleNamer(outDir string) outputNamer {
 	})
 }
 
-func mappingNamer(outMap string, tr *renderer) outputNamer {
+// mappingNamer names outputs by rendering the output map. When outDir is set,
+// the rendered paths are relative to it, and must stay within it. Otherwise
+// they're used as-is, and may be absolute or outside the working directory.
+func mappingNamer(outMap, outDir string, tr *renderer) outputNamer {
 	return outputNamerFunc(func(ctx context.Context, inPath string) (string, error) {
 		tcontext, err := createTmplContext(ctx, tr.tctxAliases, tr.sr)
 		if err != nil {
@@ -133,6 +275,17 @@ func mappingNamer(outMap string, tr *renderer) outputNamer {
 			return "", fmt.Errorf("failed to render outputMap with ctx %+v and inPath %s: %w", tctx, inPath, err)
 		}
 
-		return filepath.Clean(strings.TrimSpace(out.String())), nil
+		outPath := strings.TrimSpace(out.String())
+		if outDir == "" {
+			return filepath.Clean(outPath), nil
+		}
+
+		// filepath.IsLocal rejects absolute paths, and paths that climb out
+		// of the directory once cleaned (but not "a/../b")
+		if !filepath.IsLocal(filepath.FromSlash(outPath)) {
+			return "", fmt.Errorf("output map gives %q for input %q: the path must be relative, and within the output directory %q", outPath, inPath, outDir)
+		}
+
+		return filepath.Join(outDir, outPath), nil
 	})
 }
@@ -158,6 +158,28 @@ func TestCustomDelim(t *testing.T) {
 	assert.Equal(t, "hi", testTemplate(t, g, `[print "hi"]`))
 }
 
+func TestChangedTemplates(t *testing.T) {
+	abs := func(s string) string {
+		p, err := filepath.Abs(filepath.FromSlash(s))
+		require.NoError(t, err)
+		return p
+	}
+
+	tmpl := []Template{{Name: "a.tmpl"}, {Name: "dir/b.tmpl"}, {Name: "<arg>"}}
+
+	assert.Equal(t, tmpl, changedTemplates(tmpl, nil))
+
+	assert.Equal(t, []Template{{Name: "dir/b.tmpl"}},
+		changedTemplates(tmpl, []string{abs("dir/b.tmpl")}))
+
+	assert.Equal(t, []Template{{Name: "dir/b.tmpl"}, {Name: "a.tmpl"}},
+		changedTemplates(tmpl, []string{abs("dir/b.tmpl"), abs("a.tmpl")}))
+
+	// anything else (like a datasource) changing means everything is rendered
+	assert.Equal(t, tmpl,
+		changedTemplates(tmpl, []string{abs("a.tmpl"), abs("data.json")}))
+}
+
 func TestSimpleNamer(t *testing.T) {
 	n := simpleNamer("out/")
 	out, err := n.Name(context.Background(), "file")
@@ -175,15 +197,54 @@ func TestMappingNamer(t *testing.T) {
 			"foo": func() string { return "foo" },
 		},
 	}
-	n := mappingNamer("out/{{ .in }}", tr)
+	n := mappingNamer("out/{{ .in }}", "", tr)
 	out, err := n.Name(ctx, "file")
 	require.NoError(t, err)
 	expected := filepath.FromSlash("out/file")
 	assert.Equal(t, expected, out)
 
-	n = mappingNamer("out/{{ foo }}{{ .in }}", tr)
+	n = mappingNamer("out/{{ foo }}{{ .in }}", "", tr)
 	out, err = n.Name(ctx, "file")
 	require.NoError(t, err)
 	expected = filepath.FromSlash("out/foofile")
 	assert.Equal(t, expected, out)
+
+	// without an output directory, any path can be given
+	n = mappingNamer("../rendered/{{ .in }}", "", tr)
+	out, err = n.Name(ctx, "file")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.FromSlash("../rendered/file"), out)
+
+	n = mappingNamer("/etc/{{ .in }}", "", tr)
+	out, err = n.Name(ctx, "file")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.FromSlash("/etc/file"), out)
+
+	// with one, paths are relative to it, and can't leave it
+	n = mappingNamer("{{ .in }}.out", "outdir", tr)
+	out, err = n.Name(ctx, "sub/file")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.FromSlash("outdir/sub/file.out"), out)
+
+	n = mappingNamer("a/../{{ .in }}", "outdir", tr)
+	out, err = n.Name(ctx, "file")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.FromSlash("outdir/file"), out)
+
+	n = mappingNamer("..{{ .in }}", "outdir", tr)
+	out, err = n.Name(ctx, "file")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.FromSlash("outdir/..file"), out)
+
+	n = mappingNamer("a/../../escaped-{{ .in }}", "outdir", tr)
+	_, err = n.Name(ctx, "file")
+	require.EqualError(t, err, `output map gives "a/../../escaped-file" for input "file": the path must be relative, and within the output directory "outdir"`)
+
+	n = mappingNamer("/etc/{{ .in }}", "outdir", tr)
+	_, err = n.Name(ctx, "file")
+	require.Error(t, err)
+
+	n = mappingNamer("{{ .nope }}", "outdir", tr)
+	_, err = n.Name(ctx, "file")
+	require.Error(t, err)
 }
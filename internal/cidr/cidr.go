@@ -53,18 +53,21 @@ func HostBig(base netip.Prefix, num *big.Int) (netip.Addr, error) {
 	maxHostNum.Lsh(maxHostNum, uint(hostLen))
 	maxHostNum.Sub(maxHostNum, big.NewInt(1))
 
-	numUint64 := big.NewInt(int64(num.Uint64()))
-	if num.Cmp(big.NewInt(0)) == -1 {
-		numUint64.Neg(num)
-		numUint64.Sub(numUint64, big.NewInt(int64(1)))
-		num.Sub(maxHostNum, numUint64)
+	// negative numbers count back from the end of the range, so -1 is the
+	// last host - num is copied so the caller's value isn't modified
+	hostNum := new(big.Int).Set(num)
+	offset := new(big.Int).Set(num)
+	if num.Sign() < 0 {
+		offset.Neg(num)
+		offset.Sub(offset, big.NewInt(1))
+		hostNum.Sub(maxHostNum, offset)
 	}
 
-	if numUint64.Cmp(maxHostNum) == 1 {
+	if offset.Cmp(maxHostNum) == 1 {
 		return netip.Addr{}, fmt.Errorf("prefix of %d does not accommodate a host numbered %d", parentLen, num)
 	}
 
-	return insertNumIntoIP(base.Masked().Addr(), num, addrLen), nil
+	return insertNumIntoIP(base.Masked().Addr(), hostNum, addrLen), nil
 }
 
 func ipToInt(ip netip.Addr) (*big.Int, int) {
@@ -147,13 +147,27 @@ func TestHostBig(t *testing.T) {
 			num:    big.NewInt(int64(-2)),
 			out:    "fd9d:bc11:4020:0:ffff:ffff:ffff:fffe",
 		},
+		{
+			prefix: "fd00::/48",
+			num:    new(big.Int).Lsh(big.NewInt(1), 64), // too large for a uint64
+			out:    "fd00:0:0:1::",
+		},
+		{
+			prefix: "10.0.0.0/8",
+			num:    new(big.Int).Lsh(big.NewInt(1), 64),
+			err:    true,
+		},
 	}
 
 	for _, testCase := range cases {
 		t.Run(fmt.Sprintf("HostBig(%v,%v)", testCase.prefix, testCase.num), func(t *testing.T) {
 			network := netip.MustParsePrefix(testCase.prefix)
 
-			gotIP, err := HostBig(network, testCase.num)
+			num := new(big.Int).Set(testCase.num)
+
+			gotIP, err := HostBig(network, num)
+			assert.Equal(t, testCase.num, num, "num must not be modified")
+
 			if testCase.err {
 				require.Error(t, err)
 			} else {
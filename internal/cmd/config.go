@@ -7,11 +7,14 @@ import (
 	"io"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"os"
 	"path"
 	"strings"
 	"time"
+	"unicode"
 
+	"github.com/anmitsu/go-shlex"
 	"github.com/hairyhenderson/gomplate/v4"
 	"github.com/hairyhenderson/gomplate/v4/conv"
 	"github.com/hairyhenderson/gomplate/v4/env"
@@ -109,6 +112,10 @@ func cobraConfig(cmd *cobra.Command, args []string) (cfg *gomplate.Config, err e
 	if err != nil {
 		return nil, err
 	}
+	cfg.InputURL, err = getString(cmd, "in-url")
+	if err != nil {
+		return nil, err
+	}
 	cfg.InputDir, err = getString(cmd, "input-dir")
 	if err != nil {
 		return nil, err
@@ -142,7 +149,11 @@ func cobraConfig(cmd *cobra.Command, args []string) (cfg *gomplate.Config, err e
 	if err != nil {
 		return nil, err
 	}
-	cfg.OutMode, err = getString(cmd, "chmod")
+	cfg.OutMode, cfg.OutModeGlobs, err = getChmod(cmd)
+	if err != nil {
+		return nil, err
+	}
+	cfg.OutUser, cfg.OutGroup, err = getChown(cmd)
 	if err != nil {
 		return nil, err
 	}
@@ -151,6 +162,18 @@ func cobraConfig(cmd *cobra.Command, args []string) (cfg *gomplate.Config, err e
 		cfg.PostExec = args
 	}
 
+	postExecEach, err := getString(cmd, "post-exec-each")
+	if err != nil {
+		return nil, err
+	}
+	if postExecEach != "" {
+		// split like a shell would, so arguments can be quoted
+		cfg.PostExecEach, err = shlex.Split(postExecEach, true)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --post-exec-each command %q: %w", postExecEach, err)
+		}
+	}
+
 	cfg.ExecPipe, err = getBool(cmd, "exec-pipe")
 	if err != nil {
 		return nil, err
@@ -159,6 +182,46 @@ func cobraConfig(cmd *cobra.Command, args []string) (cfg *gomplate.Config, err e
 	if err != nil {
 		return nil, err
 	}
+	cfg.Watch, err = getBool(cmd, "watch")
+	if err != nil {
+		return nil, err
+	}
+	cfg.DryRun, err = getBool(cmd, "dry-run")
+	if err != nil {
+		return nil, err
+	}
+	cfg.FailOnChange, err = getBool(cmd, "fail-on-change")
+	if err != nil {
+		return nil, err
+	}
+	cfg.Force, err = getBool(cmd, "force")
+	if err != nil {
+		return nil, err
+	}
+	cfg.Stream, err = getBool(cmd, "stream")
+	if err != nil {
+		return nil, err
+	}
+	cfg.DeleteEmpty, err = getBool(cmd, "delete-empty")
+	if err != nil {
+		return nil, err
+	}
+	cfg.FrontMatter, err = getBool(cmd, "front-matter")
+	if err != nil {
+		return nil, err
+	}
+	cfg.Combine, err = getBool(cmd, "combine")
+	if err != nil {
+		return nil, err
+	}
+	cfg.MetricsJSON, err = getBool(cmd, "metrics-json")
+	if err != nil {
+		return nil, err
+	}
+	cfg.NoCache, err = getBool(cmd, "no-cache")
+	if err != nil {
+		return nil, err
+	}
 
 	cfg.LDelim, err = getString(cmd, "left-delim")
 	if err != nil {
@@ -174,6 +237,21 @@ func cobraConfig(cmd *cobra.Command, args []string) (cfg *gomplate.Config, err e
 		return nil, err
 	}
 
+	cfg.ErrorFormat, err = getString(cmd, "error-format")
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.Compress, err = getString(cmd, "compress")
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.Parallelism, err = getInt(cmd, "parallelism")
+	if err != nil {
+		return nil, err
+	}
+
 	ds, err := getStringSlice(cmd, "datasource")
 	if err != nil {
 		return nil, err
@@ -220,6 +298,60 @@ func getString(cmd *cobra.Command, flag string) (s string, err error) {
 	return s, err
 }
 
+// getChmod processes the --chmod flags - values in glob=mode form are per-glob
+// overrides, and a plain mode sets the mode for all other output files
+func getChmod(cmd *cobra.Command) (mode string, globs []gomplate.OutModeGlob, err error) {
+	if cmd.Flag("chmod") == nil || !cmd.Flag("chmod").Changed {
+		return "", nil, nil
+	}
+
+	values, err := cmd.Flags().GetStringArray("chmod")
+	if err != nil {
+		return "", nil, err
+	}
+
+	for _, v := range values {
+		if !strings.Contains(v, "=") {
+			if mode != "" {
+				return "", nil, fmt.Errorf("only one --chmod value may be given without a glob, got %q and %q", mode, v)
+			}
+			mode = v
+
+			continue
+		}
+
+		g, err := gomplate.ParseOutModeGlob(v)
+		if err != nil {
+			return "", nil, err
+		}
+		globs = append(globs, g)
+	}
+
+	return mode, globs, nil
+}
+
+// getChown processes the --chown flag, given as user, user:group, or :group
+func getChown(cmd *cobra.Command) (user, group string, err error) {
+	owner, err := getString(cmd, "chown")
+	if err != nil || owner == "" {
+		return "", "", err
+	}
+
+	user, group, _ = strings.Cut(owner, ":")
+	if user == "" && group == "" {
+		return "", "", fmt.Errorf("invalid --chown value %q, must be user, user:group, or :group", owner)
+	}
+
+	return user, group, nil
+}
+
+func getInt(cmd *cobra.Command, flag string) (i int, err error) {
+	if cmd.Flag(flag) != nil && cmd.Flag(flag).Changed {
+		i, err = cmd.Flags().GetInt(flag)
+	}
+	return i, err
+}
+
 func getBool(cmd *cobra.Command, flag string) (b bool, err error) {
 	if cmd.Flag(flag) != nil && cmd.Flag(flag).Changed {
 		b, err = cmd.Flags().GetBool(flag)
@@ -257,10 +389,22 @@ func applyEnvVars(_ context.Context, cfg *gomplate.Config) (*gomplate.Config, er
 		cfg.PluginTimeout = t
 	}
 
+	if to := env.Getenv("GOMPLATE_TEMPLATE_TIMEOUT"); cfg.TemplateTimeout == 0 && to != "" {
+		t, err := time.ParseDuration(to)
+		if err != nil {
+			return nil, fmt.Errorf("GOMPLATE_TEMPLATE_TIMEOUT set to invalid value %q: %w", to, err)
+		}
+		cfg.TemplateTimeout = t
+	}
+
 	if !cfg.Experimental && conv.ToBool(env.Getenv("GOMPLATE_EXPERIMENTAL", "false")) {
 		cfg.Experimental = true
 	}
 
+	if !cfg.MetricsJSON && conv.ToBool(env.Getenv("GOMPLATE_METRICS_JSON", "false")) {
+		cfg.MetricsJSON = true
+	}
+
 	if cfg.LDelim == "" {
 		cfg.LDelim = env.Getenv("GOMPLATE_LEFT_DELIM")
 	}
@@ -433,13 +577,63 @@ func splitHeader(header string) (name, value string, err error) {
 	return name, value, nil
 }
 
+// parseTemplateArg parses a --template argument, in either alias=url or url
+// form. Only the first '=' separates the alias, so the URL may contain '='.
 func parseTemplateArg(value string) (alias string, ds gomplate.DataSource, err error) {
-	alias, u, _ := strings.Cut(value, "=")
-	if u == "" {
+	alias, u, found := strings.Cut(value, "=")
+	if found && isAbsURL(alias) {
+		// a bare URL with '=' in its query, like https://example.com/t?a=b
+		alias, u, found = value, value, false
+	}
+
+	if !found {
 		u = alias
+	} else if err = validateTemplateAlias(alias); err != nil {
+		return "", ds, fmt.Errorf("invalid template argument (%s): %w", value, err)
+	}
+
+	if u == "" {
+		return "", ds, fmt.Errorf("invalid template argument (%s): missing URL", value)
 	}
 
 	ds.URL, err = urlhelpers.ParseSourceURL(u)
 
 	return alias, ds, err
 }
+
+// isAbsURL returns true if s parses as a URL with a scheme
+func isAbsURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && u.IsAbs()
+}
+
+// validateTemplateAlias ensures an explicit template alias is usable as a
+// template name and as a prefix for the templates in a directory
+func validateTemplateAlias(alias string) error {
+	if alias == "" {
+		return fmt.Errorf("alias must not be empty")
+	}
+
+	if strings.ContainsFunc(alias, func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsControl(r)
+	}) {
+		return fmt.Errorf("alias %q must not contain whitespace or control characters", alias)
+	}
+
+	// these would make the alias look like a URL
+	if strings.ContainsAny(alias, ":?") {
+		return fmt.Errorf("alias %q must not contain ':' or '?'", alias)
+	}
+
+	if path.IsAbs(alias) {
+		return fmt.Errorf("alias %q must not be an absolute path", alias)
+	}
+
+	for _, elem := range strings.Split(alias, "/") {
+		if elem == ".." {
+			return fmt.Errorf("alias %q must not contain '..'", alias)
+		}
+	}
+
+	return nil
+}
@@ -164,6 +164,154 @@ func TestCobraConfig(t *testing.T) {
 		InputFiles: []string{"in"},
 		PostExec:   []string{"echo", "foo"},
 	}, cfg)
+
+	cmd = &cobra.Command{}
+	cmd.Flags().Int("parallelism", 8, "...")
+	cmd.ParseFlags([]string{"--parallelism", "2"})
+
+	cfg, err = cobraConfig(cmd, cmd.Flags().Args())
+	require.NoError(t, err)
+	assert.EqualValues(t, &gomplate.Config{Parallelism: 2}, cfg)
+
+	cmd = &cobra.Command{}
+	cmd.Flags().Bool("watch", false, "...")
+	cmd.ParseFlags([]string{"--watch"})
+
+	cfg, err = cobraConfig(cmd, cmd.Flags().Args())
+	require.NoError(t, err)
+	assert.EqualValues(t, &gomplate.Config{Watch: true}, cfg)
+
+	cmd = &cobra.Command{}
+	cmd.Flags().StringArray("chmod", []string{}, "...")
+	cmd.ParseFlags([]string{"--chmod", "*.sh=0755", "--chmod", "0600", "--chmod", "conf/*.yaml=0644"})
+
+	cfg, err = cobraConfig(cmd, cmd.Flags().Args())
+	require.NoError(t, err)
+	assert.EqualValues(t, &gomplate.Config{
+		OutMode: "0600",
+		OutModeGlobs: []gomplate.OutModeGlob{
+			{Glob: "*.sh", Mode: "0755"},
+			{Glob: "conf/*.yaml", Mode: "0644"},
+		},
+	}, cfg)
+
+	cmd = &cobra.Command{}
+	cmd.Flags().Bool("dry-run", false, "...")
+	cmd.Flags().Bool("fail-on-change", false, "...")
+	cmd.ParseFlags([]string{"--dry-run", "--fail-on-change"})
+
+	cfg, err = cobraConfig(cmd, cmd.Flags().Args())
+	require.NoError(t, err)
+	assert.EqualValues(t, &gomplate.Config{DryRun: true, FailOnChange: true}, cfg)
+
+	cmd = &cobra.Command{}
+	cmd.Flags().Bool("stream", false, "...")
+	cmd.ParseFlags([]string{"--stream"})
+
+	cfg, err = cobraConfig(cmd, cmd.Flags().Args())
+	require.NoError(t, err)
+	assert.EqualValues(t, &gomplate.Config{Stream: true}, cfg)
+
+	cmd = &cobra.Command{}
+	cmd.Flags().Bool("delete-empty", false, "...")
+	cmd.ParseFlags([]string{"--delete-empty"})
+
+	cfg, err = cobraConfig(cmd, cmd.Flags().Args())
+	require.NoError(t, err)
+	assert.EqualValues(t, &gomplate.Config{DeleteEmpty: true}, cfg)
+
+	cmd = &cobra.Command{}
+	cmd.Flags().Bool("front-matter", false, "...")
+	cmd.ParseFlags([]string{"--front-matter"})
+
+	cfg, err = cobraConfig(cmd, cmd.Flags().Args())
+	require.NoError(t, err)
+	assert.EqualValues(t, &gomplate.Config{FrontMatter: true}, cfg)
+
+	cmd = &cobra.Command{}
+	cmd.Flags().Bool("combine", false, "...")
+	cmd.ParseFlags([]string{"--combine"})
+
+	cfg, err = cobraConfig(cmd, cmd.Flags().Args())
+	require.NoError(t, err)
+	assert.EqualValues(t, &gomplate.Config{Combine: true}, cfg)
+
+	cmd = &cobra.Command{}
+	cmd.Flags().Bool("no-cache", false, "...")
+	cmd.ParseFlags([]string{"--no-cache"})
+
+	cfg, err = cobraConfig(cmd, cmd.Flags().Args())
+	require.NoError(t, err)
+	assert.EqualValues(t, &gomplate.Config{NoCache: true}, cfg)
+
+	cmd = &cobra.Command{}
+	cmd.Flags().String("error-format", "text", "...")
+	cmd.ParseFlags([]string{"--error-format", "json"})
+
+	cfg, err = cobraConfig(cmd, cmd.Flags().Args())
+	require.NoError(t, err)
+	assert.EqualValues(t, &gomplate.Config{ErrorFormat: "json"}, cfg)
+
+	cmd = &cobra.Command{}
+	cmd.Flags().String("compress", "", "...")
+	cmd.ParseFlags([]string{"--compress", "gzip"})
+
+	cfg, err = cobraConfig(cmd, cmd.Flags().Args())
+	require.NoError(t, err)
+	assert.EqualValues(t, &gomplate.Config{Compress: "gzip"}, cfg)
+
+	cmd = &cobra.Command{}
+	cmd.Flags().String("post-exec-each", "", "...")
+	cmd.ParseFlags([]string{"--post-exec-each", "gofmt -w {}"})
+
+	cfg, err = cobraConfig(cmd, cmd.Flags().Args())
+	require.NoError(t, err)
+	assert.EqualValues(t, &gomplate.Config{PostExecEach: []string{"gofmt", "-w", "{}"}}, cfg)
+
+	cmd = &cobra.Command{}
+	cmd.Flags().String("post-exec-each", "", "...")
+	cmd.ParseFlags([]string{"--post-exec-each", `sh -c 'echo "$0 done"' {}`})
+
+	cfg, err = cobraConfig(cmd, cmd.Flags().Args())
+	require.NoError(t, err)
+	assert.EqualValues(t, &gomplate.Config{PostExecEach: []string{"sh", "-c", `echo "$0 done"`, "{}"}}, cfg)
+
+	cmd = &cobra.Command{}
+	cmd.Flags().String("post-exec-each", "", "...")
+	cmd.ParseFlags([]string{"--post-exec-each", `echo "oops`})
+
+	_, err = cobraConfig(cmd, cmd.Flags().Args())
+	require.ErrorContains(t, err, "invalid --post-exec-each command")
+
+	cmd = &cobra.Command{}
+	cmd.Flags().StringArray("chmod", []string{}, "...")
+	cmd.ParseFlags([]string{"--chmod", "0755", "--chmod", "0600"})
+
+	_, err = cobraConfig(cmd, cmd.Flags().Args())
+	require.Error(t, err)
+
+	for in, expected := range map[string]*gomplate.Config{
+		"www-data":     {OutUser: "www-data"},
+		"1000:1000":    {OutUser: "1000", OutGroup: "1000"},
+		":staff":       {OutGroup: "staff"},
+		"nobody:nogrp": {OutUser: "nobody", OutGroup: "nogrp"},
+		"www-data:":    {OutUser: "www-data"},
+	} {
+		cmd = &cobra.Command{}
+		cmd.Flags().String("chown", "", "...")
+		cmd.ParseFlags([]string{"--chown", in})
+
+		cfg, err = cobraConfig(cmd, cmd.Flags().Args())
+		require.NoError(t, err)
+		assert.EqualValues(t, expected, cfg, in)
+	}
+
+	cmd = &cobra.Command{}
+	cmd.Flags().String("chown", "", "...")
+	cmd.ParseFlags([]string{"--chown", ":"})
+
+	_, err = cobraConfig(cmd, cmd.Flags().Args())
+	require.ErrorContains(t, err, `invalid --chown value ":"`)
 }
 
 func TestProcessIncludes(t *testing.T) {
@@ -220,6 +368,12 @@ func TestApplyEnvVars(t *testing.T) {
 		require.Error(t, err)
 	})
 
+	t.Run("invalid GOMPLATE_TEMPLATE_TIMEOUT", func(t *testing.T) {
+		t.Setenv("GOMPLATE_TEMPLATE_TIMEOUT", "bogus")
+		_, err := applyEnvVars(context.Background(), &gomplate.Config{})
+		require.Error(t, err)
+	})
+
 	data := []struct {
 		input, expected *gomplate.Config
 		env             string
@@ -255,6 +409,26 @@ func TestApplyEnvVars(t *testing.T) {
 			&gomplate.Config{Experimental: true},
 			"GOMPLATE_EXPERIMENTAL", "false",
 		},
+		{
+			&gomplate.Config{},
+			&gomplate.Config{TemplateTimeout: 2 * time.Second},
+			"GOMPLATE_TEMPLATE_TIMEOUT", "2s",
+		},
+		{
+			&gomplate.Config{TemplateTimeout: time.Minute},
+			&gomplate.Config{TemplateTimeout: time.Minute},
+			"GOMPLATE_TEMPLATE_TIMEOUT", "2s",
+		},
+		{
+			&gomplate.Config{},
+			&gomplate.Config{MetricsJSON: true},
+			"GOMPLATE_METRICS_JSON", "true",
+		},
+		{
+			&gomplate.Config{},
+			&gomplate.Config{MetricsJSON: false},
+			"GOMPLATE_METRICS_JSON", "bogus",
+		},
 		{
 			&gomplate.Config{},
 			&gomplate.Config{LDelim: "--"},
@@ -405,6 +579,54 @@ func TestParsePluginFlags(t *testing.T) {
 	assert.EqualValues(t, &gomplate.Config{Plugins: map[string]gomplate.PluginConfig{"foo": {Cmd: "bar"}}}, cfg)
 }
 
+func TestParseTemplateArg(t *testing.T) {
+	alias, ds, err := parseTemplateArg("alias=./dir")
+	require.NoError(t, err)
+	assert.Equal(t, "alias", alias)
+	assert.EqualValues(t, &url.URL{Path: "./dir"}, ds.URL)
+
+	alias, ds, err = parseTemplateArg("./file")
+	require.NoError(t, err)
+	assert.Equal(t, "./file", alias)
+	assert.EqualValues(t, &url.URL{Path: "./file"}, ds.URL)
+
+	// only the first '=' separates the alias
+	alias, ds, err = parseTemplateArg("a=b=c")
+	require.NoError(t, err)
+	assert.Equal(t, "a", alias)
+	assert.EqualValues(t, &url.URL{Path: "b=c"}, ds.URL)
+
+	alias, ds, err = parseTemplateArg("t=https://example.com/t.tmpl?a=b")
+	require.NoError(t, err)
+	assert.Equal(t, "t", alias)
+	assert.Equal(t, "https://example.com/t.tmpl?a=b", ds.URL.String())
+
+	// without an alias, '=' in a URL's query isn't mistaken for one
+	alias, ds, err = parseTemplateArg("https://example.com/t.tmpl?a=b")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/t.tmpl?a=b", alias)
+	assert.Equal(t, "https://example.com/t.tmpl?a=b", ds.URL.String())
+
+	alias, ds, err = parseTemplateArg("file:///tmp/t.tmpl?type=text/plain&x=y")
+	require.NoError(t, err)
+	assert.Equal(t, "file:///tmp/t.tmpl?type=text/plain&x=y", alias)
+	assert.Equal(t, "/tmp/t.tmpl", ds.URL.Path)
+
+	// anything that parses as an absolute URL before the '=' is a URL
+	alias, ds, err = parseTemplateArg("a:b=foo.t")
+	require.NoError(t, err)
+	assert.Equal(t, "a:b=foo.t", alias)
+	assert.Equal(t, "a", ds.URL.Scheme)
+
+	for _, v := range []string{
+		"", "=foo.t", "t=", "my alias=foo.t", "/abs=foo.t", "../up=foo.t", "a/../b=foo.t",
+		"./a:b=foo.t", "a?b=foo.t",
+	} {
+		_, _, err = parseTemplateArg(v)
+		assert.Error(t, err, v)
+	}
+}
+
 func TestParseDatasourceArgNoAlias(t *testing.T) {
 	alias, ds, err := parseDatasourceArg("foo.json")
 	require.NoError(t, err)
@@ -25,16 +25,10 @@ func createLogHandler(format string, out io.Writer, level slog.Level) slog.Handl
 	var handler slog.Handler
 	switch format {
 	case "console":
-		// logFormat() already checks if this is a terminal, but we need to
-		// check again because the format may be overridden with `GOMPLATE_LOG_FORMAT`
-		useColour := false
-		if f, ok := out.(*os.File); ok && term.IsTerminal(int(f.Fd())) && runtime.GOOS != "windows" {
-			useColour = true
-		}
 		handler = tint.NewHandler(out, &tint.Options{
 			Level:      level,
 			TimeFormat: "15:04:05",
-			NoColor:    !useColour,
+			NoColor:    !useColour(out),
 		})
 	case "simple":
 		handler = tint.NewHandler(out, &tint.Options{
@@ -60,6 +54,20 @@ func createLogHandler(format string, out io.Writer, level slog.Level) slog.Handl
 	return handler
 }
 
+// useColour reports whether console output to out should be coloured. The
+// NO_COLOR environment variable (see https://no-color.org) disables colour.
+func useColour(out io.Writer) bool {
+	if env.Getenv("NO_COLOR") != "" {
+		return false
+	}
+
+	// logFormat() already checks if this is a terminal, but we need to
+	// check again because the format may be overridden with `GOMPLATE_LOG_FORMAT`
+	f, ok := out.(*os.File)
+
+	return ok && term.IsTerminal(int(f.Fd())) && runtime.GOOS != "windows"
+}
+
 func initLogger(out io.Writer, level slog.Level) {
 	// default to warn level
 	if level == 0 {
@@ -25,6 +25,15 @@ func TestLogFormat(t *testing.T) {
 	assert.Equal(t, "simple", logFormat(&bytes.Buffer{}))
 }
 
+func TestUseColour(t *testing.T) {
+	assert.False(t, useColour(&bytes.Buffer{}))
+	// os.Stdout isn't a terminal when this runs as a unit test...
+	assert.False(t, useColour(os.Stdout))
+
+	t.Setenv("NO_COLOR", "1")
+	assert.False(t, useColour(os.Stdout))
+}
+
 // a slog handler that strips the 'time' field
 type noTimestampHandler struct {
 	slog.Handler
@@ -2,12 +2,14 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"os"
 	"os/exec"
 	"os/signal"
+	"runtime"
 
 	"github.com/hairyhenderson/gomplate/v4"
 	"github.com/hairyhenderson/gomplate/v4/env"
@@ -104,10 +106,16 @@ func NewGomplateCmd(stderr io.Writer) *cobra.Command {
 
 			slog.DebugContext(ctx, "completed rendering",
 				slog.Int("templatesRendered", gomplate.Metrics.TemplatesProcessed),
+				slog.Int("templatesUnchanged", gomplate.Metrics.TemplatesUnchanged),
 				slog.Int("errors", gomplate.Metrics.Errors),
 				slog.Duration("duration", gomplate.Metrics.TotalRenderDuration))
 
 			if err != nil {
+				// the error was already written to stderr as JSON
+				if cfg.ErrorFormat == "json" {
+					return &reportedError{err}
+				}
+
 				return err
 			}
 
@@ -134,6 +142,7 @@ func InitFlags(command *cobra.Command) {
 
 	command.Flags().StringSliceP("file", "f", []string{"-"}, "Template `file` to process. Omit to use standard input, or use --in or --input-dir")
 	command.Flags().StringP("in", "i", "", "Template `string` to process (alternative to --file and --input-dir)")
+	command.Flags().String("in-url", "", "datasource `URL` to read the template from (alternative to --in, --file, and --input-dir)")
 	command.Flags().String("input-dir", "", "`directory` which is examined recursively for templates (alternative to --file and --in)")
 
 	command.Flags().StringSlice("exclude", []string{}, "glob of files to not parse")
@@ -144,9 +153,27 @@ func InitFlags(command *cobra.Command) {
 	command.Flags().StringSliceP("template", "t", []string{}, "Additional template file(s)")
 	command.Flags().String("output-dir", ".", "`directory` to store the processed templates. Only used for --input-dir")
 	command.Flags().String("output-map", "", "Template `string` to map the input file to an output path")
-	command.Flags().String("chmod", "", "set the mode for output file(s). Omit to inherit from input file(s)")
+	command.Flags().StringArray("chmod", []string{}, "set the `mode` for output file(s), or for output files matching a glob in glob=mode form. Can be specified multiple times. Omit to inherit from input file(s)")
+	command.Flags().String("chown", "", "set the `owner` of output file(s), as user, user:group, or :group (names or numeric IDs). Only works when running as root")
 
 	command.Flags().Bool("exec-pipe", false, "pipe the output to the post-run exec command")
+	command.Flags().String("post-exec-each", "", "run the `command` after each output file is written - the file's name replaces {}, or is appended")
+
+	command.Flags().Bool("watch", false, "keep running, and re-render when input files or local datasources change")
+
+	command.Flags().Bool("dry-run", false, "don't write output files, instead print a diff and summary of the changes that would be made")
+	command.Flags().Bool("fail-on-change", false, "with --dry-run, exit with an error if any output file would be created or changed")
+	command.Flags().Bool("force", false, "always write output files, even when their content is unchanged")
+	command.Flags().Bool("stream", false, "write output directly as it's rendered, instead of to a temporary file that's moved into place afterwards")
+	command.Flags().Bool("delete-empty", false, "delete existing output files when the template renders to empty output")
+	command.Flags().String("compress", "", "compress output files with the given `format` (only gzip is supported), adding a .gz suffix to their names")
+	command.Flags().Bool("combine", false, "parse all --file templates as one set and render the last one to a single output, so templates defined in earlier files can be used in later ones")
+	command.Flags().Bool("front-matter", false, "read per-template settings from a YAML front matter block at the top of each template file")
+	command.Flags().Bool("no-cache", false, "read datasources every time they're used, instead of only once per run")
+	command.Flags().Bool("metrics-json", false, "write render metrics to stderr as a line of JSON after rendering")
+	command.Flags().String("error-format", "text", "`format` to report errors in - text, or json to write them to stderr as a line of JSON with the template name, line, and function as separate fields")
+
+	command.Flags().Int("parallelism", runtime.GOMAXPROCS(0), "maximum `number` of templates to render concurrently")
 
 	// these are only set for the help output - these defaults aren't actually used
 	ldDefault := env.Getenv("GOMPLATE_LEFT_DELIM", "{{")
@@ -179,8 +206,24 @@ func Main(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io
 	command.SetErr(stderr)
 
 	err := command.ExecuteContext(ctx)
-	if err != nil {
+
+	var re *reportedError
+	if err != nil && !errors.As(err, &re) {
 		slog.Error("", slog.Any("err", err))
 	}
 	return err
 }
+
+// reportedError wraps an error that has already been reported, and so
+// shouldn't be logged again
+type reportedError struct {
+	err error
+}
+
+func (e *reportedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *reportedError) Unwrap() error {
+	return e.err
+}
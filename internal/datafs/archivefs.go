@@ -0,0 +1,191 @@
+package datafs
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hack-pad/hackpadfs"
+	"github.com/hack-pad/hackpadfs/mem"
+	"github.com/hairyhenderson/go-fsimpl"
+)
+
+// NewArchiveFS returns a filesystem (an fs.FS) that can be used to read files
+// from a zip or tar archive. Tar archives may be gzip-compressed. The format
+// is detected from the archive's content, not its name.
+//
+// The URL's path is the archive file. A URL with a host (like
+// archive://bundles/templates.zip) is treated as a path relative to the
+// working directory. As with git and boltdb URLs, a path within the archive
+// can follow a double-slash (archive:///tmp/bundle.zip//templates/foo.tmpl).
+//
+// Only regular files and directories are read from tar archives - other
+// entries (like symlinks), and entries with names outside the archive's root,
+// are skipped.
+func NewArchiveFS(u *url.URL) (fs.FS, error) {
+	p := u.Host + u.Path
+	if p == "" {
+		return nil, fmt.Errorf("archive: missing archive file path")
+	}
+
+	return &archiveFS{path: filepath.FromSlash(p)}, nil
+}
+
+//nolint:gochecknoglobals
+var ArchiveFS = fsimpl.FSProviderFunc(NewArchiveFS, "archive")
+
+type archiveFS struct {
+	path string
+}
+
+var _ fs.FS = (*archiveFS)(nil)
+
+func (f *archiveFS) Open(name string) (fs.File, error) {
+	// directory datasource URLs end with a slash
+	name = strings.TrimSuffix(name, "/")
+
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{
+			Op:   "open",
+			Path: name,
+			Err:  fs.ErrInvalid,
+		}
+	}
+
+	fsys, err := loadArchive(f.path)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+
+	return fsys.Open(name)
+}
+
+// archives are the archives that have already been read, so that each is only
+// read once, even though a new archiveFS is created for every file read from
+// it. An archive is read again when its size or modification time changes.
+//
+//nolint:gochecknoglobals
+var archives = struct {
+	m map[string]*cachedArchive
+	sync.Mutex
+}{m: map[string]*cachedArchive{}}
+
+type cachedArchive struct {
+	fsys    fs.FS
+	modTime time.Time
+	size    int64
+}
+
+func loadArchive(name string) (fs.FS, error) {
+	name, err := filepath.Abs(name)
+	if err != nil {
+		return nil, fmt.Errorf("archive: %w", err)
+	}
+
+	fi, err := os.Stat(name)
+	if err != nil {
+		return nil, fmt.Errorf("archive: %w", err)
+	}
+
+	archives.Lock()
+	defer archives.Unlock()
+
+	if c, ok := archives.m[name]; ok && c.size == fi.Size() && c.modTime.Equal(fi.ModTime()) {
+		return c.fsys, nil
+	}
+
+	b, err := os.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("archive: %w", err)
+	}
+
+	fsys, err := parseArchive(b)
+	if err != nil {
+		return nil, fmt.Errorf("archive: failed to read %q: %w", name, err)
+	}
+
+	archives.m[name] = &cachedArchive{fsys: fsys, modTime: fi.ModTime(), size: fi.Size()}
+
+	return fsys, nil
+}
+
+// parseArchive returns a filesystem for the zip, tar, or gzip-compressed tar
+// archive in b
+func parseArchive(b []byte) (fs.FS, error) {
+	switch {
+	case bytes.HasPrefix(b, []byte("PK\x03\x04")), bytes.HasPrefix(b, []byte("PK\x05\x06")):
+		return zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	case bytes.HasPrefix(b, []byte{0x1f, 0x8b}):
+		zr, err := gzip.NewReader(bytes.NewReader(b))
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+
+		return tarFS(zr)
+	default:
+		return tarFS(bytes.NewReader(b))
+	}
+}
+
+// tarFS reads the tar archive into an in-memory filesystem
+func tarFS(r io.Reader) (fs.FS, error) {
+	fsys, err := mem.NewFS()
+	if err != nil {
+		return nil, err
+	}
+
+	tr := tar.NewReader(r)
+	for i := 0; ; i++ {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			// not a tar archive at all
+			if i == 0 {
+				return nil, fmt.Errorf("unsupported archive format, must be zip, tar, or gzip-compressed tar")
+			}
+			return nil, err
+		}
+
+		name := path.Clean(strings.TrimPrefix(hdr.Name, "/"))
+		if name == "." || !fs.ValidPath(name) {
+			continue
+		}
+
+		perm := hdr.FileInfo().Mode().Perm()
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			err = hackpadfs.MkdirAll(fsys, name, perm|0o700)
+		case tar.TypeReg:
+			err = hackpadfs.MkdirAll(fsys, path.Dir(name), 0o755)
+			if err == nil {
+				var content []byte
+				content, err = io.ReadAll(tr)
+				if err == nil {
+					err = hackpadfs.WriteFullFile(fsys, name, content, perm)
+				}
+			}
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %q from tar archive: %w", hdr.Name, err)
+		}
+	}
+
+	return fsys, nil
+}
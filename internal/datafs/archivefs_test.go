@@ -0,0 +1,176 @@
+package datafs
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeZip(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	zw := zip.NewWriter(buf)
+	for name, content := range files {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate})
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+
+	return buf.Bytes()
+}
+
+func writeTar(t *testing.T, hdrs []*tar.Header, contents []string) []byte {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	tw := tar.NewWriter(buf)
+	for i, hdr := range hdrs {
+		hdr.Size = int64(len(contents[i]))
+		require.NoError(t, tw.WriteHeader(hdr))
+		_, err := tw.Write([]byte(contents[i]))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+
+	return buf.Bytes()
+}
+
+func gzipBytes(t *testing.T, b []byte) []byte {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	zw := gzip.NewWriter(buf)
+	_, err := zw.Write(b)
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	return buf.Bytes()
+}
+
+func TestArchiveFS(t *testing.T) {
+	tarball := writeTar(t, []*tar.Header{
+		{Name: "templates/", Typeflag: tar.TypeDir, Mode: 0o755},
+		{Name: "templates/hello.tmpl", Typeflag: tar.TypeReg, Mode: 0o644},
+		{Name: "templates/sub/run.sh", Typeflag: tar.TypeReg, Mode: 0o755},
+		{Name: "/data/config.yaml", Typeflag: tar.TypeReg, Mode: 0o600},
+		// entries outside the root, and symlinks, are skipped
+		{Name: "../escape.txt", Typeflag: tar.TypeReg, Mode: 0o644},
+		{Name: "templates/link", Typeflag: tar.TypeSymlink, Linkname: "/etc/passwd"},
+	}, []string{"", "hello", "#!/bin/sh", "name: foo", "nope", ""})
+
+	dir := t.TempDir()
+	archives := map[string][]byte{
+		"bundle.zip": writeZip(t, map[string]string{
+			"templates/hello.tmpl": "hello",
+			"templates/sub/run.sh": "#!/bin/sh",
+			"data/config.yaml":     "name: foo",
+		}),
+		"bundle.tar":    tarball,
+		"bundle.tar.gz": gzipBytes(t, tarball),
+	}
+
+	for name, b := range archives {
+		t.Run(name, func(t *testing.T) {
+			p := filepath.Join(dir, name)
+			require.NoError(t, os.WriteFile(p, b, 0o644))
+
+			fsys, err := NewArchiveFS(&url.URL{Scheme: "archive", Path: filepath.ToSlash(p)})
+			require.NoError(t, err)
+
+			b, err := fs.ReadFile(fsys, "templates/hello.tmpl")
+			require.NoError(t, err)
+			assert.Equal(t, "hello", string(b))
+
+			b, err = fs.ReadFile(fsys, "data/config.yaml")
+			require.NoError(t, err)
+			assert.Equal(t, "name: foo", string(b))
+
+			des, err := fs.ReadDir(fsys, "templates")
+			require.NoError(t, err)
+
+			names := []string{}
+			for _, de := range des {
+				names = append(names, de.Name())
+			}
+			assert.ElementsMatch(t, []string{"hello.tmpl", "sub"}, names)
+
+			// as in directory datasource URLs
+			fi, err := fs.Stat(fsys, "templates/")
+			require.NoError(t, err)
+			assert.True(t, fi.IsDir())
+
+			_, err = fs.Stat(fsys, "escape.txt")
+			require.ErrorIs(t, err, fs.ErrNotExist)
+
+			_, err = fs.Stat(fsys, "templates/missing.tmpl")
+			require.ErrorIs(t, err, fs.ErrNotExist)
+		})
+	}
+
+	// tar archives keep their files' modes
+	p := filepath.Join(dir, "bundle.tar")
+	fsys, err := NewArchiveFS(&url.URL{Scheme: "archive", Path: filepath.ToSlash(p)})
+	require.NoError(t, err)
+
+	fi, err := fs.Stat(fsys, "templates/sub/run.sh")
+	require.NoError(t, err)
+	assert.Equal(t, fs.FileMode(0o755), fi.Mode().Perm())
+}
+
+func TestArchiveFS_Errors(t *testing.T) {
+	_, err := NewArchiveFS(&url.URL{Scheme: "archive"})
+	require.Error(t, err)
+
+	dir := t.TempDir()
+
+	fsys, err := NewArchiveFS(&url.URL{Scheme: "archive", Path: filepath.ToSlash(filepath.Join(dir, "missing.zip"))})
+	require.NoError(t, err)
+
+	_, err = fs.ReadFile(fsys, "foo")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+
+	p := filepath.Join(dir, "notanarchive.txt")
+	require.NoError(t, os.WriteFile(p, []byte("hello world"), 0o644))
+
+	fsys, err = NewArchiveFS(&url.URL{Scheme: "archive", Path: filepath.ToSlash(p)})
+	require.NoError(t, err)
+
+	_, err = fs.ReadFile(fsys, "foo")
+	require.ErrorContains(t, err, "unsupported archive format")
+
+	_, err = fsys.Open("../foo")
+	require.ErrorIs(t, err, fs.ErrInvalid)
+}
+
+func TestArchiveFS_Reload(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "bundle.zip")
+	require.NoError(t, os.WriteFile(p, writeZip(t, map[string]string{"a.txt": "one"}), 0o644))
+
+	fsys, err := NewArchiveFS(&url.URL{Scheme: "archive", Path: filepath.ToSlash(p)})
+	require.NoError(t, err)
+
+	b, err := fs.ReadFile(fsys, "a.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "one", string(b))
+
+	// the archive is read again once it changes
+	require.NoError(t, os.WriteFile(p, writeZip(t, map[string]string{"a.txt": "two!"}), 0o644))
+	require.NoError(t, os.Chtimes(p, time.Now(), time.Now().Add(time.Minute)))
+
+	b, err = fs.ReadFile(fsys, "a.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "two!", string(b))
+}
@@ -0,0 +1,245 @@
+package datafs
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/hairyhenderson/go-fsimpl"
+	bolt "go.etcd.io/bbolt"
+)
+
+// defaultBoltTimeout is how long to wait for a lock on the database file when
+// BOLTDB_TIMEOUT isn't set
+const defaultBoltTimeout = 5 * time.Second
+
+// NewBoltDBFS returns a filesystem (an fs.FS) that can be used to read keys
+// from a bucket in a BoltDB database file.
+//
+// The URL's path is the database file, and its fragment is the bucket. A URL
+// with a host (like boltdb://data/lookup.db) is treated as a path relative to
+// the working directory. Files are named for keys in the bucket, and the root
+// of the filesystem lists the bucket's keys.
+func NewBoltDBFS(u *url.URL) (fs.FS, error) {
+	p := u.Host + u.Path
+	if p == "" {
+		return nil, fmt.Errorf("boltdb: missing database file path")
+	}
+
+	if u.Fragment == "" {
+		return nil, fmt.Errorf("boltdb: missing bucket, must be given in the URL fragment")
+	}
+
+	timeout, err := boltTimeout()
+	if err != nil {
+		return nil, err
+	}
+
+	return &boltFS{
+		path:    filepath.FromSlash(p),
+		bucket:  u.Fragment,
+		timeout: timeout,
+	}, nil
+}
+
+// boltTimeout returns the timeout set in BOLTDB_TIMEOUT (in seconds), or the
+// default
+func boltTimeout() (time.Duration, error) {
+	v := getenv("BOLTDB_TIMEOUT")
+	if v == "" {
+		return defaultBoltTimeout, nil
+	}
+
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid BOLTDB_TIMEOUT %q: must be a positive number of seconds", v)
+	}
+
+	return time.Duration(n) * time.Second, nil
+}
+
+type boltFS struct {
+	path    string
+	bucket  string
+	timeout time.Duration
+}
+
+//nolint:gochecknoglobals
+var BoltDBFS = fsimpl.FSProviderFunc(NewBoltDBFS, "boltdb")
+
+var _ fs.FS = (*boltFS)(nil)
+
+func (f *boltFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{
+			Op:   "open",
+			Path: name,
+			Err:  fs.ErrInvalid,
+		}
+	}
+
+	return &boltFile{fsys: f, name: name}, nil
+}
+
+// boltDBs holds the open databases, one per file. BoltDB locks the file when
+// it's opened, so sharing a single read-only handle per file avoids reopening
+// (and relocking) it for every read, and concurrent renders contending for locks
+// on the same file. They're closed by Cleanup.
+//
+//nolint:gochecknoglobals
+var boltDBs = newConnPool((*bolt.DB).Close)
+
+// openBolt opens the database file read-only, or returns the handle that's
+// already open
+func openBolt(path string, timeout time.Duration) (*bolt.DB, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("boltdb: %w", err)
+	}
+
+	return boltDBs.get(path, func() (*bolt.DB, error) {
+		// bolt.Open would create a missing file, even in read-only mode
+		if _, err := os.Stat(path); err != nil {
+			return nil, fmt.Errorf("boltdb: %w", err)
+		}
+
+		db, err := bolt.Open(path, 0o400, &bolt.Options{ReadOnly: true, Timeout: timeout})
+		if err != nil {
+			return nil, fmt.Errorf("boltdb: failed to open %q: %w", path, err)
+		}
+
+		return db, nil
+	})
+}
+
+type boltFile struct {
+	fsys *boltFS
+	body io.Reader
+	name string
+	size int64
+
+	dirents []fs.DirEntry
+	diroff  int
+}
+
+var (
+	_ fs.File        = (*boltFile)(nil)
+	_ fs.ReadDirFile = (*boltFile)(nil)
+)
+
+func (f *boltFile) Close() error {
+	f.body = nil
+	return nil
+}
+
+// view runs fn in a read-only transaction on the file's bucket
+func (f *boltFile) view(fn func(b *bolt.Bucket) error) error {
+	db, err := openBolt(f.fsys.path, f.fsys.timeout)
+	if err != nil {
+		return err
+	}
+
+	return db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(f.fsys.bucket))
+		if b == nil {
+			return fmt.Errorf("boltdb: bucket %q not found in %q", f.fsys.bucket, f.fsys.path)
+		}
+
+		return fn(b)
+	})
+}
+
+// fetch reads the key's value. Missing keys are read as empty, but a missing
+// bucket is an error.
+func (f *boltFile) fetch() error {
+	if f.body != nil {
+		return nil
+	}
+
+	var b []byte
+
+	err := f.view(func(bucket *bolt.Bucket) error {
+		// values are only valid during the transaction, so must be copied
+		b = bytes.Clone(bucket.Get([]byte(f.name)))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	f.size = int64(len(b))
+	f.body = bytes.NewReader(b)
+
+	return nil
+}
+
+func (f *boltFile) Stat() (fs.FileInfo, error) {
+	if f.name == "." {
+		return DirInfo(f.name, time.Time{}), nil
+	}
+
+	if err := f.fetch(); err != nil {
+		return nil, err
+	}
+
+	return FileInfo(f.name, f.size, 0o444, time.Time{}, ""), nil
+}
+
+func (f *boltFile) Read(p []byte) (int, error) {
+	if f.name == "." {
+		return 0, &fs.PathError{Op: "read", Path: f.name, Err: fs.ErrInvalid}
+	}
+
+	if err := f.fetch(); err != nil {
+		return 0, err
+	}
+
+	return f.body.Read(p)
+}
+
+func (f *boltFile) ReadDir(n int) ([]fs.DirEntry, error) {
+	if f.name != "." {
+		return nil, fmt.Errorf("%s: not a directory", f.name)
+	}
+
+	if f.dirents == nil {
+		f.dirents = []fs.DirEntry{}
+
+		err := f.view(func(b *bolt.Bucket) error {
+			return b.ForEach(func(k, v []byte) error {
+				f.dirents = append(f.dirents, FileInfoDirEntry(
+					FileInfo(string(k), int64(len(v)), 0o444, time.Time{}, ""),
+				))
+				return nil
+			})
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if n > 0 && f.diroff >= len(f.dirents) {
+		return nil, io.EOF
+	}
+
+	low := f.diroff
+	high := f.diroff + n
+
+	// clamp high at the max, and ensure it's higher than low
+	if high >= len(f.dirents) || high <= low {
+		high = len(f.dirents)
+	}
+
+	entries := make([]fs.DirEntry, high-low)
+	copy(entries, f.dirents[f.diroff:])
+
+	f.diroff = high
+
+	return entries, nil
+}
@@ -0,0 +1,158 @@
+package datafs
+
+import (
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	bolt "go.etcd.io/bbolt"
+)
+
+func setupBoltDB(t *testing.T) string {
+	t.Helper()
+
+	p := filepath.Join(t.TempDir(), "lookup.db")
+
+	db, err := bolt.Open(p, 0o600, nil)
+	require.NoError(t, err)
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucket([]byte("regions"))
+		if err != nil {
+			return err
+		}
+
+		if err := b.Put([]byte("us-east-1"), []byte("N. Virginia")); err != nil {
+			return err
+		}
+
+		return b.Put([]byte("eu-west-1"), []byte("Ireland"))
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	return p
+}
+
+func TestBoltDBFS(t *testing.T) {
+	p := setupBoltDB(t)
+
+	// close the file before the temp dir is removed
+	t.Cleanup(func() { _ = Cleanup() })
+
+	u := &url.URL{Scheme: "boltdb", Path: filepath.ToSlash(p), Fragment: "regions"}
+	fsys, err := NewBoltDBFS(u)
+	require.NoError(t, err)
+
+	b, err := fs.ReadFile(fsys, "us-east-1")
+	require.NoError(t, err)
+	assert.Equal(t, "N. Virginia", string(b))
+
+	// missing keys are empty, not errors
+	b, err = fs.ReadFile(fsys, "ap-south-1")
+	require.NoError(t, err)
+	assert.Empty(t, b)
+
+	des, err := fs.ReadDir(fsys, ".")
+	require.NoError(t, err)
+	require.Len(t, des, 2)
+	assert.Equal(t, "eu-west-1", des[0].Name())
+	assert.Equal(t, "us-east-1", des[1].Name())
+
+	// the handle is kept open for later reads, until Cleanup
+	db := boltDBs.conns[p]
+	require.NotNil(t, db)
+
+	b, err = fs.ReadFile(fsys, "eu-west-1")
+	require.NoError(t, err)
+	assert.Equal(t, "Ireland", string(b))
+	assert.Same(t, db, boltDBs.conns[p])
+
+	require.NoError(t, Cleanup())
+	assert.Empty(t, boltDBs.conns)
+
+	// reads after Cleanup reopen the file
+	b, err = fs.ReadFile(fsys, "us-east-1")
+	require.NoError(t, err)
+	assert.Equal(t, "N. Virginia", string(b))
+
+	u.Fragment = "nope"
+	fsys, err = NewBoltDBFS(u)
+	require.NoError(t, err)
+
+	_, err = fs.ReadFile(fsys, "us-east-1")
+	require.ErrorContains(t, err, `bucket "nope" not found`)
+
+	u.Path = filepath.ToSlash(filepath.Join(filepath.Dir(p), "missing.db"))
+	fsys, err = NewBoltDBFS(u)
+	require.NoError(t, err)
+
+	_, err = fs.ReadFile(fsys, "us-east-1")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+	assert.NoFileExists(t, filepath.Join(filepath.Dir(p), "missing.db"))
+
+	u.Fragment = ""
+	_, err = NewBoltDBFS(u)
+	require.ErrorContains(t, err, "missing bucket")
+}
+
+func TestBoltDBFS_Concurrent(t *testing.T) {
+	p := setupBoltDB(t)
+
+	// close the file before the temp dir is removed
+	t.Cleanup(func() { _ = Cleanup() })
+
+	u := &url.URL{Scheme: "boltdb", Path: filepath.ToSlash(p), Fragment: "regions"}
+	fsys, err := NewBoltDBFS(u)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			b, err := fs.ReadFile(fsys, "eu-west-1")
+			assert.NoError(t, err)
+			assert.Equal(t, "Ireland", string(b))
+		}()
+	}
+	wg.Wait()
+
+	assert.Len(t, boltDBs.conns, 1)
+}
+
+func TestBoltTimeout(t *testing.T) {
+	t.Setenv("BOLTDB_TIMEOUT", "")
+
+	d, err := boltTimeout()
+	require.NoError(t, err)
+	assert.Equal(t, defaultBoltTimeout, d)
+
+	t.Setenv("BOLTDB_TIMEOUT", "10")
+
+	d, err = boltTimeout()
+	require.NoError(t, err)
+	assert.Equal(t, 10*time.Second, d)
+
+	t.Setenv("BOLTDB_TIMEOUT", "0")
+
+	_, err = boltTimeout()
+	require.ErrorContains(t, err, "invalid BOLTDB_TIMEOUT")
+
+	t.Setenv("BOLTDB_TIMEOUT", "")
+
+	timeoutFile := filepath.Join(t.TempDir(), "timeout")
+	require.NoError(t, os.WriteFile(timeoutFile, []byte("2\n"), 0o600))
+	t.Setenv("BOLTDB_TIMEOUT_FILE", timeoutFile)
+
+	d, err = boltTimeout()
+	require.NoError(t, err)
+	assert.Equal(t, 2*time.Second, d)
+}
@@ -0,0 +1,93 @@
+package datafs
+
+import (
+	"errors"
+	"sync"
+)
+
+//nolint:gochecknoglobals
+var (
+	cleanupMu    sync.Mutex
+	cleanupHooks []func() error
+)
+
+// addCleanupHook registers a function to be called by Cleanup
+func addCleanupHook(hook func() error) {
+	cleanupMu.Lock()
+	defer cleanupMu.Unlock()
+
+	cleanupHooks = append(cleanupHooks, hook)
+}
+
+// Cleanup closes any connections that were kept open to be shared between
+// reads, like database clients. Hooks are run in the reverse of the order
+// they were added, and removed once they've run, so it's safe to call
+// Cleanup more than once. Datasources can still be read afterwards - new
+// connections are made as needed.
+func Cleanup() error {
+	cleanupMu.Lock()
+	hooks := cleanupHooks
+	cleanupHooks = nil
+	cleanupMu.Unlock()
+
+	errs := make([]error, 0, len(hooks))
+	for i := len(hooks) - 1; i >= 0; i-- {
+		errs = append(errs, hooks[i]())
+	}
+
+	return errors.Join(errs...)
+}
+
+// connPool shares connections (or clients) between reads. A filesystem is
+// created for every read, so connections can't be kept in the filesystem
+// itself. The first connection made registers a cleanup hook that closes
+// all of them.
+type connPool[T any] struct {
+	conns map[string]T
+	close func(T) error
+	mu    sync.Mutex
+}
+
+func newConnPool[T any](closeConn func(T) error) *connPool[T] {
+	return &connPool[T]{close: closeConn}
+}
+
+// get returns the connection for key, calling dial to make one if there
+// isn't one yet. Failed dials aren't remembered.
+func (p *connPool[T]) get(key string, dial func() (T, error)) (T, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if c, ok := p.conns[key]; ok {
+		return c, nil
+	}
+
+	c, err := dial()
+	if err != nil {
+		return c, err
+	}
+
+	if p.conns == nil {
+		p.conns = map[string]T{}
+		addCleanupHook(p.closeAll)
+	}
+
+	p.conns[key] = c
+
+	return c, nil
+}
+
+// closeAll closes and forgets all the connections in the pool
+func (p *connPool[T]) closeAll() error {
+	p.mu.Lock()
+	conns := p.conns
+	p.conns = nil
+	p.mu.Unlock()
+
+	errs := make([]error, 0, len(conns))
+	for _, c := range conns {
+		errs = append(errs, p.close(c))
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,70 @@
+package datafs
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeConn struct {
+	key    string
+	closed bool
+}
+
+func TestConnPool(t *testing.T) {
+	t.Cleanup(func() { _ = Cleanup() })
+
+	closeErr := errors.New("close failed")
+	pool := newConnPool(func(c *fakeConn) error {
+		c.closed = true
+		if c.key == "bad" {
+			return closeErr
+		}
+		return nil
+	})
+
+	dials := 0
+	dial := func(key string) func() (*fakeConn, error) {
+		return func() (*fakeConn, error) {
+			dials++
+			return &fakeConn{key: key}, nil
+		}
+	}
+
+	a, err := pool.get("a", dial("a"))
+	require.NoError(t, err)
+
+	a2, err := pool.get("a", dial("a"))
+	require.NoError(t, err)
+	assert.Same(t, a, a2)
+	assert.Equal(t, 1, dials)
+
+	// failed dials aren't remembered
+	_, err = pool.get("b", func() (*fakeConn, error) { return nil, errors.New("refused") })
+	require.EqualError(t, err, "refused")
+
+	b, err := pool.get("b", dial("b"))
+	require.NoError(t, err)
+
+	bad, err := pool.get("bad", dial("bad"))
+	require.NoError(t, err)
+
+	err = Cleanup()
+	require.ErrorIs(t, err, closeErr)
+	assert.True(t, a.closed)
+	assert.True(t, b.closed)
+	assert.True(t, bad.closed)
+
+	// nothing is left to close
+	require.NoError(t, Cleanup())
+
+	// and the pool can be used again
+	a3, err := pool.get("a", dial("a"))
+	require.NoError(t, err)
+	assert.NotSame(t, a, a3)
+
+	require.NoError(t, Cleanup())
+	assert.True(t, a3.closed)
+}
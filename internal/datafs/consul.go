@@ -0,0 +1,93 @@
+package datafs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// consulRecurseParam is the query parameter that makes a Consul KV prefix
+// read as all the keys in its subtree, rather than just the direct children
+const consulRecurseParam = "recurse"
+
+func isConsulURL(u *url.URL) bool {
+	switch u.Scheme {
+	case "consul", "consul+http", "consul+https":
+		return true
+	}
+	return false
+}
+
+// extractConsulParams removes the recurse parameter from Consul URLs. Other
+// URLs are left untouched, since the parameter may be meaningful to the
+// datasource itself.
+func extractConsulParams(u *url.URL) (*url.URL, bool, error) {
+	if !isConsulURL(u) || !u.Query().Has(consulRecurseParam) {
+		return u, false, nil
+	}
+
+	s := u.Query().Get(consulRecurseParam)
+	recurse, err := strconv.ParseBool(s)
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid %s value %q for %s: must be true or false", consulRecurseParam, s, u.Redacted())
+	}
+
+	return removeQueryParam(u, consulRecurseParam), recurse, nil
+}
+
+// readConsulTree reads all the keys under dir, returning a JSON object that
+// maps each key (relative to dir) to its value
+func readConsulTree(fsys fs.FS, dir string) ([]byte, error) {
+	tree := map[string]string{}
+
+	err := fs.WalkDir(fsys, dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			return nil
+		}
+
+		b, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+
+		key := p
+		if dir != "." {
+			key = strings.TrimPrefix(p, dir+"/")
+		}
+		tree[key] = string(b)
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("walk %q: %w", dir, err)
+	}
+
+	return json.Marshal(tree)
+}
+
+// consulError makes it clear whether a Consul read failed because the key
+// doesn't exist, or because the agent couldn't be reached. Other errors are
+// wrapped with the operation, as for other datasources.
+func consulError(op string, u *url.URL, key string, err error) error {
+	var netErr interface{ Timeout() bool }
+
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return fmt.Errorf("consul key %q not found: %w", key, err)
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return fmt.Errorf("couldn't connect to Consul for key %q (connection refused - is CONSUL_HTTP_ADDR or the URL's host correct?): %w", key, err)
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return fmt.Errorf("timed out connecting to Consul for key %q: %w", key, err)
+	}
+
+	return fmt.Errorf("%s (url: %q, name: %q): %w", op, u, key, err)
+}
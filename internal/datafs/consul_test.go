@@ -0,0 +1,122 @@
+package datafs
+
+import (
+	"context"
+	"encoding/json"
+	"io/fs"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/hairyhenderson/go-fsimpl"
+	"github.com/hairyhenderson/go-fsimpl/consulfs"
+	"github.com/hairyhenderson/gomplate/v4/internal/config"
+	"github.com/hairyhenderson/gomplate/v4/internal/iohelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeConsulKV serves the parts of Consul's KV API that consulfs uses
+func fakeConsulKV(t *testing.T, kv map[string]string) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimPrefix(r.URL.Path, "/v1/kv/")
+
+		var body interface{}
+		if r.URL.Query().Has("keys") {
+			keys := []string{}
+			for k := range kv {
+				if strings.HasPrefix(k, key) {
+					keys = append(keys, k)
+				}
+			}
+			sort.Strings(keys)
+
+			if len(keys) > 0 {
+				body = keys
+			}
+		} else if v, ok := kv[key]; ok {
+			body = []map[string]interface{}{{"Key": key, "Value": []byte(v)}}
+		}
+
+		if body == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(body)
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestConsulDataSource(t *testing.T) {
+	srv := fakeConsulKV(t, map[string]string{
+		"app/name":    "web",
+		"app/db/host": "db.local",
+		"app/db/port": "5432",
+		"other":       "x",
+	})
+	host := strings.TrimPrefix(srv.URL, "http://")
+
+	fsp := fsimpl.NewMux()
+	fsp.Add(consulfs.FS)
+	ctx := ContextWithFSProvider(context.Background(), fsp)
+
+	read := func(t *testing.T, u string) (string, []byte, error) {
+		t.Helper()
+
+		reg := NewRegistry()
+		reg.Register("consul", config.DataSource{URL: mustParseURL(u)})
+
+		return NewSourceReader(reg).ReadSource(ctx, "consul")
+	}
+
+	ct, b, err := read(t, "consul+http://"+host+"/app/name")
+	require.NoError(t, err)
+	assert.Equal(t, "web", string(b))
+	assert.Equal(t, iohelpers.TextMimetype, ct)
+
+	// without recurse, only direct children are listed
+	ct, b, err = read(t, "consul+http://"+host+"/app/")
+	require.NoError(t, err)
+	assert.Equal(t, iohelpers.JSONArrayMimetype, ct)
+	assert.JSONEq(t, `["db", "name"]`, string(b))
+
+	ct, b, err = read(t, "consul+http://"+host+"/app/?recurse=true")
+	require.NoError(t, err)
+	assert.Equal(t, iohelpers.JSONMimetype, ct)
+	assert.JSONEq(t, `{"name": "web", "db/host": "db.local", "db/port": "5432"}`, string(b))
+
+	_, b, err = read(t, "consul+http://"+host+"/?recurse=1")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"app/name": "web", "app/db/host": "db.local", "app/db/port": "5432", "other": "x"}`, string(b))
+
+	// recurse is ignored for single keys
+	_, b, err = read(t, "consul+http://"+host+"/app/name?recurse=true")
+	require.NoError(t, err)
+	assert.Equal(t, "web", string(b))
+
+	_, _, err = read(t, "consul+http://"+host+"/app/?recurse=lots")
+	require.ErrorContains(t, err, `invalid recurse value "lots"`)
+
+	_, _, err = read(t, "consul+http://"+host+"/app/missing")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+	assert.ErrorContains(t, err, `consul key "app/missing" not found`)
+
+	// a closed port refuses connections
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := lis.Addr().String()
+	require.NoError(t, lis.Close())
+
+	_, _, err = read(t, "consul+http://"+addr+"/app/name")
+	require.ErrorContains(t, err, `couldn't connect to Consul for key "app/name" (connection refused`)
+	assert.NotErrorIs(t, err, fs.ErrNotExist)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"io"
 	"io/fs"
+	"net/http"
 	"os"
 )
 
@@ -13,6 +14,12 @@ type withContexter interface {
 	WithContext(ctx context.Context) fs.FS
 }
 
+// withHeaderer is an fs.FS that can be configured with HTTP headers
+// copied from go-fsimpl - see internal/types.go
+type withHeaderer interface {
+	WithHeader(headers http.Header) fs.FS
+}
+
 type withDataSourceRegistryer interface {
 	WithDataSourceRegistry(registry Registry) fs.FS
 }
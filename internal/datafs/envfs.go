@@ -2,6 +2,7 @@ package datafs
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/fs"
@@ -11,16 +12,40 @@ import (
 	"time"
 
 	"github.com/hairyhenderson/go-fsimpl"
+	"github.com/hairyhenderson/gomplate/v4/internal/iohelpers"
 )
 
 // NewEnvFS returns a filesystem (an fs.FS) that can be used to read data from
 // environment variables.
-func NewEnvFS(_ *url.URL) (fs.FS, error) {
-	return &envFS{locfs: os.DirFS("/")}, nil
+//
+// When the URL has a prefix query parameter, the root of the filesystem can be
+// read as a JSON object containing all environment variables with that prefix.
+// The prefix is removed from the keys when the strip parameter is true.
+func NewEnvFS(u *url.URL) (fs.FS, error) {
+	fsys := &envFS{locfs: os.DirFS("/")}
+
+	if u != nil {
+		q := u.Query()
+		if q.Has("prefix") {
+			fsys.prefix = &envPrefix{
+				prefix: q.Get("prefix"),
+				strip:  q.Get("strip") == "true",
+			}
+		}
+	}
+
+	return fsys, nil
 }
 
 type envFS struct {
-	locfs fs.FS
+	locfs  fs.FS
+	prefix *envPrefix
+}
+
+// envPrefix configures how environment variables are collected into a map
+type envPrefix struct {
+	prefix string
+	strip  bool
 }
 
 //nolint:gochecknoglobals
@@ -37,13 +62,19 @@ func (f *envFS) Open(name string) (fs.File, error) {
 		}
 	}
 
-	return &envFile{locfs: f.locfs, name: name}, nil
+	ef := &envFile{locfs: f.locfs, name: name}
+	if name == "." {
+		ef.prefix = f.prefix
+	}
+
+	return ef, nil
 }
 
 type envFile struct {
-	locfs fs.FS
-	body  io.Reader
-	name  string
+	locfs  fs.FS
+	body   io.Reader
+	prefix *envPrefix
+	name   string
 
 	dirents []fs.DirEntry
 	diroff  int
@@ -66,6 +97,10 @@ func (e *envFile) Close() error {
 }
 
 func (e *envFile) envReader() (int, io.Reader, error) {
+	if e.prefix != nil {
+		return e.prefixReader()
+	}
+
 	v, found := lookupEnv(e.name)
 	if found {
 		return len(v), bytes.NewBufferString(v), nil
@@ -88,13 +123,49 @@ func (e *envFile) envReader() (int, io.Reader, error) {
 	return 0, nil, fs.ErrNotExist
 }
 
+// prefixReader returns a JSON object containing all environment variables
+// matching the prefix
+func (e *envFile) prefixReader() (int, io.Reader, error) {
+	vars := map[string]string{}
+	for _, env := range environ() {
+		name, value, _ := strings.Cut(env, "=")
+
+		key, found := strings.CutPrefix(name, e.prefix.prefix)
+		if !found || name == "" {
+			continue
+		}
+
+		if !e.prefix.strip {
+			key = name
+		}
+
+		if key == "" {
+			continue
+		}
+
+		vars[key] = value
+	}
+
+	b, err := json.Marshal(vars)
+	if err != nil {
+		return 0, nil, fmt.Errorf("marshal env vars: %w", err)
+	}
+
+	return len(b), bytes.NewBuffer(b), nil
+}
+
 func (e *envFile) Stat() (fs.FileInfo, error) {
 	n, _, err := e.envReader()
 	if err != nil {
 		return nil, err
 	}
 
-	return FileInfo(e.name, int64(n), 0o444, time.Time{}, ""), nil
+	contentType := ""
+	if e.prefix != nil {
+		contentType = iohelpers.JSONMimetype
+	}
+
+	return FileInfo(e.name, int64(n), 0o444, time.Time{}, contentType), nil
 }
 
 func (e *envFile) Read(p []byte) (int, error) {
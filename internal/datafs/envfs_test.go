@@ -7,6 +7,7 @@ import (
 	"testing"
 	"testing/fstest"
 
+	"github.com/hairyhenderson/go-fsimpl"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -101,6 +102,42 @@ func TestEnvFS(t *testing.T) {
 	require.NoError(t, fstest.TestFS(fsys, "FOO", "FOO_FILE"))
 }
 
+func TestEnvFS_Prefix(t *testing.T) {
+	t.Cleanup(func() { environ = os.Environ })
+	environ = func() []string {
+		return []string{"APP_FOO=bar", "APP_BAZ=qux", "APP_=empty", "OTHER=x", "=C:=C:\\tmp"}
+	}
+
+	u, _ := url.Parse("env:?prefix=APP_")
+	fsys, err := NewEnvFS(u)
+	require.NoError(t, err)
+
+	f, err := fsys.Open(".")
+	require.NoError(t, err)
+
+	fi, err := f.Stat()
+	require.NoError(t, err)
+	assert.Equal(t, "application/json", fsimpl.ContentType(fi))
+
+	b, err := fs.ReadFile(fsys, ".")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"APP_FOO":"bar","APP_BAZ":"qux","APP_":"empty"}`, string(b))
+
+	u, _ = url.Parse("env:?prefix=APP_&strip=true")
+	fsys, err = NewEnvFS(u)
+	require.NoError(t, err)
+
+	b, err = fs.ReadFile(fsys, ".")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"FOO":"bar","BAZ":"qux"}`, string(b))
+
+	// individual variables can still be read
+	t.Setenv("OTHER", "x")
+	b, err = fs.ReadFile(fsys, "OTHER")
+	require.NoError(t, err)
+	assert.Equal(t, "x", string(b))
+}
+
 func TestEnvFile_ReadDir(t *testing.T) {
 	t.Cleanup(func() { environ = os.Environ })
 
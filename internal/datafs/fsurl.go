@@ -11,9 +11,10 @@ func SplitFSMuxURL(in *url.URL) (*url.URL, string) {
 
 	// git URLs are special - they have double-slashes that separate a repo
 	// from a path in the repo. A missing double-slash means the path is the
-	// root.
+	// root. BoltDB URLs similarly separate the database file from the key,
+	// and archive URLs the archive file from the path in the archive.
 	switch u.Scheme {
-	case "git", "git+file", "git+http", "git+https", "git+ssh":
+	case "git", "git+file", "git+http", "git+https", "git+ssh", "boltdb", "archive":
 		repo, base, _ := strings.Cut(u.Path, "//")
 		u.Path = repo
 		if base == "" {
@@ -70,6 +70,26 @@ func TestSplitFSMuxURL(t *testing.T) {
 			"git+ssh://git@github.com/hairyhenderson/go-which.git?q=1",
 			"a/b/c/d",
 		},
+		{
+			"boltdb:///tmp/lookup.db//mykey#bucket",
+			"boltdb:///tmp/lookup.db#bucket",
+			"mykey",
+		},
+		{
+			"boltdb:///tmp/lookup.db#bucket",
+			"boltdb:///tmp/lookup.db#bucket",
+			".",
+		},
+		{
+			"archive:///tmp/bundle.zip//templates/foo.tmpl",
+			"archive:///tmp/bundle.zip",
+			"templates/foo.tmpl",
+		},
+		{
+			"archive://bundles/bundle.tar.gz",
+			"archive://bundles/bundle.tar.gz",
+			".",
+		},
 		{
 			"merge:file:///tmp/jsonfile.json",
 			"merge:///",
@@ -50,10 +50,14 @@ func FSysForPath(ctx context.Context, path string) (fs.FS, error) {
 		// git URLs are special - they have double-slashes that separate a repo from
 		// a path in the repo. A missing double-slash means the path is the root.
 		u.Path, _, _ = strings.Cut(u.Path, "//")
+	case "boltdb", "archive":
+		// similarly, the database or archive file is separated from the key or
+		// the path in the archive
+		u.Path, _, _ = strings.Cut(u.Path, "//")
 	}
 
 	switch u.Scheme {
-	case "git+http", "git+https", "git+ssh", "git":
+	case "git+http", "git+https", "git+ssh", "git", "boltdb", "archive":
 		// no-op, these are handled
 	case "", "file", "git+file":
 		// default to "/" so we have a rooted filesystem for all schemes, but also
@@ -1,11 +1,41 @@
 package datafs
 
 import (
+	"fmt"
 	"io/fs"
 	"os"
 	"strings"
+
+	osfs "github.com/hack-pad/hackpadfs/os"
 )
 
+// getenv - like env.Getenv (which can't be used here, since the env package
+// imports this one), supporting `_FILE` variants of variables
+func getenv(key string, def ...string) string {
+	return GetenvFsys(WrapWdFS(osfs.NewFS()), key, def...)
+}
+
+// lookupEnvFile - like os.LookupEnv, but supporting `_FILE` variants of
+// variables like getenv does. Unlike getenv, it's an error if the `_FILE`
+// variant names a file that can't be read.
+func lookupEnvFile(key string) (string, bool, error) {
+	if val, ok := lookupEnv(key); ok {
+		return val, true, nil
+	}
+
+	p, ok := lookupEnv(key + "_FILE")
+	if !ok {
+		return "", false, nil
+	}
+
+	val, err := readFile(WrapWdFS(osfs.NewFS()), p)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read %s_FILE: %w", key, err)
+	}
+
+	return strings.TrimSpace(val), true, nil
+}
+
 // ExpandEnvFsys - a convenience function intended for internal use only!
 func ExpandEnvFsys(fsys fs.FS, s string) string {
 	return os.Expand(s, func(s string) string {
@@ -0,0 +1,360 @@
+package datafs
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/hairyhenderson/go-fsimpl"
+	"github.com/hairyhenderson/gomplate/v4/internal/iohelpers"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// NewGRPCFS returns a filesystem (an fs.FS) that can be used to call unary
+// methods of gRPC services, described by the server's reflection service.
+//
+// Files are named for the method to call, as package.Service/Method. The
+// request message is given as JSON in the URL's fragment, and the response is
+// read as a JSON object. The grpcs scheme connects with TLS. Headers given with
+// WithHeader are sent as request metadata.
+func NewGRPCFS(u *url.URL) (fs.FS, error) {
+	if u.Host == "" {
+		return nil, fmt.Errorf("missing host in gRPC URL %q", u)
+	}
+
+	body := u.Fragment
+	if body == "" {
+		body = "{}"
+	}
+
+	if !json.Valid([]byte(body)) {
+		return nil, fmt.Errorf("invalid gRPC request %q: must be JSON", body)
+	}
+
+	return &grpcFS{
+		ctx:  context.Background(),
+		host: u.Host,
+		tls:  u.Scheme == "grpcs",
+		body: body,
+	}, nil
+}
+
+type grpcFS struct {
+	ctx  context.Context
+	md   metadata.MD
+	host string
+	body string
+	tls  bool
+}
+
+//nolint:gochecknoglobals
+var GRPCFS = fsimpl.FSProviderFunc(NewGRPCFS, "grpc", "grpcs")
+
+var (
+	_ fs.FS         = (*grpcFS)(nil)
+	_ withContexter = (*grpcFS)(nil)
+	_ withHeaderer  = (*grpcFS)(nil)
+)
+
+func (f grpcFS) WithContext(ctx context.Context) fs.FS {
+	fsys := f
+	fsys.ctx = ctx
+
+	return &fsys
+}
+
+// WithHeader - the headers are sent as request metadata, with lower-cased
+// keys
+func (f grpcFS) WithHeader(headers http.Header) fs.FS {
+	fsys := f
+	fsys.md = f.md.Copy()
+	if fsys.md == nil {
+		fsys.md = metadata.MD{}
+	}
+
+	for k, v := range headers {
+		fsys.md.Append(k, v...)
+	}
+
+	return &fsys
+}
+
+func (f *grpcFS) Open(name string) (fs.File, error) {
+	service, method, ok := strings.Cut(name, "/")
+	if !fs.ValidPath(name) || !ok || service == "" || method == "" || strings.Contains(method, "/") {
+		return nil, &fs.PathError{
+			Op:   "open",
+			Path: name,
+			Err:  fmt.Errorf("%w: must be package.Service/Method", fs.ErrInvalid),
+		}
+	}
+
+	return &grpcFile{fsys: f, name: name, service: service, method: method}, nil
+}
+
+// grpcDial returns a client connection to the host - the connection is only
+// made once it's used. It can be overridden in tests.
+//
+//nolint:gochecknoglobals
+var grpcDial = func(host string, useTLS bool) (*grpc.ClientConn, error) {
+	creds := insecure.NewCredentials()
+	if useTLS {
+		creds = credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
+	}
+
+	return grpc.NewClient(host, grpc.WithTransportCredentials(creds))
+}
+
+type grpcFile struct {
+	fsys    *grpcFS
+	body    io.Reader
+	err     error
+	name    string
+	service string
+	method  string
+	size    int64
+}
+
+var _ fs.File = (*grpcFile)(nil)
+
+func (f *grpcFile) Close() error {
+	f.body = nil
+	return nil
+}
+
+// fetch calls the method, once per file - failures are remembered too, so the
+// call is never retried
+func (f *grpcFile) fetch() error {
+	if f.body == nil && f.err == nil {
+		f.err = f.call()
+	}
+
+	return f.err
+}
+
+// call looks up the method with server reflection, and calls it. The
+// connection is closed as soon as the response has been read.
+func (f *grpcFile) call() error {
+	conn, err := grpcDial(f.fsys.host, f.fsys.tls)
+	if err != nil {
+		return fmt.Errorf("grpc: failed to create client for %s: %w", f.fsys.host, err)
+	}
+	defer conn.Close()
+
+	ctx := f.fsys.ctx
+	if len(f.fsys.md) > 0 {
+		ctx = metadata.NewOutgoingContext(ctx, f.fsys.md)
+	}
+
+	md, err := f.resolveMethod(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	req := dynamicpb.NewMessage(md.Input())
+	if err := protojson.Unmarshal([]byte(f.fsys.body), req); err != nil {
+		return fmt.Errorf("grpc: invalid request for %s: %w", f.name, err)
+	}
+
+	resp := dynamicpb.NewMessage(md.Output())
+	if err := conn.Invoke(ctx, "/"+f.name, req, resp); err != nil {
+		return f.callError(err)
+	}
+
+	b, err := protojson.MarshalOptions{EmitUnpopulated: true}.Marshal(resp)
+	if err != nil {
+		return fmt.Errorf("grpc: failed to marshal response from %s: %w", f.name, err)
+	}
+
+	f.size = int64(len(b))
+	f.body = bytes.NewReader(b)
+
+	return nil
+}
+
+// callError distinguishes missing resources and denied access from other
+// errors, the same way as other datasources
+func (f *grpcFile) callError(err error) error {
+	var target error
+
+	switch status.Code(err) {
+	case codes.NotFound:
+		target = fs.ErrNotExist
+	case codes.PermissionDenied, codes.Unauthenticated:
+		target = fs.ErrPermission
+	default:
+		return fmt.Errorf("grpc: call to %s failed: %w", f.name, err)
+	}
+
+	return &fs.PathError{
+		Op:   "read",
+		Path: f.name,
+		Err:  fmt.Errorf("grpc: call to %s failed: %w: %w", f.name, target, err),
+	}
+}
+
+// resolveMethod finds the method's descriptor with server reflection. Only
+// unary methods can be called.
+func (f *grpcFile) resolveMethod(ctx context.Context, conn *grpc.ClientConn) (protoreflect.MethodDescriptor, error) {
+	files, err := reflectFiles(ctx, conn, f.service)
+	if err != nil {
+		return nil, fmt.Errorf("grpc: failed to describe service %s on %s: %w", f.service, f.fsys.host, err)
+	}
+
+	d, err := files.FindDescriptorByName(protoreflect.FullName(f.service))
+	if err != nil {
+		return nil, fmt.Errorf("grpc: service %s not found on %s: %w", f.service, f.fsys.host, err)
+	}
+
+	sd, ok := d.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("grpc: %s is not a service", f.service)
+	}
+
+	md := sd.Methods().ByName(protoreflect.Name(f.method))
+	if md == nil {
+		return nil, fmt.Errorf("grpc: service %s has no method %s", f.service, f.method)
+	}
+
+	if md.IsStreamingClient() || md.IsStreamingServer() {
+		return nil, fmt.Errorf("grpc: %s is a streaming method - only unary methods can be called", f.name)
+	}
+
+	return md, nil
+}
+
+// errNoReflection is returned when the server doesn't implement any version of
+// the reflection service
+var errNoReflection = errors.New("server reflection isn't available - the server must register the gRPC reflection service")
+
+// reflectionMethods are the reflection service methods to try, newest first.
+// Both versions use the same messages.
+//
+//nolint:gochecknoglobals
+var reflectionMethods = []string{
+	"/grpc.reflection.v1.ServerReflection/ServerReflectionInfo",
+	"/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo",
+}
+
+// reflectFiles returns the file containing the symbol, and its dependencies,
+// as described by the server's reflection service
+func reflectFiles(ctx context.Context, conn *grpc.ClientConn, symbol string) (*protoregistry.Files, error) {
+	for _, m := range reflectionMethods {
+		files, err := reflectFilesWith(ctx, conn, m, symbol)
+		if status.Code(err) == codes.Unimplemented {
+			continue
+		}
+
+		return files, err
+	}
+
+	return nil, errNoReflection
+}
+
+func reflectFilesWith(ctx context.Context, conn *grpc.ClientConn, method, symbol string) (*protoregistry.Files, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := conn.NewStream(ctx, &grpc.StreamDesc{ClientStreams: true, ServerStreams: true}, method)
+	if err != nil {
+		return nil, err
+	}
+
+	protos := map[string]*descriptorpb.FileDescriptorProto{}
+	requested := map[string]bool{}
+
+	pending := []*rpb.ServerReflectionRequest{{
+		MessageRequest: &rpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: symbol},
+	}}
+
+	for len(pending) > 0 {
+		req := pending[0]
+		pending = pending[1:]
+
+		// when the stream fails, the error is returned by RecvMsg
+		if err := stream.SendMsg(req); err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+
+		resp := &rpb.ServerReflectionResponse{}
+		if err := stream.RecvMsg(resp); err != nil {
+			return nil, err
+		}
+
+		if e := resp.GetErrorResponse(); e != nil {
+			return nil, status.Error(codes.Code(e.GetErrorCode()), e.GetErrorMessage())
+		}
+
+		for _, b := range resp.GetFileDescriptorResponse().GetFileDescriptorProto() {
+			fd := &descriptorpb.FileDescriptorProto{}
+			if err := proto.Unmarshal(b, fd); err != nil {
+				return nil, fmt.Errorf("invalid file descriptor: %w", err)
+			}
+
+			protos[fd.GetName()] = fd
+		}
+
+		// servers usually send all of the dependencies too, but any that are
+		// missing need to be asked for
+		for _, fd := range protos {
+			for _, dep := range fd.GetDependency() {
+				if _, ok := protos[dep]; ok || requested[dep] {
+					continue
+				}
+
+				requested[dep] = true
+				pending = append(pending, &rpb.ServerReflectionRequest{
+					MessageRequest: &rpb.ServerReflectionRequest_FileByFilename{FileByFilename: dep},
+				})
+			}
+		}
+	}
+
+	_ = stream.CloseSend()
+
+	set := &descriptorpb.FileDescriptorSet{}
+	for _, fd := range protos {
+		set.File = append(set.File, fd)
+	}
+
+	return protodesc.NewFiles(set)
+}
+
+func (f *grpcFile) Stat() (fs.FileInfo, error) {
+	if err := f.fetch(); err != nil {
+		return nil, err
+	}
+
+	return FileInfo(path.Base(f.name), f.size, 0o444, time.Time{}, iohelpers.JSONMimetype), nil
+}
+
+func (f *grpcFile) Read(p []byte) (int, error) {
+	if err := f.fetch(); err != nil {
+		return 0, err
+	}
+
+	return f.body.Read(p)
+}
@@ -0,0 +1,146 @@
+package datafs
+
+import (
+	"context"
+	"io/fs"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/hairyhenderson/go-fsimpl"
+	"github.com/hairyhenderson/gomplate/v4/internal/config"
+	"github.com/hairyhenderson/gomplate/v4/internal/iohelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// setupGRPC starts a server with the health service, and (optionally) the
+// reflection service, and points grpcDial at it. The metadata of the last
+// health check is recorded.
+func setupGRPC(t *testing.T, withReflection bool) *metadata.MD {
+	t.Helper()
+
+	md := &metadata.MD{}
+
+	srv := grpc.NewServer(grpc.UnaryInterceptor(
+		func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			*md, _ = metadata.FromIncomingContext(ctx)
+			return handler(ctx, req)
+		}))
+
+	hs := health.NewServer()
+	hs.SetServingStatus("app", healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(srv, hs)
+
+	if withReflection {
+		reflection.Register(srv)
+	}
+
+	lis := bufconn.Listen(1024 * 1024)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	orig := grpcDial
+	t.Cleanup(func() { grpcDial = orig })
+
+	grpcDial = func(_ string, _ bool) (*grpc.ClientConn, error) {
+		return grpc.NewClient("passthrough:///bufconn",
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+				return lis.DialContext(ctx)
+			}),
+			grpc.WithTransportCredentials(insecure.NewCredentials()))
+	}
+
+	return md
+}
+
+func TestGRPCFS(t *testing.T) {
+	md := setupGRPC(t, true)
+
+	fsys, err := NewGRPCFS(mustParseURL(`grpc://localhost:50051/#{"service": "app"}`))
+	require.NoError(t, err)
+
+	fsys = fsimpl.WithHeaderFS(http.Header{"Authorization": {"Bearer foo"}}, fsys)
+
+	b, err := fs.ReadFile(fsys, "grpc.health.v1.Health/Check")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"status": "SERVING"}`, string(b))
+
+	fi, err := fs.Stat(fsys, "grpc.health.v1.Health/Check")
+	require.NoError(t, err)
+	assert.Equal(t, iohelpers.JSONMimetype, fsimpl.ContentType(fi))
+
+	assert.Equal(t, []string{"Bearer foo"}, md.Get("authorization"))
+
+	// NotFound statuses are reported as missing files
+	fsys, err = NewGRPCFS(mustParseURL(`grpc://localhost:50051/#{"service": "missing"}`))
+	require.NoError(t, err)
+
+	_, err = fs.ReadFile(fsys, "grpc.health.v1.Health/Check")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+
+	_, err = fs.ReadFile(fsys, "grpc.health.v1.Health/Bogus")
+	require.ErrorContains(t, err, "service grpc.health.v1.Health has no method Bogus")
+
+	_, err = fs.ReadFile(fsys, "grpc.health.v1.Health/Watch")
+	require.ErrorContains(t, err, "only unary methods can be called")
+
+	_, err = fs.ReadFile(fsys, "bogus.Service/Method")
+	require.ErrorContains(t, err, "failed to describe service bogus.Service")
+
+	_, err = fs.ReadFile(fsys, "grpc.health.v1.Health")
+	require.ErrorIs(t, err, fs.ErrInvalid)
+
+	fsys, err = NewGRPCFS(mustParseURL(`grpc://localhost:50051/#{"bogus": true}`))
+	require.NoError(t, err)
+
+	_, err = fs.ReadFile(fsys, "grpc.health.v1.Health/Check")
+	require.ErrorContains(t, err, "invalid request for grpc.health.v1.Health/Check")
+
+	_, err = NewGRPCFS(mustParseURL(`grpc://localhost:50051/#{bogus`))
+	require.ErrorContains(t, err, "must be JSON")
+
+	_, err = NewGRPCFS(mustParseURL(`grpc:///`))
+	require.ErrorContains(t, err, "missing host")
+
+	t.Run("datasource", func(t *testing.T) {
+		md := setupGRPC(t, true)
+
+		fsp := fsimpl.NewMux()
+		fsp.Add(GRPCFS)
+		ctx := ContextWithFSProvider(context.Background(), fsp)
+
+		reg := NewRegistry()
+		reg.Register("health", config.DataSource{
+			URL:    mustParseURL(`grpc://localhost:50051/grpc.health.v1.Health/Check#{"service":"app"}`),
+			Header: http.Header{"X-Team": {"platform"}},
+		})
+
+		sr := NewSourceReader(reg)
+
+		ct, b, err := sr.ReadSource(ctx, "health")
+		require.NoError(t, err)
+		assert.Equal(t, iohelpers.JSONMimetype, ct)
+		assert.JSONEq(t, `{"status": "SERVING"}`, string(b))
+
+		assert.Equal(t, []string{"platform"}, md.Get("x-team"))
+	})
+}
+
+func TestGRPCFS_NoReflection(t *testing.T) {
+	setupGRPC(t, false)
+
+	fsys, err := NewGRPCFS(mustParseURL(`grpc://localhost:50051/`))
+	require.NoError(t, err)
+
+	_, err = fs.ReadFile(fsys, "grpc.health.v1.Health/Check")
+	require.ErrorIs(t, err, errNoReflection)
+	require.ErrorContains(t, err, "server reflection isn't available")
+}
@@ -0,0 +1,42 @@
+package datafs
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/url"
+
+	"gocloud.dev/gcerrors"
+	"google.golang.org/api/googleapi"
+)
+
+// gsDenied returns true when err is a GCS error returned because the
+// credentials can't be used to read an object. GCS returns 403 Forbidden when
+// the object doesn't exist _and_ the credentials can't list the bucket, which
+// the Go CDK reports as NotFound, so the HTTP status is checked first.
+func gsDenied(err error) bool {
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Code == http.StatusUnauthorized || apiErr.Code == http.StatusForbidden
+	}
+
+	return gcerrors.Code(err) == gcerrors.PermissionDenied
+}
+
+// gsError makes it clear whether a GCS read failed because the object doesn't
+// exist, or because the credentials aren't allowed to read it. Other errors are
+// wrapped with the operation, as for other datasources.
+func gsError(op string, u *url.URL, key string, err error) error {
+	switch {
+	case gsDenied(err):
+		return fmt.Errorf("access to gs object %q in bucket %q denied (check the credentials, or set GOOGLE_ANON=true for public buckets): %w: %w",
+			key, u.Host, fs.ErrPermission, err)
+	case errors.Is(err, fs.ErrNotExist):
+		return fmt.Errorf("gs object %q not found in bucket %q: %w", key, u.Host, err)
+	case gcerrors.Code(err) == gcerrors.NotFound:
+		return fmt.Errorf("gs object %q not found in bucket %q: %w: %w", key, u.Host, fs.ErrNotExist, err)
+	}
+
+	return fmt.Errorf("%s (url: %q, name: %q): %w", op, u, key, err)
+}
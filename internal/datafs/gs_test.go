@@ -0,0 +1,49 @@
+package datafs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gocloud.dev/blob/memblob"
+	"google.golang.org/api/googleapi"
+)
+
+func TestGSError(t *testing.T) {
+	u := mustParseURL("gs://mybucket/")
+
+	err := gsError("stat", u, "foo.json", fmt.Errorf("stat: %w", fs.ErrNotExist))
+	require.ErrorIs(t, err, fs.ErrNotExist)
+	assert.ErrorContains(t, err, `gs object "foo.json" not found in bucket "mybucket"`)
+
+	// Go CDK NotFound errors don't match fs.ErrNotExist on their own
+	bucket := memblob.OpenBucket(nil)
+	t.Cleanup(func() { _ = bucket.Close() })
+
+	_, cerr := bucket.NewReader(context.Background(), "foo.json", nil)
+	require.Error(t, cerr)
+
+	err = gsError("read", u, "foo.json", &fs.PathError{Op: "read", Path: "foo.json", Err: cerr})
+	require.ErrorIs(t, err, fs.ErrNotExist)
+	assert.ErrorContains(t, err, `gs object "foo.json" not found in bucket "mybucket"`)
+
+	err = gsError("stat", u, "foo.json",
+		fmt.Errorf("blob: %w", &googleapi.Error{Code: http.StatusForbidden, Message: "denied"}))
+	require.ErrorIs(t, err, fs.ErrPermission)
+	assert.ErrorContains(t, err, `access to gs object "foo.json" in bucket "mybucket" denied`)
+
+	err = gsError("stat", u, "foo.json",
+		&googleapi.Error{Code: http.StatusUnauthorized, Message: "invalid credentials"})
+	require.ErrorIs(t, err, fs.ErrPermission)
+	assert.ErrorContains(t, err, "denied")
+
+	err = gsError("read", u, "foo.json", errors.New("boom"))
+	assert.NotErrorIs(t, err, fs.ErrPermission)
+	assert.NotErrorIs(t, err, fs.ErrNotExist)
+	assert.EqualError(t, err, `read (url: "gs://mybucket/", name: "foo.json"): boom`)
+}
@@ -0,0 +1,88 @@
+package datafs
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// ExpandHeader returns a copy of the header, with references to secrets in
+// the values resolved, so that they don't need to be given on the command
+// line:
+//
+//   - $VAR or ${VAR} is replaced with the value of the environment variable
+//     VAR, which must be set - or, as for other variables gomplate reads,
+//     with the contents of the file named in VAR_FILE. Use $$ for a literal
+//     '$' (so $${VAR} is the literal text "${VAR}").
+//   - a value of the form @path is replaced with the contents of the file at
+//     path, without trailing newlines. Use @@ for a value starting with a
+//     literal '@'.
+//
+// Errors name the header, but never its value.
+func ExpandHeader(hdr http.Header) (http.Header, error) {
+	if hdr == nil {
+		return nil, nil
+	}
+
+	out := make(http.Header, len(hdr))
+
+	for name, values := range hdr {
+		expanded := make([]string, len(values))
+
+		for i, v := range values {
+			var err error
+
+			expanded[i], err = expandHeaderValue(v)
+			if err != nil {
+				return nil, fmt.Errorf("header %q: %w", name, err)
+			}
+		}
+
+		out[name] = expanded
+	}
+
+	return out, nil
+}
+
+func expandHeaderValue(v string) (string, error) {
+	if strings.HasPrefix(v, "@@") {
+		v = v[1:]
+	} else if p, ok := strings.CutPrefix(v, "@"); ok {
+		b, err := os.ReadFile(p)
+		if err != nil {
+			return "", fmt.Errorf("failed to read value from file: %w", err)
+		}
+
+		return strings.TrimRight(string(b), "\r\n"), nil
+	}
+
+	var lookupErr error
+
+	v = os.Expand(v, func(name string) string {
+		// os.Expand reads "$$" as a reference to the variable named "$"
+		if name == "$" {
+			return "$"
+		}
+
+		if lookupErr != nil {
+			return ""
+		}
+
+		val, ok, err := lookupEnvFile(name)
+		switch {
+		case err != nil:
+			lookupErr = fmt.Errorf("environment variable %q: %w", name, err)
+		case !ok:
+			lookupErr = fmt.Errorf("environment variable %q is not set", name)
+		}
+
+		return val
+	})
+
+	if lookupErr != nil {
+		return "", lookupErr
+	}
+
+	return v, nil
+}
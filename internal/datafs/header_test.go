@@ -0,0 +1,57 @@
+package datafs
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandHeader(t *testing.T) {
+	hdr, err := ExpandHeader(nil)
+	require.NoError(t, err)
+	assert.Nil(t, hdr)
+
+	t.Setenv("TOKEN", "s3cr3t")
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("Bearer fromfile\n"), 0o600))
+
+	fileTokenFile := filepath.Join(t.TempDir(), "file_token")
+	require.NoError(t, os.WriteFile(fileTokenFile, []byte("fromfile\n"), 0o600))
+	t.Setenv("FILE_TOKEN_FILE", fileTokenFile)
+
+	in := http.Header{
+		"Authorization": {"Bearer $TOKEN"},
+		"X-Braces":      {"a${TOKEN}b"},
+		"X-File":        {"@" + tokenFile},
+		"X-Literal":     {"@@not a file", "costs $$5", "plain", "$${TOKEN}"},
+		"X-From-File":   {"Bearer $FILE_TOKEN"},
+	}
+
+	hdr, err = ExpandHeader(in)
+	require.NoError(t, err)
+	assert.Equal(t, http.Header{
+		"Authorization": {"Bearer s3cr3t"},
+		"X-Braces":      {"as3cr3tb"},
+		"X-File":        {"Bearer fromfile"},
+		"X-Literal":     {"@not a file", "costs $5", "plain", "${TOKEN}"},
+		"X-From-File":   {"Bearer fromfile"},
+	}, hdr)
+
+	// the input isn't modified
+	assert.Equal(t, "Bearer $TOKEN", in.Get("Authorization"))
+
+	_, err = ExpandHeader(http.Header{"Authorization": {"Bearer $NOT_SET_ANYWHERE"}})
+	require.EqualError(t, err, `header "Authorization": environment variable "NOT_SET_ANYWHERE" is not set`)
+
+	t.Setenv("BAD_TOKEN_FILE", tokenFile+".missing")
+	_, err = ExpandHeader(http.Header{"Authorization": {"Bearer $BAD_TOKEN"}})
+	require.ErrorContains(t, err, `header "Authorization": environment variable "BAD_TOKEN": failed to read BAD_TOKEN_FILE`)
+
+	_, err = ExpandHeader(http.Header{"Authorization": {"@" + tokenFile + ".missing"}})
+	require.ErrorContains(t, err, `header "Authorization": failed to read value from file`)
+}
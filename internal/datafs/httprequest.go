@@ -0,0 +1,282 @@
+package datafs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+// query parameters for sending HTTP datasource requests with a method other
+// than GET, or with a body - these are removed from the URL before the
+// request is made
+const (
+	httpMethodParam = "method"
+	httpBodyParam   = "body"
+)
+
+// maxHTTPErrorBody is how much of an unsuccessful response's body is included
+// in the error
+const maxHTTPErrorBody = 512
+
+// httpRequestMethods are the methods that can be given in the method
+// parameter
+//
+//nolint:gochecknoglobals
+var httpRequestMethods = []string{
+	http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete,
+}
+
+// extractHTTPRequestParams removes the method and body parameters from http(s)
+// URLs. When the method isn't set (or is GET), the method is empty, and the URL
+// can be read with the usual http filesystem. Other URLs are left untouched.
+//
+// Since the server may also use parameters with these names, a method that
+// isn't one of httpRequestMethods is left in the URL, to be sent with the
+// request. The body is only sent when a method other than GET is given, and is
+// otherwise also left in the URL.
+//
+// The body is given literally, or as @path to read it from a file, resolved
+// the same way as with file.Read. Use @@ for a body starting with a literal '@'.
+func extractHTTPRequestParams(ctx context.Context, u *url.URL) (*url.URL, string, []byte, error) {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return u, "", nil, nil
+	}
+
+	q := u.Query()
+
+	method := strings.ToUpper(q.Get(httpMethodParam))
+	if !slices.Contains(httpRequestMethods, method) {
+		return u, "", nil, nil
+	}
+
+	u = removeQueryParam(u, httpMethodParam)
+
+	if method == http.MethodGet {
+		return u, "", nil, nil
+	}
+
+	if !q.Has(httpBodyParam) {
+		return u, method, nil, nil
+	}
+
+	u = removeQueryParam(u, httpBodyParam)
+
+	body, err := httpRequestBody(ctx, q.Get(httpBodyParam))
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	return u, method, body, nil
+}
+
+// httpRequestBody resolves the body parameter
+func httpRequestBody(ctx context.Context, v string) ([]byte, error) {
+	if strings.HasPrefix(v, "@@") {
+		return []byte(v[1:]), nil
+	}
+
+	name, ok := strings.CutPrefix(v, "@")
+	if !ok {
+		return []byte(v), nil
+	}
+
+	fsys, err := FSysForPath(ctx, "/")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body from %q: %w", name, err)
+	}
+
+	b, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body from %q: %w", name, err)
+	}
+
+	return b, nil
+}
+
+// newHTTPRequestFS returns a filesystem which reads files by sending a request
+// with the given method and body to the file's URL, relative to base. Each
+// file is requested just once, and its content type is taken from the
+// response. JSON bodies are sent with a Content-Type of application/json,
+// unless a Content-Type header is set with WithHeader.
+func newHTTPRequestFS(base *url.URL, method string, body []byte) fs.FS {
+	u := *base
+	u.Path = "/"
+
+	return &httpRequestFS{
+		ctx:    context.Background(),
+		client: http.DefaultClient,
+		base:   &u,
+		method: method,
+		body:   body,
+	}
+}
+
+type httpRequestFS struct {
+	ctx    context.Context
+	client *http.Client
+	header http.Header
+	base   *url.URL
+	method string
+	body   []byte
+}
+
+var (
+	_ fs.FS         = (*httpRequestFS)(nil)
+	_ withContexter = (*httpRequestFS)(nil)
+	_ withHeaderer  = (*httpRequestFS)(nil)
+)
+
+func (f httpRequestFS) WithContext(ctx context.Context) fs.FS {
+	fsys := f
+	fsys.ctx = ctx
+
+	return &fsys
+}
+
+func (f httpRequestFS) WithHeader(headers http.Header) fs.FS {
+	fsys := f
+	fsys.header = f.header.Clone()
+	if fsys.header == nil {
+		fsys.header = http.Header{}
+	}
+
+	for k, v := range headers {
+		for _, vv := range v {
+			fsys.header.Add(k, vv)
+		}
+	}
+
+	return &fsys
+}
+
+func (f httpRequestFS) WithHTTPClient(client *http.Client) fs.FS {
+	fsys := f
+	fsys.client = client
+
+	return &fsys
+}
+
+func (f *httpRequestFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+
+	u := *f.base
+	if name != "." {
+		u.Path = "/" + name
+	}
+
+	return &httpRequestFile{fsys: f, name: name, u: &u}, nil
+}
+
+type httpRequestFile struct {
+	fsys *httpRequestFS
+	u    *url.URL
+	body io.Reader
+	fi   fs.FileInfo
+	err  error
+	name string
+}
+
+var _ fs.File = (*httpRequestFile)(nil)
+
+func (f *httpRequestFile) Close() error {
+	f.body = nil
+	return nil
+}
+
+// fetch sends the request, once per file - failures are remembered too, so
+// the request is never repeated
+func (f *httpRequestFile) fetch() error {
+	if f.body == nil && f.err == nil {
+		f.err = f.request()
+	}
+
+	return f.err
+}
+
+func (f *httpRequestFile) request() error {
+	var body io.Reader
+	if f.fsys.body != nil {
+		body = bytes.NewReader(f.fsys.body)
+	}
+
+	req, err := http.NewRequestWithContext(f.fsys.ctx, f.fsys.method, f.u.String(), body)
+	if err != nil {
+		return err
+	}
+
+	if f.fsys.header != nil {
+		req.Header = f.fsys.header.Clone()
+	}
+
+	if req.Header.Get("Content-Type") == "" && json.Valid(f.fsys.body) {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := f.fsys.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("http %s: failed to read response: %w", f.fsys.method, err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		err = fmt.Errorf("http %s failed with status %s", f.fsys.method, resp.Status)
+		if s := truncateBody(b); s != "" {
+			err = fmt.Errorf("%w: %s", err, s)
+		}
+
+		return err
+	}
+
+	modTime := time.Time{}
+	if mod := resp.Header.Get("Last-Modified"); mod != "" {
+		modTime, _ = http.ParseTime(mod)
+	}
+
+	f.fi = FileInfo(path.Base(f.name), int64(len(b)), 0o444, modTime, resp.Header.Get("Content-Type"))
+	f.body = bytes.NewReader(b)
+
+	return nil
+}
+
+// truncateBody shortens an error response's body, so large error pages don't
+// swamp the error message
+func truncateBody(b []byte) string {
+	s := strings.TrimSpace(string(b))
+	if len(s) > maxHTTPErrorBody {
+		s = s[:maxHTTPErrorBody] + "..."
+	}
+
+	return s
+}
+
+func (f *httpRequestFile) Stat() (fs.FileInfo, error) {
+	if err := f.fetch(); err != nil {
+		return nil, err
+	}
+
+	return f.fi, nil
+}
+
+func (f *httpRequestFile) Read(p []byte) (int, error) {
+	if err := f.fetch(); err != nil {
+		return 0, err
+	}
+
+	return f.body.Read(p)
+}
@@ -0,0 +1,156 @@
+package datafs
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hairyhenderson/go-fsimpl"
+	"github.com/hairyhenderson/go-fsimpl/httpfs"
+	"github.com/hairyhenderson/gomplate/v4/internal/iohelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractHTTPRequestParams(t *testing.T) {
+	fsys := WrapWdFS(fstest.MapFS{
+		"query.graphql": &fstest.MapFile{Data: []byte(`{"query": "{ viewer { login } }"}`)},
+	})
+	ctx := ContextWithFSProvider(context.Background(), WrappedFSProvider(fsys, "file", ""))
+
+	u, method, body, err := extractHTTPRequestParams(ctx, mustParseURL("file:///foo.json?method=POST&body=foo"))
+	require.NoError(t, err)
+	assert.Equal(t, "file:///foo.json?method=POST&body=foo", u.String())
+	assert.Empty(t, method)
+	assert.Nil(t, body)
+
+	u, method, body, err = extractHTTPRequestParams(ctx, mustParseURL("https://example.com/api?a=b"))
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/api?a=b", u.String())
+	assert.Empty(t, method)
+	assert.Nil(t, body)
+
+	u, method, body, err = extractHTTPRequestParams(ctx, mustParseURL("https://example.com/graphql?a=b&method=post&body=@/query.graphql"))
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/graphql?a=b", u.String())
+	assert.Equal(t, http.MethodPost, method)
+	assert.Equal(t, `{"query": "{ viewer { login } }"}`, string(body))
+
+	// a body is only sent when a method is given, otherwise it's for the server
+	u, method, body, err = extractHTTPRequestParams(ctx, mustParseURL("https://example.com/api?body=hello"))
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/api?body=hello", u.String())
+	assert.Empty(t, method)
+	assert.Nil(t, body)
+
+	_, _, body, err = extractHTTPRequestParams(ctx, mustParseURL("https://example.com/api?method=POST&body=@@me"))
+	require.NoError(t, err)
+	assert.Equal(t, "@me", string(body))
+
+	_, method, body, err = extractHTTPRequestParams(ctx, mustParseURL("https://example.com/api?method=DELETE"))
+	require.NoError(t, err)
+	assert.Equal(t, http.MethodDelete, method)
+	assert.Nil(t, body)
+
+	// GET is an ordinary request, and the body is left for the server
+	u, method, body, err = extractHTTPRequestParams(ctx, mustParseURL("https://example.com/api?method=GET&body=foo"))
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/api?body=foo", u.String())
+	assert.Empty(t, method)
+	assert.Nil(t, body)
+
+	// methods that aren't HTTP methods are also for the server
+	u, method, body, err = extractHTTPRequestParams(ctx, mustParseURL("https://example.com/api?method=flickr.photos.search&body=x"))
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/api?method=flickr.photos.search&body=x", u.String())
+	assert.Empty(t, method)
+	assert.Nil(t, body)
+
+	_, _, _, err = extractHTTPRequestParams(ctx, mustParseURL("https://example.com/api?method=POST&body=@/missing.graphql"))
+	require.ErrorContains(t, err, `failed to read request body from "/missing.graphql"`)
+}
+
+func TestReadFileContent_HTTPRequest(t *testing.T) {
+	var requests atomic.Int32
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+
+		if r.Method != http.MethodPost || r.URL.RawQuery != "" {
+			http.Error(w, "unexpected request "+r.Method+" "+r.URL.String(), http.StatusBadRequest)
+			return
+		}
+
+		if r.Header.Get("Authorization") != "Bearer foo" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		b, _ := io.ReadAll(r.Body)
+		if r.Header.Get("Content-Type") != iohelpers.JSONMimetype || string(b) != `{"query": "{ viewer { login } }"}` {
+			http.Error(w, "unexpected body "+string(b), http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", iohelpers.JSONMimetype)
+		w.Write([]byte(`{"data": {"viewer": {"login": "octocat"}}}`))
+	})
+	mux.HandleFunc("/items/1", func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+
+		b, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte(r.Method + " " + r.Header.Get("Content-Type") + " " + string(b)))
+	})
+	mux.HandleFunc("/huge", func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, strings.Repeat("x", 2000), http.StatusInternalServerError)
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	fsys := WrapWdFS(fstest.MapFS{
+		"query.graphql": &fstest.MapFile{Data: []byte(`{"query": "{ viewer { login } }"}`)},
+	})
+
+	fsp := fsimpl.NewMux()
+	fsp.Add(httpfs.FS)
+	fsp.Add(WrappedFSProvider(fsys, "file", ""))
+	ctx := ContextWithFSProvider(context.Background(), fsp)
+
+	sr := &dsReader{Registry: NewRegistry()}
+
+	hdr := http.Header{"Authorization": {"Bearer foo"}}
+
+	fc, err := sr.readFileContent(ctx, mustParseURL(srv.URL+"/graphql?method=POST&body=@/query.graphql"), hdr)
+	require.NoError(t, err)
+	assert.Equal(t, iohelpers.JSONMimetype, fc.contentType)
+	assert.JSONEq(t, `{"data": {"viewer": {"login": "octocat"}}}`, string(fc.b))
+
+	// the request is only sent once
+	assert.Equal(t, int32(1), requests.Load())
+
+	_, err = sr.readFileContent(ctx, mustParseURL(srv.URL+"/graphql?method=POST&body=@/query.graphql"), nil)
+	require.ErrorContains(t, err, "http POST failed with status 401 Unauthorized: unauthorized")
+
+	// the Content-Type header can be set explicitly
+	fc, err = sr.readFileContent(ctx, mustParseURL(srv.URL+"/items/1?method=put&body={}"),
+		http.Header{"Content-Type": {"text/plain"}})
+	require.NoError(t, err)
+	assert.Equal(t, "PUT text/plain {}", string(fc.b))
+
+	fc, err = sr.readFileContent(ctx, mustParseURL(srv.URL+"/items/1?method=DELETE"), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "DELETE  ", string(fc.b))
+
+	// long error responses are truncated
+	_, err = sr.readFileContent(ctx, mustParseURL(srv.URL+"/huge?method=POST"), nil)
+	require.ErrorContains(t, err, "http POST failed with status 500 Internal Server Error: "+strings.Repeat("x", maxHTTPErrorBody)+"...")
+	assert.NotContains(t, err.Error(), strings.Repeat("x", maxHTTPErrorBody+1))
+}
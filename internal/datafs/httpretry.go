@@ -0,0 +1,218 @@
+package datafs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// query parameters for controlling HTTP datasource requests - these are
+// removed from the URL before the request is made
+const (
+	httpTimeoutParam = "timeout"
+	httpRetriesParam = "retries"
+)
+
+// httpRetryBackoff is the delay before the first retry, doubled for each
+// subsequent retry. Overridable for testing.
+//
+//nolint:gochecknoglobals
+var httpRetryBackoff = 250 * time.Millisecond
+
+// maxHTTPRetryBackoff caps the delay between retries
+const maxHTTPRetryBackoff = 10 * time.Second
+
+// extractHTTPParams removes the timeout and retries parameters from http(s)
+// URLs, falling back to the GOMPLATE_HTTP_TIMEOUT and GOMPLATE_HTTP_RETRIES
+// environment variables. Other URLs are left untouched.
+//
+// Since the server may also use parameters with these names, values that
+// aren't valid (i.e. a timeout that's not a duration) are left in the URL, to
+// be sent with the request.
+func extractHTTPParams(u *url.URL) (*url.URL, time.Duration, int, error) {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return u, 0, 0, nil
+	}
+
+	q := u.Query()
+
+	timeout, ok := parseHTTPTimeout(q.Get(httpTimeoutParam))
+	if ok {
+		u = removeQueryParam(u, httpTimeoutParam)
+	} else {
+		var err error
+		timeout, err = httpTimeoutFromEnv()
+		if err != nil {
+			return nil, 0, 0, err
+		}
+	}
+
+	retries, ok := parseHTTPRetries(q.Get(httpRetriesParam))
+	if ok {
+		u = removeQueryParam(u, httpRetriesParam)
+	} else {
+		var err error
+		retries, err = httpRetriesFromEnv()
+		if err != nil {
+			return nil, 0, 0, err
+		}
+	}
+
+	return u, timeout, retries, nil
+}
+
+// parseHTTPTimeout parses a non-negative duration like 5s
+func parseHTTPTimeout(v string) (time.Duration, bool) {
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		return 0, false
+	}
+
+	return d, true
+}
+
+// parseHTTPRetries parses a non-negative number of retries
+func parseHTTPRetries(v string) (int, bool) {
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+
+	return n, true
+}
+
+// httpTimeoutFromEnv parses GOMPLATE_HTTP_TIMEOUT. No timeout (0) is the
+// default.
+func httpTimeoutFromEnv() (time.Duration, error) {
+	v := getenv("GOMPLATE_HTTP_TIMEOUT")
+	if v == "" {
+		return 0, nil
+	}
+
+	d, ok := parseHTTPTimeout(v)
+	if !ok {
+		return 0, fmt.Errorf("invalid GOMPLATE_HTTP_TIMEOUT %q: must be a non-negative duration like 5s", v)
+	}
+
+	return d, nil
+}
+
+// httpRetriesFromEnv parses GOMPLATE_HTTP_RETRIES. No retries (0) is the
+// default.
+func httpRetriesFromEnv() (int, error) {
+	v := getenv("GOMPLATE_HTTP_RETRIES")
+	if v == "" {
+		return 0, nil
+	}
+
+	n, ok := parseHTTPRetries(v)
+	if !ok {
+		return 0, fmt.Errorf("invalid GOMPLATE_HTTP_RETRIES %q: must be a non-negative number", v)
+	}
+
+	return n, nil
+}
+
+// newRetryingHTTPClient returns a client that gives each attempt the given
+// timeout (if non-zero), and retries failed requests with exponential backoff.
+// Only connection errors and 5xx responses are retried.
+func newRetryingHTTPClient(timeout time.Duration, retries int) *http.Client {
+	return &http.Client{
+		Transport: &retryTransport{
+			base:    http.DefaultTransport,
+			timeout: timeout,
+			retries: retries,
+		},
+	}
+}
+
+type retryTransport struct {
+	base    http.RoundTripper
+	timeout time.Duration
+	retries int
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	backoff := httpRetryBackoff
+
+	for attempt := 1; ; attempt++ {
+		resp, err := t.roundTrip(req)
+
+		retryable := err != nil || resp.StatusCode >= http.StatusInternalServerError
+		if !retryable || t.retries == 0 || !canRetry(req) {
+			return resp, err
+		}
+
+		if err == nil {
+			// make sure the connection can be reused
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+
+			err = fmt.Errorf("server responded with %s", resp.Status)
+		}
+
+		// give up when the caller did - a timed-out attempt is still retried
+		if req.Context().Err() != nil {
+			return nil, err
+		}
+
+		if attempt > t.retries {
+			return nil, fmt.Errorf("giving up after %d attempts: %w", attempt, err)
+		}
+
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(backoff):
+		}
+
+		backoff = min(backoff*2, maxHTTPRetryBackoff)
+	}
+}
+
+// roundTrip makes a single attempt, with the timeout if one is set. The
+// timeout covers reading the body too, so it's only cancelled once the body
+// is closed.
+func (t *retryTransport) roundTrip(req *http.Request) (*http.Response, error) {
+	if t.timeout == 0 {
+		return t.base.RoundTrip(req)
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), t.timeout)
+
+	resp, err := t.base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+
+		if errors.Is(err, context.DeadlineExceeded) && req.Context().Err() == nil {
+			err = fmt.Errorf("timed out after %s: %w", t.timeout, err)
+		}
+
+		return nil, err
+	}
+
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+
+	return resp, nil
+}
+
+// canRetry reports whether the request can be sent again - requests with
+// bodies aren't retried, since the body has already been read
+func canRetry(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody
+}
+
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	defer c.cancel()
+	return c.ReadCloser.Close()
+}
@@ -0,0 +1,140 @@
+package datafs
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hairyhenderson/go-fsimpl"
+	"github.com/hairyhenderson/go-fsimpl/httpfs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractHTTPParams(t *testing.T) {
+	u, timeout, retries, err := extractHTTPParams(mustParseURL("file:///foo.json?timeout=5s&retries=3"))
+	require.NoError(t, err)
+	assert.Equal(t, "file:///foo.json?timeout=5s&retries=3", u.String())
+	assert.Zero(t, timeout)
+	assert.Zero(t, retries)
+
+	u, timeout, retries, err = extractHTTPParams(mustParseURL("https://example.com/foo.json?a=b&timeout=5s&retries=3"))
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/foo.json?a=b", u.String())
+	assert.Equal(t, 5*time.Second, timeout)
+	assert.Equal(t, 3, retries)
+
+	t.Setenv("GOMPLATE_HTTP_TIMEOUT", "1m")
+	t.Setenv("GOMPLATE_HTTP_RETRIES", "2")
+
+	u, timeout, retries, err = extractHTTPParams(mustParseURL("http://example.com/foo.json"))
+	require.NoError(t, err)
+	assert.Equal(t, "http://example.com/foo.json", u.String())
+	assert.Equal(t, time.Minute, timeout)
+	assert.Equal(t, 2, retries)
+
+	// parameters override the environment
+	_, timeout, retries, err = extractHTTPParams(mustParseURL("http://example.com/foo.json?retries=0&timeout=1s"))
+	require.NoError(t, err)
+	assert.Equal(t, time.Second, timeout)
+	assert.Equal(t, 0, retries)
+
+	// parameters that aren't valid may be meant for the server, so they're
+	// sent with the request, and the environment still applies
+	u, timeout, retries, err = extractHTTPParams(mustParseURL("http://example.com/foo.json?timeout=30&retries=all"))
+	require.NoError(t, err)
+	assert.Equal(t, "http://example.com/foo.json?timeout=30&retries=all", u.String())
+	assert.Equal(t, time.Minute, timeout)
+	assert.Equal(t, 2, retries)
+
+	u, _, retries, err = extractHTTPParams(mustParseURL("http://example.com/foo.json?retries=-1&timeout=2s"))
+	require.NoError(t, err)
+	assert.Equal(t, "http://example.com/foo.json?retries=-1", u.String())
+	assert.Equal(t, 2, retries)
+
+	t.Setenv("GOMPLATE_HTTP_RETRIES", "lots")
+	_, _, _, err = extractHTTPParams(mustParseURL("http://example.com/foo.json"))
+	require.ErrorContains(t, err, `invalid GOMPLATE_HTTP_RETRIES "lots"`)
+
+	// _FILE variants of the environment variables are supported
+	f := filepath.Join(t.TempDir(), "timeout")
+	require.NoError(t, os.WriteFile(f, []byte("3s\n"), 0o600))
+
+	t.Setenv("GOMPLATE_HTTP_TIMEOUT", "")
+	t.Setenv("GOMPLATE_HTTP_TIMEOUT_FILE", f)
+	t.Setenv("GOMPLATE_HTTP_RETRIES", "")
+
+	_, timeout, _, err = extractHTTPParams(mustParseURL("http://example.com/foo.json"))
+	require.NoError(t, err)
+	assert.Equal(t, 3*time.Second, timeout)
+}
+
+func TestReadFileContent_HTTPRetries(t *testing.T) {
+	httpRetryBackoff = time.Millisecond
+	t.Cleanup(func() { httpRetryBackoff = 250 * time.Millisecond })
+
+	var flaky, broken, missing atomic.Int32
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/flaky.json", func(w http.ResponseWriter, r *http.Request) {
+		// the retry parameters must not be sent to the server
+		if r.URL.RawQuery != "" {
+			http.Error(w, "unexpected query "+r.URL.RawQuery, http.StatusBadRequest)
+			return
+		}
+
+		if flaky.Add(1) <= 2 {
+			http.Error(w, "try again", http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"foo": "bar"}`))
+	})
+	mux.HandleFunc("/broken.json", func(w http.ResponseWriter, _ *http.Request) {
+		broken.Add(1)
+		http.Error(w, "broken", http.StatusBadGateway)
+	})
+	mux.HandleFunc("/missing.json", func(w http.ResponseWriter, r *http.Request) {
+		missing.Add(1)
+		http.NotFound(w, r)
+	})
+	mux.HandleFunc("/slow.json", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	fsp := fsimpl.NewMux()
+	fsp.Add(httpfs.FS)
+	ctx := ContextWithFSProvider(context.Background(), fsp)
+
+	sr := &dsReader{Registry: NewRegistry()}
+
+	fc, err := sr.readFileContent(ctx, mustParseURL(srv.URL+"/flaky.json?retries=3"), nil)
+	require.NoError(t, err)
+	assert.Equal(t, []byte(`{"foo": "bar"}`), fc.b)
+
+	_, err = sr.readFileContent(ctx, mustParseURL(srv.URL+"/broken.json?retries=2"), nil)
+	require.ErrorContains(t, err, "giving up after 3 attempts: server responded with 502 Bad Gateway")
+	assert.Equal(t, int32(3), broken.Load())
+
+	// 4xx responses aren't retried
+	_, err = sr.readFileContent(ctx, mustParseURL(srv.URL+"/missing.json?retries=2"), nil)
+	require.Error(t, err)
+	assert.Equal(t, int32(1), missing.Load())
+
+	start := time.Now()
+	_, err = sr.readFileContent(ctx, mustParseURL(srv.URL+"/slow.json?timeout=50ms&retries=1"), nil)
+	require.ErrorContains(t, err, "giving up after 2 attempts: timed out after 50ms")
+	assert.Less(t, time.Since(start), time.Second)
+}
@@ -0,0 +1,358 @@
+package datafs
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/hairyhenderson/go-fsimpl"
+	"github.com/hairyhenderson/gomplate/v4/internal/iohelpers"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// NewK8sFS returns a filesystem (an fs.FS) that can be used to read
+// ConfigMaps and Secrets from a Kubernetes cluster.
+//
+// The namespace is taken from the URL's host. When it's empty, the namespace
+// of the current kubeconfig context (or of the pod, when running in a
+// cluster) is used. Files are named kind/name, or kind/name/key to read only
+// a single key, where kind is configmap or secret. The cluster is found with
+// the kubeconfig file (KUBECONFIG, or ~/.kube/config), or with the in-cluster
+// config. The context query parameter selects a kubeconfig context other than
+// the current one.
+func NewK8sFS(u *url.URL) (fs.FS, error) {
+	return &k8sFS{
+		ctx:         context.Background(),
+		namespace:   u.Host,
+		kubeContext: u.Query().Get("context"),
+	}, nil
+}
+
+type k8sFS struct {
+	ctx         context.Context
+	namespace   string
+	kubeContext string
+}
+
+//nolint:gochecknoglobals
+var K8sFS = fsimpl.FSProviderFunc(NewK8sFS, "k8s")
+
+var (
+	_ fs.FS         = (*k8sFS)(nil)
+	_ withContexter = (*k8sFS)(nil)
+)
+
+func (f k8sFS) WithContext(ctx context.Context) fs.FS {
+	fsys := f
+	fsys.ctx = ctx
+
+	return &fsys
+}
+
+func (f *k8sFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) || name == "." {
+		return nil, &fs.PathError{
+			Op:   "open",
+			Path: name,
+			Err:  fs.ErrInvalid,
+		}
+	}
+
+	parts := strings.SplitN(name, "/", 3)
+	if len(parts) < 2 {
+		return nil, &fs.PathError{
+			Op:   "open",
+			Path: name,
+			Err:  fmt.Errorf("%w: must be configmap/name or secret/name, optionally followed by /key", fs.ErrInvalid),
+		}
+	}
+
+	kind := strings.ToLower(parts[0])
+	switch kind {
+	case "configmap", "configmaps", "cm":
+		kind = "configmap"
+	case "secret", "secrets":
+		kind = "secret"
+	default:
+		return nil, &fs.PathError{
+			Op:   "open",
+			Path: name,
+			Err:  fmt.Errorf("%w: unsupported kind %q, must be configmap or secret", fs.ErrInvalid, parts[0]),
+		}
+	}
+
+	file := &k8sFile{fsys: f, name: name, kind: kind, objName: parts[1]}
+	if len(parts) == 3 {
+		file.key = parts[2]
+	}
+
+	return file, nil
+}
+
+// k8sClient reads core/v1 objects with plain REST requests - the typed
+// clientset isn't used, since it includes every API group, and would make
+// the binary much larger
+type k8sClient struct {
+	http *http.Client
+	base *url.URL
+}
+
+// newK8sClient returns a client for the cluster, the default namespace, and a
+// function to tear down the client's connections
+func newK8sClient(kubeContext string) (*k8sClient, string, func(), error) {
+	cc := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		clientcmd.NewDefaultClientConfigLoadingRules(),
+		&clientcmd.ConfigOverrides{CurrentContext: kubeContext},
+	)
+
+	cfg, err := cc.ClientConfig()
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("k8s: failed to load client config: %w", err)
+	}
+
+	ns, _, err := cc.Namespace()
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("k8s: failed to determine namespace: %w", err)
+	}
+
+	base, _, err := rest.DefaultServerUrlFor(cfg)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("k8s: invalid server URL: %w", err)
+	}
+
+	httpClient, err := rest.HTTPClientFor(cfg)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("k8s: failed to create HTTP client: %w", err)
+	}
+
+	return &k8sClient{http: httpClient, base: base}, ns, httpClient.CloseIdleConnections, nil
+}
+
+// k8sObject holds the fields of ConfigMaps and Secrets that are read. Secret
+// values are base64-encoded on the wire, so they're decoded into []byte.
+type k8sObject struct {
+	Metadata struct {
+		CreationTimestamp time.Time `json:"creationTimestamp"`
+	} `json:"metadata"`
+	// Data - a ConfigMap's data is a map of strings, but a Secret's is
+	// base64-encoded
+	Data       json.RawMessage   `json:"data"`
+	BinaryData map[string][]byte `json:"binaryData"`
+}
+
+// k8sStatus is the error returned by the API server
+type k8sStatus struct {
+	Message string `json:"message"`
+	Reason  string `json:"reason"`
+}
+
+// get reads the named object of the given resource type (configmaps or
+// secrets)
+func (c *k8sClient) get(ctx context.Context, ns, resource, name string) (*k8sObject, int, error) {
+	u := c.base.JoinPath("api", "v1", "namespaces", ns, resource, name)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, resp.StatusCode, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		status := k8sStatus{}
+		if json.Unmarshal(b, &status) != nil || status.Message == "" {
+			status.Message = strings.TrimSpace(string(b))
+		}
+
+		return nil, resp.StatusCode, fmt.Errorf("%s (%s)", status.Message, resp.Status)
+	}
+
+	obj := &k8sObject{}
+	if err := json.Unmarshal(b, obj); err != nil {
+		return nil, resp.StatusCode, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return obj, resp.StatusCode, nil
+}
+
+type k8sFile struct {
+	fsys    *k8sFS
+	body    io.Reader
+	name    string
+	kind    string
+	objName string
+	key     string
+
+	contentType string
+	size        int64
+	modTime     time.Time
+}
+
+var _ fs.File = (*k8sFile)(nil)
+
+func (f *k8sFile) Close() error {
+	f.body = nil
+	return nil
+}
+
+// fetch reads the ConfigMap or Secret. The client is only held for the
+// duration of the read, so nothing needs to be torn down when the file is
+// closed.
+func (f *k8sFile) fetch() error {
+	if f.body != nil {
+		return nil
+	}
+
+	client, ns, closeClient, err := newK8sClient(f.fsys.kubeContext)
+	if err != nil {
+		return err
+	}
+	defer closeClient()
+
+	if f.fsys.namespace != "" {
+		ns = f.fsys.namespace
+	}
+
+	data, binaryData, modTime, err := f.get(client, ns)
+	if err != nil {
+		return err
+	}
+
+	f.modTime = modTime
+
+	var b []byte
+
+	if f.key != "" {
+		v, ok := data[f.key]
+		if !ok {
+			v, ok = binaryData[f.key]
+		}
+
+		if !ok {
+			return &fs.PathError{
+				Op:   "read",
+				Path: f.name,
+				Err:  fmt.Errorf("key %q not found in %s %s/%s: %w", f.key, f.kind, ns, f.objName, fs.ErrNotExist),
+			}
+		}
+
+		b = v
+	} else {
+		m := make(map[string]string, len(data)+len(binaryData))
+		for k, v := range data {
+			m[k] = string(v)
+		}
+
+		// binary data may not be valid text, so it's kept base64-encoded
+		for k, v := range binaryData {
+			m[k] = base64.StdEncoding.EncodeToString(v)
+		}
+
+		b, err = json.Marshal(m)
+		if err != nil {
+			return fmt.Errorf("k8s: failed to marshal %s %s/%s: %w", f.kind, ns, f.objName, err)
+		}
+
+		f.contentType = iohelpers.JSONMimetype
+	}
+
+	f.size = int64(len(b))
+	f.body = bytes.NewReader(b)
+
+	return nil
+}
+
+// get reads the object's data, with Secret values base64-decoded
+func (f *k8sFile) get(client *k8sClient, ns string) (data, binaryData map[string][]byte, modTime time.Time, err error) {
+	resource := f.kind + "s"
+
+	obj, code, err := client.get(f.fsys.ctx, ns, resource, f.objName)
+	if err != nil {
+		return nil, nil, modTime, f.apiError(ns, code, err)
+	}
+
+	switch f.kind {
+	case "secret":
+		err = unmarshalK8sData(obj.Data, &data)
+	default:
+		var strData map[string]string
+		err = unmarshalK8sData(obj.Data, &strData)
+
+		data = make(map[string][]byte, len(strData))
+		for k, v := range strData {
+			data[k] = []byte(v)
+		}
+	}
+
+	if err != nil {
+		return nil, nil, modTime, fmt.Errorf("k8s: failed to parse %s %s/%s: %w", f.kind, ns, f.objName, err)
+	}
+
+	return data, obj.BinaryData, obj.Metadata.CreationTimestamp, nil
+}
+
+func unmarshalK8sData(raw json.RawMessage, v interface{}) error {
+	if len(raw) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(raw, v)
+}
+
+// apiError distinguishes missing objects from those the client isn't allowed
+// to read, so that RBAC problems aren't mistaken for typos
+func (f *k8sFile) apiError(ns string, code int, err error) error {
+	switch code {
+	case http.StatusNotFound:
+		return &fs.PathError{
+			Op:   "read",
+			Path: f.name,
+			Err:  fmt.Errorf("%s %s/%s not found: %w", f.kind, ns, f.objName, fs.ErrNotExist),
+		}
+	case http.StatusForbidden, http.StatusUnauthorized:
+		return &fs.PathError{
+			Op:   "read",
+			Path: f.name,
+			Err:  fmt.Errorf("access to %s %s/%s denied (check RBAC permissions): %w: %w", f.kind, ns, f.objName, fs.ErrPermission, err),
+		}
+	default:
+		return fmt.Errorf("k8s: failed to get %s %s/%s: %w", f.kind, ns, f.objName, err)
+	}
+}
+
+func (f *k8sFile) Stat() (fs.FileInfo, error) {
+	if err := f.fetch(); err != nil {
+		return nil, err
+	}
+
+	// when a single key is read, the content type is inferred from its name
+	return FileInfo(path.Base(f.name), f.size, 0o444, f.modTime, f.contentType), nil
+}
+
+func (f *k8sFile) Read(p []byte) (int, error) {
+	if err := f.fetch(); err != nil {
+		return 0, err
+	}
+
+	return f.body.Read(p)
+}
@@ -0,0 +1,210 @@
+package datafs
+
+import (
+	"context"
+	"encoding/json"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hairyhenderson/go-fsimpl"
+	"github.com/hairyhenderson/gomplate/v4/internal/config"
+	"github.com/hairyhenderson/gomplate/v4/internal/iohelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupK8s starts a fake Kubernetes API server, and points KUBECONFIG at it
+func setupK8s(t *testing.T) {
+	t.Helper()
+
+	objects := map[string]map[string]interface{}{
+		"/api/v1/namespaces/apps/configmaps/app": {
+			"kind":       "ConfigMap",
+			"apiVersion": "v1",
+			"metadata":   map[string]interface{}{"name": "app", "namespace": "apps"},
+			"data":       map[string]string{"name": "app", "config.json": `{"replicas": 3}`},
+			"binaryData": map[string]string{"blob": "AAEC"},
+		},
+		"/api/v1/namespaces/default/configmaps/app": {
+			"kind":       "ConfigMap",
+			"apiVersion": "v1",
+			"metadata":   map[string]interface{}{"name": "app", "namespace": "default"},
+			"data":       map[string]string{"name": "default app"},
+		},
+		"/api/v1/namespaces/apps/secrets/creds": {
+			"kind":       "Secret",
+			"apiVersion": "v1",
+			"metadata":   map[string]interface{}{"name": "creds", "namespace": "apps"},
+			// secret values are base64-encoded on the wire
+			"data": map[string]string{"user": "YWRtaW4=", "pass": "aHVudGVyMg=="},
+		},
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.URL.Path == "/api/v1/namespaces/apps/secrets/private" {
+			w.WriteHeader(http.StatusForbidden)
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"kind": "Status", "apiVersion": "v1", "status": "Failure",
+				"reason": "Forbidden", "code": http.StatusForbidden,
+				"message": `secrets "private" is forbidden: User "test" cannot get resource "secrets"`,
+			})
+
+			return
+		}
+
+		obj, ok := objects[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"kind": "Status", "apiVersion": "v1", "status": "Failure",
+				"reason": "NotFound", "code": http.StatusNotFound,
+				"message": "not found",
+			})
+
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(obj)
+	}))
+	t.Cleanup(srv.Close)
+
+	kubeconfig := `apiVersion: v1
+kind: Config
+clusters:
+- name: test
+  cluster:
+    server: ` + srv.URL + `
+users:
+- name: test
+  user:
+    token: test-token
+contexts:
+- name: test
+  context:
+    cluster: test
+    user: test
+- name: other
+  context:
+    cluster: test
+    user: test
+    namespace: apps
+current-context: test
+`
+
+	f := filepath.Join(t.TempDir(), "kubeconfig")
+	require.NoError(t, os.WriteFile(f, []byte(kubeconfig), 0o600))
+	t.Setenv("KUBECONFIG", f)
+}
+
+func TestK8sFS(t *testing.T) {
+	setupK8s(t)
+
+	fsys, err := NewK8sFS(mustParseURL("k8s://apps/"))
+	require.NoError(t, err)
+
+	fsys = fsimpl.WithContextFS(context.Background(), fsys)
+
+	b, err := fs.ReadFile(fsys, "configmap/app")
+	require.NoError(t, err)
+	// binary data is kept base64-encoded
+	assert.JSONEq(t, `{"name": "app", "config.json": "{\"replicas\": 3}", "blob": "AAEC"}`, string(b))
+
+	fi, err := fs.Stat(fsys, "configmap/app")
+	require.NoError(t, err)
+	assert.Equal(t, iohelpers.JSONMimetype, fsimpl.ContentType(fi))
+
+	b, err = fs.ReadFile(fsys, "secret/creds")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"user": "admin", "pass": "hunter2"}`, string(b))
+
+	// single keys are read raw
+	b, err = fs.ReadFile(fsys, "secrets/creds/pass")
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", string(b))
+
+	b, err = fs.ReadFile(fsys, "cm/app/config.json")
+	require.NoError(t, err)
+	assert.Equal(t, `{"replicas": 3}`, string(b))
+
+	b, err = fs.ReadFile(fsys, "configmap/app/blob")
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0, 1, 2}, b)
+
+	_, err = fs.ReadFile(fsys, "configmap/app/missing")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+	require.ErrorContains(t, err, `key "missing" not found in configmap apps/app`)
+
+	_, err = fs.ReadFile(fsys, "configmap/missing")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+	require.ErrorContains(t, err, "configmap apps/missing not found")
+
+	// RBAC errors aren't reported as missing objects
+	_, err = fs.ReadFile(fsys, "secret/private")
+	require.ErrorIs(t, err, fs.ErrPermission)
+	require.NotErrorIs(t, err, fs.ErrNotExist)
+	require.ErrorContains(t, err, "access to secret apps/private denied")
+
+	_, err = fs.ReadFile(fsys, "pod/foo")
+	require.ErrorIs(t, err, fs.ErrInvalid)
+
+	_, err = fs.ReadFile(fsys, "configmap")
+	require.ErrorIs(t, err, fs.ErrInvalid)
+
+	t.Run("default namespace", func(t *testing.T) {
+		fsys, err := NewK8sFS(mustParseURL("k8s:///"))
+		require.NoError(t, err)
+
+		b, err := fs.ReadFile(fsys, "configmap/app/name")
+		require.NoError(t, err)
+		assert.Equal(t, "default app", string(b))
+
+		// the context's namespace is used
+		fsys, err = NewK8sFS(mustParseURL("k8s:///?context=other"))
+		require.NoError(t, err)
+
+		b, err = fs.ReadFile(fsys, "configmap/app/name")
+		require.NoError(t, err)
+		assert.Equal(t, "app", string(b))
+	})
+
+	t.Run("datasource", func(t *testing.T) {
+		fsp := fsimpl.NewMux()
+		fsp.Add(K8sFS)
+		ctx := ContextWithFSProvider(context.Background(), fsp)
+
+		reg := NewRegistry()
+		reg.Register("creds", config.DataSource{URL: mustParseURL("k8s://apps/secret/creds")})
+		reg.Register("config", config.DataSource{URL: mustParseURL("k8s://apps/configmap/app/config.json")})
+
+		sr := NewSourceReader(reg)
+
+		ct, b, err := sr.ReadSource(ctx, "creds")
+		require.NoError(t, err)
+		assert.Equal(t, iohelpers.JSONMimetype, ct)
+		assert.JSONEq(t, `{"user": "admin", "pass": "hunter2"}`, string(b))
+
+		ct, b, err = sr.ReadSource(ctx, "config")
+		require.NoError(t, err)
+		assert.Equal(t, iohelpers.JSONMimetype, ct)
+		assert.Equal(t, `{"replicas": 3}`, string(b))
+	})
+}
+
+func TestK8sFS_NoConfig(t *testing.T) {
+	t.Setenv("KUBECONFIG", filepath.Join(t.TempDir(), "missing"))
+	t.Setenv("KUBERNETES_SERVICE_HOST", "")
+
+	u, _ := url.Parse("k8s://apps/")
+	fsys, err := NewK8sFS(u)
+	require.NoError(t, err)
+
+	_, err = fs.ReadFile(fsys, "configmap/app")
+	require.ErrorContains(t, err, "k8s: failed to load client config")
+}
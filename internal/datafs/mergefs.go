@@ -145,9 +145,17 @@ func (f *mergeFS) Open(name string) (fs.File, error) {
 			}
 		}
 
+		hdr, err := ExpandHeader(subSource.Header)
+		if err != nil {
+			return nil, &fs.PathError{
+				Op: "open", Path: name,
+				Err: fmt.Errorf("merge part %q: %w", part, err),
+			}
+		}
+
 		// pass in the context and other bits
 		fsys = fsimpl.WithContextFS(f.ctx, fsys)
-		fsys = fsimpl.WithHeaderFS(subSource.Header, fsys)
+		fsys = fsimpl.WithHeaderFS(hdr, fsys)
 
 		fsys = fsimpl.WithHTTPClientFS(f.httpClient, fsys)
 
@@ -0,0 +1,304 @@
+package datafs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hairyhenderson/go-fsimpl"
+	"github.com/hairyhenderson/gomplate/v4/internal/iohelpers"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"go.mongodb.org/mongo-driver/v2/x/mongo/driver/auth"
+)
+
+// NewMongoFS returns a filesystem (an fs.FS) that can be used to read
+// documents from MongoDB collections.
+//
+// Files are named database/collection. Opening one runs a find with the
+// filter in the URL's query parameter (in MongoDB Extended JSON), and reads
+// the matching documents as a JSON array - or only the first one as a JSON
+// object, when the one parameter is true. Credentials and any other query
+// parameters are passed through in the connection string.
+func NewMongoFS(u *url.URL) (fs.FS, error) {
+	q := u.Query()
+
+	filter := bson.D{}
+	if s := q.Get("query"); s != "" {
+		if err := bson.UnmarshalExtJSON([]byte(s), false, &filter); err != nil {
+			return nil, fmt.Errorf("invalid MongoDB query %q: %w", s, err)
+		}
+	}
+
+	one := false
+	if s := q.Get("one"); s != "" {
+		var err error
+		one, err = strconv.ParseBool(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %q for the one parameter: %w", s, err)
+		}
+	}
+
+	q.Del("query")
+	q.Del("one")
+
+	host := u.Host
+	if host == "" {
+		host = "localhost"
+	}
+
+	connURL := url.URL{
+		Scheme:   u.Scheme,
+		User:     u.User,
+		Host:     host,
+		Path:     "/",
+		RawQuery: q.Encode(),
+	}
+
+	return &mongoFS{
+		ctx:    context.Background(),
+		uri:    connURL.String(),
+		filter: filter,
+		one:    one,
+	}, nil
+}
+
+type mongoFS struct {
+	ctx    context.Context
+	uri    string
+	filter bson.D
+	one    bool
+}
+
+//nolint:gochecknoglobals
+var MongoFS = fsimpl.FSProviderFunc(NewMongoFS, "mongodb", "mongodb+srv")
+
+var (
+	_ fs.FS         = (*mongoFS)(nil)
+	_ withContexter = (*mongoFS)(nil)
+)
+
+func (f mongoFS) WithContext(ctx context.Context) fs.FS {
+	fsys := f
+	fsys.ctx = ctx
+
+	return &fsys
+}
+
+func (f *mongoFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{
+			Op:   "open",
+			Path: name,
+			Err:  fs.ErrInvalid,
+		}
+	}
+
+	db, coll, ok := strings.Cut(name, "/")
+	if !ok || db == "" || coll == "" || strings.Contains(coll, "/") {
+		return nil, &fs.PathError{
+			Op:   "open",
+			Path: name,
+			Err:  fmt.Errorf("%w: must be database/collection", fs.ErrInvalid),
+		}
+	}
+
+	return &mongoFile{fsys: f, name: name, db: db, coll: coll}, nil
+}
+
+// mongoClients holds a client per connection string, so that reading several
+// collections (or reading again with --watch) doesn't reconnect every time.
+// Clients are disconnected by Cleanup.
+//
+//nolint:gochecknoglobals
+var mongoClients = newConnPool(func(c *mongo.Client) error {
+	return c.Disconnect(context.Background())
+})
+
+// mongoFind returns the documents in the collection that match the filter -
+// at most one when one is set. It can be overridden in tests.
+//
+//nolint:gochecknoglobals
+var mongoFind = func(ctx context.Context, uri, db, coll string, filter bson.D, one bool) (docs []bson.M, err error) {
+	client, err := mongoClients.get(uri, func() (*mongo.Client, error) {
+		return mongo.Connect(options.Client().ApplyURI(uri))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("connect: %w", err)
+	}
+
+	opts := options.Find()
+	if one {
+		opts.SetLimit(1)
+	}
+
+	cursor, err := client.Database(db).Collection(coll).Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	docs = []bson.M{}
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+
+	return docs, nil
+}
+
+type mongoFile struct {
+	fsys *mongoFS
+	body io.Reader
+	err  error
+	name string
+	db   string
+	coll string
+
+	contentType string
+	size        int64
+}
+
+var _ fs.File = (*mongoFile)(nil)
+
+func (f *mongoFile) Close() error {
+	f.body = nil
+	return nil
+}
+
+// fetch runs the find, once per file - failures are remembered too, so the
+// query is never retried
+func (f *mongoFile) fetch() error {
+	if f.body == nil && f.err == nil {
+		f.err = f.find()
+	}
+
+	return f.err
+}
+
+func (f *mongoFile) find() error {
+	docs, err := mongoFind(f.fsys.ctx, f.fsys.uri, f.db, f.coll, f.fsys.filter, f.fsys.one)
+	if err != nil {
+		return f.queryError(err)
+	}
+
+	var out interface{}
+	if f.fsys.one {
+		if len(docs) == 0 {
+			return &fs.PathError{
+				Op:   "read",
+				Path: f.name,
+				Err:  fmt.Errorf("no documents in %s.%s match the query: %w", f.db, f.coll, fs.ErrNotExist),
+			}
+		}
+
+		out = fromBSON(docs[0])
+		f.contentType = iohelpers.JSONMimetype
+	} else {
+		out = fromBSON(docs)
+		f.contentType = iohelpers.JSONArrayMimetype
+	}
+
+	b, err := json.Marshal(out)
+	if err != nil {
+		return fmt.Errorf("mongodb: failed to marshal documents from %s.%s: %w", f.db, f.coll, err)
+	}
+
+	f.size = int64(len(b))
+	f.body = bytes.NewReader(b)
+
+	return nil
+}
+
+// queryError distinguishes authentication and authorization failures from
+// other errors, so that they aren't mistaken for connection problems
+func (f *mongoFile) queryError(err error) error {
+	var authErr *auth.Error
+	var cmdErr mongo.CommandError
+
+	// 13 is Unauthorized, and 18 is AuthenticationFailed
+	if errors.As(err, &authErr) || (errors.As(err, &cmdErr) && (cmdErr.Code == 13 || cmdErr.Code == 18)) {
+		return &fs.PathError{
+			Op:   "read",
+			Path: f.name,
+			Err:  fmt.Errorf("access to %s.%s denied (check the credentials and roles): %w: %w", f.db, f.coll, fs.ErrPermission, err),
+		}
+	}
+
+	return fmt.Errorf("mongodb: failed to query %s.%s: %w", f.db, f.coll, err)
+}
+
+// fromBSON converts BSON values to plain values that marshal to readable JSON
+// - ObjectIDs become hex strings, dates become RFC 3339 timestamps, and
+// regular expressions are written as /pattern/options
+func fromBSON(in interface{}) interface{} {
+	switch v := in.(type) {
+	case []bson.M:
+		out := make([]interface{}, len(v))
+		for i, d := range v {
+			out[i] = fromBSON(d)
+		}
+
+		return out
+	case bson.M:
+		out := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			out[k] = fromBSON(e)
+		}
+
+		return out
+	case bson.D:
+		out := make(map[string]interface{}, len(v))
+		for _, e := range v {
+			out[e.Key] = fromBSON(e.Value)
+		}
+
+		return out
+	case bson.A:
+		out := make([]interface{}, len(v))
+		for i, e := range v {
+			out[i] = fromBSON(e)
+		}
+
+		return out
+	case bson.ObjectID:
+		return v.Hex()
+	case bson.DateTime:
+		return v.Time().UTC().Format(time.RFC3339Nano)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	case bson.Timestamp:
+		return time.Unix(int64(v.T), 0).UTC().Format(time.RFC3339)
+	case bson.Decimal128:
+		return v.String()
+	case bson.Binary:
+		return v.Data
+	case bson.Regex:
+		return "/" + v.Pattern + "/" + v.Options
+	default:
+		return v
+	}
+}
+
+func (f *mongoFile) Stat() (fs.FileInfo, error) {
+	if err := f.fetch(); err != nil {
+		return nil, err
+	}
+
+	return FileInfo(f.name, f.size, 0o444, time.Time{}, f.contentType), nil
+}
+
+func (f *mongoFile) Read(p []byte) (int, error) {
+	if err := f.fetch(); err != nil {
+		return 0, err
+	}
+
+	return f.body.Read(p)
+}
@@ -1,18 +1,25 @@
 package datafs
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"io/fs"
+	"mime"
 	"net/http"
 	"net/url"
 	"os"
+	"path"
 	"runtime"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/hairyhenderson/go-fsimpl"
+	"github.com/hairyhenderson/gomplate/v4/base64"
 	"github.com/hairyhenderson/gomplate/v4/internal/config"
 	"github.com/hairyhenderson/gomplate/v4/internal/iohelpers"
 )
@@ -34,8 +41,9 @@ type DataSourceReader interface {
 	// arguments. If the datasource is not found, the alias is interpreted as a
 	// URL. If the alias is not a valid URL, an error is returned.
 	//
-	// Returned content is cached, so subsequent calls with the same alias and
-	// arguments will return the same content.
+	// Returned content is cached by URL (unless the reader was created with
+	// NewUncachedSourceReader), so subsequent calls that resolve to the same
+	// URL, with the same headers, will return the same content.
 	ReadSource(ctx context.Context, alias string, args ...string) (string, []byte, error)
 
 	// contains registry
@@ -43,7 +51,9 @@ type DataSourceReader interface {
 }
 
 type dsReader struct {
-	cache map[string]*content
+	cache     *contentCache
+	cacheOnce sync.Once
+	noCache   bool
 
 	Registry
 }
@@ -54,10 +64,41 @@ type content struct {
 	b           []byte
 }
 
+// NewSourceReader returns a DataSourceReader for the datasources in reg. Each
+// unique URL is only read once - see ReadSource.
 func NewSourceReader(reg Registry) DataSourceReader {
 	return &dsReader{Registry: reg}
 }
 
+// NewUncachedSourceReader returns a DataSourceReader for the datasources in
+// reg, which reads content fresh on every call to ReadSource.
+func NewUncachedSourceReader(reg Registry) DataSourceReader {
+	return &dsReader{Registry: reg, noCache: true}
+}
+
+// SourceReaderWithRegistry returns a DataSourceReader for the datasources in
+// reg, which shares sr's cache (or lack of one). This is useful for reading
+// datasources from an overlay registry (see NewOverlayRegistry) without
+// reading the datasources they share again.
+func SourceReaderWithRegistry(sr DataSourceReader, reg Registry) DataSourceReader {
+	d, ok := sr.(*dsReader)
+	if !ok {
+		return NewSourceReader(reg)
+	}
+
+	return &dsReader{Registry: reg, cache: d.contentCache(), noCache: d.noCache}
+}
+
+func (d *dsReader) contentCache() *contentCache {
+	d.cacheOnce.Do(func() {
+		if d.cache == nil {
+			d.cache = &contentCache{}
+		}
+	})
+
+	return d.cache
+}
+
 func (d *dsReader) ReadSource(ctx context.Context, alias string, args ...string) (string, []byte, error) {
 	source, ok := d.Lookup(alias)
 	if !ok {
@@ -73,18 +114,6 @@ func (d *dsReader) ReadSource(ctx context.Context, alias string, args ...string)
 		source, _ = d.Lookup(alias)
 	}
 
-	if d.cache == nil {
-		d.cache = make(map[string]*content)
-	}
-	cacheKey := alias
-	for _, v := range args {
-		cacheKey += v
-	}
-	cached, ok := d.cache[cacheKey]
-	if ok {
-		return cached.contentType, cached.b, nil
-	}
-
 	arg := ""
 	if len(args) > 0 {
 		arg = args[0]
@@ -94,15 +123,94 @@ func (d *dsReader) ReadSource(ctx context.Context, alias string, args ...string)
 		return "", nil, err
 	}
 
-	fc, err := d.readFileContent(ctx, u, source.Header)
+	hdr, err := ExpandHeader(source.Header)
+	if err != nil {
+		return "", nil, fmt.Errorf("couldn't read datasource '%s': %w", alias, err)
+	}
+
+	read := func() (*content, error) {
+		return d.readFileContent(ctx, u, hdr)
+	}
+
+	var fc *content
+	if d.noCache {
+		fc, err = read()
+	} else {
+		fc, err = d.contentCache().get(cacheKey(u, hdr), read)
+	}
 	if err != nil {
 		return "", nil, fmt.Errorf("couldn't read datasource '%s' (%s): %w", alias, u, err)
 	}
-	d.cache[cacheKey] = fc
 
 	return fc.contentType, fc.b, nil
 }
 
+// cacheKey identifies content by its resolved URL (including the query), and
+// the headers it's read with, since they can change what's returned
+func cacheKey(u *url.URL, hdr http.Header) string {
+	key := &strings.Builder{}
+	key.WriteString(u.String())
+
+	names := make([]string, 0, len(hdr))
+	for k := range hdr {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	for _, k := range names {
+		fmt.Fprintf(key, "\n%s: %q", k, hdr[k])
+	}
+
+	return key.String()
+}
+
+// contentCache holds the content read by a DataSourceReader. It's safe for
+// concurrent use - when the same key is requested concurrently, the content is
+// only read once, and shared. Failed reads aren't cached, and so are retried
+// by later calls.
+type contentCache struct {
+	entries map[string]*cacheEntry
+	mu      sync.Mutex
+}
+
+type cacheEntry struct {
+	c    *content
+	err  error
+	done chan struct{}
+}
+
+// get returns the cached content for key, calling read to read it if it isn't
+// cached yet
+func (c *contentCache) get(key string, read func() (*content, error)) (*content, error) {
+	c.mu.Lock()
+	if e, ok := c.entries[key]; ok {
+		c.mu.Unlock()
+		<-e.done
+
+		return e.c, e.err
+	}
+
+	if c.entries == nil {
+		c.entries = map[string]*cacheEntry{}
+	}
+
+	e := &cacheEntry{done: make(chan struct{})}
+	c.entries[key] = e
+	c.mu.Unlock()
+
+	e.c, e.err = read()
+
+	if e.err != nil {
+		c.mu.Lock()
+		delete(c.entries, key)
+		c.mu.Unlock()
+	}
+
+	close(e.done)
+
+	return e.c, e.err
+}
+
 func removeQueryParam(u *url.URL, key string) *url.URL {
 	q := u.Query()
 	q.Del(key)
@@ -110,6 +218,97 @@ func removeQueryParam(u *url.URL, key string) *url.URL {
 	return u
 }
 
+// csvParams are the query parameters used to control how CSV datasources are
+// parsed. They're passed to the parser as MIME type parameters.
+var csvParams = []string{"delim", "header"}
+
+// extractCSVParams removes the CSV parsing parameters from the URL, if the URL
+// (or the given type hint) indicates it refers to CSV data. Other URLs are left
+// untouched, since the parameters may be meaningful to the datasource itself.
+func extractCSVParams(u *url.URL, mimeType string) (*url.URL, map[string]string) {
+	isCSV := iohelpers.MimeAlias(mimeType) == iohelpers.CSVMimetype
+	if mimeType == "" {
+		isCSV = strings.EqualFold(path.Ext(u.Path), ".csv")
+	}
+
+	if !isCSV {
+		return u, nil
+	}
+
+	q := u.Query()
+	params := map[string]string{}
+	for _, k := range csvParams {
+		if q.Has(k) {
+			params[k] = q.Get(k)
+			u = removeQueryParam(u, k)
+		}
+	}
+
+	return u, params
+}
+
+// withMimeParams adds the given parameters to the MIME type, overriding any
+// existing parameters with the same name
+func withMimeParams(mimeType string, params map[string]string) string {
+	mt, p, err := mime.ParseMediaType(mimeType)
+	if err != nil {
+		return mimeType
+	}
+
+	for k, v := range params {
+		p[k] = v
+	}
+
+	if f := mime.FormatMediaType(mt, p); f != "" {
+		return f
+	}
+
+	return mimeType
+}
+
+// decodeParam is the query parameter used to list the decoders to apply to a
+// datasource's raw content before it's parsed, such as "base64,gzip"
+const decodeParam = "decode"
+
+// decode applies the list of decoders to b, in order. Decoders are separated
+// by commas or spaces - an unescaped '+' in a query parameter is decoded as a
+// space, so "base64+gzip" is also supported, which is convenient since commas
+// separate values in the --datasource flag.
+func decode(decoders string, b []byte) ([]byte, error) {
+	for _, dec := range strings.FieldsFunc(decoders, func(r rune) bool {
+		return r == ',' || r == ' '
+	}) {
+		var err error
+
+		switch dec {
+		case "base64":
+			b, err = base64.Decode(string(bytes.TrimSpace(b)))
+			if err != nil {
+				return nil, fmt.Errorf("base64 decode: %w", err)
+			}
+		case "gzip":
+			b, err = gunzip(b)
+			if err != nil {
+				return nil, fmt.Errorf("gzip decode: %w", err)
+			}
+		default:
+			return nil, fmt.Errorf("unsupported decoder %q, must be one of base64 or gzip", dec)
+		}
+	}
+
+	return b, nil
+}
+
+func gunzip(b []byte) ([]byte, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	return io.ReadAll(zr)
+}
+
 func (d *dsReader) readFileContent(ctx context.Context, u *url.URL, hdr http.Header) (*content, error) {
 	// possible type hint in the type query param. Contrary to spec, we allow
 	// unescaped '+' characters to make it simpler to provide types like
@@ -122,9 +321,41 @@ func (d *dsReader) readFileContent(ctx context.Context, u *url.URL, hdr http.Hea
 	// leaking into the filesystem layer
 	u = removeQueryParam(u, overrideType)
 
-	fsys, err := FSysForPath(ctx, u.String())
+	// same for CSV parsing parameters and decoders
+	u, csvOpts := extractCSVParams(u, mimeType)
+
+	decoders := u.Query().Get(decodeParam)
+	u = removeQueryParam(u, decodeParam)
+
+	// and the HTTP timeout, retry, and request parameters
+	u, timeout, retries, err := extractHTTPParams(u)
+	if err != nil {
+		return nil, err
+	}
+
+	u, method, body, err := extractHTTPRequestParams(ctx, u)
 	if err != nil {
-		return nil, fmt.Errorf("fsys for path %v: %w", u, err)
+		return nil, err
+	}
+
+	// and whether to read a whole Consul subtree
+	u, recurse, err := extractConsulParams(u)
+	if err != nil {
+		return nil, err
+	}
+
+	var fsys fs.FS
+	if method != "" {
+		fsys = newHTTPRequestFS(u, method, body)
+	} else {
+		fsys, err = FSysForPath(ctx, u.String())
+		if err != nil {
+			return nil, fmt.Errorf("fsys for path %v: %w", u, err)
+		}
+	}
+
+	if timeout > 0 || retries > 0 {
+		fsys = fsimpl.WithHTTPClientFS(newRetryingHTTPClient(timeout, retries), fsys)
 	}
 
 	u, fname := SplitFSMuxURL(u)
@@ -141,13 +372,13 @@ func (d *dsReader) readFileContent(ctx context.Context, u *url.URL, hdr http.Hea
 
 	f, err := fsys.Open(fname)
 	if err != nil {
-		return nil, fmt.Errorf("open (url: %q, name: %q): %w", u, fname, err)
+		return nil, sourceError("open", u, fname, err)
 	}
 	defer f.Close()
 
 	fi, err := f.Stat()
 	if err != nil {
-		return nil, fmt.Errorf("stat (url: %q, name: %q): %w", u, fname, err)
+		return nil, sourceError("stat", u, fname, err)
 	}
 
 	if mimeType == "" {
@@ -156,7 +387,14 @@ func (d *dsReader) readFileContent(ctx context.Context, u *url.URL, hdr http.Hea
 
 	var data []byte
 
-	if fi.IsDir() {
+	if fi.IsDir() && recurse {
+		data, err = readConsulTree(fsys, fname)
+		if err != nil {
+			return nil, sourceError("readTree", u, fname, err)
+		}
+
+		mimeType = iohelpers.JSONMimetype
+	} else if fi.IsDir() {
 		var dirents []fs.DirEntry
 		dirents, err = fs.ReadDir(fsys, fname)
 		if err != nil {
@@ -176,7 +414,14 @@ func (d *dsReader) readFileContent(ctx context.Context, u *url.URL, hdr http.Hea
 	} else {
 		data, err = io.ReadAll(f)
 		if err != nil {
-			return nil, fmt.Errorf("read (url: %q, name: %s): %w", u, fname, err)
+			return nil, sourceError("read", u, fname, err)
+		}
+
+		if decoders != "" {
+			data, err = decode(decoders, data)
+			if err != nil {
+				return nil, fmt.Errorf("decode (url: %q, name: %s): %w", u, fname, err)
+			}
 		}
 	}
 
@@ -185,9 +430,28 @@ func (d *dsReader) readFileContent(ctx context.Context, u *url.URL, hdr http.Hea
 		mimeType = iohelpers.TextMimetype
 	}
 
+	if len(csvOpts) > 0 && iohelpers.MimeAlias(mimeType) == iohelpers.CSVMimetype {
+		mimeType = withMimeParams(mimeType, csvOpts)
+	}
+
 	return &content{contentType: mimeType, b: data}, nil
 }
 
+// sourceError wraps an error reading the named file from the datasource at u.
+// Some datasources have their own errors, to make it clearer what went wrong.
+func sourceError(op string, u *url.URL, name string, err error) error {
+	switch {
+	case isConsulURL(u):
+		return consulError(op, u, name, err)
+	case u.Scheme == "s3":
+		return s3Error(op, u, name, err)
+	case u.Scheme == "gs":
+		return gsError(op, u, name, err)
+	}
+
+	return fmt.Errorf("%s (url: %q, name: %q): %w", op, u, name, err)
+}
+
 // COPIED FROM /data/datasource.go
 //
 // resolveURL parses the relative URL rel against base, and returns the
@@ -229,12 +493,24 @@ func resolveURL(base *url.URL, rel string) (*url.URL, error) {
 		return nil, err
 	}
 
-	// URL.ResolveReference requires (or assumes, at least) that the base is
-	// absolute. We want to support relative URLs too though, so we need to
-	// correct for that.
-	out := base.ResolveReference(relURL)
-	if out.Scheme == "" && out.Path[0] == '/' {
-		out.Path = out.Path[1:]
+	var out *url.URL
+
+	if base.Scheme == "boltdb" && relURL.Path != "" {
+		// BoltDB keys are separated from the database file by a double-slash,
+		// and the fragment names the bucket, so it must be kept
+		u := *base
+		u.Path, _, _ = strings.Cut(u.Path, "//")
+		u.Path += "//" + strings.TrimPrefix(relURL.Path, "/")
+		u.RawQuery = relURL.RawQuery
+		out = &u
+	} else {
+		// URL.ResolveReference requires (or assumes, at least) that the base
+		// is absolute. We want to support relative URLs too though, so we need
+		// to correct for that.
+		out = base.ResolveReference(relURL)
+		if out.Scheme == "" && out.Path[0] == '/' {
+			out.Path = out.Path[1:]
+		}
 	}
 
 	if base.RawQuery != "" {
@@ -1,12 +1,17 @@
 package datafs
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"encoding/base64"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"os"
 	"runtime"
+	"sync"
 	"testing"
 	"testing/fstest"
 
@@ -61,6 +66,15 @@ func TestResolveURL(t *testing.T) {
 	_, err = resolveURL(mustParseURL("git+ssh://git@example.com/foo//bar"), "baz//myfile")
 	require.Error(t, err)
 
+	// boltdb keys follow a double-slash, and the bucket is kept
+	out, err = resolveURL(mustParseURL("boltdb:///tmp/lookup.db#bucket"), "mykey")
+	require.NoError(t, err)
+	assert.Equal(t, "boltdb:///tmp/lookup.db//mykey#bucket", out.String())
+
+	out, err = resolveURL(mustParseURL("boltdb:///tmp/lookup.db//a?type=application/json#bucket"), "b")
+	require.NoError(t, err)
+	assert.Equal(t, "boltdb:///tmp/lookup.db//b?type=application%2Fjson#bucket", out.String())
+
 	// relative urls must remain relative
 	out, err = resolveURL(mustParseURL("tmp/foo.json"), "")
 	require.NoError(t, err)
@@ -85,6 +99,8 @@ func TestReadFileContent(t *testing.T) {
 
 	fsys := WrapWdFS(fstest.MapFS{
 		"foo.json":          &fstest.MapFile{Data: []byte(`{"foo": "bar"}`)},
+		"foo.json.gz":       &fstest.MapFile{Data: gzipped(t, `{"foo": "bar"}`)},
+		"foo.json.gz.b64":   &fstest.MapFile{Data: []byte(base64.StdEncoding.EncodeToString(gzipped(t, `{"foo": "bar"}`)) + "\n")},
 		"dir/1.yaml":        &fstest.MapFile{Data: []byte(`foo: bar`)},
 		"dir/2.yaml":        &fstest.MapFile{Data: []byte(`baz: qux`)},
 		"dir/sub/sub1.yaml": &fstest.MapFile{Data: []byte(`quux: corge`)},
@@ -110,6 +126,75 @@ func TestReadFileContent(t *testing.T) {
 	fc, err = sr.readFileContent(ctx, mustParseURL(srv.URL+"/foo.json"), nil)
 	require.NoError(t, err)
 	assert.Equal(t, []byte(`{"foo": "bar"}`), fc.b)
+
+	fc, err = sr.readFileContent(ctx, mustParseURL("file:///foo.json.gz?type=application/json&decode=gzip"), nil)
+	require.NoError(t, err)
+	assert.Equal(t, []byte(`{"foo": "bar"}`), fc.b)
+	assert.Equal(t, iohelpers.JSONMimetype, fc.contentType)
+
+	fc, err = sr.readFileContent(ctx, mustParseURL("file:///foo.json.gz.b64?type=application/json&decode=base64,gzip"), nil)
+	require.NoError(t, err)
+	assert.Equal(t, []byte(`{"foo": "bar"}`), fc.b)
+
+	_, err = sr.readFileContent(ctx, mustParseURL("file:///foo.json?decode=gzip"), nil)
+	require.ErrorContains(t, err, "gzip decode")
+}
+
+func TestDecode(t *testing.T) {
+	b, err := decode("gzip", gzipped(t, "hello"))
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(b))
+
+	b, err = decode("base64", []byte("aGVsbG8=\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(b))
+
+	b, err = decode("base64 gzip", []byte(base64.StdEncoding.EncodeToString(gzipped(t, "hello"))))
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(b))
+
+	_, err = decode("base64", []byte("not base64!"))
+	require.ErrorContains(t, err, "base64 decode")
+
+	_, err = decode("gzip", []byte("not gzip"))
+	require.ErrorContains(t, err, "gzip decode")
+
+	_, err = decode("bzip2", []byte("hello"))
+	require.ErrorContains(t, err, `unsupported decoder "bzip2"`)
+}
+
+func gzipped(t *testing.T, s string) []byte {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	zw := gzip.NewWriter(buf)
+	_, err := zw.Write([]byte(s))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	return buf.Bytes()
+}
+
+func TestExtractCSVParams(t *testing.T) {
+	u, params := extractCSVParams(mustParseURL("file:///foo.csv?delim=%3B&header=true"), "")
+	assert.Equal(t, "file:///foo.csv", u.String())
+	assert.Equal(t, map[string]string{"delim": ";", "header": "true"}, params)
+
+	u, params = extractCSVParams(mustParseURL("https://example.com/data?delim=x"), iohelpers.CSVMimetype)
+	assert.Equal(t, "https://example.com/data", u.String())
+	assert.Equal(t, map[string]string{"delim": "x"}, params)
+
+	// non-CSV URLs are left alone
+	u, params = extractCSVParams(mustParseURL("https://example.com/data.json?delim=x"), "")
+	assert.Equal(t, "https://example.com/data.json?delim=x", u.String())
+	assert.Nil(t, params)
+}
+
+func TestWithMimeParams(t *testing.T) {
+	assert.Equal(t, `text/csv; delim=";"; header=true`,
+		withMimeParams("text/csv", map[string]string{"delim": ";", "header": "true"}))
+	assert.Equal(t, "text/csv; charset=utf-8; header=false",
+		withMimeParams("text/csv; charset=utf-8", map[string]string{"header": "false"}))
 }
 
 func TestDatasource(t *testing.T) {
@@ -160,3 +245,133 @@ func TestDatasource(t *testing.T) {
 	_, _, err = d.ReadSource(ctx, "bar")
 	require.Error(t, err)
 }
+
+func TestReadSource_Cache(t *testing.T) {
+	hits := map[string]int{}
+	mu := sync.Mutex{}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		// each read starts with a HEAD request to stat the file, so only
+		// those are counted
+		if r.Method == http.MethodHead {
+			mu.Lock()
+			hits[r.URL.RequestURI()]++
+			mu.Unlock()
+		}
+
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", iohelpers.JSONMimetype)
+		fmt.Fprintf(w, `{"path": %q, "auth": %q}`, r.URL.RequestURI(), r.Header.Get("Authorization"))
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	fsp := fsimpl.NewMux()
+	fsp.Add(httpfs.FS)
+	ctx := ContextWithFSProvider(context.Background(), fsp)
+
+	setup := func(sr DataSourceReader) {
+		sr.Register("a", config.DataSource{URL: mustParseURL(srv.URL + "/data?x=1")})
+		sr.Register("b", config.DataSource{URL: mustParseURL(srv.URL + "/data?x=1")})
+		sr.Register("dir", config.DataSource{URL: mustParseURL(srv.URL + "/")})
+		sr.Register("auth", config.DataSource{
+			URL:    mustParseURL(srv.URL + "/data?x=1"),
+			Header: http.Header{"Authorization": {"Bearer foo"}},
+		})
+		sr.Register("fail", config.DataSource{URL: mustParseURL(srv.URL + "/fail")})
+	}
+
+	reset := func() {
+		mu.Lock()
+		defer mu.Unlock()
+
+		clear(hits)
+	}
+
+	t.Run("cached", func(t *testing.T) {
+		reset()
+
+		sr := NewSourceReader(NewRegistry())
+		setup(sr)
+
+		// different aliases with the same URL and headers share content, and
+		// subpaths are keyed by their resolved URL
+		for _, alias := range []string{"a", "b", "a"} {
+			_, b, err := sr.ReadSource(ctx, alias)
+			require.NoError(t, err)
+			assert.JSONEq(t, `{"path": "/data?x=1", "auth": ""}`, string(b))
+		}
+
+		_, b, err := sr.ReadSource(ctx, "dir", "data?x=1")
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"path": "/data?x=1", "auth": ""}`, string(b))
+
+		_, b, err = sr.ReadSource(ctx, "dir", "data?x=2")
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"path": "/data?x=2", "auth": ""}`, string(b))
+
+		// different headers mean different content
+		_, b, err = sr.ReadSource(ctx, "auth")
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"path": "/data?x=1", "auth": "Bearer foo"}`, string(b))
+
+		// a reader for an overlay registry shares the cache
+		reg := NewOverlayRegistry(sr)
+		reg.Register("c", config.DataSource{URL: mustParseURL(srv.URL + "/data?x=1")})
+		_, _, err = SourceReaderWithRegistry(sr, reg).ReadSource(ctx, "c")
+		require.NoError(t, err)
+
+		// failures aren't cached
+		_, _, err = sr.ReadSource(ctx, "fail")
+		require.Error(t, err)
+		_, _, err = sr.ReadSource(ctx, "fail")
+		require.Error(t, err)
+
+		assert.Equal(t, map[string]int{"/data?x=1": 2, "/data?x=2": 1, "/fail": 2}, hits)
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		reset()
+
+		sr := NewSourceReader(NewRegistry())
+		setup(sr)
+
+		wg := sync.WaitGroup{}
+		for range 20 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				_, _, err := sr.ReadSource(ctx, "a")
+				assert.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, map[string]int{"/data?x=1": 1}, hits)
+	})
+
+	t.Run("uncached", func(t *testing.T) {
+		reset()
+
+		sr := NewUncachedSourceReader(NewRegistry())
+		setup(sr)
+
+		for _, alias := range []string{"a", "b", "a"} {
+			_, _, err := sr.ReadSource(ctx, alias)
+			require.NoError(t, err)
+		}
+
+		reg := NewOverlayRegistry(sr)
+		_, _, err := SourceReaderWithRegistry(sr, reg).ReadSource(ctx, "a")
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string]int{"/data?x=1": 4}, hits)
+	})
+}
@@ -0,0 +1,242 @@
+package datafs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hairyhenderson/go-fsimpl"
+	"github.com/hairyhenderson/gomplate/v4/internal/iohelpers"
+	"github.com/redis/go-redis/v9"
+)
+
+// defaultRedisTimeout is used for connecting to, reading from, and writing to
+// Redis when REDIS_TIMEOUT isn't set
+const defaultRedisTimeout = 5 * time.Second
+
+// NewRedisFS returns a filesystem (an fs.FS) that can be used to read keys
+// from a Redis server.
+//
+// The server address and credentials are taken from the URL's authority, or
+// from the REDIS_URL environment variable when the URL has no host. Files are
+// named db/key, where the database number is optional.
+func NewRedisFS(u *url.URL) (fs.FS, error) {
+	addr := &url.URL{Scheme: u.Scheme, User: u.User, Host: u.Host}
+	if u.Host == "" {
+		env := getenv("REDIS_URL", "redis://localhost:6379")
+
+		var err error
+		addr, err = url.Parse(env)
+		if err != nil {
+			return nil, fmt.Errorf("invalid REDIS_URL %q: %w", env, err)
+		}
+	}
+
+	// only the address and credentials are passed through, since go-redis
+	// rejects query parameters it doesn't know about
+	connURL := (&url.URL{
+		Scheme: addr.Scheme,
+		User:   addr.User,
+		Host:   addr.Host,
+		Path:   addr.Path,
+	}).String()
+
+	opts, err := redis.ParseURL(connURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis URL: %w", err)
+	}
+
+	timeout, err := redisTimeout()
+	if err != nil {
+		return nil, err
+	}
+
+	opts.DialTimeout = timeout
+	opts.ReadTimeout = timeout
+	opts.WriteTimeout = timeout
+
+	// fail fast rather than retrying when the server is unreachable
+	opts.MaxRetries = -1
+
+	return &redisFS{
+		ctx:     context.Background(),
+		opts:    opts,
+		connKey: fmt.Sprintf("%s %s", connURL, timeout),
+	}, nil
+}
+
+// redisTimeout returns the timeout set in REDIS_TIMEOUT (in seconds), or the
+// default
+func redisTimeout() (time.Duration, error) {
+	v := getenv("REDIS_TIMEOUT")
+	if v == "" {
+		return defaultRedisTimeout, nil
+	}
+
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid REDIS_TIMEOUT %q: must be a positive number of seconds", v)
+	}
+
+	return time.Duration(n) * time.Second, nil
+}
+
+type redisFS struct {
+	ctx  context.Context
+	opts *redis.Options
+	// connKey identifies the server, credentials, and timeout, to share
+	// clients between reads
+	connKey string
+}
+
+//nolint:gochecknoglobals
+var RedisFS = fsimpl.FSProviderFunc(NewRedisFS, "redis", "rediss")
+
+var (
+	_ fs.FS         = (*redisFS)(nil)
+	_ withContexter = (*redisFS)(nil)
+)
+
+func (f redisFS) WithContext(ctx context.Context) fs.FS {
+	fsys := f
+	fsys.ctx = ctx
+
+	return &fsys
+}
+
+func (f *redisFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) || name == "." {
+		return nil, &fs.PathError{
+			Op:   "open",
+			Path: name,
+			Err:  fs.ErrInvalid,
+		}
+	}
+
+	opts := *f.opts
+	key := name
+
+	// a leading number selects the database
+	if db, rest, ok := strings.Cut(name, "/"); ok {
+		if n, err := strconv.Atoi(db); err == nil {
+			opts.DB = n
+			key = rest
+		}
+	}
+
+	return &redisFile{
+		ctx:     f.ctx,
+		opts:    &opts,
+		connKey: fmt.Sprintf("%s db=%d", f.connKey, opts.DB),
+		name:    name,
+		key:     key,
+	}, nil
+}
+
+// redisClients holds a client per server, database, and set of credentials,
+// so that reading several keys (or reading again with --watch) shares their
+// connection pools. Clients are closed by Cleanup.
+//
+//nolint:gochecknoglobals
+var redisClients = newConnPool((*redis.Client).Close)
+
+type redisFile struct {
+	ctx     context.Context
+	opts    *redis.Options
+	body    io.Reader
+	connKey string
+	name    string
+	key     string
+
+	contentType string
+	size        int64
+}
+
+var _ fs.File = (*redisFile)(nil)
+
+func (f *redisFile) Close() error {
+	f.body = nil
+	return nil
+}
+
+// fetch reads the key's value. The client is shared, so nothing needs to be
+// torn down when the file is closed. Missing keys are read as empty, while any
+// other failure is an error.
+func (f *redisFile) fetch() error {
+	if f.body != nil {
+		return nil
+	}
+
+	client, err := redisClients.get(f.connKey, func() (*redis.Client, error) {
+		return redis.NewClient(f.opts), nil
+	})
+	if err != nil {
+		return err
+	}
+
+	typ, err := client.Type(f.ctx, f.key).Result()
+	if err != nil {
+		return fmt.Errorf("redis: failed to read %q: %w", f.key, err)
+	}
+
+	var b []byte
+
+	switch typ {
+	case "none":
+		b = []byte{}
+	case "string":
+		s, err := client.Get(f.ctx, f.key).Result()
+		if errors.Is(err, redis.Nil) {
+			// the key expired between the two commands
+			s, err = "", nil
+		}
+		if err != nil {
+			return fmt.Errorf("redis: failed to get %q: %w", f.key, err)
+		}
+
+		b = []byte(s)
+	case "hash":
+		m, err := client.HGetAll(f.ctx, f.key).Result()
+		if err != nil {
+			return fmt.Errorf("redis: failed to get hash %q: %w", f.key, err)
+		}
+
+		b, err = json.Marshal(m)
+		if err != nil {
+			return fmt.Errorf("redis: failed to marshal hash %q: %w", f.key, err)
+		}
+
+		f.contentType = iohelpers.JSONMimetype
+	default:
+		return fmt.Errorf("redis: unsupported type %q for key %q, must be a string or hash", typ, f.key)
+	}
+
+	f.size = int64(len(b))
+	f.body = bytes.NewReader(b)
+
+	return nil
+}
+
+func (f *redisFile) Stat() (fs.FileInfo, error) {
+	if err := f.fetch(); err != nil {
+		return nil, err
+	}
+
+	return FileInfo(f.name, f.size, 0o444, time.Time{}, f.contentType), nil
+}
+
+func (f *redisFile) Read(p []byte) (int, error) {
+	if err := f.fetch(); err != nil {
+		return 0, err
+	}
+
+	return f.body.Read(p)
+}
@@ -0,0 +1,170 @@
+package datafs
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/hairyhenderson/go-fsimpl"
+	"github.com/hairyhenderson/gomplate/v4/internal/iohelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupRedis(t *testing.T) *miniredis.Miniredis {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	t.Cleanup(func() { _ = Cleanup() })
+
+	require.NoError(t, mr.Set("flag", "on"))
+	mr.HSet("config", "name", "foo", "size", "3")
+
+	mr.Select(2)
+	require.NoError(t, mr.Set("a/b", "in db 2"))
+	mr.Select(0)
+
+	return mr
+}
+
+func TestRedisFS(t *testing.T) {
+	mr := setupRedis(t)
+
+	u, _ := url.Parse("redis://" + mr.Addr() + "/")
+	fsys, err := NewRedisFS(u)
+	require.NoError(t, err)
+
+	fsys = fsimpl.WithContextFS(context.Background(), fsys)
+
+	b, err := fs.ReadFile(fsys, "flag")
+	require.NoError(t, err)
+	assert.Equal(t, "on", string(b))
+
+	b, err = fs.ReadFile(fsys, "0/flag")
+	require.NoError(t, err)
+	assert.Equal(t, "on", string(b))
+
+	b, err = fs.ReadFile(fsys, "2/a/b")
+	require.NoError(t, err)
+	assert.Equal(t, "in db 2", string(b))
+
+	f, err := fsys.Open("config")
+	require.NoError(t, err)
+	defer f.Close()
+
+	fi, err := f.Stat()
+	require.NoError(t, err)
+	assert.Equal(t, iohelpers.JSONMimetype, fsimpl.ContentType(fi))
+
+	b, err = io.ReadAll(f)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name":"foo","size":"3"}`, string(b))
+
+	// missing keys are empty, not errors
+	b, err = fs.ReadFile(fsys, "missing")
+	require.NoError(t, err)
+	assert.Empty(t, b)
+
+	mr.Lpush("list", "x")
+	_, err = fs.ReadFile(fsys, "list")
+	require.ErrorContains(t, err, `unsupported type "list"`)
+
+	_, err = fsys.Open(".")
+	require.ErrorIs(t, err, fs.ErrInvalid)
+}
+
+func TestRedisFS_Auth(t *testing.T) {
+	mr := setupRedis(t)
+	mr.RequireUserAuth("user", "secret")
+
+	u, _ := url.Parse("redis://user:secret@" + mr.Addr() + "/")
+	fsys, err := NewRedisFS(u)
+	require.NoError(t, err)
+
+	b, err := fs.ReadFile(fsys, "flag")
+	require.NoError(t, err)
+	assert.Equal(t, "on", string(b))
+
+	u, _ = url.Parse("redis://user:wrong@" + mr.Addr() + "/")
+	fsys, err = NewRedisFS(u)
+	require.NoError(t, err)
+
+	_, err = fs.ReadFile(fsys, "flag")
+	require.Error(t, err)
+}
+
+func TestRedisFS_RedisURL(t *testing.T) {
+	mr := setupRedis(t)
+
+	t.Setenv("REDIS_URL", "redis://"+mr.Addr()+"/2")
+
+	u, _ := url.Parse("redis:///")
+	fsys, err := NewRedisFS(u)
+	require.NoError(t, err)
+
+	b, err := fs.ReadFile(fsys, "a/b")
+	require.NoError(t, err)
+	assert.Equal(t, "in db 2", string(b))
+}
+
+func TestRedisFS_RedisURLFile(t *testing.T) {
+	mr := setupRedis(t)
+
+	urlFile := filepath.Join(t.TempDir(), "redis_url")
+	require.NoError(t, os.WriteFile(urlFile, []byte("redis://"+mr.Addr()+"/2\n"), 0o600))
+	t.Setenv("REDIS_URL_FILE", urlFile)
+
+	u, _ := url.Parse("redis:///")
+	fsys, err := NewRedisFS(u)
+	require.NoError(t, err)
+
+	b, err := fs.ReadFile(fsys, "a/b")
+	require.NoError(t, err)
+	assert.Equal(t, "in db 2", string(b))
+}
+
+func TestRedisFS_SharesClients(t *testing.T) {
+	mr := setupRedis(t)
+
+	u, _ := url.Parse("redis://" + mr.Addr() + "/")
+
+	for range 3 {
+		fsys, err := NewRedisFS(u)
+		require.NoError(t, err)
+
+		b, err := fs.ReadFile(fsys, "flag")
+		require.NoError(t, err)
+		assert.Equal(t, "on", string(b))
+	}
+
+	assert.Equal(t, 1, mr.TotalConnectionCount())
+
+	require.NoError(t, Cleanup())
+	assert.Eventually(t, func() bool { return mr.CurrentConnectionCount() == 0 },
+		time.Second, 10*time.Millisecond)
+}
+
+func TestRedisFS_ConnectionError(t *testing.T) {
+	mr := setupRedis(t)
+	addr := mr.Addr()
+	mr.Close()
+
+	t.Setenv("REDIS_TIMEOUT", "1")
+
+	u, _ := url.Parse("redis://" + addr + "/")
+	fsys, err := NewRedisFS(u)
+	require.NoError(t, err)
+
+	_, err = fs.ReadFile(fsys, "flag")
+	require.ErrorContains(t, err, `redis: failed to read "flag"`)
+
+	t.Setenv("REDIS_TIMEOUT", "soon")
+	_, err = NewRedisFS(u)
+	require.ErrorContains(t, err, "invalid REDIS_TIMEOUT")
+}
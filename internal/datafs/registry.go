@@ -86,3 +86,77 @@ func (r *dsRegistry) AddExtraHeader(alias string, hdr http.Header) {
 
 	r.extraHeaders[alias] = hdr
 }
+
+// extraHeaderer is implemented by registries that can look up the extra headers
+// added with AddExtraHeader
+type extraHeaderer interface {
+	extraHeader(alias string) (http.Header, bool)
+}
+
+func (r *dsRegistry) extraHeader(alias string) (http.Header, bool) {
+	r.RLock()
+	defer r.RUnlock()
+
+	hdr, ok := r.extraHeaders[alias]
+	return hdr, ok
+}
+
+// NewOverlayRegistry returns a registry that looks up datasources in its own
+// datasources first, and then in parent. Datasources (and extra headers) added
+// to it aren't added to parent, but extra headers added to parent are applied
+// to datasources registered in the overlay.
+func NewOverlayRegistry(parent Registry) Registry {
+	return &overlayRegistry{Registry: NewRegistry(), parent: parent}
+}
+
+type overlayRegistry struct {
+	Registry
+	parent Registry
+}
+
+// Register a datasource in the overlay
+func (r *overlayRegistry) Register(alias string, ds config.DataSource) {
+	// the overlay's own extra headers take precedence over the parent's
+	if hdr, ok := r.extraHeader(alias); ok && ds.Header == nil {
+		ds.Header = hdr
+	}
+
+	r.Registry.Register(alias, ds)
+}
+
+func (r *overlayRegistry) extraHeader(alias string) (http.Header, bool) {
+	if own, ok := r.Registry.(extraHeaderer); ok {
+		if hdr, ok := own.extraHeader(alias); ok {
+			return hdr, ok
+		}
+	}
+
+	if p, ok := r.parent.(extraHeaderer); ok {
+		return p.extraHeader(alias)
+	}
+
+	return nil, false
+}
+
+// Lookup a registered datasource
+func (r *overlayRegistry) Lookup(alias string) (config.DataSource, bool) {
+	if ds, ok := r.Registry.Lookup(alias); ok {
+		return ds, ok
+	}
+
+	return r.parent.Lookup(alias)
+}
+
+// List registered datasource aliases, from both registries
+func (r *overlayRegistry) List() []string {
+	keys := r.parent.List()
+	for _, k := range r.Registry.List() {
+		if _, ok := r.parent.Lookup(k); !ok {
+			keys = append(keys, k)
+		}
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
@@ -46,3 +46,66 @@ func TestDefaultRegistry_AddExtraHeader(t *testing.T) {
 	require.True(t, ok)
 	require.Equal(t, hdr, ds.Header)
 }
+
+func TestOverlayRegistry(t *testing.T) {
+	parent := NewRegistry()
+	parent.Register("a", config.DataSource{Header: http.Header{"X": {"parent"}}})
+	parent.Register("b", config.DataSource{})
+
+	reg := NewOverlayRegistry(parent)
+	reg.Register("b", config.DataSource{Header: http.Header{"X": {"overlay"}}})
+	reg.Register("c", config.DataSource{})
+
+	ds, ok := reg.Lookup("a")
+	require.True(t, ok)
+	require.Equal(t, http.Header{"X": {"parent"}}, ds.Header)
+
+	// the overlay's datasources take precedence
+	ds, ok = reg.Lookup("b")
+	require.True(t, ok)
+	require.Equal(t, http.Header{"X": {"overlay"}}, ds.Header)
+
+	_, ok = reg.Lookup("d")
+	require.False(t, ok)
+
+	require.Equal(t, []string{"a", "b", "c"}, reg.List())
+
+	// the parent is unaffected
+	_, ok = parent.Lookup("c")
+	require.False(t, ok)
+
+	ds, _ = parent.Lookup("b")
+	require.Nil(t, ds.Header)
+}
+
+func TestOverlayRegistry_ExtraHeaders(t *testing.T) {
+	parent := NewRegistry()
+	parent.AddExtraHeader("a", http.Header{"X": {"parent"}})
+	parent.AddExtraHeader("b", http.Header{"X": {"parent"}})
+
+	reg := NewOverlayRegistry(parent)
+	reg.AddExtraHeader("b", http.Header{"X": {"overlay"}})
+
+	reg.Register("a", config.DataSource{})
+	reg.Register("b", config.DataSource{})
+	reg.Register("c", config.DataSource{Header: http.Header{"X": {"own"}}})
+
+	// the parent's extra headers apply to the overlay's datasources
+	ds, ok := reg.Lookup("a")
+	require.True(t, ok)
+	require.Equal(t, http.Header{"X": {"parent"}}, ds.Header)
+
+	// but the overlay's own extra headers take precedence
+	ds, _ = reg.Lookup("b")
+	require.Equal(t, http.Header{"X": {"overlay"}}, ds.Header)
+
+	// and so do the datasource's own headers
+	ds, _ = reg.Lookup("c")
+	require.Equal(t, http.Header{"X": {"own"}}, ds.Header)
+
+	// overlays of overlays still see the parent's extra headers
+	reg = NewOverlayRegistry(reg)
+	reg.Register("a", config.DataSource{})
+	ds, _ = reg.Lookup("a")
+	require.Equal(t, http.Header{"X": {"parent"}}, ds.Header)
+}
@@ -0,0 +1,55 @@
+package datafs
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"slices"
+)
+
+// s3DeniedCodes are the S3 error codes returned when the credentials can't be
+// used to read an object
+var s3DeniedCodes = []string{
+	"AccessDenied", "Forbidden", "InvalidAccessKeyId", "SignatureDoesNotMatch",
+	"ExpiredToken", "InvalidToken", "TokenRefreshRequired",
+}
+
+// s3ErrorCode returns the AWS error code in err's chain, if any. Errors from
+// both versions of the AWS SDK are supported.
+func s3ErrorCode(err error) string {
+	// AWS SDK v1 (awserr.Error)
+	var v1 interface{ Code() string }
+	if errors.As(err, &v1) {
+		return v1.Code()
+	}
+
+	// AWS SDK v2 (smithy.APIError)
+	var v2 interface{ ErrorCode() string }
+	if errors.As(err, &v2) {
+		return v2.ErrorCode()
+	}
+
+	return ""
+}
+
+// s3Error makes it clear whether an S3 read failed because the object doesn't
+// exist, because no credentials could be found, or because the credentials
+// aren't allowed to read it. Other errors are wrapped with the operation, as
+// for other datasources.
+func s3Error(op string, u *url.URL, key string, err error) error {
+	code := s3ErrorCode(err)
+
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return fmt.Errorf("s3 object %q not found in bucket %q: %w", key, u.Host, err)
+	case code == "NoCredentialProviders":
+		return fmt.Errorf("no AWS credentials found to read s3 object %q (set AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY, AWS_PROFILE, or use an instance role): %w: %w",
+			key, fs.ErrPermission, err)
+	case slices.Contains(s3DeniedCodes, code):
+		return fmt.Errorf("access to s3 object %q in bucket %q denied (check the credentials and bucket policy): %w: %w",
+			key, u.Host, fs.ErrPermission, err)
+	}
+
+	return fmt.Errorf("%s (url: %q, name: %q): %w", op, u, key, err)
+}
@@ -0,0 +1,44 @@
+package datafs
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAPIError looks like an AWS SDK v2 (smithy) API error
+type fakeAPIError struct{ code string }
+
+func (e fakeAPIError) Error() string     { return "api error " + e.code }
+func (e fakeAPIError) ErrorCode() string { return e.code }
+
+func TestS3Error(t *testing.T) {
+	u := mustParseURL("s3://mybucket/")
+
+	err := s3Error("read", u, "foo.json", fmt.Errorf("stat: %w", fs.ErrNotExist))
+	require.ErrorIs(t, err, fs.ErrNotExist)
+	assert.ErrorContains(t, err, `s3 object "foo.json" not found in bucket "mybucket"`)
+
+	err = s3Error("read", u, "foo.json",
+		awserr.New("NoCredentialProviders", "no valid providers in chain", nil))
+	require.ErrorIs(t, err, fs.ErrPermission)
+	assert.ErrorContains(t, err, `no AWS credentials found to read s3 object "foo.json"`)
+
+	err = s3Error("read", u, "foo.json",
+		fmt.Errorf("blob: %w", awserr.New("AccessDenied", "Access Denied", nil)))
+	require.ErrorIs(t, err, fs.ErrPermission)
+	assert.ErrorContains(t, err, `access to s3 object "foo.json" in bucket "mybucket" denied`)
+
+	err = s3Error("read", u, "foo.json", fakeAPIError{code: "InvalidAccessKeyId"})
+	require.ErrorIs(t, err, fs.ErrPermission)
+	assert.ErrorContains(t, err, "denied")
+
+	err = s3Error("read", u, "foo.json", errors.New("boom"))
+	assert.NotErrorIs(t, err, fs.ErrPermission)
+	assert.EqualError(t, err, `read (url: "s3://mybucket/", name: "foo.json"): boom`)
+}
@@ -0,0 +1,312 @@
+package datafs
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/hairyhenderson/go-fsimpl"
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// defaultSFTPTimeout is used for connecting to the SSH server
+const defaultSFTPTimeout = 30 * time.Second
+
+// NewSFTPFS returns a filesystem (an fs.FS) that can be used to read files
+// from an SSH server over SFTP.
+//
+// The user, host, and port are taken from the URL's authority. Clients are
+// authenticated with the key file named in the key query parameter, with an
+// SSH agent (at SSH_AUTH_SOCK), and with the password in the URL, in that
+// order. The server's host key is verified against the known_hosts file
+// (SSH_KNOWN_HOSTS, or ~/.ssh/known_hosts), unless the insecure query
+// parameter is set to true.
+func NewSFTPFS(u *url.URL) (fs.FS, error) {
+	if u.Host == "" {
+		return nil, fmt.Errorf("sftp: missing host in URL %q", u.Redacted())
+	}
+
+	addr := u.Host
+	if u.Port() == "" {
+		addr = net.JoinHostPort(u.Hostname(), "22")
+	}
+
+	username := u.User.Username()
+	if username == "" {
+		username = os.Getenv("USER")
+	}
+
+	q := u.Query()
+
+	insecure := false
+	if v := q.Get("insecure"); v != "" {
+		var err error
+
+		insecure, err = strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("sftp: invalid insecure parameter %q: %w", v, err)
+		}
+	}
+
+	password, _ := u.User.Password()
+
+	return &sftpFS{
+		ctx:      context.Background(),
+		addr:     addr,
+		user:     username,
+		password: password,
+		keyFile:  q.Get("key"),
+		insecure: insecure,
+	}, nil
+}
+
+type sftpFS struct {
+	ctx      context.Context
+	addr     string
+	user     string
+	password string
+	keyFile  string
+	insecure bool
+}
+
+//nolint:gochecknoglobals
+var SFTPFS = fsimpl.FSProviderFunc(NewSFTPFS, "sftp")
+
+var (
+	_ fs.FS         = (*sftpFS)(nil)
+	_ withContexter = (*sftpFS)(nil)
+)
+
+func (f sftpFS) WithContext(ctx context.Context) fs.FS {
+	fsys := f
+	fsys.ctx = ctx
+
+	return &fsys
+}
+
+func (f *sftpFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{
+			Op:   "open",
+			Path: name,
+			Err:  fs.ErrInvalid,
+		}
+	}
+
+	return &sftpFile{fsys: f, name: name}, nil
+}
+
+// connect opens an SFTP session. The returned function must be called to close
+// the session and the underlying connections.
+func (f *sftpFS) connect() (*sftp.Client, func(), error) {
+	var closers []io.Closer
+
+	closeAll := func() {
+		for i := len(closers) - 1; i >= 0; i-- {
+			_ = closers[i].Close()
+		}
+	}
+
+	auth, agentConn, err := f.authMethods()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if agentConn != nil {
+		closers = append(closers, agentConn)
+	}
+
+	hostKeyCallback, err := f.hostKeyCallback()
+	if err != nil {
+		closeAll()
+		return nil, nil, err
+	}
+
+	cfg := &ssh.ClientConfig{
+		User:            f.user,
+		Auth:            auth,
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         defaultSFTPTimeout,
+	}
+
+	d := net.Dialer{Timeout: defaultSFTPTimeout}
+
+	conn, err := d.DialContext(f.ctx, "tcp", f.addr)
+	if err != nil {
+		closeAll()
+		return nil, nil, fmt.Errorf("sftp: failed to connect to %s: %w", f.addr, err)
+	}
+
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, f.addr, cfg)
+	if err != nil {
+		conn.Close()
+		closeAll()
+
+		return nil, nil, fmt.Errorf("sftp: SSH handshake with %s failed: %w", f.addr, err)
+	}
+
+	client := ssh.NewClient(sshConn, chans, reqs)
+	closers = append(closers, client)
+
+	sc, err := sftp.NewClient(client)
+	if err != nil {
+		closeAll()
+		return nil, nil, fmt.Errorf("sftp: failed to start SFTP session with %s: %w", f.addr, err)
+	}
+
+	closers = append(closers, sc)
+
+	return sc, closeAll, nil
+}
+
+// authMethods returns the SSH authentication methods to try. If an agent is
+// used, its connection is returned too, so it can be closed later.
+func (f *sftpFS) authMethods() ([]ssh.AuthMethod, net.Conn, error) {
+	var methods []ssh.AuthMethod
+
+	if f.keyFile != "" {
+		b, err := os.ReadFile(f.keyFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("sftp: failed to read key: %w", err)
+		}
+
+		signer, err := ssh.ParsePrivateKey(b)
+		if err != nil {
+			return nil, nil, fmt.Errorf("sftp: failed to parse key %s: %w", f.keyFile, err)
+		}
+
+		methods = append(methods, ssh.PublicKeys(signer))
+	}
+
+	var agentConn net.Conn
+
+	if sock := os.Getenv("SSH_AUTH_SOCK"); sock != "" {
+		var err error
+
+		agentConn, err = net.Dial("unix", sock)
+		if err != nil {
+			return nil, nil, fmt.Errorf("sftp: failed to connect to SSH agent: %w", err)
+		}
+
+		methods = append(methods, ssh.PublicKeysCallback(agent.NewClient(agentConn).Signers))
+	}
+
+	if f.password != "" {
+		methods = append(methods, ssh.Password(f.password))
+	}
+
+	if len(methods) == 0 {
+		return nil, nil, fmt.Errorf("sftp: no authentication method available - set the key parameter, SSH_AUTH_SOCK, or a password")
+	}
+
+	return methods, agentConn, nil
+}
+
+func (f *sftpFS) hostKeyCallback() (ssh.HostKeyCallback, error) {
+	if f.insecure {
+		//nolint:gosec
+		return ssh.InsecureIgnoreHostKey(), nil
+	}
+
+	knownHosts := os.Getenv("SSH_KNOWN_HOSTS")
+	if knownHosts == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("sftp: failed to find known_hosts file: %w", err)
+		}
+
+		knownHosts = filepath.Join(home, ".ssh", "known_hosts")
+	}
+
+	cb, err := knownhosts.New(knownHosts)
+	if err != nil {
+		return nil, fmt.Errorf("sftp: failed to read known_hosts file: %w", err)
+	}
+
+	return cb, nil
+}
+
+type sftpFile struct {
+	fsys *sftpFS
+	body io.Reader
+	fi   fs.FileInfo
+	name string
+}
+
+var _ fs.File = (*sftpFile)(nil)
+
+func (f *sftpFile) Close() error {
+	f.body = nil
+	return nil
+}
+
+// fetch reads the whole file. The connection is only held for the duration
+// of the read, so nothing needs to be torn down when the file is closed.
+func (f *sftpFile) fetch() error {
+	if f.body != nil {
+		return nil
+	}
+
+	client, closeAll, err := f.fsys.connect()
+	if err != nil {
+		return err
+	}
+	defer closeAll()
+
+	// names are relative to the root of the remote filesystem
+	p := path.Join("/", f.name)
+
+	fi, err := client.Stat(p)
+	if err != nil {
+		return &fs.PathError{Op: "stat", Path: f.name, Err: err}
+	}
+
+	if fi.IsDir() {
+		return &fs.PathError{Op: "read", Path: f.name, Err: fmt.Errorf("is a directory")}
+	}
+
+	rf, err := client.Open(p)
+	if err != nil {
+		return &fs.PathError{Op: "open", Path: f.name, Err: err}
+	}
+	defer rf.Close()
+
+	b, err := io.ReadAll(rf)
+	if err != nil {
+		return &fs.PathError{Op: "read", Path: f.name, Err: err}
+	}
+
+	// the content type is left to be inferred from the file's extension
+	f.fi = FileInfo(path.Base(f.name), int64(len(b)), fi.Mode(), fi.ModTime(), "")
+	f.body = bytes.NewReader(b)
+
+	return nil
+}
+
+func (f *sftpFile) Stat() (fs.FileInfo, error) {
+	if err := f.fetch(); err != nil {
+		return nil, err
+	}
+
+	return f.fi, nil
+}
+
+func (f *sftpFile) Read(p []byte) (int, error) {
+	if err := f.fetch(); err != nil {
+		return 0, err
+	}
+
+	return f.body.Read(p)
+}
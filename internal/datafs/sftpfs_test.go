@@ -0,0 +1,226 @@
+package datafs
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"io/fs"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hairyhenderson/go-fsimpl"
+	"github.com/hairyhenderson/gomplate/v4/internal/config"
+	"github.com/hairyhenderson/gomplate/v4/internal/iohelpers"
+	"github.com/pkg/sftp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// startSFTPServer starts an SFTP server that accepts the given client key,
+// and returns its address and host key
+func startSFTPServer(t *testing.T, clientKey ssh.PublicKey) (string, ssh.PublicKey) {
+	t.Helper()
+
+	_, hostPriv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	hostSigner, err := ssh.NewSignerFromKey(hostPriv)
+	require.NoError(t, err)
+
+	cfg := &ssh.ServerConfig{
+		PublicKeyCallback: func(_ ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if string(key.Marshal()) == string(clientKey.Marshal()) {
+				return nil, nil
+			}
+
+			return nil, assert.AnError
+		},
+	}
+	cfg.AddHostKey(hostSigner)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+
+			go serveSFTP(conn, cfg)
+		}
+	}()
+
+	return l.Addr().String(), hostSigner.PublicKey()
+}
+
+func serveSFTP(conn net.Conn, cfg *ssh.ServerConfig) {
+	defer conn.Close()
+
+	sconn, chans, reqs, err := ssh.NewServerConn(conn, cfg)
+	if err != nil {
+		return
+	}
+	defer sconn.Close()
+
+	go ssh.DiscardRequests(reqs)
+
+	for nc := range chans {
+		if nc.ChannelType() != "session" {
+			_ = nc.Reject(ssh.UnknownChannelType, "unsupported")
+			continue
+		}
+
+		ch, creqs, err := nc.Accept()
+		if err != nil {
+			return
+		}
+
+		go func() {
+			for req := range creqs {
+				ok := req.Type == "subsystem" && string(req.Payload[4:]) == "sftp"
+				_ = req.Reply(ok, nil)
+
+				if ok {
+					srv, err := sftp.NewServer(ch)
+					if err != nil {
+						return
+					}
+
+					_ = srv.Serve()
+					srv.Close()
+
+					return
+				}
+			}
+		}()
+	}
+}
+
+func TestSFTPFS(t *testing.T) {
+	t.Setenv("SSH_AUTH_SOCK", "")
+
+	_, clientPriv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	clientSigner, err := ssh.NewSignerFromKey(clientPriv)
+	require.NoError(t, err)
+
+	addr, hostKey := startSFTPServer(t, clientSigner.PublicKey())
+
+	tmpDir := t.TempDir()
+
+	block, err := ssh.MarshalPrivateKey(clientPriv, "")
+	require.NoError(t, err)
+
+	keyFile := filepath.Join(tmpDir, "id_ed25519")
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(block), 0o600))
+
+	knownHostsFile := filepath.Join(tmpDir, "known_hosts")
+	require.NoError(t, os.WriteFile(knownHostsFile,
+		[]byte(knownhosts.Line([]string{addr}, hostKey)+"\n"), 0o600))
+	t.Setenv("SSH_KNOWN_HOSTS", knownHostsFile)
+
+	dataDir := filepath.Join(tmpDir, "data")
+	require.NoError(t, os.Mkdir(dataDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dataDir, "config.json"), []byte(`{"foo": "bar"}`), 0o644))
+
+	u, _ := url.Parse("sftp://user@" + addr + "/?key=" + url.QueryEscape(keyFile))
+	fsys, err := NewSFTPFS(u)
+	require.NoError(t, err)
+
+	fsys = fsimpl.WithContextFS(context.Background(), fsys)
+
+	name := filepath.ToSlash(filepath.Join(dataDir, "config.json"))[1:]
+
+	b, err := fs.ReadFile(fsys, name)
+	require.NoError(t, err)
+	assert.Equal(t, `{"foo": "bar"}`, string(b))
+
+	fi, err := fs.Stat(fsys, name)
+	require.NoError(t, err)
+	assert.Equal(t, "config.json", fi.Name())
+	assert.Equal(t, iohelpers.JSONMimetype, fsimpl.ContentType(fi))
+
+	_, err = fs.ReadFile(fsys, name+".missing")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+
+	_, err = fs.ReadFile(fsys, filepath.ToSlash(dataDir)[1:])
+	require.ErrorContains(t, err, "is a directory")
+
+	t.Run("datasource", func(t *testing.T) {
+		fsp := fsimpl.NewMux()
+		fsp.Add(SFTPFS)
+		ctx := ContextWithFSProvider(context.Background(), fsp)
+
+		reg := NewRegistry()
+		reg.Register("config", config.DataSource{URL: mustParseURL("sftp://user@" + addr +
+			filepath.ToSlash(dataDir) + "/config.json?key=" + url.QueryEscape(keyFile))})
+
+		ct, b, err := NewSourceReader(reg).ReadSource(ctx, "config")
+		require.NoError(t, err)
+		assert.Equal(t, iohelpers.JSONMimetype, ct)
+		assert.Equal(t, `{"foo": "bar"}`, string(b))
+	})
+
+	t.Run("unknown host key", func(t *testing.T) {
+		emptyKnownHosts := filepath.Join(t.TempDir(), "known_hosts")
+		require.NoError(t, os.WriteFile(emptyKnownHosts, nil, 0o600))
+		t.Setenv("SSH_KNOWN_HOSTS", emptyKnownHosts)
+
+		fsys, err := NewSFTPFS(u)
+		require.NoError(t, err)
+
+		_, err = fs.ReadFile(fsys, name)
+		require.ErrorContains(t, err, "key is unknown")
+
+		// unless verification is turned off
+		u, _ := url.Parse(u.String() + "&insecure=true")
+		fsys, err = NewSFTPFS(u)
+		require.NoError(t, err)
+
+		b, err := fs.ReadFile(fsys, name)
+		require.NoError(t, err)
+		assert.Equal(t, `{"foo": "bar"}`, string(b))
+	})
+
+	t.Run("wrong key", func(t *testing.T) {
+		_, otherPriv, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		block, err := ssh.MarshalPrivateKey(otherPriv, "")
+		require.NoError(t, err)
+
+		otherKey := filepath.Join(t.TempDir(), "id_other")
+		require.NoError(t, os.WriteFile(otherKey, pem.EncodeToMemory(block), 0o600))
+
+		u, _ := url.Parse("sftp://user@" + addr + "/?key=" + url.QueryEscape(otherKey))
+		fsys, err := NewSFTPFS(u)
+		require.NoError(t, err)
+
+		_, err = fs.ReadFile(fsys, name)
+		require.ErrorContains(t, err, "unable to authenticate")
+	})
+
+	t.Run("invalid URLs", func(t *testing.T) {
+		_, err := NewSFTPFS(mustParseURL("sftp:///foo"))
+		require.ErrorContains(t, err, "missing host")
+
+		_, err = NewSFTPFS(mustParseURL("sftp://host/foo?insecure=maybe"))
+		require.ErrorContains(t, err, "invalid insecure parameter")
+
+		fsys, err := NewSFTPFS(mustParseURL("sftp://user@" + addr + "/"))
+		require.NoError(t, err)
+
+		_, err = fs.ReadFile(fsys, name)
+		require.ErrorContains(t, err, "no authentication method available")
+	})
+}
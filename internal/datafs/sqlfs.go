@@ -0,0 +1,311 @@
+package datafs
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/hairyhenderson/go-fsimpl"
+	"github.com/hairyhenderson/gomplate/v4/internal/iohelpers"
+
+	// register the postgres driver
+	_ "github.com/lib/pq"
+)
+
+// NewSQLFS returns a filesystem (an fs.FS) that can be used to read the results
+// of SQL queries from Postgres or MySQL databases.
+//
+// The query is taken from the URL's fragment or its query parameter, and only
+// SELECT statements are allowed. Opening a file named for a database runs the
+// query against it, and reads the resulting rows as a JSON array of objects.
+func NewSQLFS(u *url.URL) (fs.FS, error) {
+	q := u.Query()
+
+	query := u.Fragment
+	if query == "" {
+		query = q.Get("query")
+	}
+
+	if err := checkSelectQuery(query); err != nil {
+		return nil, err
+	}
+
+	q.Del("query")
+
+	fsys := &sqlFS{
+		ctx:    context.Background(),
+		query:  query,
+		scheme: u.Scheme,
+		user:   u.User,
+		host:   u.Host,
+		params: q,
+	}
+
+	return fsys, nil
+}
+
+// checkSelectQuery returns an error unless the query is a single SELECT
+// statement. This is a guard against obvious mistakes only - the query is
+// also run in a read-only transaction.
+func checkSelectQuery(query string) error {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return fmt.Errorf("missing SQL query, must be given in the URL fragment or the query parameter")
+	}
+
+	keyword := strings.Fields(query)[0]
+	if !strings.EqualFold(keyword, "select") {
+		return fmt.Errorf("only SELECT queries are allowed, got %q", keyword)
+	}
+
+	if strings.Contains(strings.TrimSuffix(query, ";"), ";") {
+		return fmt.Errorf("only a single SQL statement is allowed")
+	}
+
+	return nil
+}
+
+type sqlFS struct {
+	ctx    context.Context
+	user   *url.Userinfo
+	params url.Values
+	query  string
+	scheme string
+	host   string
+}
+
+//nolint:gochecknoglobals
+var SQLFS = fsimpl.FSProviderFunc(NewSQLFS, "postgres", "postgresql", "mysql")
+
+var (
+	_ fs.FS         = (*sqlFS)(nil)
+	_ withContexter = (*sqlFS)(nil)
+)
+
+func (f sqlFS) WithContext(ctx context.Context) fs.FS {
+	fsys := f
+	fsys.ctx = ctx
+
+	return &fsys
+}
+
+func (f *sqlFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) || strings.Contains(name, "/") {
+		return nil, &fs.PathError{
+			Op:   "open",
+			Path: name,
+			Err:  fs.ErrInvalid,
+		}
+	}
+
+	dbname := name
+	if dbname == "." {
+		dbname = ""
+	}
+
+	driver, dsn := f.dsn(dbname)
+
+	return &sqlFile{ctx: f.ctx, name: name, driver: driver, dsn: dsn, query: f.query}, nil
+}
+
+// dsn returns the driver name and data source name for connecting to the
+// given database
+func (f *sqlFS) dsn(dbname string) (string, string) {
+	if f.scheme == "mysql" {
+		return "mysql", f.mysqlDSN(dbname)
+	}
+
+	// the postgres driver accepts URLs, and reads PG* environment variables
+	// for anything missing
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     f.user,
+		Host:     f.host,
+		Path:     "/" + dbname,
+		RawQuery: f.params.Encode(),
+	}
+
+	return "postgres", u.String()
+}
+
+// mysqlDSN builds a DSN for the mysql driver. Credentials and the host fall
+// back to the MYSQL_USER, MYSQL_PWD, and MYSQL_HOST environment variables (or
+// their `_FILE` variants), and URL query parameters are passed through as
+// driver parameters.
+func (f *sqlFS) mysqlDSN(dbname string) string {
+	cfg := mysql.NewConfig()
+	cfg.Net = "tcp"
+	cfg.DBName = dbname
+
+	cfg.User = getenv("MYSQL_USER")
+	cfg.Passwd = getenv("MYSQL_PWD")
+	if f.user != nil {
+		cfg.User = f.user.Username()
+		if p, ok := f.user.Password(); ok {
+			cfg.Passwd = p
+		}
+	}
+
+	cfg.Addr = f.host
+	if cfg.Addr == "" {
+		cfg.Addr = getenv("MYSQL_HOST")
+	}
+	if cfg.Addr == "" {
+		cfg.Addr = "localhost"
+	}
+	if _, _, err := net.SplitHostPort(cfg.Addr); err != nil {
+		cfg.Addr = net.JoinHostPort(cfg.Addr, "3306")
+	}
+
+	if len(f.params) > 0 {
+		cfg.Params = map[string]string{}
+		for k := range f.params {
+			cfg.Params[k] = f.params.Get(k)
+		}
+	}
+
+	return cfg.FormatDSN()
+}
+
+// overridable for testing
+var sqlOpen = sql.Open
+
+// sqlDBs holds a connection pool per driver and DSN, so that queries against
+// the same database (or re-renders with --watch) share connections. They're
+// closed by Cleanup.
+//
+//nolint:gochecknoglobals
+var sqlDBs = newConnPool((*sql.DB).Close)
+
+type sqlFile struct {
+	ctx    context.Context
+	body   io.Reader
+	err    error
+	name   string
+	driver string
+	dsn    string
+	query  string
+	size   int64
+}
+
+var _ fs.File = (*sqlFile)(nil)
+
+func (f *sqlFile) Close() error {
+	f.body = nil
+	return nil
+}
+
+// fetch runs the query, once per file - failures are remembered too, so the
+// query is never retried
+func (f *sqlFile) fetch() error {
+	if f.body == nil && f.err == nil {
+		f.err = f.runQuery()
+	}
+
+	return f.err
+}
+
+// runQuery runs the query and buffers the result
+func (f *sqlFile) runQuery() error {
+	db, err := sqlDBs.get(f.driver+" "+f.dsn, func() (*sql.DB, error) {
+		return sqlOpen(f.driver, f.dsn)
+	})
+	if err != nil {
+		return fmt.Errorf("%s: open: %w", f.driver, err)
+	}
+
+	rows, err := queryRows(f.ctx, db, f.query)
+	if err != nil {
+		return fmt.Errorf("%s: %w", f.driver, err)
+	}
+
+	b, err := json.Marshal(rows)
+	if err != nil {
+		return fmt.Errorf("%s: failed to marshal rows: %w", f.driver, err)
+	}
+
+	f.size = int64(len(b))
+	f.body = bytes.NewReader(b)
+
+	return nil
+}
+
+// queryRows runs the query in a read-only transaction, and returns the rows
+// as maps of column names to values
+func queryRows(ctx context.Context, db *sql.DB, query string) ([]map[string]interface{}, error) {
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+	//nolint:errcheck
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("columns: %w", err)
+	}
+
+	out := []map[string]interface{}{}
+
+	for rows.Next() {
+		vals := make([]interface{}, len(cols))
+		ptrs := make([]interface{}, len(cols))
+		for i := range vals {
+			ptrs[i] = &vals[i]
+		}
+
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+
+		row := make(map[string]interface{}, len(cols))
+		for i, col := range cols {
+			// drivers return text and numeric types as bytes, which would
+			// otherwise be marshalled as base64
+			if b, ok := vals[i].([]byte); ok {
+				vals[i] = string(b)
+			}
+
+			row[col] = vals[i]
+		}
+
+		out = append(out, row)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("read rows: %w", err)
+	}
+
+	return out, nil
+}
+
+func (f *sqlFile) Stat() (fs.FileInfo, error) {
+	if err := f.fetch(); err != nil {
+		return nil, err
+	}
+
+	return FileInfo(f.name, f.size, 0o444, time.Time{}, iohelpers.JSONArrayMimetype), nil
+}
+
+func (f *sqlFile) Read(p []byte) (int, error) {
+	if err := f.fetch(); err != nil {
+		return 0, err
+	}
+
+	return f.body.Read(p)
+}
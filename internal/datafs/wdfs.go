@@ -272,6 +272,8 @@ var (
 	_ hackpadfs.MkdirAllFS = (*wdFS)(nil)
 	_ hackpadfs.RemoveFS   = (*wdFS)(nil)
 	_ hackpadfs.ChmodFS    = (*wdFS)(nil)
+	_ hackpadfs.RenameFS   = (*wdFS)(nil)
+	_ hackpadfs.LstatFS    = (*wdFS)(nil)
 )
 
 func (w *wdFS) fsysFor(vol string) (fs.FS, error) {
@@ -352,12 +354,24 @@ func (w *wdFS) Sub(name string) (fs.FS, error) {
 	return fs.Sub(w.fsys, name)
 }
 
-func (w *wdFS) Glob(_ string) ([]string, error) {
-	// I'm not sure how to handle this, so I'm just going to error for now -
-	// I have no need of Glob anyway.
-	return nil, fmt.Errorf("glob not supported by wdFS: %w", fs.ErrInvalid)
+// Glob returns the names of files matching the pattern, with the same
+// semantics as fs.Glob. Relative patterns are matched in the working directory.
+func (w *wdFS) Glob(pattern string) ([]string, error) {
+	return fs.Glob(noGlobFS{w}, pattern)
 }
 
+// noGlobFS hides wdFS's Glob method, so that fs.Glob doesn't just call it
+// again, but uses ReadDir and Stat instead
+type noGlobFS struct {
+	w *wdFS
+}
+
+func (n noGlobFS) Open(name string) (fs.File, error) { return n.w.Open(name) }
+
+func (n noGlobFS) ReadDir(name string) ([]fs.DirEntry, error) { return n.w.ReadDir(name) }
+
+func (n noGlobFS) Stat(name string) (fs.FileInfo, error) { return n.w.Stat(name) }
+
 func (w *wdFS) Create(name string) (fs.File, error) {
 	root, resolved, err := resolveLocalPath(w.vol, name)
 	if err != nil {
@@ -422,6 +436,43 @@ func (w *wdFS) Remove(name string) error {
 	return hackpadfs.Remove(fsys, resolved)
 }
 
+func (w *wdFS) Lstat(name string) (fs.FileInfo, error) {
+	root, resolved, err := resolveLocalPath(w.vol, name)
+	if err != nil {
+		return nil, fmt.Errorf("resolve: %w", err)
+	}
+	fsys, err := w.fsysFor(root)
+	if err != nil {
+		return nil, err
+	}
+	return hackpadfs.LstatOrStat(fsys, resolved)
+}
+
+// Readlink returns the destination of the named symbolic link. Only links on
+// the local filesystem can be read.
+func (w *wdFS) Readlink(name string) (string, error) {
+	root, resolved, err := resolveLocalPath(w.vol, name)
+	if err != nil {
+		return "", fmt.Errorf("resolve: %w", err)
+	}
+	fsys, err := w.fsysFor(root)
+	if err != nil {
+		return "", err
+	}
+
+	osfsys, ok := fsys.(*osfs.FS)
+	if !ok {
+		return "", &fs.PathError{Op: "readlink", Path: name, Err: hackpadfs.ErrNotImplemented}
+	}
+
+	p, err := osfsys.ToOSPath(resolved)
+	if err != nil {
+		return "", err
+	}
+
+	return os.Readlink(p)
+}
+
 func (w *wdFS) Chmod(name string, mode fs.FileMode) error {
 	root, resolved, err := resolveLocalPath(w.vol, name)
 	if err != nil {
@@ -433,3 +484,32 @@ func (w *wdFS) Chmod(name string, mode fs.FileMode) error {
 	}
 	return hackpadfs.Chmod(fsys, resolved, mode)
 }
+
+func (w *wdFS) Rename(oldname, newname string) error {
+	root, oldResolved, err := resolveLocalPath(w.vol, oldname)
+	if err != nil {
+		return fmt.Errorf("resolve: %w", err)
+	}
+	newRoot, newResolved, err := resolveLocalPath(w.vol, newname)
+	if err != nil {
+		return fmt.Errorf("resolve: %w", err)
+	}
+	// both names must resolve to the same underlying filesystem
+	vol := func(r string) string {
+		if r == "" || r == "/" {
+			return w.vol
+		}
+		return r
+	}
+	if !strings.EqualFold(vol(root), vol(newRoot)) {
+		return &hackpadfs.LinkError{
+			Op: "rename", Old: oldname, New: newname,
+			Err: fmt.Errorf("can't rename across volumes (%q and %q): %w", root, newRoot, fs.ErrInvalid),
+		}
+	}
+	fsys, err := w.fsysFor(root)
+	if err != nil {
+		return err
+	}
+	return hackpadfs.Rename(fsys, oldResolved, newResolved)
+}
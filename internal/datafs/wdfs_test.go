@@ -5,6 +5,7 @@ import (
 	"io"
 	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
 	"runtime"
 	"testing"
@@ -62,6 +63,22 @@ func TestWDFS_ReadOps(t *testing.T) {
 	b, err = fs.ReadFile(subfs, "bar")
 	require.NoError(t, err)
 	assert.Equal(t, "goodnight moon", string(b))
+
+	matches, err := fs.Glob(fsys, "/tmp/*.txt")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/tmp/one.txt", "/tmp/three.txt", "/tmp/two.txt"}, matches)
+
+	// relative to the working directory
+	matches, err = fs.Glob(fsys, "tmp/*/b?r")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tmp/sub/bar"}, matches)
+
+	matches, err = fs.Glob(fsys, "tmp/*.json")
+	require.NoError(t, err)
+	assert.Empty(t, matches)
+
+	_, err = fs.Glob(fsys, "tmp/[")
+	require.ErrorIs(t, err, path.ErrBadPattern)
 }
 
 func TestWDFS_WriteOps(t *testing.T) {
@@ -133,6 +150,16 @@ func TestWDFS_WriteOps(t *testing.T) {
 	assert.True(t, fi.Mode().IsRegular())
 	assert.Equal(t, "0444", fmt.Sprintf("%#o", fi.Mode().Perm()))
 
+	// rename it and back again
+	err = fsys.Rename("/tmp/foo", "/tmp/foo.bak")
+	require.NoError(t, err)
+
+	_, err = fsys.Stat("/tmp/foo")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+
+	err = fsys.Rename("/tmp/foo.bak", "/tmp/foo")
+	require.NoError(t, err)
+
 	// now delete it
 	err = fsys.Remove("/tmp/foo")
 	require.NoError(t, err)
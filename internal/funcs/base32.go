@@ -0,0 +1,62 @@
+package funcs
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hairyhenderson/gomplate/v4/base32"
+	"github.com/hairyhenderson/gomplate/v4/conv"
+)
+
+// CreateBase32Funcs -
+func CreateBase32Funcs(ctx context.Context) map[string]interface{} {
+	f := map[string]interface{}{}
+
+	ns := &Base32Funcs{ctx}
+	f["base32"] = func() interface{} { return ns }
+
+	return f
+}
+
+// Base32Funcs -
+type Base32Funcs struct {
+	ctx context.Context
+}
+
+// Encode -
+func (Base32Funcs) Encode(args ...interface{}) (string, error) {
+	pad := true
+
+	switch len(args) {
+	case 1:
+	case 2:
+		m, ok := args[0].(map[string]interface{})
+		if !ok {
+			return "", fmt.Errorf("expected options to be a map, got %T", args[0])
+		}
+
+		for k, v := range m {
+			switch k {
+			case "padding":
+				pad = conv.ToBool(v)
+			default:
+				return "", fmt.Errorf("unknown base32 option %q", k)
+			}
+		}
+	default:
+		return "", fmt.Errorf("wrong number of args: want 1 or 2, got %d", len(args))
+	}
+
+	return base32.Encode(toBytes(args[len(args)-1]), pad), nil
+}
+
+// Decode -
+func (Base32Funcs) Decode(in interface{}) (string, error) {
+	out, err := base32.Decode(conv.ToString(in))
+	return string(out), err
+}
+
+// DecodeBytes -
+func (Base32Funcs) DecodeBytes(in interface{}) ([]byte, error) {
+	return base32.Decode(conv.ToString(in))
+}
@@ -0,0 +1,66 @@
+package funcs
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateBase32Funcs(t *testing.T) {
+	t.Parallel()
+
+	for i := 0; i < 10; i++ {
+		// Run this a bunch to catch race conditions
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			fmap := CreateBase32Funcs(ctx)
+			actual := fmap["base32"].(func() interface{})
+
+			assert.Equal(t, ctx, actual().(*Base32Funcs).ctx)
+		})
+	}
+}
+
+func TestBase32Encode(t *testing.T) {
+	t.Parallel()
+
+	bf := &Base32Funcs{}
+	assert.Equal(t, "MZXW6YTBOI======", must(bf.Encode("foobar")))
+	assert.Equal(t, "MZXW6YTBOI======", must(bf.Encode([]byte("foobar"))))
+	assert.Equal(t, "MZXW6YTBOI", must(bf.Encode(map[string]interface{}{"padding": false}, "foobar")))
+	assert.Equal(t, "MZXW6YTBOI======", must(bf.Encode(map[string]interface{}{"padding": "true"}, "foobar")))
+
+	_, err := bf.Encode(map[string]interface{}{"pad": false}, "foobar")
+	require.ErrorContains(t, err, `unknown base32 option "pad"`)
+
+	_, err = bf.Encode("foo", "bar")
+	require.Error(t, err)
+
+	_, err = bf.Encode()
+	require.Error(t, err)
+}
+
+func TestBase32Decode(t *testing.T) {
+	t.Parallel()
+
+	bf := &Base32Funcs{}
+	assert.Equal(t, "foobar", must(bf.Decode("MZXW6YTBOI======")))
+	assert.Equal(t, "foobar", must(bf.Decode("mzxw6ytboi")))
+
+	_, err := bf.Decode("not base32!")
+	require.ErrorContains(t, err, "invalid base32 input")
+}
+
+func TestBase32DecodeBytes(t *testing.T) {
+	t.Parallel()
+
+	bf := &Base32Funcs{}
+	out, err := bf.DecodeBytes("MZXW6YTBOI")
+	require.NoError(t, err)
+	assert.Equal(t, "foobar", string(out))
+}
@@ -3,6 +3,7 @@ package funcs
 import (
 	"context"
 	"fmt"
+	"math"
 	"reflect"
 
 	"github.com/hairyhenderson/gomplate/v4/conv"
@@ -123,12 +124,25 @@ func (CollFuncs) Merge(dst map[string]interface{}, src ...map[string]interface{}
 
 // Sort -
 func (CollFuncs) Sort(args ...interface{}) ([]interface{}, error) {
-	var (
-		key  string
-		list interface{}
-	)
+	key, list, err := sortArgs(args...)
+	if err != nil {
+		return nil, err
+	}
+	return coll.Sort(key, list)
+}
+
+// SortDesc -
+func (CollFuncs) SortDesc(args ...interface{}) ([]interface{}, error) {
+	key, list, err := sortArgs(args...)
+	if err != nil {
+		return nil, err
+	}
+	return coll.SortDesc(key, list)
+}
+
+func sortArgs(args ...interface{}) (key string, list interface{}, err error) {
 	if len(args) == 0 || len(args) > 2 {
-		return nil, fmt.Errorf("wrong number of args: wanted 1 or 2, got %d", len(args))
+		return "", nil, fmt.Errorf("wrong number of args: wanted 1 or 2, got %d", len(args))
 	}
 	if len(args) == 1 {
 		list = args[0]
@@ -137,7 +151,74 @@ func (CollFuncs) Sort(args ...interface{}) ([]interface{}, error) {
 		key = conv.ToString(args[0])
 		list = args[1]
 	}
-	return coll.Sort(key, list)
+	return key, list, nil
+}
+
+// GroupBy -
+func (CollFuncs) GroupBy(key string, list interface{}) (map[string][]interface{}, error) {
+	return coll.GroupBy(key, list)
+}
+
+// SubSlice returns part of the last argument, from the start index up to (but
+// not including) the optional end index. The list is given last for pipeline
+// compatibility.
+func (CollFuncs) SubSlice(args ...interface{}) ([]interface{}, error) {
+	if len(args) < 2 || len(args) > 3 {
+		return nil, fmt.Errorf("wrong number of args: wanted 2 or 3, got %d", len(args))
+	}
+
+	list := args[len(args)-1]
+
+	start, err := conv.ToInt(args[0])
+	if err != nil {
+		return nil, fmt.Errorf("expected a number for start: %w", err)
+	}
+
+	end := math.MaxInt
+	if len(args) == 3 {
+		end, err = conv.ToInt(args[1])
+		if err != nil {
+			return nil, fmt.Errorf("expected a number for end: %w", err)
+		}
+	}
+
+	return coll.SubSlice(list, start, end)
+}
+
+// Range returns the integers from start up to (but not including) end. With
+// one argument, it's the end, and the range starts at 0. The step defaults to
+// 1, or -1 when end is less than start.
+func (CollFuncs) Range(args ...interface{}) ([]int64, error) {
+	if len(args) == 0 || len(args) > 3 {
+		return nil, fmt.Errorf("wrong number of args: wanted 1, 2, or 3, got %d", len(args))
+	}
+
+	n := make([]int64, len(args))
+	for i, a := range args {
+		var err error
+
+		n[i], err = conv.ToInt64(a)
+		if err != nil {
+			return nil, fmt.Errorf("expected a number: %w", err)
+		}
+	}
+
+	start, end, step := int64(0), int64(0), int64(1)
+
+	switch len(n) {
+	case 1:
+		end = n[0]
+	case 2:
+		start, end = n[0], n[1]
+	case 3:
+		start, end, step = n[0], n[1], n[2]
+	}
+
+	if len(n) < 3 && end < start {
+		step = -1
+	}
+
+	return coll.Range(start, end, step)
 }
 
 // JSONPath -
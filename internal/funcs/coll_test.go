@@ -248,3 +248,92 @@ func TestCollFuncs_Unset(t *testing.T) {
 	require.NoError(t, err)
 	assert.Empty(t, out)
 }
+
+func TestCollFuncs_SortDesc(t *testing.T) {
+	t.Parallel()
+
+	c := &CollFuncs{}
+
+	_, err := c.SortDesc()
+	require.Error(t, err)
+
+	out, err := c.SortDesc([]interface{}{1, 3, 2})
+	require.NoError(t, err)
+	assert.EqualValues(t, []interface{}{3, 2, 1}, out)
+
+	in := []interface{}{
+		map[string]interface{}{"a": 1},
+		map[string]interface{}{"a": 3},
+	}
+	out, err = c.SortDesc("a", in)
+	require.NoError(t, err)
+	assert.EqualValues(t, []interface{}{in[1], in[0]}, out)
+}
+
+func TestCollFuncs_SubSlice(t *testing.T) {
+	t.Parallel()
+
+	c := &CollFuncs{}
+
+	in := []interface{}{"a", "b", "c", "d"}
+
+	out, err := c.SubSlice(1, 3, in)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"b", "c"}, out)
+
+	out, err = c.SubSlice("-2", in)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"c", "d"}, out)
+
+	out, err = c.SubSlice(0, -1, in)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"a", "b", "c"}, out)
+
+	_, err = c.SubSlice(in)
+	require.Error(t, err)
+
+	_, err = c.SubSlice("one", in)
+	require.ErrorContains(t, err, "expected a number for start")
+
+	_, err = c.SubSlice(0, "two", in)
+	require.ErrorContains(t, err, "expected a number for end")
+}
+
+func TestCollFuncs_Range(t *testing.T) {
+	t.Parallel()
+
+	c := &CollFuncs{}
+
+	out, err := c.Range(0, 10, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{0, 2, 4, 6, 8}, out)
+
+	out, err = c.Range(3)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{0, 1, 2}, out)
+
+	out, err = c.Range("2", "5")
+	require.NoError(t, err)
+	assert.Equal(t, []int64{2, 3, 4}, out)
+
+	// descending by default when end is less than start
+	out, err = c.Range(3, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{3, 2, 1}, out)
+
+	out, err = c.Range(0, 3, -1)
+	require.NoError(t, err)
+	assert.Empty(t, out)
+
+	_, err = c.Range(0, 10, 0)
+	require.ErrorContains(t, err, "step must not be 0")
+
+	_, err = c.Range()
+	require.Error(t, err)
+
+	_, err = c.Range(1, 2, 3, 4)
+	require.Error(t, err)
+
+	_, err = c.Range("ten")
+	require.ErrorContains(t, err, "expected a number")
+}
@@ -2,12 +2,18 @@ package funcs
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/url"
+	"reflect"
 	"strconv"
+	"strings"
 	"text/template"
 
 	"github.com/hairyhenderson/gomplate/v4/conv"
+	iconv "github.com/hairyhenderson/gomplate/v4/internal/conv"
 	"github.com/hairyhenderson/gomplate/v4/internal/deprecated"
+	"github.com/hairyhenderson/gomplate/v4/internal/parsers"
 )
 
 // CreateConvFuncs -
@@ -44,7 +50,7 @@ func (ConvFuncs) ToBool(in interface{}) bool {
 
 // ToBools -
 func (ConvFuncs) ToBools(in ...interface{}) []bool {
-	return conv.ToBools(in...)
+	return conv.ToBools(expandSlice(in)...)
 }
 
 // Join -
@@ -52,19 +58,113 @@ func (ConvFuncs) Join(in interface{}, sep string) (string, error) {
 	return conv.Join(in, sep)
 }
 
-// ParseInt -
-func (ConvFuncs) ParseInt(s interface{}, base, bitSize int) (int64, error) {
-	return strconv.ParseInt(conv.ToString(s), base, bitSize)
+// ParseInt - like strconv.ParseInt, but also accepts a 0x, 0o, or 0b prefix
+// matching the base
+func (ConvFuncs) ParseInt(s, base, bitSize interface{}) (int64, error) {
+	str := conv.ToString(s)
+
+	b, bits, err := parseBaseAndBitSize(base, bitSize)
+	if err != nil {
+		return 0, err
+	}
+
+	i, err := strconv.ParseInt(trimBasePrefix(str, b), b, bits)
+	if err != nil {
+		return i, numError("ParseInt", str, err)
+	}
+
+	return i, nil
 }
 
 // ParseFloat -
-func (ConvFuncs) ParseFloat(s interface{}, bitSize int) (float64, error) {
-	return strconv.ParseFloat(conv.ToString(s), bitSize)
+func (ConvFuncs) ParseFloat(s, bitSize interface{}) (float64, error) {
+	str := conv.ToString(s)
+
+	bits, err := conv.ToInt(bitSize)
+	if err != nil {
+		return 0, fmt.Errorf("bitSize must be an integer: %w", err)
+	}
+
+	return strconv.ParseFloat(str, bits)
+}
+
+// ParseUint - like strconv.ParseUint, but also accepts a 0x, 0o, or 0b prefix
+// matching the base
+func (ConvFuncs) ParseUint(s, base, bitSize interface{}) (uint64, error) {
+	str := conv.ToString(s)
+
+	b, bits, err := parseBaseAndBitSize(base, bitSize)
+	if err != nil {
+		return 0, err
+	}
+
+	i, err := strconv.ParseUint(trimBasePrefix(str, b), b, bits)
+	if err != nil {
+		return i, numError("ParseUint", str, err)
+	}
+
+	return i, nil
 }
 
-// ParseUint -
-func (ConvFuncs) ParseUint(s interface{}, base, bitSize int) (uint64, error) {
-	return strconv.ParseUint(conv.ToString(s), base, bitSize)
+func parseBaseAndBitSize(base, bitSize interface{}) (int, int, error) {
+	b, err := conv.ToInt(base)
+	if err != nil {
+		return 0, 0, fmt.Errorf("base must be an integer: %w", err)
+	}
+
+	if b != 0 && (b < 2 || b > 36) {
+		return 0, 0, fmt.Errorf("base must be 0, or between 2 and 36, got %d", b)
+	}
+
+	bits, err := conv.ToInt(bitSize)
+	if err != nil {
+		return 0, 0, fmt.Errorf("bitSize must be an integer: %w", err)
+	}
+
+	if bits < 0 || bits > 64 {
+		return 0, 0, fmt.Errorf("bitSize must be between 0 and 64, got %d", bits)
+	}
+
+	return b, bits, nil
+}
+
+// trimBasePrefix removes a prefix matching the base (such as 0x for base 16)
+// from s, after any sign, since strconv only accepts prefixes with base 0
+func trimBasePrefix(s string, base int) string {
+	prefix := ""
+
+	switch base {
+	case 2:
+		prefix = "0b"
+	case 8:
+		prefix = "0o"
+	case 16:
+		prefix = "0x"
+	default:
+		return s
+	}
+
+	sign := ""
+	if s != "" && (s[0] == '+' || s[0] == '-') {
+		sign, s = s[:1], s[1:]
+	}
+
+	if len(s) > len(prefix) && strings.EqualFold(s[:len(prefix)], prefix) {
+		s = s[len(prefix):]
+	}
+
+	return sign + s
+}
+
+// numError reports the original input string in the error, rather than the
+// one with the prefix trimmed
+func numError(fn, s string, err error) error {
+	var ne *strconv.NumError
+	if errors.As(err, &ne) {
+		return &strconv.NumError{Func: fn, Num: s, Err: ne.Err}
+	}
+
+	return err
 }
 
 // Atoi -
@@ -89,12 +189,12 @@ func (ConvFuncs) ToInt(in interface{}) (int, error) {
 
 // ToInt64s -
 func (ConvFuncs) ToInt64s(in ...interface{}) ([]int64, error) {
-	return conv.ToInt64s(in...)
+	return conv.ToInt64s(expandSlice(in)...)
 }
 
 // ToInts -
 func (ConvFuncs) ToInts(in ...interface{}) ([]int, error) {
-	return conv.ToInts(in...)
+	return conv.ToInts(expandSlice(in)...)
 }
 
 // ToFloat64 -
@@ -104,12 +204,54 @@ func (ConvFuncs) ToFloat64(in interface{}) (float64, error) {
 
 // ToFloat64s -
 func (ConvFuncs) ToFloat64s(in ...interface{}) ([]float64, error) {
-	return conv.ToFloat64s(in...)
+	return conv.ToFloat64s(expandSlice(in)...)
 }
 
-// ToString -
-func (ConvFuncs) ToString(in interface{}) string {
-	return conv.ToString(in)
+// ToString - converts the input to a string. An optional format can be given
+// before the input: "go" (the default) formats maps, slices, and arrays the
+// way Go does, and "json" formats them as compact JSON. Other values are
+// formatted the same way in both.
+func (ConvFuncs) ToString(args ...interface{}) (string, error) {
+	format := "go"
+
+	var in interface{}
+	switch len(args) {
+	case 1:
+		in = args[0]
+	case 2:
+		format = conv.ToString(args[0])
+		in = args[1]
+	default:
+		return "", fmt.Errorf("wrong number of args: want 1 or 2, got %d", len(args))
+	}
+
+	switch format {
+	case "go":
+		return conv.ToString(in), nil
+	case "json":
+		if !isNested(in) {
+			return conv.ToString(in), nil
+		}
+
+		return parsers.ToJSON(in)
+	default:
+		return "", fmt.Errorf("unsupported format %q, must be one of: go, json", format)
+	}
+}
+
+// isNested reports whether the value is a map, slice, or array - byte slices
+// are strings, for conversion purposes
+func isNested(in interface{}) bool {
+	if _, ok := in.([]byte); ok {
+		return false
+	}
+
+	switch reflect.Indirect(reflect.ValueOf(in)).Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array:
+		return true
+	default:
+		return false
+	}
 }
 
 // ToStrings -
@@ -117,11 +259,33 @@ func (ConvFuncs) ToStrings(in ...interface{}) []string {
 	return conv.ToStrings(in...)
 }
 
-// Default -
-func (ConvFuncs) Default(def, in interface{}) interface{} {
-	if truth, ok := template.IsTrue(in); truth && ok {
+// expandSlice returns the elements of the slice or array when it's the only
+// argument, so that conversion functions can be given either a list of
+// arguments or a single slice
+func expandSlice(in []interface{}) []interface{} {
+	if len(in) != 1 {
+		return in
+	}
+
+	switch in[0].(type) {
+	case string, []byte:
 		return in
 	}
 
+	if s, err := iconv.InterfaceSlice(in[0]); err == nil {
+		return s
+	}
+
+	return in
+}
+
+// Default - returns the first non-empty input, or def if all are empty
+func (ConvFuncs) Default(def interface{}, in ...interface{}) interface{} {
+	for _, v := range in {
+		if truth, ok := template.IsTrue(v); truth && ok {
+			return v
+		}
+	}
+
 	return def
 }
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCreateConvFuncs(t *testing.T) {
@@ -61,3 +62,168 @@ func TestDefault(t *testing.T) {
 		})
 	}
 }
+
+func TestDefaultMany(t *testing.T) {
+	t.Parallel()
+
+	c := &ConvFuncs{}
+
+	assert.Equal(t, "DEFAULT", c.Default("DEFAULT"))
+	assert.Equal(t, "DEFAULT", c.Default("DEFAULT", "", nil, 0, []string{}, map[string]string{}))
+	assert.Equal(t, "foo", c.Default("DEFAULT", "", nil, "foo", "bar"))
+	assert.Equal(t, []string{"a"}, c.Default("DEFAULT", map[string]int{}, []string{"a"}, "bar"))
+}
+
+func TestConvSlices(t *testing.T) {
+	t.Parallel()
+
+	c := &ConvFuncs{}
+
+	ints, err := c.ToInts([]interface{}{"1", 2, "3.0"})
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3}, ints)
+
+	ints, err = c.ToInts("1", "2")
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, ints)
+
+	_, err = c.ToInts([]string{"1", "two"})
+	require.ErrorContains(t, err, "element 1: ")
+
+	int64s, err := c.ToInt64s([]string{"4", "5"})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{4, 5}, int64s)
+
+	floats, err := c.ToFloat64s([]interface{}{"1.5", 2})
+	require.NoError(t, err)
+	assert.Equal(t, []float64{1.5, 2}, floats)
+
+	_, err = c.ToFloat64s([]interface{}{"1.5", "x"})
+	require.ErrorContains(t, err, "element 1: ")
+
+	assert.Equal(t, []bool{true, false, true}, c.ToBools([]string{"true", "no", "1"}))
+	assert.Equal(t, []bool{true}, c.ToBools("yes"))
+
+	// a single byte slice is not expanded
+	_, err = c.ToInts([]byte("42"))
+	require.Error(t, err)
+}
+
+func TestParseInt(t *testing.T) {
+	t.Parallel()
+
+	c := &ConvFuncs{}
+
+	testdata := []struct {
+		in        interface{}
+		base, bit interface{}
+		out       int64
+	}{
+		{"42", 10, 64, 42},
+		{"-42", "10", "64", -42},
+		{"7C0", 16, 32, 1984},
+		{"0xFF", 16, 64, 255},
+		{"-0XfF", 16, 64, -255},
+		{"0b101", 2, 8, 5},
+		{"0o755", 8, 32, 0o755},
+		{"755", 8, 32, 0o755},
+		{"0x1F", 0, 64, 31},
+		{"zz", 36, 64, 1295},
+	}
+
+	for _, d := range testdata {
+		out, err := c.ParseInt(d.in, d.base, d.bit)
+		require.NoError(t, err, d.in)
+		assert.Equal(t, d.out, out, d.in)
+	}
+
+	// the error includes the original input, not the trimmed one
+	_, err := c.ParseInt("0xZZ", 16, 64)
+	require.EqualError(t, err, `strconv.ParseInt: parsing "0xZZ": invalid syntax`)
+
+	_, err = c.ParseInt("0x100", 16, 8)
+	require.EqualError(t, err, `strconv.ParseInt: parsing "0x100": value out of range`)
+
+	_, err = c.ParseInt("10", 1, 64)
+	require.ErrorContains(t, err, "base must be 0, or between 2 and 36")
+
+	_, err = c.ParseInt("10", 10, 65)
+	require.ErrorContains(t, err, "bitSize must be between 0 and 64")
+
+	_, err = c.ParseInt("10", "ten", 64)
+	require.ErrorContains(t, err, "base must be an integer")
+}
+
+func TestParseUint(t *testing.T) {
+	t.Parallel()
+
+	c := &ConvFuncs{}
+
+	out, err := c.ParseUint("0xFFFFFFFFFFFFFFFF", 16, 64)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0xFFFFFFFFFFFFFFFF), out)
+
+	out, err = c.ParseUint("0644", 8, 32)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0o644), out)
+
+	_, err = c.ParseUint("-1", 10, 64)
+	require.EqualError(t, err, `strconv.ParseUint: parsing "-1": invalid syntax`)
+
+	_, err = c.ParseUint("0b102", 2, 8)
+	require.EqualError(t, err, `strconv.ParseUint: parsing "0b102": invalid syntax`)
+}
+
+func TestParseFloat(t *testing.T) {
+	t.Parallel()
+
+	c := &ConvFuncs{}
+
+	out, err := c.ParseFloat("3.14159", "64")
+	require.NoError(t, err)
+	assert.InEpsilon(t, 3.14159, out, 1e-9)
+
+	_, err = c.ParseFloat("pi", 64)
+	require.EqualError(t, err, `strconv.ParseFloat: parsing "pi": invalid syntax`)
+
+	_, err = c.ParseFloat("3.14", "sixty-four")
+	require.ErrorContains(t, err, "bitSize must be an integer")
+}
+
+func TestToString(t *testing.T) {
+	t.Parallel()
+
+	c := &ConvFuncs{}
+
+	testdata := []struct {
+		expected string
+		args     []interface{}
+	}{
+		{"42", []interface{}{42}},
+		{"map[foo:bar]", []interface{}{map[string]string{"foo": "bar"}}},
+		{"map[foo:bar]", []interface{}{"go", map[string]string{"foo": "bar"}}},
+		{`{"foo":"bar"}`, []interface{}{"json", map[string]string{"foo": "bar"}}},
+		{`{"a":[1,2,{"b":true}]}`, []interface{}{"json", map[string]interface{}{
+			"a": []interface{}{1, 2, map[interface{}]interface{}{"b": true}},
+		}}},
+		{`["a","b"]`, []interface{}{"json", &[]string{"a", "b"}}},
+		{"[1,2]", []interface{}{"json", [2]int{1, 2}}},
+		// scalars aren't quoted
+		{"foo", []interface{}{"json", "foo"}},
+		{"42", []interface{}{"json", 42}},
+		{"nil", []interface{}{"json", nil}},
+		{"hello", []interface{}{"json", []byte("hello")}},
+	}
+
+	for _, d := range testdata {
+		out, err := c.ToString(d.args...)
+		require.NoError(t, err)
+		assert.Equal(t, d.expected, out)
+	}
+
+	_, err := c.ToString()
+	require.Error(t, err)
+
+	_, err = c.ToString("yaml", []int{1})
+	require.EqualError(t, err, `unsupported format "yaml", must be one of: go, json`)
+}
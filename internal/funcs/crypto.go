@@ -4,26 +4,38 @@ import (
 	"context"
 	gcrypto "crypto"
 	"crypto/elliptic"
+	"crypto/md5"  //nolint: gosec
 	"crypto/sha1" //nolint: gosec
 	"crypto/sha256"
 	"crypto/sha512"
 	"encoding/base64"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"hash"
+	"io"
+	"io/fs"
 	"strings"
 	"unicode/utf8"
 
 	"golang.org/x/crypto/bcrypt"
 
+	osfs "github.com/hack-pad/hackpadfs/os"
 	"github.com/hairyhenderson/gomplate/v4/conv"
 	"github.com/hairyhenderson/gomplate/v4/crypto"
+	"github.com/hairyhenderson/gomplate/v4/internal/datafs"
 )
 
 // CreateCryptoFuncs -
 func CreateCryptoFuncs(ctx context.Context) map[string]interface{} {
 	f := map[string]interface{}{}
 
-	ns := &CryptoFuncs{ctx}
+	fsys, err := datafs.FSysForPath(ctx, "/")
+	if err != nil {
+		fsys = datafs.WrapWdFS(osfs.NewFS())
+	}
+
+	ns := &CryptoFuncs{ctx: ctx, fs: fsys}
 
 	f["crypto"] = func() interface{} { return ns }
 	return f
@@ -32,38 +44,72 @@ func CreateCryptoFuncs(ctx context.Context) map[string]interface{} {
 // CryptoFuncs -
 type CryptoFuncs struct {
 	ctx context.Context
+	fs  fs.FS
 }
 
 // PBKDF2 - Run the Password-Based Key Derivation Function #2 as defined in
 // RFC 2898 (PKCS #5 v2.0). This function outputs the binary result in hex
 // format.
-func (CryptoFuncs) PBKDF2(password, salt, iter, keylen interface{}, hashFunc ...string) (k string, err error) {
-	var h gcrypto.Hash
-	if len(hashFunc) == 0 {
-		h = gcrypto.SHA1
-	} else {
-		h, err = crypto.StrToHash(hashFunc[0])
-		if err != nil {
-			return "", err
-		}
+func (f CryptoFuncs) PBKDF2(password, salt, iter, keylen interface{}, hashFunc ...string) (string, error) {
+	dk, err := f.PBKDF2Bytes(password, salt, iter, keylen, hashFunc...)
+	return fmt.Sprintf("%02x", dk), err
+}
+
+// PBKDF2Bytes - Run the Password-Based Key Derivation Function #2 as defined
+// in RFC 2898 (PKCS #5 v2.0), returning the raw bytes of the key.
+func (CryptoFuncs) PBKDF2Bytes(password, salt, iter, keylen interface{}, hashFunc ...string) ([]byte, error) {
+	h, err := hashOrDefault(hashFunc, gcrypto.SHA1)
+	if err != nil {
+		return nil, err
 	}
 	pw := toBytes(password)
 	s := toBytes(salt)
 
 	i, err := conv.ToInt(iter)
 	if err != nil {
-		return "", fmt.Errorf("iter must be an integer: %w", err)
+		return nil, fmt.Errorf("iter must be an integer: %w", err)
 	}
 
 	kl, err := conv.ToInt(keylen)
 	if err != nil {
-		return "", fmt.Errorf("keylen must be an integer: %w", err)
+		return nil, fmt.Errorf("keylen must be an integer: %w", err)
 	}
 
-	dk, err := crypto.PBKDF2(pw, s, i, kl, h)
+	return crypto.PBKDF2(pw, s, i, kl, h)
+}
+
+// HKDF - Run the HMAC-based Extract-and-Expand Key Derivation Function as
+// defined in RFC 5869. This function outputs the binary result in hex format.
+func (f CryptoFuncs) HKDF(secret, salt, info, keylen interface{}, hashFunc ...string) (string, error) {
+	dk, err := f.HKDFBytes(secret, salt, info, keylen, hashFunc...)
 	return fmt.Sprintf("%02x", dk), err
 }
 
+// HKDFBytes - Run the HMAC-based Extract-and-Expand Key Derivation Function as
+// defined in RFC 5869, returning the raw bytes of the key.
+func (CryptoFuncs) HKDFBytes(secret, salt, info, keylen interface{}, hashFunc ...string) ([]byte, error) {
+	h, err := hashOrDefault(hashFunc, gcrypto.SHA256)
+	if err != nil {
+		return nil, err
+	}
+
+	kl, err := conv.ToInt(keylen)
+	if err != nil {
+		return nil, fmt.Errorf("keylen must be an integer: %w", err)
+	}
+
+	return crypto.HKDF(toBytes(secret), toBytes(salt), toBytes(info), kl, h)
+}
+
+// hashOrDefault - the named hash function, or def if none is named
+func hashOrDefault(hashFunc []string, def gcrypto.Hash) (gcrypto.Hash, error) {
+	if len(hashFunc) == 0 {
+		return def, nil
+	}
+
+	return crypto.StrToHash(hashFunc[0])
+}
+
 // WPAPSK - Convert an ASCII passphrase to WPA PSK for a given SSID
 func (f CryptoFuncs) WPAPSK(ssid, password interface{}) (string, error) {
 	return f.PBKDF2(password, ssid, 4096, 32)
@@ -115,6 +161,46 @@ func (f CryptoFuncs) SHA512_256(input interface{}) string {
 	return fmt.Sprintf("%02x", out)
 }
 
+// MD5File - compute the MD5 checksum of the named file, as a hex string.
+// Note: MD5 is cryptographically broken and should not be used for secure
+// applications.
+func (f CryptoFuncs) MD5File(path interface{}) (string, error) {
+	return f.hashFile(md5.New(), path) //nolint: gosec
+}
+
+// SHA256File - compute the SHA-256 checksum of the named file, as a hex string
+func (f CryptoFuncs) SHA256File(path interface{}) (string, error) {
+	return f.hashFile(sha256.New(), path)
+}
+
+// SHA512File - compute the SHA-512 checksum of the named file, as a hex string
+func (f CryptoFuncs) SHA512File(path interface{}) (string, error) {
+	return f.hashFile(sha512.New(), path)
+}
+
+// hashFile streams the file through the hash, so large files are never held
+// in memory. Paths are resolved the same way as for file.Read.
+func (f CryptoFuncs) hashFile(h hash.Hash, path interface{}) (string, error) {
+	fsys := f.fs
+	if fsys == nil {
+		fsys = datafs.WrapWdFS(osfs.NewFS())
+	}
+
+	name := conv.ToString(path)
+
+	file, err := fsys.Open(name)
+	if err != nil {
+		return "", fmt.Errorf("failed to open %q: %w", name, err)
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(h, file); err != nil {
+		return "", fmt.Errorf("failed to read %q: %w", name, err)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
 // SHA1 - Note: SHA-1 is cryptographically broken and should not be used for secure applications.
 func (CryptoFuncs) SHA1Bytes(input interface{}) ([]byte, error) {
 	//nolint:gosec
@@ -193,10 +279,27 @@ func (CryptoFuncs) Bcrypt(args ...interface{}) (string, error) {
 		return "", fmt.Errorf("wrong number of args: want 1 or 2, got %d", len(args))
 	}
 
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		return "", fmt.Errorf("bcrypt cost must be between %d and %d, got %d", bcrypt.MinCost, bcrypt.MaxCost, cost)
+	}
+
 	hash, err := bcrypt.GenerateFromPassword([]byte(input), cost)
 	return string(hash), err
 }
 
+// CheckBcrypt - returns true if the input matches the bcrypt hash
+func (CryptoFuncs) CheckBcrypt(hash string, input interface{}) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(hash), toBytes(input))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check bcrypt hash: %w", err)
+	}
+
+	return true, nil
+}
+
 // RSAEncrypt -
 // Experimental!
 func (f *CryptoFuncs) RSAEncrypt(key string, in interface{}) ([]byte, error) {
@@ -227,6 +330,33 @@ func (f *CryptoFuncs) RSADecryptBytes(key string, in []byte) ([]byte, error) {
 	return out, err
 }
 
+// RSAEncryptOAEP -
+// Experimental!
+func (f *CryptoFuncs) RSAEncryptOAEP(key string, in interface{}) (string, error) {
+	if err := checkExperimental(f.ctx); err != nil {
+		return "", err
+	}
+	out, err := crypto.RSAEncryptOAEP(key, toBytes(in))
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(out), nil
+}
+
+// RSADecryptOAEP -
+// Experimental!
+func (f *CryptoFuncs) RSADecryptOAEP(key string, in interface{}) (string, error) {
+	if err := checkExperimental(f.ctx); err != nil {
+		return "", err
+	}
+	b, err := base64.StdEncoding.DecodeString(strings.TrimSpace(conv.ToString(in)))
+	if err != nil {
+		return "", fmt.Errorf("ciphertext must be base64-encoded: %w", err)
+	}
+	out, err := crypto.RSADecryptOAEP(key, b)
+	return string(out), err
+}
+
 // RSAGenerateKey -
 // Experimental!
 func (f *CryptoFuncs) RSAGenerateKey(args ...interface{}) (string, error) {
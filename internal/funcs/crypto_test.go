@@ -3,11 +3,14 @@ package funcs
 import (
 	"context"
 	"encoding/base64"
+	"io/fs"
 	"strconv"
 	"strings"
 	"testing"
+	"testing/fstest"
 
 	"github.com/hairyhenderson/gomplate/v4/internal/config"
+	"github.com/hairyhenderson/gomplate/v4/internal/datafs"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -45,6 +48,46 @@ func TestPBKDF2(t *testing.T) {
 
 	_, err = c.PBKDF2(nil, nil, nil, nil, "bogus")
 	require.Error(t, err)
+
+	_, err = c.PBKDF2("password", "salt", 0, 32, "sha256")
+	require.ErrorContains(t, err, "iteration count must be at least 1")
+
+	b, err := c.PBKDF2Bytes("password", []byte("IEEE"), 4096, 4)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0xf4, 0x2c, 0x6f, 0xc5}, b)
+}
+
+func TestHKDF(t *testing.T) {
+	t.Parallel()
+
+	c := testCryptoNS()
+
+	// with the default hash, SHA-256
+	dk, err := c.HKDF("secret", "salt", "info", 32)
+	require.NoError(t, err)
+	assert.Len(t, dk, 64)
+
+	dk2, err := c.HKDF([]byte("secret"), []byte("salt"), []byte("info"), "32", "sha256")
+	require.NoError(t, err)
+	assert.Equal(t, dk, dk2)
+
+	// different info gives a different key
+	dk2, err = c.HKDF("secret", "salt", "other", 32)
+	require.NoError(t, err)
+	assert.NotEqual(t, dk, dk2)
+
+	b, err := c.HKDFBytes("secret", "", "", 16, "SHA-512")
+	require.NoError(t, err)
+	assert.Len(t, b, 16)
+
+	_, err = c.HKDF("secret", "salt", "info", "foo")
+	require.Error(t, err)
+
+	_, err = c.HKDF("secret", "salt", "info", 32, "bogus")
+	require.Error(t, err)
+
+	_, err = c.HKDF("", "salt", "info", 32)
+	require.ErrorContains(t, err, "secret must not be empty")
 }
 
 func TestWPAPSK(t *testing.T) {
@@ -77,6 +120,37 @@ func TestSHA(t *testing.T) {
 	assert.Equal(t, sha512_256, c.SHA512_256(in))
 }
 
+func TestHashFile(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{
+		"tmp":     &fstest.MapFile{Mode: fs.ModeDir | 0o777},
+		"tmp/abc": &fstest.MapFile{Data: []byte("abc")},
+	}
+
+	c := testCryptoNS()
+	c.fs = datafs.WrapWdFS(fsys)
+
+	out, err := c.MD5File("/tmp/abc")
+	require.NoError(t, err)
+	assert.Equal(t, "900150983cd24fb0d6963f7d28e17f72", out)
+
+	out, err = c.SHA256File("/tmp/abc")
+	require.NoError(t, err)
+	assert.Equal(t, c.SHA256("abc"), out)
+
+	out, err = c.SHA512File("/tmp/abc")
+	require.NoError(t, err)
+	assert.Equal(t, c.SHA512("abc"), out)
+
+	_, err = c.SHA256File("/tmp/missing")
+	require.ErrorContains(t, err, `"/tmp/missing"`)
+	require.ErrorIs(t, err, fs.ErrNotExist)
+
+	_, err = c.SHA256File("/tmp")
+	require.Error(t, err)
+}
+
 func TestBcrypt(t *testing.T) {
 	t.Parallel()
 
@@ -95,12 +169,14 @@ func TestBcrypt(t *testing.T) {
 		assert.True(t, strings.HasPrefix(actual, "$2a$10$"))
 	})
 
-	t.Run("cost less than min", func(t *testing.T) {
+	t.Run("cost out of range", func(t *testing.T) {
 		t.Parallel()
 
-		actual, err := c.Bcrypt(0, in)
-		require.NoError(t, err)
-		assert.True(t, strings.HasPrefix(actual, "$2a$10$"))
+		_, err := c.Bcrypt(0, in)
+		require.ErrorContains(t, err, "bcrypt cost must be between 4 and 31, got 0")
+
+		_, err = c.Bcrypt(32, in)
+		require.Error(t, err)
 	})
 
 	t.Run("cost equal to min", func(t *testing.T) {
@@ -119,6 +195,26 @@ func TestBcrypt(t *testing.T) {
 	})
 }
 
+func TestCheckBcrypt(t *testing.T) {
+	t.Parallel()
+
+	c := testCryptoNS()
+
+	hash, err := c.Bcrypt(4, "foo")
+	require.NoError(t, err)
+
+	ok, err := c.CheckBcrypt(hash, "foo")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = c.CheckBcrypt(hash, "bar")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	_, err = c.CheckBcrypt("not a hash", "foo")
+	require.Error(t, err)
+}
+
 func TestRSAGenerateKey(t *testing.T) {
 	t.Parallel()
 
@@ -243,6 +339,33 @@ func TestRSACrypt(t *testing.T) {
 	assert.Equal(t, dec, string(b))
 }
 
+func TestRSACryptOAEP(t *testing.T) {
+	t.Parallel()
+
+	c := testCryptoNS()
+	key, err := c.RSAGenerateKey(2048)
+	require.NoError(t, err)
+	pub, err := c.RSADerivePublicKey(key)
+	require.NoError(t, err)
+
+	in := "hello world"
+	enc, err := c.RSAEncryptOAEP(pub, in)
+	require.NoError(t, err)
+
+	_, err = base64.StdEncoding.DecodeString(enc)
+	require.NoError(t, err)
+
+	dec, err := c.RSADecryptOAEP(key, enc)
+	require.NoError(t, err)
+	assert.Equal(t, in, dec)
+
+	_, err = c.RSADecryptOAEP(key, "not base64!")
+	require.ErrorContains(t, err, "ciphertext must be base64-encoded")
+
+	_, err = c.RSAEncryptOAEP("not a key", in)
+	require.Error(t, err)
+}
+
 func TestAESCrypt(t *testing.T) {
 	c := testCryptoNS()
 	key := "0123456789012345"
@@ -3,6 +3,7 @@ package funcs
 import (
 	"context"
 
+	"github.com/hairyhenderson/gomplate/v4/coll"
 	"github.com/hairyhenderson/gomplate/v4/conv"
 	"github.com/hairyhenderson/gomplate/v4/internal/parsers"
 )
@@ -24,10 +25,12 @@ func CreateDataFuncs(ctx context.Context) map[string]interface{} {
 	f["csvByRow"] = ns.CSVByRow
 	f["csvByColumn"] = ns.CSVByColumn
 	f["cue"] = ns.CUE
+	f["xml"] = ns.XML
 	f["toJSON"] = ns.ToJSON
 	f["toJSONPretty"] = ns.ToJSONPretty
 	f["toYAML"] = ns.ToYAML
 	f["toTOML"] = ns.ToTOML
+	f["toHCL"] = ns.ToHCL
 	f["toCSV"] = ns.ToCSV
 	f["toCUE"] = ns.ToCUE
 	return f
@@ -83,6 +86,16 @@ func (f *DataFuncs) CUE(in interface{}) (interface{}, error) {
 	return parsers.CUE(conv.ToString(in))
 }
 
+// XML -
+func (f *DataFuncs) XML(in interface{}) (map[string]interface{}, error) {
+	return parsers.XML(conv.ToString(in))
+}
+
+// JSONPointer -
+func (f *DataFuncs) JSONPointer(p string, in interface{}) (interface{}, error) {
+	return coll.JSONPointer(p, in)
+}
+
 // ToCSV -
 func (f *DataFuncs) ToCSV(args ...interface{}) (string, error) {
 	return parsers.ToCSV(args...)
@@ -112,3 +125,8 @@ func (f *DataFuncs) ToYAML(in interface{}) (string, error) {
 func (f *DataFuncs) ToTOML(in interface{}) (string, error) {
 	return parsers.ToTOML(in)
 }
+
+// ToHCL -
+func (f *DataFuncs) ToHCL(in interface{}) (string, error) {
+	return parsers.ToHCL(in)
+}
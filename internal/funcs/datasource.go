@@ -2,9 +2,14 @@ package funcs
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"log/slog"
+	"net/url"
+	"sync"
 
+	"github.com/hairyhenderson/gomplate/v4/conv"
 	"github.com/hairyhenderson/gomplate/v4/internal/config"
 	"github.com/hairyhenderson/gomplate/v4/internal/datafs"
 	"github.com/hairyhenderson/gomplate/v4/internal/parsers"
@@ -18,6 +23,29 @@ func CreateDataSourceFuncs(ctx context.Context, sr datafs.DataSourceReader) map[
 		sr:  sr,
 	}
 
+	return dataSourceFuncMap(ns)
+}
+
+// CreateTemplateDataSourceFuncs - like CreateDataSourceFuncs, but for a
+// template with its own datasources, read with sr. Datasources defined with
+// defineDatasource are shared with the funcs in base (from
+// CreateDataSourceFuncs), so they're visible to all templates either way.
+func CreateTemplateDataSourceFuncs(ctx context.Context, sr datafs.DataSourceReader, base map[string]interface{}) map[string]interface{} {
+	ns := &dataSourceFuncs{
+		ctx: ctx,
+		sr:  sr,
+	}
+
+	if f, ok := base["_datasource"].(func() interface{}); ok {
+		if baseNS, ok := f().(*dataSourceFuncs); ok {
+			ns.defs = baseNS.defined()
+		}
+	}
+
+	return dataSourceFuncMap(ns)
+}
+
+func dataSourceFuncMap(ns *dataSourceFuncs) map[string]interface{} {
 	f := map[string]interface{}{}
 
 	// undocumented but available
@@ -25,6 +53,7 @@ func CreateDataSourceFuncs(ctx context.Context, sr datafs.DataSourceReader) map[
 
 	f["datasource"] = ns.Datasource
 	f["ds"] = ns.Datasource
+	f["datasourceOr"] = ns.DatasourceOr
 	f["datasourceExists"] = ns.DatasourceExists
 	f["datasourceReachable"] = ns.DatasourceReachable
 	f["defineDatasource"] = ns.DefineDatasource
@@ -38,6 +67,30 @@ func CreateDataSourceFuncs(ctx context.Context, sr datafs.DataSourceReader) map[
 type dataSourceFuncs struct {
 	ctx context.Context
 	sr  datafs.DataSourceReader
+
+	// defs - the datasources defined with defineDatasource, possibly shared
+	// with other templates' funcs
+	defs     *definedSources
+	defsOnce sync.Once
+}
+
+// definedSources tracks the datasources defined with defineDatasource
+type definedSources struct {
+	// sr - the reader the datasources are registered with
+	sr datafs.DataSourceReader
+	// urls - the URLs of the defined datasources
+	urls map[string]string
+	mu   sync.Mutex
+}
+
+func (d *dataSourceFuncs) defined() *definedSources {
+	d.defsOnce.Do(func() {
+		if d.defs == nil {
+			d.defs = &definedSources{sr: d.sr, urls: map[string]string{}}
+		}
+	})
+
+	return d.defs
 }
 
 // Include - Reads from the named datasource, without parsing the data, which
@@ -61,21 +114,99 @@ func (d *dataSourceFuncs) Datasource(alias string, args ...string) (interface{},
 	return parsers.ParseData(ct, string(b))
 }
 
-// DefineDatasource -
+// DatasourceOr - Reads from the named datasource like Datasource, but returns
+// the default (the last argument) when the datasource isn't defined, or the
+// datasource or given subpath doesn't exist. Other errors are still returned.
+//
+// An options map may be given first, to only return the default in one of the
+// cases: "undefined" and "missing" can be set to false to return an error
+// instead.
+func (d *dataSourceFuncs) DatasourceOr(args ...interface{}) (interface{}, error) {
+	ifUndefined, ifMissing := true, true
+
+	if len(args) > 0 {
+		if m, ok := args[0].(map[string]interface{}); ok {
+			for k, v := range m {
+				switch k {
+				case "undefined":
+					ifUndefined = conv.ToBool(v)
+				case "missing":
+					ifMissing = conv.ToBool(v)
+				default:
+					return nil, fmt.Errorf("unknown datasourceOr option %q", k)
+				}
+			}
+
+			args = args[1:]
+		}
+	}
+
+	var subpath []string
+
+	switch len(args) {
+	case 2:
+	case 3:
+		subpath = []string{conv.ToString(args[1])}
+	default:
+		return nil, fmt.Errorf("wrong number of args: want 2 or 3 (after any options), got %d", len(args))
+	}
+
+	alias, ok := args[0].(string)
+	if !ok {
+		return nil, fmt.Errorf("expected alias to be a string, got %T", args[0])
+	}
+
+	def := args[len(args)-1]
+
+	// undefined aliases are only read when they're URLs
+	if ifUndefined && !d.DatasourceExists(alias) {
+		if u, err := url.Parse(alias); err != nil || !u.IsAbs() {
+			return def, nil
+		}
+	}
+
+	out, err := d.Datasource(alias, subpath...)
+	if ifMissing && errors.Is(err, fs.ErrNotExist) {
+		return def, nil
+	}
+
+	return out, err
+}
+
+// DefineDatasource - registers the datasource, unless the alias is already
+// defined (i.e. with --datasource). Defining the same alias again with a
+// different URL is an error, since which definition applies would otherwise
+// depend on the order templates are rendered in. Datasources are defined for
+// all templates, even when called from a template with its own datasources
+// (in front matter).
 func (d *dataSourceFuncs) DefineDatasource(alias, value string) (string, error) {
 	if alias == "" {
 		return "", fmt.Errorf("datasource alias must be provided")
 	}
-	if d.DatasourceExists(alias) {
-		slog.DebugContext(d.ctx, "defineDatasource: ignoring attempt to redefine datasource", "alias", alias)
-		return "", nil
-	}
 	srcURL, err := urlhelpers.ParseSourceURL(value)
 	if err != nil {
 		return "", fmt.Errorf("parse datasource URL: %w", err)
 	}
 
-	d.sr.Register(alias, config.DataSource{URL: srcURL})
+	defs := d.defined()
+	defs.mu.Lock()
+	defer defs.mu.Unlock()
+
+	if prev, ok := defs.urls[alias]; ok {
+		if prev != srcURL.String() {
+			return "", fmt.Errorf("datasource %q is already defined with URL %q, can't redefine it with URL %q", alias, prev, srcURL)
+		}
+		return "", nil
+	}
+
+	if d.DatasourceExists(alias) {
+		slog.DebugContext(d.ctx, "defineDatasource: ignoring attempt to redefine datasource", "alias", alias)
+		return "", nil
+	}
+
+	defs.sr.Register(alias, config.DataSource{URL: srcURL})
+	defs.urls[alias] = srcURL.String()
+
 	return "", nil
 }
 
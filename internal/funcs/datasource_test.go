@@ -2,6 +2,7 @@ package funcs
 
 import (
 	"context"
+	"io/fs"
 	"net/url"
 	"runtime"
 	"strconv"
@@ -88,6 +89,86 @@ func TestDatasource(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestDatasourceOr(t *testing.T) {
+	fsys := datafs.WrapWdFS(fstest.MapFS{
+		"tmp/foo.json":    &fstest.MapFile{Data: []byte(`{"hello":"world"}`)},
+		"tmp/d/bar.json":  &fstest.MapFile{Data: []byte(`{"a":1}`)},
+		"tmp/broken.json": &fstest.MapFile{Data: []byte(`{`)},
+	})
+	ctx := datafs.ContextWithFSProvider(context.Background(), datafs.WrappedFSProvider(fsys, "file", ""))
+
+	uPath := "/tmp/"
+	if runtime.GOOS == osWindows {
+		uPath = "C:/tmp/"
+	}
+
+	reg := datafs.NewRegistry()
+	reg.Register("foo", config.DataSource{URL: &url.URL{Scheme: "file", Path: uPath + "foo.json"}})
+	reg.Register("dir", config.DataSource{URL: &url.URL{Scheme: "file", Path: uPath + "d/"}})
+	reg.Register("broken", config.DataSource{URL: &url.URL{Scheme: "file", Path: uPath + "broken.json"}})
+
+	d := &dataSourceFuncs{sr: datafs.NewSourceReader(reg), ctx: ctx}
+
+	out, err := d.DatasourceOr("foo", "default")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"hello": "world"}, out)
+
+	out, err = d.DatasourceOr("dir", "bar.json", "default")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"a": 1}, out)
+
+	// missing subpath
+	out, err = d.DatasourceOr("dir", "missing.json", "default")
+	require.NoError(t, err)
+	assert.Equal(t, "default", out)
+
+	// undefined datasource
+	out, err = d.DatasourceOr("undefined", map[string]interface{}{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{}, out)
+
+	// parse errors aren't hidden
+	_, err = d.DatasourceOr("broken", "default")
+	require.Error(t, err)
+
+	// with options, the default is only returned in the chosen cases
+	onlyMissing := map[string]interface{}{"undefined": false}
+	onlyUndefined := map[string]interface{}{"missing": "false"}
+
+	out, err = d.DatasourceOr(onlyMissing, "dir", "missing.json", "default")
+	require.NoError(t, err)
+	assert.Equal(t, "default", out)
+
+	_, err = d.DatasourceOr(onlyMissing, "undefined", "default")
+	require.ErrorContains(t, err, "undefined datasource 'undefined'")
+
+	out, err = d.DatasourceOr(onlyUndefined, "undefined", "default")
+	require.NoError(t, err)
+	assert.Equal(t, "default", out)
+
+	_, err = d.DatasourceOr(onlyUndefined, "dir", "missing.json", "default")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+
+	out, err = d.DatasourceOr(onlyUndefined, "foo", "default")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"hello": "world"}, out)
+
+	_, err = d.DatasourceOr(map[string]interface{}{"nope": true}, "foo", "default")
+	require.EqualError(t, err, `unknown datasourceOr option "nope"`)
+
+	_, err = d.DatasourceOr("foo")
+	require.Error(t, err)
+
+	_, err = d.DatasourceOr(onlyMissing, "foo")
+	require.Error(t, err)
+
+	_, err = d.DatasourceOr("foo", "a", "b", "c")
+	require.Error(t, err)
+
+	_, err = d.DatasourceOr(42, "default")
+	require.EqualError(t, err, "expected alias to be a string, got int")
+}
+
 func TestDatasourceReachable(t *testing.T) {
 	fname := "foo.json"
 	var uPath string
@@ -206,6 +287,35 @@ func TestDefineDatasource(t *testing.T) {
 	assert.Equal(t, "/otherdir/foo", s.URL.Path)
 }
 
+func TestDefineDatasource_Redefine(t *testing.T) {
+	reg := datafs.NewRegistry()
+	reg.Register("flag", config.DataSource{URL: &url.URL{Scheme: "file", Path: "/flag.json"}})
+	d := &dataSourceFuncs{sr: datafs.NewSourceReader(reg)}
+
+	_, err := d.DefineDatasource("data", "/foo.json")
+	require.NoError(t, err)
+
+	// the same URL again is fine
+	_, err = d.DefineDatasource("data", "/foo.json")
+	require.NoError(t, err)
+
+	_, err = d.DefineDatasource("data", "/bar.json")
+	require.ErrorContains(t, err, `datasource "data" is already defined`)
+
+	s, _ := reg.Lookup("data")
+	assert.Equal(t, "/foo.json", s.URL.Path)
+
+	// datasources defined with --datasource still take precedence
+	_, err = d.DefineDatasource("flag", "/default.json")
+	require.NoError(t, err)
+
+	_, err = d.DefineDatasource("flag", "/other.json")
+	require.NoError(t, err)
+
+	s, _ = reg.Lookup("flag")
+	assert.Equal(t, "/flag.json", s.URL.Path)
+}
+
 func TestListDatasources(t *testing.T) {
 	reg := datafs.NewRegistry()
 	reg.Register("foo", config.DataSource{})
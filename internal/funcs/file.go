@@ -2,8 +2,12 @@ package funcs
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"io/fs"
+	"path"
 	"path/filepath"
+	"strings"
 
 	osfs "github.com/hack-pad/hackpadfs/os"
 	"github.com/hairyhenderson/gomplate/v4/conv"
@@ -72,6 +76,25 @@ func (f *FileFuncs) ReadDir(path interface{}) ([]string, error) {
 	return names, nil
 }
 
+// ReadDirEntries - like ReadDir, but returns the entries' file info (as with
+// Stat) rather than just their names
+func (f *FileFuncs) ReadDirEntries(path interface{}) ([]fs.FileInfo, error) {
+	des, err := fs.ReadDir(f.fs, conv.ToString(path))
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]fs.FileInfo, len(des))
+	for i, de := range des {
+		infos[i], err = de.Info()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return infos, nil
+}
+
 // Walk -
 func (f *FileFuncs) Walk(path interface{}) ([]string, error) {
 	files := make([]string, 0)
@@ -91,6 +114,126 @@ func (f *FileFuncs) Walk(path interface{}) ([]string, error) {
 	return files, err
 }
 
+// WalkFiles - list the regular files beneath the path, relative to it,
+// optionally filtered by a glob
+func (f *FileFuncs) WalkFiles(args ...interface{}) ([]string, error) {
+	return f.walkRel(false, args...)
+}
+
+// WalkDirs - list the directories beneath the path, relative to it, optionally
+// filtered by a glob
+func (f *FileFuncs) WalkDirs(args ...interface{}) ([]string, error) {
+	return f.walkRel(true, args...)
+}
+
+// walkRel walks the tree rooted at the path (the last argument), returning
+// the relative paths of either all regular files or all directories beneath
+// it. If a glob is given (as the first argument), only paths matching it are
+// returned - globs containing a '/' are matched against the whole relative
+// path, and others against the base name.
+//
+// Symbolic links to regular files are included with files, but symbolic links
+// to directories are not followed, so link cycles can't cause endless walks.
+func (f *FileFuncs) walkRel(dirs bool, args ...interface{}) ([]string, error) {
+	var glob, root string
+	switch len(args) {
+	case 1:
+		root = conv.ToString(args[0])
+	case 2:
+		glob = conv.ToString(args[0])
+		root = conv.ToString(args[1])
+	default:
+		return nil, fmt.Errorf("wrong number of args: want 1 or 2, got %d", len(args))
+	}
+
+	if glob != "" {
+		// validate the pattern up-front, as path.Match only reports bad
+		// patterns when it gets far enough to notice
+		if _, err := path.Match(glob, ""); err != nil {
+			return nil, fmt.Errorf("invalid glob %q: %w", glob, err)
+		}
+	}
+
+	out := make([]string, 0)
+	err := fs.WalkDir(f.fs, root, func(subpath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if subpath == root {
+			return nil
+		}
+
+		isDir := d.IsDir()
+		if !isDir {
+			ok, err := f.isRegular(subpath, d)
+			if err != nil {
+				return err
+			}
+
+			// skip anything other than regular files, like devices, sockets,
+			// broken links, and links to directories
+			if !ok {
+				return nil
+			}
+		}
+
+		if isDir != dirs {
+			return nil
+		}
+
+		rel, err := filepath.Rel(filepath.FromSlash(root), filepath.FromSlash(subpath))
+		if err != nil {
+			return err
+		}
+
+		if glob != "" && !globMatch(glob, filepath.ToSlash(rel)) {
+			return nil
+		}
+
+		out = append(out, rel)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// isRegular returns true if the entry is a regular file, or a symbolic link
+// to one
+func (f *FileFuncs) isRegular(name string, d fs.DirEntry) (bool, error) {
+	if d.Type().IsRegular() {
+		return true, nil
+	}
+
+	if d.Type()&fs.ModeSymlink == 0 {
+		return false, nil
+	}
+
+	fi, err := fs.Stat(f.fs, name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return fi.Mode().IsRegular(), nil
+}
+
+func globMatch(glob, rel string) bool {
+	name := rel
+	if !strings.Contains(glob, "/") {
+		name = path.Base(rel)
+	}
+
+	// the pattern has already been validated
+	ok, _ := path.Match(glob, name)
+	return ok
+}
+
 // Write -
 func (f *FileFuncs) Write(path interface{}, data interface{}) (s string, err error) {
 	type byteser interface{ Bytes() []byte }
@@ -6,6 +6,7 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strconv"
 	"testing"
 	"testing/fstest"
@@ -86,6 +87,84 @@ func TestFileWalk(t *testing.T) {
 	assert.Equal(t, expectedPaths, actualPaths)
 }
 
+func TestFileWalkFilesDirs(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{
+		"tmp":                 &fstest.MapFile{Mode: fs.ModeDir | 0o777},
+		"tmp/a.tmpl":          &fstest.MapFile{Data: []byte("a")},
+		"tmp/b.txt":           &fstest.MapFile{Data: []byte("b")},
+		"tmp/sub":             &fstest.MapFile{Mode: fs.ModeDir | 0o777},
+		"tmp/sub/c.tmpl":      &fstest.MapFile{Data: []byte("c")},
+		"tmp/sub/deeper":      &fstest.MapFile{Mode: fs.ModeDir | 0o777},
+		"tmp/sub/deeper/d.md": &fstest.MapFile{Data: []byte("d")},
+		"tmp/empty":           &fstest.MapFile{Mode: fs.ModeDir | 0o777},
+	}
+
+	ff := &FileFuncs{fs: datafs.WrapWdFS(fsys)}
+	root := string(filepath.Separator) + "tmp"
+
+	files, err := ff.WalkFiles(root)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"a.tmpl",
+		"b.txt",
+		filepath.Join("sub", "c.tmpl"),
+		filepath.Join("sub", "deeper", "d.md"),
+	}, files)
+
+	files, err = ff.WalkFiles("*.tmpl", root)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a.tmpl", filepath.Join("sub", "c.tmpl")}, files)
+
+	files, err = ff.WalkFiles("sub/*", root)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join("sub", "c.tmpl")}, files)
+
+	dirs, err := ff.WalkDirs(root)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"empty", "sub", filepath.Join("sub", "deeper")}, dirs)
+
+	dirs, err = ff.WalkDirs("d*", root)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join("sub", "deeper")}, dirs)
+
+	_, err = ff.WalkFiles("[", root)
+	require.ErrorContains(t, err, "invalid glob")
+
+	_, err = ff.WalkFiles()
+	require.Error(t, err)
+
+	_, err = ff.WalkFiles(string(filepath.Separator) + "nonexistent")
+	require.Error(t, err)
+}
+
+func TestFileWalkFiles_Symlinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks require special privileges on Windows")
+	}
+
+	rootDir := tfs.NewDir(t, "gomplate-test",
+		tfs.WithFile("file", "hello"),
+		tfs.WithDir("sub", tfs.WithFile("other", "world")),
+		// a link to a file, a broken link, and a link cycle
+		tfs.WithSymlink("link", "file"),
+		tfs.WithSymlink("broken", "nonexistent"),
+		tfs.WithSymlink("sub/loop", ".."),
+	)
+	t.Cleanup(rootDir.Remove)
+
+	ff := &FileFuncs{fs: datafs.WrapWdFS(osfs.NewFS())}
+
+	files, err := ff.WalkFiles(rootDir.Path())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"file", "link", filepath.Join("sub", "other")}, files)
+
+	dirs, err := ff.WalkDirs(rootDir.Path())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sub"}, dirs)
+}
+
 func TestReadDir(t *testing.T) {
 	fsys := fs.FS(fstest.MapFS{
 		"tmp":          &fstest.MapFile{Mode: fs.ModeDir | 0o777},
@@ -111,6 +190,41 @@ func TestReadDir(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestReadDirEntries(t *testing.T) {
+	fsys := fs.FS(fstest.MapFS{
+		"tmp":          &fstest.MapFile{Mode: fs.ModeDir | 0o777},
+		"tmp/foo":      &fstest.MapFile{Data: []byte("foo")},
+		"tmp/bar":      &fstest.MapFile{Data: []byte("barbar")},
+		"tmp/qux":      &fstest.MapFile{Mode: fs.ModeDir | 0o777},
+		"tmp/qux/quux": &fstest.MapFile{Data: []byte("quux")},
+	})
+
+	fsys = datafs.WrapWdFS(fsys)
+
+	ff := &FileFuncs{
+		ctx: context.Background(),
+		fs:  fsys,
+	}
+
+	actual, err := ff.ReadDirEntries("/tmp")
+	require.NoError(t, err)
+	require.Len(t, actual, 3)
+
+	assert.Equal(t, "bar", actual[0].Name())
+	assert.False(t, actual[0].IsDir())
+	assert.Equal(t, int64(6), actual[0].Size())
+	assert.Equal(t, "foo", actual[1].Name())
+	assert.Equal(t, int64(3), actual[1].Size())
+	assert.Equal(t, "qux", actual[2].Name())
+	assert.True(t, actual[2].IsDir())
+
+	_, err = ff.ReadDirEntries("/tmp/foo")
+	require.Error(t, err)
+
+	_, err = ff.ReadDirEntries("/bogus")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+}
+
 func TestWrite(t *testing.T) {
 	oldwd, _ := os.Getwd()
 	defer os.Chdir(oldwd)
@@ -2,14 +2,23 @@ package funcs
 
 import (
 	"context"
+	"io/fs"
 	"path/filepath"
 
+	osfs "github.com/hack-pad/hackpadfs/os"
 	"github.com/hairyhenderson/gomplate/v4/conv"
+	"github.com/hairyhenderson/gomplate/v4/internal/datafs"
 )
 
 // CreateFilePathFuncs -
 func CreateFilePathFuncs(ctx context.Context) map[string]interface{} {
-	ns := &FilePathFuncs{ctx}
+	// Glob needs the same filesystem as the file functions
+	fsys, err := datafs.FSysForPath(ctx, "/")
+	if err != nil {
+		fsys = datafs.WrapWdFS(osfs.NewFS())
+	}
+
+	ns := &FilePathFuncs{ctx: ctx, fs: fsys}
 
 	return map[string]interface{}{
 		"filepath": func() interface{} { return ns },
@@ -19,6 +28,7 @@ func CreateFilePathFuncs(ctx context.Context) map[string]interface{} {
 // FilePathFuncs -
 type FilePathFuncs struct {
 	ctx context.Context
+	fs  fs.FS
 }
 
 // Base -
@@ -62,6 +72,22 @@ func (f *FilePathFuncs) Match(pattern, name interface{}) (matched bool, err erro
 	return filepath.Match(conv.ToString(pattern), conv.ToString(name))
 }
 
+// Glob - list the paths matching the pattern, relative to the working
+// directory unless the pattern is absolute
+func (f *FilePathFuncs) Glob(pattern interface{}) ([]string, error) {
+	matches, err := fs.Glob(f.fs, filepath.ToSlash(conv.ToString(pattern)))
+	if err != nil {
+		return nil, err
+	}
+
+	// fs.Glob always uses slash-separated paths, even on Windows
+	for i, m := range matches {
+		matches[i] = filepath.FromSlash(m)
+	}
+
+	return matches, nil
+}
+
 // Rel -
 func (f *FilePathFuncs) Rel(basepath, targpath interface{}) (string, error) {
 	return filepath.Rel(conv.ToString(basepath), conv.ToString(targpath))
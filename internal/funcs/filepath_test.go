@@ -2,10 +2,13 @@ package funcs
 
 import (
 	"context"
+	"path/filepath"
 	"strconv"
 	"testing"
+	"testing/fstest"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCreateFilePathFuncs(t *testing.T) {
@@ -24,3 +27,27 @@ func TestCreateFilePathFuncs(t *testing.T) {
 		})
 	}
 }
+
+func TestFilePathGlob(t *testing.T) {
+	t.Parallel()
+
+	f := &FilePathFuncs{fs: fstest.MapFS{
+		"conf.d/one.conf": {},
+		"conf.d/two.conf": {},
+		"conf.d/README":   {},
+	}}
+
+	matches, err := f.Glob("conf.d/*.conf")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join("conf.d", "one.conf"),
+		filepath.Join("conf.d", "two.conf"),
+	}, matches)
+
+	matches, err = f.Glob("*.conf")
+	require.NoError(t, err)
+	assert.Empty(t, matches)
+
+	_, err = f.Glob("[")
+	require.Error(t, err)
+}
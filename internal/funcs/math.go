@@ -386,3 +386,32 @@ func (f MathFuncs) Round(n interface{}) (interface{}, error) {
 
 	return gmath.Round(in), nil
 }
+
+// RoundTo - round to the given number of decimal places (or to tens, hundreds,
+// etc. when negative), rounding half away from zero
+func (f MathFuncs) RoundTo(places, n interface{}) (interface{}, error) {
+	p, err := conv.ToInt(places)
+	if err != nil {
+		return nil, fmt.Errorf("places must be an integer: %w", err)
+	}
+
+	in, err := conv.ToFloat64(n)
+	if err != nil {
+		return nil, fmt.Errorf("n must be a number: %w", err)
+	}
+
+	scale := gmath.Pow10(p)
+	out := gmath.Round(in*scale) / scale
+	if gmath.IsInf(in*scale, 0) || gmath.IsNaN(out) {
+		// too large (or small) to scale, or not a number at all
+		return in, nil
+	}
+
+	if p <= 0 {
+		return out, nil
+	}
+
+	// remove any floating-point noise (e.g. 0.30000000000000004) by parsing
+	// the shortest decimal representation with the desired places
+	return strconv.ParseFloat(strconv.FormatFloat(out, 'f', p, 64), 64)
+}
@@ -492,6 +492,53 @@ func TestRound(t *testing.T) {
 	})
 }
 
+func TestRoundTo(t *testing.T) {
+	t.Parallel()
+
+	m := MathFuncs{}
+	data := []struct {
+		p interface{}
+		n interface{}
+		a float64
+	}{
+		{2, 3.14159, 3.14},
+		{"2", "3.14159", 3.14},
+		{1, 0.1 + 0.2, 0.3},
+		{1, -2.45, -2.5},
+		{0, 2.5, 3},
+		{3, 42, 42},
+		{-1, 1234.5, 1230},
+		{-2, 1250, 1300},
+		{2, "Inf", gmath.Inf(1)},
+		{2, gmath.MaxFloat64, gmath.MaxFloat64},
+	}
+	for _, d := range data {
+		d := d
+		t.Run(fmt.Sprintf("%v,%v==%v", d.p, d.n, d.a), func(t *testing.T) {
+			t.Parallel()
+
+			actual, err := m.RoundTo(d.p, d.n)
+			require.NoError(t, err)
+			assert.Equal(t, d.a, actual)
+		})
+	}
+
+	// no floating-point noise when formatted
+	actual, err := m.RoundTo(2, 0.1+0.2+0.004)
+	require.NoError(t, err)
+	assert.Equal(t, "0.3", fmt.Sprint(actual))
+
+	t.Run("error cases", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := m.RoundTo(2, "foo")
+		require.Error(t, err)
+
+		_, err = m.RoundTo("foo", 1.5)
+		require.Error(t, err)
+	})
+}
+
 func TestAbs(t *testing.T) {
 	t.Parallel()
 
@@ -6,6 +6,7 @@ import (
 	"math/big"
 	stdnet "net"
 	"net/netip"
+	"strings"
 
 	"github.com/hairyhenderson/gomplate/v4/conv"
 	"github.com/hairyhenderson/gomplate/v4/internal/cidr"
@@ -30,32 +31,32 @@ type NetFuncs struct {
 
 // LookupIP -
 func (f NetFuncs) LookupIP(name interface{}) (string, error) {
-	return net.LookupIP(conv.ToString(name))
+	return net.LookupIPContext(f.ctx, conv.ToString(name))
 }
 
 // LookupIPs -
 func (f NetFuncs) LookupIPs(name interface{}) ([]string, error) {
-	return net.LookupIPs(conv.ToString(name))
+	return net.LookupIPsContext(f.ctx, conv.ToString(name))
 }
 
 // LookupCNAME -
 func (f NetFuncs) LookupCNAME(name interface{}) (string, error) {
-	return net.LookupCNAME(conv.ToString(name))
+	return net.LookupCNAMEContext(f.ctx, conv.ToString(name))
 }
 
 // LookupSRV -
 func (f NetFuncs) LookupSRV(name interface{}) (*stdnet.SRV, error) {
-	return net.LookupSRV(conv.ToString(name))
+	return net.LookupSRVContext(f.ctx, conv.ToString(name))
 }
 
 // LookupSRVs -
 func (f NetFuncs) LookupSRVs(name interface{}) ([]*stdnet.SRV, error) {
-	return net.LookupSRVs(conv.ToString(name))
+	return net.LookupSRVsContext(f.ctx, conv.ToString(name))
 }
 
 // LookupTXT -
 func (f NetFuncs) LookupTXT(name interface{}) ([]string, error) {
-	return net.LookupTXT(conv.ToString(name))
+	return net.LookupTXTContext(f.ctx, conv.ToString(name))
 }
 
 // ParseIP -
@@ -87,6 +88,53 @@ func (f NetFuncs) ParseAddr(ip interface{}) (netip.Addr, error) {
 	return netip.ParseAddr(conv.ToString(ip))
 }
 
+// ParseMAC - parses a MAC address in any of the formats supported by Go's
+// net.ParseMAC, or as a plain string of hex digits, and returns it in the
+// canonical colon-separated lowercase form
+func (f NetFuncs) ParseMAC(mac interface{}) (string, error) {
+	s := strings.TrimSpace(conv.ToString(mac))
+
+	// insert separators into plain hex strings (e.g. "0011223344ff")
+	if (len(s) == 12 || len(s) == 16) && isHex(s) {
+		parts := make([]string, 0, len(s)/2)
+		for i := 0; i < len(s); i += 2 {
+			parts = append(parts, s[i:i+2])
+		}
+		s = strings.Join(parts, ":")
+	}
+
+	hw, err := stdnet.ParseMAC(s)
+	if err != nil {
+		return "", fmt.Errorf("invalid MAC address %q", conv.ToString(mac))
+	}
+
+	return hw.String(), nil
+}
+
+func isHex(s string) bool {
+	for _, r := range s {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", r) {
+			return false
+		}
+	}
+	return true
+}
+
+// Interfaces -
+func (f NetFuncs) Interfaces() ([]net.Interface, error) {
+	return net.Interfaces()
+}
+
+// FirstPrivateIP -
+func (f NetFuncs) FirstPrivateIP() (string, error) {
+	return net.FirstPrivateIP()
+}
+
+// FirstGlobalIP -
+func (f NetFuncs) FirstGlobalIP() (string, error) {
+	return net.FirstGlobalIP()
+}
+
 // ParsePrefix -
 func (f NetFuncs) ParsePrefix(ipprefix interface{}) (netip.Prefix, error) {
 	return netip.ParsePrefix(conv.ToString(ipprefix))
@@ -132,16 +180,37 @@ func (f *NetFuncs) CIDRHost(hostnum interface{}, prefix interface{}) (netip.Addr
 		return netip.Addr{}, err
 	}
 
-	n, err := conv.ToInt64(hostnum)
+	n, err := toBigInt(hostnum)
 	if err != nil {
 		return netip.Addr{}, fmt.Errorf("expected a number: %w", err)
 	}
 
-	ip, err := cidr.HostBig(network, big.NewInt(n))
+	ip, err := cidr.HostBig(network, n)
 
 	return ip, err
 }
 
+// toBigInt converts v to a big.Int - decimal strings are parsed directly, so
+// that numbers too large for an int64 (as IPv6 host numbers can be) can be
+// given
+func toBigInt(v interface{}) (*big.Int, error) {
+	switch n := v.(type) {
+	case *big.Int:
+		return n, nil
+	case string:
+		if b, ok := new(big.Int).SetString(strings.TrimSpace(n), 10); ok {
+			return b, nil
+		}
+	}
+
+	i, err := conv.ToInt64(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return big.NewInt(i), nil
+}
+
 // CIDRNetmask -
 // Experimental!
 func (f *NetFuncs) CIDRNetmask(prefix interface{}) (netip.Addr, error) {
@@ -168,14 +237,22 @@ func (f *NetFuncs) CIDRNetmask(prefix interface{}) (netip.Addr, error) {
 	return m, nil
 }
 
+// maxCIDRSubnets is the most subnets CIDRSubnets will return, so that a large
+// newbits or count can't exhaust memory
+const maxCIDRSubnets = 1 << 16
+
 // CIDRSubnets -
 // Experimental!
-func (f *NetFuncs) CIDRSubnets(newbits interface{}, prefix interface{}) ([]netip.Prefix, error) {
+func (f *NetFuncs) CIDRSubnets(newbits interface{}, args ...interface{}) ([]netip.Prefix, error) {
 	if err := checkExperimental(f.ctx); err != nil {
 		return nil, err
 	}
 
-	network, err := f.parseNetipPrefix(prefix)
+	if len(args) < 1 || len(args) > 2 {
+		return nil, fmt.Errorf("wrong number of args: want 2 or 3, got %d", len(args)+1)
+	}
+
+	network, err := f.parseNetipPrefix(args[len(args)-1])
 	if err != nil {
 		return nil, err
 	}
@@ -189,9 +266,44 @@ func (f *NetFuncs) CIDRSubnets(newbits interface{}, prefix interface{}) ([]netip
 		return nil, fmt.Errorf("must extend prefix by at least one bit")
 	}
 
-	maxNetNum := int64(1 << uint64(nBits))
-	retValues := make([]netip.Prefix, maxNetNum)
-	for i := int64(0); i < maxNetNum; i++ {
+	if network.Bits()+nBits > network.Addr().BitLen() {
+		return nil, fmt.Errorf("insufficient address space to extend prefix of %d by %d", network.Bits(), nBits)
+	}
+
+	// the number of subnets available
+	available := new(big.Int).Lsh(big.NewInt(1), uint(nBits))
+
+	var count int64
+	if len(args) == 2 {
+		count, err = conv.ToInt64(args[0])
+		if err != nil {
+			return nil, fmt.Errorf("count must be a number: %w", err)
+		}
+
+		if count < 0 {
+			return nil, fmt.Errorf("count must not be negative, got %d", count)
+		}
+
+		if available.Cmp(big.NewInt(count)) < 0 {
+			return nil, fmt.Errorf("count %d is out of range: prefix of %d extended by %d only has %s subnets",
+				count, network.Bits(), nBits, available)
+		}
+
+		if count > maxCIDRSubnets {
+			return nil, fmt.Errorf("count %d is too large: at most %d subnets may be returned", count, maxCIDRSubnets)
+		}
+	} else {
+		// every subnet is returned, so the number must be kept reasonable
+		if available.Cmp(big.NewInt(maxCIDRSubnets)) > 0 {
+			return nil, fmt.Errorf("prefix of %d extended by %d has %s subnets, but at most %d may be returned: give a count to return fewer",
+				network.Bits(), nBits, available, maxCIDRSubnets)
+		}
+
+		count = available.Int64()
+	}
+
+	retValues := make([]netip.Prefix, count)
+	for i := int64(0); i < count; i++ {
 		subnet, err := cidr.SubnetBig(network, nBits, big.NewInt(i))
 		if err != nil {
 			return nil, err
@@ -33,7 +33,7 @@ func TestCreateNetFuncs(t *testing.T) {
 func TestNetLookupIP(t *testing.T) {
 	t.Parallel()
 
-	n := NetFuncs{}
+	n := NetFuncs{ctx: context.Background()}
 	assert.Equal(t, "127.0.0.1", must(n.LookupIP("localhost")))
 }
 
@@ -101,6 +101,40 @@ func TestParseAddr(t *testing.T) {
 	}), ip)
 }
 
+func TestParseMAC(t *testing.T) {
+	t.Parallel()
+
+	n := testNetNS()
+
+	testdata := []struct {
+		in       interface{}
+		expected string
+	}{
+		{"00:11:22:aa:bb:cc", "00:11:22:aa:bb:cc"},
+		{"00-11-22-AA-BB-CC", "00:11:22:aa:bb:cc"},
+		{"0011.22aa.bbcc", "00:11:22:aa:bb:cc"},
+		{"001122AABBCC", "00:11:22:aa:bb:cc"},
+		{" 00:11:22:aa:bb:cc\n", "00:11:22:aa:bb:cc"},
+		{"0011223344556677", "00:11:22:33:44:55:66:77"},
+		{"00:11:22:33:44:55:66:77", "00:11:22:33:44:55:66:77"},
+	}
+
+	for _, d := range testdata {
+		out, err := n.ParseMAC(d.in)
+		require.NoError(t, err)
+		assert.Equal(t, d.expected, out)
+	}
+
+	_, err := n.ParseMAC("00:11:22:aa:bb")
+	require.EqualError(t, err, `invalid MAC address "00:11:22:aa:bb"`)
+
+	_, err = n.ParseMAC("00112233445g")
+	require.EqualError(t, err, `invalid MAC address "00112233445g"`)
+
+	_, err = n.ParseMAC("")
+	require.EqualError(t, err, `invalid MAC address ""`)
+}
+
 func TestParsePrefix(t *testing.T) {
 	t.Parallel()
 
@@ -185,6 +219,25 @@ func TestCIDRHost(t *testing.T) {
 	ip, err = n.CIDRHost(34, prefix)
 	require.NoError(t, err)
 	assert.Equal(t, "fd00:fd12:3456:7890::22", ip.String())
+
+	// negative numbers count back from the end
+	ip, err = n.CIDRHost(-2, "10.0.0.0/24")
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.254", ip.String())
+
+	// IPv6 host numbers can be too large for an int64
+	ip, err = n.CIDRHost("18446744073709551616", "fd00::/48")
+	require.NoError(t, err)
+	assert.Equal(t, "fd00:0:0:1::", ip.String())
+
+	_, err = n.CIDRHost(256, "10.0.0.0/24")
+	require.EqualError(t, err, "prefix of 24 does not accommodate a host numbered 256")
+
+	_, err = n.CIDRHost("18446744073709551616", "10.0.0.0/8")
+	require.EqualError(t, err, "prefix of 8 does not accommodate a host numbered 18446744073709551616")
+
+	_, err = n.CIDRHost("foo", "10.0.0.0/8")
+	require.ErrorContains(t, err, "expected a number")
 }
 
 func TestCIDRNetmask(t *testing.T) {
@@ -214,6 +267,55 @@ func TestCIDRSubnets(t *testing.T) {
 	assert.Equal(t, "10.0.64.0/18", subnets[1].String())
 	assert.Equal(t, "10.0.128.0/18", subnets[2].String())
 	assert.Equal(t, "10.0.192.0/18", subnets[3].String())
+
+	// with a count, only the first subnets are returned
+	subnets, err = n.CIDRSubnets(8, 3, network)
+	require.NoError(t, err)
+	require.Len(t, subnets, 3)
+	assert.Equal(t, "10.0.2.0/24", subnets[2].String())
+
+	subnets, err = n.CIDRSubnets(64, "2", "fd00::/48")
+	require.NoError(t, err)
+	assert.Equal(t, []netip.Prefix{
+		netip.MustParsePrefix("fd00::/112"),
+		netip.MustParsePrefix("fd00::1:0/112"),
+	}, subnets)
+
+	subnets, err = n.CIDRSubnets(2, 0, network)
+	require.NoError(t, err)
+	assert.Empty(t, subnets)
+
+	_, err = n.CIDRSubnets(2, 5, network)
+	require.EqualError(t, err, "count 5 is out of range: prefix of 16 extended by 2 only has 4 subnets")
+
+	_, err = n.CIDRSubnets(2, -1, network)
+	require.EqualError(t, err, "count must not be negative, got -1")
+
+	_, err = n.CIDRSubnets(17, network)
+	require.EqualError(t, err, "insufficient address space to extend prefix of 16 by 17")
+
+	_, err = n.CIDRSubnets(40, "fd00::/48")
+	require.EqualError(t, err, "prefix of 48 extended by 40 has 1099511627776 subnets, but at most 65536 may be returned: give a count to return fewer")
+
+	_, err = n.CIDRSubnets(32, "fd00::/48")
+	require.EqualError(t, err, "prefix of 48 extended by 32 has 4294967296 subnets, but at most 65536 may be returned: give a count to return fewer")
+
+	subnets, err = n.CIDRSubnets(16, "fd00::/48")
+	require.NoError(t, err)
+	assert.Len(t, subnets, 65536)
+
+	_, err = n.CIDRSubnets(32, 1<<40, "fd00::/48")
+	require.EqualError(t, err, "count 1099511627776 is out of range: prefix of 48 extended by 32 only has 4294967296 subnets")
+
+	_, err = n.CIDRSubnets(64, 1<<40, "fd00::/48")
+	require.EqualError(t, err, "count 1099511627776 is too large: at most 65536 subnets may be returned")
+
+	subnets, err = n.CIDRSubnets(64, 1<<16, "fd00::/48")
+	require.NoError(t, err)
+	assert.Len(t, subnets, 65536)
+
+	_, err = n.CIDRSubnets(2)
+	require.EqualError(t, err, "wrong number of args: want 2 or 3, got 1")
 }
 
 func TestCIDRSubnetSizes(t *testing.T) {
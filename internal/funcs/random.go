@@ -61,10 +61,6 @@ func (RandomFuncs) String(count interface{}, args ...interface{}) (string, error
 		return "", fmt.Errorf("count must be an integer: %w", err)
 	}
 
-	if c == 0 {
-		return "", fmt.Errorf("count must be greater than 0")
-	}
-
 	m := ""
 	switch len(args) {
 	case 0:
@@ -131,12 +127,47 @@ func toCodePoints(l, u string) (rune, rune, error) {
 }
 
 // Item -
-func (RandomFuncs) Item(items interface{}) (interface{}, error) {
-	i, err := iconv.InterfaceSlice(items)
+func (RandomFuncs) Item(args ...interface{}) (interface{}, error) {
+	seed, items, err := seedAndItems(args)
+	if err != nil {
+		return nil, err
+	}
+
+	return random.Item(items, seed...)
+}
+
+// Shuffle -
+func (RandomFuncs) Shuffle(args ...interface{}) ([]interface{}, error) {
+	seed, items, err := seedAndItems(args)
 	if err != nil {
 		return nil, err
 	}
-	return random.Item(i)
+
+	return random.Shuffle(items, seed...)
+}
+
+// seedAndItems parses the arguments to Item and Shuffle - the input slice is
+// always the last argument, and may be preceded by a seed
+func seedAndItems(args []interface{}) (seed []int64, items []interface{}, err error) {
+	switch len(args) {
+	case 1:
+	case 2:
+		s, err := conv.ToInt64(args[0])
+		if err != nil {
+			return nil, nil, fmt.Errorf("seed must be an integer: %w", err)
+		}
+
+		seed = []int64{s}
+	default:
+		return nil, nil, fmt.Errorf("wrong number of args: wanted 1 or 2, got %d", len(args))
+	}
+
+	items, err = iconv.InterfaceSlice(args[len(args)-1])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return seed, items, nil
 }
 
 // Number -
@@ -123,7 +123,18 @@ func TestString(t *testing.T) {
 	require.NoError(t, err)
 	assert.Len(t, out, 42)
 
-	_, err = f.String(0)
+	out, err = f.String(0)
+	require.NoError(t, err)
+	assert.Empty(t, out)
+
+	_, err = f.String(-1)
+	require.Error(t, err)
+
+	out, err = f.String(32, "hex")
+	require.NoError(t, err)
+	assert.Regexp(t, "^[0-9a-f]{32}$", out)
+
+	_, err = f.String(8, "nomatch")
 	require.Error(t, err)
 
 	out, err = f.String(8, "[a-z]")
@@ -177,6 +188,40 @@ func TestItem(t *testing.T) {
 	assert.NotEqual(t, "barbarbarbarbarbarbarbarbarbar", got)
 }
 
+func TestShuffle(t *testing.T) {
+	t.Parallel()
+
+	f := RandomFuncs{}
+	_, err := f.Shuffle([]string{})
+	require.Error(t, err)
+
+	_, err = f.Shuffle("foo")
+	require.Error(t, err)
+
+	_, err = f.Shuffle("notaseed", []string{"foo"})
+	require.Error(t, err)
+
+	_, err = f.Shuffle()
+	require.Error(t, err)
+
+	in := []string{"a", "b", "c", "d", "e"}
+	out, err := f.Shuffle(in)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []interface{}{"a", "b", "c", "d", "e"}, out)
+
+	seeded, err := f.Shuffle(7, in)
+	require.NoError(t, err)
+	out, err = f.Shuffle("7", in)
+	require.NoError(t, err)
+	assert.Equal(t, seeded, out)
+
+	i, err := f.Item(7, in)
+	require.NoError(t, err)
+	j, err := f.Item(7, in)
+	require.NoError(t, err)
+	assert.Equal(t, i, j)
+}
+
 func TestNumber(t *testing.T) {
 	t.Parallel()
 
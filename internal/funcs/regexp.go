@@ -54,6 +54,11 @@ func (ReFuncs) FindAll(args ...interface{}) ([]string, error) {
 	return regexp.FindAll(re, n, input)
 }
 
+// FindNamed -
+func (ReFuncs) FindNamed(re, input interface{}) (map[string]string, error) {
+	return regexp.FindNamed(conv.ToString(re), conv.ToString(input))
+}
+
 // Match -
 func (ReFuncs) Match(re, input interface{}) (bool, error) {
 	return regexp.Match(conv.ToString(re), conv.ToString(input))
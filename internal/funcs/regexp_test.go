@@ -55,7 +55,7 @@ func TestFind(t *testing.T) {
 	assert.Equal(t, "foo", f)
 
 	_, err = re.Find(`[a-`, "")
-	require.Error(t, err)
+	require.EqualError(t, err, `error compiling expression "[a-": missing closing ]`)
 
 	f, err = re.Find("4", 42)
 	require.NoError(t, err)
@@ -66,6 +66,18 @@ func TestFind(t *testing.T) {
 	assert.Equal(t, "", f)
 }
 
+func TestFindNamed(t *testing.T) {
+	t.Parallel()
+
+	re := &ReFuncs{}
+	m, err := re.FindNamed(`(?P<key>\w+)=(?P<value>\w+)`, "a=1 b=2")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"key": "a", "value": "1"}, m)
+
+	_, err = re.FindNamed(`[a-`, "")
+	require.Error(t, err)
+}
+
 func TestFindAll(t *testing.T) {
 	t.Parallel()
 
@@ -9,6 +9,7 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
 	"unicode/utf8"
 
@@ -35,7 +36,9 @@ func CreateStringFuncs(ctx context.Context) map[string]interface{} {
 	f["toLower"] = ns.ToLower
 	f["trimSpace"] = ns.TrimSpace
 	f["indent"] = ns.Indent
+	f["nindent"] = ns.Nindent
 	f["quote"] = ns.Quote
+	f["unquote"] = ns.Unquote
 	f["shellQuote"] = ns.ShellQuote
 	f["squote"] = ns.Squote
 
@@ -155,6 +158,11 @@ func (StringFuncs) ReplaceAll(old, new string, s interface{}) string {
 	return strings.ReplaceAll(conv.ToString(s), old, new)
 }
 
+// ReplaceN -
+func (StringFuncs) ReplaceN(old, new string, n int, s interface{}) string {
+	return strings.Replace(conv.ToString(s), old, new, n)
+}
+
 // Contains -
 func (StringFuncs) Contains(substr string, s interface{}) bool {
 	return strings.Contains(conv.ToString(s), substr)
@@ -305,6 +313,16 @@ func (StringFuncs) Indent(args ...interface{}) (string, error) {
 	return gompstrings.Indent(width, indent, input)
 }
 
+// Nindent - like Indent, but with a leading newline
+func (f StringFuncs) Nindent(args ...interface{}) (string, error) {
+	out, err := f.Indent(args...)
+	if err != nil {
+		return "", err
+	}
+
+	return "\n" + out, nil
+}
+
 // Slug -
 func (StringFuncs) Slug(in interface{}) string {
 	return slug.Make(conv.ToString(in))
@@ -315,6 +333,18 @@ func (StringFuncs) Quote(in interface{}) string {
 	return fmt.Sprintf("%q", conv.ToString(in))
 }
 
+// Unquote -
+func (StringFuncs) Unquote(in interface{}) (string, error) {
+	s := conv.ToString(in)
+
+	out, err := strconv.Unquote(s)
+	if err != nil {
+		return "", fmt.Errorf("invalid quoted string %s: %w", s, err)
+	}
+
+	return out, nil
+}
+
 // ShellQuote -
 func (StringFuncs) ShellQuote(in interface{}) string {
 	val := reflect.ValueOf(in)
@@ -355,6 +385,16 @@ func (StringFuncs) KebabCase(in interface{}) (string, error) {
 	return gompstrings.KebabCase(conv.ToString(in)), nil
 }
 
+// PascalCase -
+func (StringFuncs) PascalCase(in interface{}) (string, error) {
+	return gompstrings.PascalCase(conv.ToString(in)), nil
+}
+
+// ScreamingSnakeCase -
+func (StringFuncs) ScreamingSnakeCase(in interface{}) (string, error) {
+	return gompstrings.ScreamingSnakeCase(conv.ToString(in)), nil
+}
+
 // WordWrap -
 func (StringFuncs) WordWrap(args ...interface{}) (string, error) {
 	if len(args) == 0 || len(args) > 3 {
@@ -367,6 +407,12 @@ func (StringFuncs) WordWrap(args ...interface{}) (string, error) {
 		switch a := (args[0]).(type) {
 		case string:
 			opts.LBSeq = a
+		case map[string]interface{}:
+			var err error
+			opts, err = wordWrapOpts(a)
+			if err != nil {
+				return "", err
+			}
 		default:
 			n, err := conv.ToInt(args[0])
 			if err != nil {
@@ -390,6 +436,36 @@ func (StringFuncs) WordWrap(args ...interface{}) (string, error) {
 	return gompstrings.WordWrap(in, opts), nil
 }
 
+// wordWrapOpts converts a map of options (width, lbseq, prefix, hard) to
+// WordWrapOpts
+func wordWrapOpts(m map[string]interface{}) (gompstrings.WordWrapOpts, error) {
+	opts := gompstrings.WordWrapOpts{}
+	for k, v := range m {
+		switch k {
+		case "width":
+			n, err := conv.ToInt(v)
+			if err != nil {
+				return opts, fmt.Errorf("expected width to be a number: %w", err)
+			}
+			if n < 0 {
+				return opts, fmt.Errorf("width must be >= 0, got %d", n)
+			}
+
+			opts.Width = uint(n)
+		case "lbseq":
+			opts.LBSeq = conv.ToString(v)
+		case "prefix":
+			opts.Prefix = conv.ToString(v)
+		case "hard":
+			opts.HardWrap = conv.ToBool(v)
+		default:
+			return opts, fmt.Errorf("unknown word wrap option %q", k)
+		}
+	}
+
+	return opts, nil
+}
+
 // RuneCount - like len(s), but for runes
 func (StringFuncs) RuneCount(args ...interface{}) (int, error) {
 	s := ""
@@ -37,6 +37,18 @@ func TestReplaceAll(t *testing.T) {
 		sf.ReplaceAll("Orig", "Replaced", "OrigOrig"))
 }
 
+func TestReplaceN(t *testing.T) {
+	t.Parallel()
+
+	sf := &StringFuncs{}
+
+	assert.Equal(t, "key: value=with=equals", sf.ReplaceN("=", ": ", 1, "key=value=with=equals"))
+	assert.Equal(t, "a-b-c.d", sf.ReplaceN(".", "-", 2, "a.b.c.d"))
+	assert.Equal(t, "a-b-c-d", sf.ReplaceN(".", "-", -1, "a.b.c.d"))
+	assert.Equal(t, "a.b.c.d", sf.ReplaceN(".", "-", 0, "a.b.c.d"))
+	assert.Equal(t, "4-2", sf.ReplaceN("2", "-2", 1, 42))
+}
+
 func TestIndent(t *testing.T) {
 	t.Parallel()
 
@@ -59,6 +71,26 @@ func TestIndent(t *testing.T) {
 	}
 }
 
+func TestNindent(t *testing.T) {
+	t.Parallel()
+
+	sf := &StringFuncs{}
+
+	out, err := sf.Nindent(2, "foo\nbar\n")
+	require.NoError(t, err)
+	assert.Equal(t, "\n  foo\n  bar\n", out)
+
+	out, err = sf.Nindent(1, "-", "foo")
+	require.NoError(t, err)
+	assert.Equal(t, "\n-foo", out)
+
+	_, err = sf.Nindent(0, "foo")
+	require.Error(t, err)
+
+	_, err = sf.Nindent()
+	require.Error(t, err)
+}
+
 func TestTrimPrefix(t *testing.T) {
 	t.Parallel()
 
@@ -182,6 +214,41 @@ func TestQuote(t *testing.T) {
 	}
 }
 
+func TestUnquote(t *testing.T) {
+	t.Parallel()
+
+	sf := &StringFuncs{}
+	testdata := []struct {
+		in  interface{}
+		out string
+	}{
+		{`""`, ``},
+		{`"foo"`, `foo`},
+		{`"hello \"world\""`, `hello "world"`},
+		{`"tab\there\n"`, "tab\there\n"},
+		{"`raw \\n`", `raw \n`},
+		{`'x'`, `x`},
+	}
+
+	for _, d := range testdata {
+		out, err := sf.Unquote(d.in)
+		require.NoError(t, err)
+		assert.Equal(t, d.out, out)
+	}
+
+	// round-trips with Quote
+	for _, in := range []string{"", "it's \"quoted\"", "line\nbreak", "ünïcödé \x00"} {
+		out, err := sf.Unquote(sf.Quote(in))
+		require.NoError(t, err)
+		assert.Equal(t, in, out)
+	}
+
+	for _, in := range []interface{}{`foo`, `"unterminated`, `'too long'`, 42} {
+		_, err := sf.Unquote(in)
+		require.Error(t, err)
+	}
+}
+
 func TestShellQuote(t *testing.T) {
 	t.Parallel()
 
@@ -257,6 +324,45 @@ func TestRuneCount(t *testing.T) {
 	assert.Equal(t, 5, n)
 }
 
+func TestWordWrap(t *testing.T) {
+	t.Parallel()
+
+	sf := &StringFuncs{}
+
+	testdata := []struct {
+		out  string
+		args []interface{}
+	}{
+		{"foo bar", []interface{}{"foo bar"}},
+		{"foo\nbar", []interface{}{3, "foo bar"}},
+		{"foo \\\nbar", []interface{}{3, " \\\n", "foo bar"}},
+		{"# foo\n# bar", []interface{}{
+			map[string]interface{}{"width": 5, "prefix": "# "}, "foo bar",
+		}},
+		{"foo\nbar\nbaz", []interface{}{
+			map[string]interface{}{"width": "3", "hard": true}, "foobarbaz",
+		}},
+		{"foo|bar", []interface{}{
+			map[string]interface{}{"width": 3, "lbseq": "|"}, "foo bar",
+		}},
+	}
+
+	for _, d := range testdata {
+		out, err := sf.WordWrap(d.args...)
+		require.NoError(t, err)
+		assert.Equal(t, d.out, out)
+	}
+
+	_, err := sf.WordWrap()
+	require.Error(t, err)
+
+	_, err = sf.WordWrap(map[string]interface{}{"bogus": 1}, "foo")
+	require.Error(t, err)
+
+	_, err = sf.WordWrap(map[string]interface{}{"width": -1}, "foo")
+	require.Error(t, err)
+}
+
 func TestTrimLeft(t *testing.T) {
 	t.Parallel()
 
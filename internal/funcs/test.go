@@ -30,13 +30,26 @@ type TestFuncs struct {
 	ctx context.Context
 }
 
-// Assert -
+// Assert - the message can be given before the value (for pipelines), or
+// after a boolean value
 func (TestFuncs) Assert(args ...interface{}) (string, error) {
+	if len(args) == 0 {
+		return "", fmt.Errorf("wrong number of args: want 1 or 2, got 0")
+	}
+
 	input := conv.ToBool(args[len(args)-1])
 	switch len(args) {
 	case 1:
 		return test.Assert(input, "")
 	case 2:
+		if b, ok := args[0].(bool); ok {
+			message, ok := args[1].(string)
+			if !ok {
+				return "", fmt.Errorf("at <2>: expected string; found %T", args[1])
+			}
+			return test.Assert(b, message)
+		}
+
 		message, ok := args[0].(string)
 		if !ok {
 			return "", fmt.Errorf("at <1>: expected string; found %T", args[0])
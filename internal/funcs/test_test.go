@@ -41,6 +41,19 @@ func TestAssert(t *testing.T) {
 
 	_, err = f.Assert("foo", "false")
 	require.EqualError(t, err, "assertion failed: foo")
+
+	// the message can also follow a boolean
+	_, err = f.Assert(true, "foo")
+	require.NoError(t, err)
+
+	_, err = f.Assert(false, "out of range")
+	require.EqualError(t, err, "assertion failed: out of range")
+
+	_, err = f.Assert(false, 42)
+	require.EqualError(t, err, "at <2>: expected string; found int")
+
+	_, err = f.Assert()
+	require.Error(t, err)
 }
 
 func TestRequired(t *testing.T) {
@@ -2,6 +2,7 @@ package funcs
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -58,14 +59,56 @@ type TimeFuncs struct {
 	StampNano   string
 }
 
-// ZoneName - return the local system's time zone's name
-func (TimeFuncs) ZoneName() string {
-	return time.ZoneName()
+// ZoneName - return the local system's time zone's name, or the name of the
+// given time's zone, or the current name of the named zone (e.g. "EST" for
+// "America/New_York")
+func (TimeFuncs) ZoneName(args ...interface{}) (string, error) {
+	if len(args) == 0 {
+		return time.ZoneName(), nil
+	}
+
+	t, err := zoneTime(args...)
+	if err != nil {
+		return "", err
+	}
+
+	name, _ := t.Zone()
+	return name, nil
 }
 
-// ZoneOffset - return the local system's time zone's name
-func (TimeFuncs) ZoneOffset() int {
-	return time.ZoneOffset()
+// ZoneOffset - return the local system's time zone offset, or the offset of
+// the given time's zone, or the current offset of the named zone, in seconds
+// east of UTC
+func (TimeFuncs) ZoneOffset(args ...interface{}) (int, error) {
+	if len(args) == 0 {
+		return time.ZoneOffset(), nil
+	}
+
+	t, err := zoneTime(args...)
+	if err != nil {
+		return 0, err
+	}
+
+	_, offset := t.Zone()
+	return offset, nil
+}
+
+// zoneTime returns the given time, or the current time in the named zone
+func zoneTime(args ...interface{}) (gotime.Time, error) {
+	if len(args) != 1 {
+		return gotime.Time{}, fmt.Errorf("wrong number of args: wanted 0 or 1, got %d", len(args))
+	}
+
+	if t, ok := args[0].(gotime.Time); ok {
+		return t, nil
+	}
+
+	loc, err := loadLocation(conv.ToString(args[0]))
+	if err != nil {
+		return gotime.Time{}, err
+	}
+
+	return gotime.Now().In(loc), nil
 }
 
 // Parse -
@@ -81,16 +124,46 @@ func (f TimeFuncs) ParseLocal(layout string, value interface{}) (gotime.Time, er
 
 // ParseInLocation -
 func (TimeFuncs) ParseInLocation(layout, location string, value interface{}) (gotime.Time, error) {
-	loc, err := gotime.LoadLocation(location)
+	loc, err := loadLocation(location)
 	if err != nil {
 		return gotime.Time{}, err
 	}
 	return gotime.ParseInLocation(layout, conv.ToString(value), loc)
 }
 
-// Now -
-func (TimeFuncs) Now() gotime.Time {
-	return gotime.Now()
+// In - convert the time to the named zone
+func (TimeFuncs) In(location string, t gotime.Time) (gotime.Time, error) {
+	loc, err := loadLocation(location)
+	if err != nil {
+		return gotime.Time{}, err
+	}
+	return t.In(loc), nil
+}
+
+// Now - return the current time, optionally in the named zone
+func (TimeFuncs) Now(location ...string) (gotime.Time, error) {
+	switch len(location) {
+	case 0:
+		return gotime.Now(), nil
+	case 1:
+		loc, err := loadLocation(location[0])
+		if err != nil {
+			return gotime.Time{}, err
+		}
+		return gotime.Now().In(loc), nil
+	default:
+		return gotime.Time{}, fmt.Errorf("wrong number of args: wanted 0 or 1, got %d", len(location))
+	}
+}
+
+// loadLocation loads the named zone (e.g. "America/New_York", "UTC", or
+// "Local")
+func loadLocation(name string) (*gotime.Location, error) {
+	loc, err := gotime.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time zone %q: %w", name, err)
+	}
+	return loc, nil
 }
 
 // Unix - convert UNIX time (in seconds since the UNIX epoch) into a time.Time for further processing
@@ -165,17 +238,79 @@ func (TimeFuncs) Hour(n interface{}) (gotime.Duration, error) {
 
 // ParseDuration -
 func (TimeFuncs) ParseDuration(n interface{}) (gotime.Duration, error) {
-	return gotime.ParseDuration(conv.ToString(n))
+	return toDuration(n)
+}
+
+// Since - the time elapsed since the given time (or RFC 3339 timestamp)
+func (TimeFuncs) Since(n interface{}) (gotime.Duration, error) {
+	t, err := toTime(n)
+	if err != nil {
+		return 0, err
+	}
+
+	return gotime.Since(t), nil
+}
+
+// Until - the duration until the given time (or RFC 3339 timestamp)
+func (TimeFuncs) Until(n interface{}) (gotime.Duration, error) {
+	t, err := toTime(n)
+	if err != nil {
+		return 0, err
+	}
+
+	return gotime.Until(t), nil
+}
+
+// Add - add the duration (or duration string) to the time (or RFC 3339
+// timestamp). The arguments can be given in either order, so both
+// `time.Add "1h" $t` (or `$t | time.Add "1h"`) and `time.Add $t "1h"` work.
+func (TimeFuncs) Add(d, t interface{}) (gotime.Time, error) {
+	dur, derr := toDuration(d)
+	tm, terr := toTime(t)
+
+	if derr != nil || terr != nil {
+		// try the other way around
+		var err error
+		if dur, err = toDuration(t); err != nil {
+			return gotime.Time{}, errors.Join(derr, terr)
+		}
+		if tm, err = toTime(d); err != nil {
+			return gotime.Time{}, errors.Join(derr, terr)
+		}
+	}
+
+	return tm.Add(dur), nil
 }
 
-// Since -
-func (TimeFuncs) Since(n gotime.Time) gotime.Duration {
-	return gotime.Since(n)
+// toTime converts a time.Time, or an RFC 3339 timestamp, to a time.Time
+func toTime(in interface{}) (gotime.Time, error) {
+	switch t := in.(type) {
+	case gotime.Time:
+		return t, nil
+	case *gotime.Time:
+		if t != nil {
+			return *t, nil
+		}
+	case string:
+		out, err := gotime.Parse(gotime.RFC3339Nano, t)
+		if err != nil {
+			return gotime.Time{}, fmt.Errorf("expected a time or an RFC 3339 timestamp: %w", err)
+		}
+
+		return out, nil
+	}
+
+	return gotime.Time{}, fmt.Errorf("expected a time or an RFC 3339 timestamp, got %T", in)
 }
 
-// Until -
-func (TimeFuncs) Until(n gotime.Time) gotime.Duration {
-	return gotime.Until(n)
+// toDuration converts a time.Duration, or a duration string like "720h", to
+// a time.Duration
+func toDuration(in interface{}) (gotime.Duration, error) {
+	if d, ok := in.(gotime.Duration); ok {
+		return d, nil
+	}
+
+	return gotime.ParseDuration(conv.ToString(in))
 }
 
 // convert a number input to a pair of int64s, representing the integer portion and the decimal remainder
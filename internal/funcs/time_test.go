@@ -6,6 +6,7 @@ import (
 	"math/big"
 	"strconv"
 	"testing"
+	gotime "time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -80,3 +81,132 @@ func TestParseNum(t *testing.T) {
 	assert.Zero(t, f)
 	require.NoError(t, err)
 }
+
+func TestTimeFuncs_Zones(t *testing.T) {
+	t.Parallel()
+
+	tf := &TimeFuncs{}
+
+	ts, err := tf.ParseInLocation(gotime.DateTime, "America/New_York", "2024-01-15 09:30:00")
+	require.NoError(t, err)
+	assert.Equal(t, "2024-01-15T14:30:00Z", ts.UTC().Format(gotime.RFC3339))
+
+	_, err = tf.ParseInLocation(gotime.DateTime, "Bogus/Zone", "2024-01-15 09:30:00")
+	require.ErrorContains(t, err, `invalid time zone "Bogus/Zone"`)
+
+	in, err := tf.In("Asia/Tokyo", ts)
+	require.NoError(t, err)
+	assert.Equal(t, "2024-01-15 23:30 JST", in.Format("2006-01-02 15:04 MST"))
+	assert.True(t, ts.Equal(in))
+
+	_, err = tf.In("Bogus/Zone", ts)
+	require.ErrorContains(t, err, `invalid time zone "Bogus/Zone"`)
+
+	name, err := tf.ZoneName(ts)
+	require.NoError(t, err)
+	assert.Equal(t, "EST", name)
+
+	name, err = tf.ZoneName("UTC")
+	require.NoError(t, err)
+	assert.Equal(t, "UTC", name)
+
+	_, err = tf.ZoneName("Bogus/Zone")
+	require.ErrorContains(t, err, `invalid time zone "Bogus/Zone"`)
+
+	_, err = tf.ZoneName("UTC", "UTC")
+	require.Error(t, err)
+
+	offset, err := tf.ZoneOffset(in)
+	require.NoError(t, err)
+	assert.Equal(t, 9*60*60, offset)
+
+	offset, err = tf.ZoneOffset("UTC")
+	require.NoError(t, err)
+	assert.Equal(t, 0, offset)
+
+	now, err := tf.Now("Asia/Tokyo")
+	require.NoError(t, err)
+	assert.Equal(t, "Asia/Tokyo", now.Location().String())
+
+	now, err = tf.Now()
+	require.NoError(t, err)
+	assert.Equal(t, gotime.Local, now.Location())
+
+	_, err = tf.Now("Bogus/Zone")
+	require.ErrorContains(t, err, `invalid time zone "Bogus/Zone"`)
+
+	_, err = tf.Now("UTC", "UTC")
+	require.Error(t, err)
+}
+
+func TestTimeFuncs_Durations(t *testing.T) {
+	t.Parallel()
+
+	tf := &TimeFuncs{}
+
+	ts := gotime.Date(2024, 1, 1, 0, 0, 0, 0, gotime.UTC)
+	expected := gotime.Date(2024, 1, 31, 0, 0, 0, 0, gotime.UTC)
+
+	for _, args := range [][2]interface{}{
+		{"720h", ts},
+		{"720h", "2024-01-01T00:00:00Z"},
+		{30 * 24 * gotime.Hour, ts},
+		// either order works
+		{ts, "720h"},
+		{"2024-01-01T00:00:00Z", "720h"},
+	} {
+		out, err := tf.Add(args[0], args[1])
+		require.NoError(t, err)
+		assert.True(t, expected.Equal(out), "%v + %v: expected %v, got %v", args[0], args[1], expected, out)
+	}
+
+	out, err := tf.Add("-1h30m", ts)
+	require.NoError(t, err)
+	assert.Equal(t, "2023-12-31T22:30:00Z", out.Format(gotime.RFC3339))
+
+	_, err = tf.Add("2 days", ts)
+	require.ErrorContains(t, err, `unknown unit " days"`)
+
+	_, err = tf.Add("1h", "yesterday")
+	require.ErrorContains(t, err, "expected a time or an RFC 3339 timestamp")
+
+	_, err = tf.Add(42, ts)
+	require.Error(t, err)
+
+	// durations format in a form that parses back to the same duration
+	d, err := tf.ParseDuration("720h")
+	require.NoError(t, err)
+	assert.Equal(t, "720h0m0s", d.String())
+
+	d2, err := tf.ParseDuration(d)
+	require.NoError(t, err)
+	assert.Equal(t, d, d2)
+
+	d2, err = tf.ParseDuration(d.String())
+	require.NoError(t, err)
+	assert.Equal(t, d, d2)
+
+	past := gotime.Now().Add(-gotime.Hour)
+
+	since, err := tf.Since(past)
+	require.NoError(t, err)
+	assert.InDelta(t, gotime.Hour, since, float64(gotime.Minute))
+
+	since, err = tf.Since(past.Format(gotime.RFC3339Nano))
+	require.NoError(t, err)
+	assert.InDelta(t, gotime.Hour, since, float64(gotime.Minute))
+
+	until, err := tf.Until(past)
+	require.NoError(t, err)
+	assert.InDelta(t, -gotime.Hour, until, float64(gotime.Minute))
+
+	until, err = tf.Until(gotime.Now().Add(gotime.Hour).Format(gotime.RFC3339))
+	require.NoError(t, err)
+	assert.InDelta(t, gotime.Hour, until, float64(gotime.Minute))
+
+	_, err = tf.Since("bogus")
+	require.Error(t, err)
+
+	_, err = tf.Until(42)
+	require.ErrorContains(t, err, "got int")
+}
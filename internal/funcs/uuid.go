@@ -2,6 +2,11 @@ package funcs
 
 import (
 	"context"
+	"crypto/md5"  //nolint:gosec
+	"crypto/sha1" //nolint:gosec
+	"fmt"
+	"hash"
+	"strings"
 
 	"github.com/hairyhenderson/gomplate/v4/conv"
 
@@ -40,6 +45,42 @@ func (UUIDFuncs) V4() (string, error) {
 	return u.String(), nil
 }
 
+// V3 - return a version 3 (name-based, using MD5) UUID. The namespace can be
+// a UUID, or one of the well-known namespaces "dns", "url", "oid", or "x500".
+// Use V5 instead when possible.
+func (UUIDFuncs) V3(namespace, name interface{}) (string, error) {
+	return nameBasedUUID(md5.New(), 3, namespace, name)
+}
+
+// V5 - return a version 5 (name-based, using SHA-1) UUID. The namespace can be
+// a UUID, or one of the well-known namespaces "dns", "url", "oid", or "x500".
+func (UUIDFuncs) V5(namespace, name interface{}) (string, error) {
+	return nameBasedUUID(sha1.New(), 5, namespace, name)
+}
+
+// wellKnownNamespaces are the predefined namespaces from RFC 4122
+var wellKnownNamespaces = map[string]uuid.UUID{
+	"dns":  uuid.NameSpaceDNS,
+	"url":  uuid.NameSpaceURL,
+	"oid":  uuid.NameSpaceOID,
+	"x500": uuid.NameSpaceX500,
+}
+
+func nameBasedUUID(h hash.Hash, version int, namespace, name interface{}) (string, error) {
+	ns := conv.ToString(namespace)
+
+	space, ok := wellKnownNamespaces[strings.ToLower(ns)]
+	if !ok {
+		var err error
+		space, err = uuid.Parse(ns)
+		if err != nil {
+			return "", fmt.Errorf("invalid namespace %q: must be a UUID or one of dns, url, oid, or x500: %w", ns, err)
+		}
+	}
+
+	return uuid.NewHash(h, space, []byte(conv.ToString(name)), version).String(), nil
+}
+
 // Nil -
 func (UUIDFuncs) Nil() (string, error) {
 	return uuid.Nil.String(), nil
@@ -50,6 +50,27 @@ func TestV4(t *testing.T) {
 	assert.Regexp(t, uuidV4Pattern, i)
 }
 
+func TestV3V5(t *testing.T) {
+	t.Parallel()
+
+	u := UUIDFuncs{ctx: context.Background()}
+
+	i, err := u.V5("dns", "python.org")
+	require.NoError(t, err)
+	assert.Equal(t, "886313e1-3b8a-5372-9b90-0c9aee199e5d", i)
+
+	i, err = u.V5("6ba7b810-9dad-11d1-80b4-00c04fd430c8", "python.org")
+	require.NoError(t, err)
+	assert.Equal(t, "886313e1-3b8a-5372-9b90-0c9aee199e5d", i)
+
+	i, err = u.V3("DNS", "python.org")
+	require.NoError(t, err)
+	assert.Equal(t, "6fa459ea-ee8a-3ca4-894e-db77e160355e", i)
+
+	_, err = u.V5("nope", "python.org")
+	require.ErrorContains(t, err, `invalid namespace "nope"`)
+}
+
 func TestNil(t *testing.T) {
 	t.Parallel()
 
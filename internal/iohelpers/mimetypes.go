@@ -11,8 +11,10 @@ const (
 	JSONArrayMimetype = "application/array+json"
 	TOMLMimetype      = "application/toml"
 	YAMLMimetype      = "application/yaml"
+	YAMLArrayMimetype = "application/array+yaml"
 	EnvMimetype       = "application/x-env"
 	CUEMimetype       = "application/cue"
+	XMLMimetype       = "application/xml"
 )
 
 // mimeTypeAliases defines a mapping for non-canonical mime types that are
@@ -20,6 +22,7 @@ const (
 var mimeTypeAliases = map[string]string{
 	"application/x-yaml": YAMLMimetype,
 	"application/text":   TextMimetype,
+	"text/xml":           XMLMimetype,
 }
 
 func MimeAlias(m string) string {
@@ -14,6 +14,7 @@ func TestMimeAlias(t *testing.T) {
 		{CSVMimetype, CSVMimetype},
 		{YAMLMimetype, YAMLMimetype},
 		{"application/x-yaml", YAMLMimetype},
+		{"text/xml; charset=utf-8", XMLMimetype},
 	}
 
 	for _, d := range data {
@@ -3,6 +3,9 @@ package iohelpers
 import (
 	"bufio"
 	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
@@ -70,6 +73,14 @@ func (f *emptySkipper) Close() error {
 	return nil
 }
 
+// Abort - implements Aborter
+func (f *emptySkipper) Abort() error {
+	if c, ok := f.w.(io.Closer); ok {
+		return Abort(c)
+	}
+	return nil
+}
+
 func allWhitespace(p []byte) bool {
 	for _, b := range p {
 		if b == ' ' || b == '\t' || b == '\n' || b == '\r' || b == '\v' {
@@ -95,10 +106,33 @@ func (n *nopCloser) Close() error {
 	return nil
 }
 
+// Aborter is implemented by writers that can discard everything written so
+// far, instead of committing it on Close.
+type Aborter interface {
+	Abort() error
+}
+
+// Abort discards the content written to c if it implements Aborter, and
+// otherwise closes it.
+func Abort(c io.Closer) error {
+	if a, ok := c.(Aborter); ok {
+		return a.Abort()
+	}
+	return c.Close()
+}
+
 var (
 	_ io.WriteCloser = (*nopCloser)(nil)
 	_ io.WriteCloser = (*emptySkipper)(nil)
 	_ io.WriteCloser = (*sameSkipper)(nil)
+	_ io.WriteCloser = (*atomicFile)(nil)
+	_ io.WriteCloser = (*gzipWriter)(nil)
+
+	_ Aborter = (*emptySkipper)(nil)
+	_ Aborter = (*sameSkipper)(nil)
+	_ Aborter = (*lazyWriteCloser)(nil)
+	_ Aborter = (*atomicFile)(nil)
+	_ Aborter = (*gzipWriter)(nil)
 )
 
 type sameSkipper struct {
@@ -130,8 +164,8 @@ func SameSkipper(r io.Reader, open func() (io.WriteCloser, error)) io.WriteClose
 func (f *sameSkipper) Write(p []byte) (n int, err error) {
 	if !f.diff {
 		in := make([]byte, len(p))
-		_, err := f.r.Read(in)
-		if err != nil && err != io.EOF {
+		_, err := io.ReadFull(f.r, in)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
 			return 0, fmt.Errorf("failed to read: %w", err)
 		}
 		if bytes.Equal(in, p) {
@@ -181,6 +215,14 @@ func (f *sameSkipper) Close() error {
 	return nil
 }
 
+// Abort - implements Aborter. Buffered content is discarded.
+func (f *sameSkipper) Abort() error {
+	if f.w == nil {
+		return nil
+	}
+	return Abort(f.w)
+}
+
 // LazyWriteCloser provides an interface to a WriteCloser that will open on the
 // first access. The wrapped io.WriteCloser must be provided by 'open'.
 func LazyWriteCloser(open func() (io.WriteCloser, error)) io.WriteCloser {
@@ -223,6 +265,199 @@ func (l *lazyWriteCloser) Write(p []byte) (n int, err error) {
 	return w.Write(p)
 }
 
+// Abort - implements Aborter. If the writer hasn't been opened yet, it never
+// will be.
+func (l *lazyWriteCloser) Abort() error {
+	l.opened.Do(func() {
+		l.openErr = errors.New("writer aborted")
+	})
+	if l.w == nil {
+		return nil
+	}
+	return Abort(l.w)
+}
+
+// CreateAtomic creates a temporary file in the same directory as the named
+// file, which replaces the named file only when closed. Use Abort (see
+// [Aborter]) instead of Close to remove the temporary file and leave the named
+// file untouched.
+//
+// If the named file is a symlink, the file it links to is replaced instead, so
+// the link is kept. Files that aren't regular files (like devices or named
+// pipes) can't be replaced, so they're written to directly.
+//
+// If the named file exists, its permissions are preserved, otherwise mode is
+// used.
+func CreateAtomic(fsys fs.FS, name string, mode fs.FileMode) (io.WriteCloser, error) {
+	name, err := resolveSymlinks(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+
+	perm := mode
+	fi, err := hackpadfs.Stat(fsys, name)
+	exists := err == nil
+	if exists {
+		if !fi.Mode().IsRegular() {
+			return openDirect(fsys, name, mode)
+		}
+		perm = fi.Mode().Perm()
+	}
+
+	suffix := make([]byte, 4)
+	if _, err = rand.Read(suffix); err != nil {
+		return nil, fmt.Errorf("failed to generate temp file name: %w", err)
+	}
+
+	tmpName := filepath.Join(filepath.Dir(name),
+		"."+filepath.Base(name)+"."+hex.EncodeToString(suffix)+".tmp")
+
+	f, err := hackpadfs.OpenFile(fsys, tmpName, os.O_RDWR|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return nil, err
+	}
+
+	// the umask may have been applied on creation, but an existing file's
+	// permissions must be kept exactly
+	if exists {
+		if err = hackpadfs.Chmod(fsys, tmpName, perm); err != nil {
+			_ = f.Close()
+			_ = hackpadfs.Remove(fsys, tmpName)
+			return nil, fmt.Errorf("failed to chmod temp file %q: %w", tmpName, err)
+		}
+	}
+
+	wc, ok := f.(io.WriteCloser)
+	if !ok {
+		_ = f.Close()
+		_ = hackpadfs.Remove(fsys, tmpName)
+		return nil, fmt.Errorf("temp file %q is not writable", tmpName)
+	}
+
+	return &atomicFile{fsys: fsys, f: wc, name: name, tmpName: tmpName}, nil
+}
+
+// readlinkFS is implemented by filesystems that can read symlinks
+type readlinkFS interface {
+	Readlink(name string) (string, error)
+}
+
+// maxSymlinks is the most symlinks followed when resolving a file - as with
+// Linux's limit, this prevents loops
+const maxSymlinks = 40
+
+// resolveSymlinks returns the name of the file that name links to, following
+// all symlinks. The last file doesn't need to exist. When the filesystem
+// can't read links, name is returned.
+func resolveSymlinks(fsys fs.FS, name string) (string, error) {
+	rfs, ok := fsys.(readlinkFS)
+	if !ok {
+		return name, nil
+	}
+
+	for i := 0; i < maxSymlinks; i++ {
+		fi, err := hackpadfs.LstatOrStat(fsys, name)
+		if err != nil || fi.Mode()&fs.ModeSymlink == 0 {
+			// a missing file will be created
+			return name, nil
+		}
+
+		target, err := rfs.Readlink(name)
+		if err != nil {
+			return "", fmt.Errorf("failed to read symlink %q: %w", name, err)
+		}
+
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(filepath.Dir(name), target)
+		}
+		name = target
+	}
+
+	return "", fmt.Errorf("too many levels of symbolic links resolving %q", name)
+}
+
+// openDirect opens the named file for writing directly - writes can't be
+// aborted
+func openDirect(fsys fs.FS, name string, mode fs.FileMode) (io.WriteCloser, error) {
+	f, err := hackpadfs.OpenFile(fsys, name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return nil, err
+	}
+
+	wc, ok := f.(io.WriteCloser)
+	if !ok {
+		_ = f.Close()
+		return nil, fmt.Errorf("file %q is not writable", name)
+	}
+
+	return wc, nil
+}
+
+type atomicFile struct {
+	fsys    fs.FS
+	f       io.WriteCloser
+	name    string
+	tmpName string
+}
+
+func (a *atomicFile) Write(p []byte) (int, error) {
+	return a.f.Write(p)
+}
+
+// Close - implements io.Closer, moving the temp file into place
+func (a *atomicFile) Close() error {
+	if err := a.f.Close(); err != nil {
+		_ = hackpadfs.Remove(a.fsys, a.tmpName)
+		return err
+	}
+
+	if err := hackpadfs.Rename(a.fsys, a.tmpName, a.name); err != nil {
+		_ = hackpadfs.Remove(a.fsys, a.tmpName)
+		return fmt.Errorf("failed to move temp file into place: %w", err)
+	}
+
+	return nil
+}
+
+// Abort - implements Aborter, removing the temp file
+func (a *atomicFile) Abort() error {
+	_ = a.f.Close()
+	return hackpadfs.Remove(a.fsys, a.tmpName)
+}
+
+// GzipWriter returns an io.WriteCloser that compresses everything written to
+// it with gzip, before writing it to w. Closing it flushes the compressed
+// stream and then closes w, and Abort (see [Aborter]) discards the output
+// instead. The gzip header has no name or modification time, so the same
+// content is always compressed the same way.
+func GzipWriter(w io.WriteCloser) io.WriteCloser {
+	return &gzipWriter{zw: gzip.NewWriter(w), w: w}
+}
+
+type gzipWriter struct {
+	zw *gzip.Writer
+	w  io.WriteCloser
+}
+
+func (g *gzipWriter) Write(p []byte) (int, error) {
+	return g.zw.Write(p)
+}
+
+// Close - implements io.Closer
+func (g *gzipWriter) Close() error {
+	if err := g.zw.Close(); err != nil {
+		_ = Abort(g.w)
+		return err
+	}
+
+	return g.w.Close()
+}
+
+// Abort - implements Aborter
+func (g *gzipWriter) Abort() error {
+	return Abort(g.w)
+}
+
 // WriteFile writes the given content to the file, truncating any existing file,
 // and creating the directory structure leading up to it if necessary.
 func WriteFile(fsys fs.FS, filename string, content []byte) error {
@@ -2,12 +2,18 @@ package iohelpers
 
 import (
 	"bytes"
+	"compress/gzip"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"runtime"
 	"testing"
 
+	"github.com/hack-pad/hackpadfs"
+	"github.com/hack-pad/hackpadfs/mem"
+	osfs "github.com/hack-pad/hackpadfs/os"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -123,6 +129,26 @@ func TestSameSkipper(t *testing.T) {
 	}
 }
 
+func TestSameSkipper_Chunks(t *testing.T) {
+	// the content is compared fully, even when writes don't line up with the
+	// reads from the current output
+	content := bytes.Repeat([]byte("0123456789"), 1000)
+
+	opened := false
+	f := SameSkipper(bytes.NewReader(content), func() (io.WriteCloser, error) {
+		opened = true
+		return newBufferCloser(&bytes.Buffer{}), nil
+	})
+
+	for _, chunk := range [][]byte{content[:3000], content[3000:6000], content[6000:]} {
+		_, err := f.Write(chunk)
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, f.Close())
+	assert.False(t, opened)
+}
+
 func TestLazyWriteCloser(t *testing.T) {
 	w := newBufferCloser(&bytes.Buffer{})
 	opened := false
@@ -161,6 +187,212 @@ func TestLazyWriteCloser(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestCreateAtomic(t *testing.T) {
+	fsys, _ := mem.NewFS()
+	require.NoError(t, hackpadfs.WriteFullFile(fsys, "out", []byte("original"), 0o600))
+
+	w, err := CreateAtomic(fsys, "out", 0o644)
+	require.NoError(t, err)
+
+	_, err = w.Write([]byte("updated"))
+	require.NoError(t, err)
+
+	// nothing is visible until the write is committed
+	b, err := fs.ReadFile(fsys, "out")
+	require.NoError(t, err)
+	assert.Equal(t, "original", string(b))
+
+	require.NoError(t, w.Close())
+
+	b, err = fs.ReadFile(fsys, "out")
+	require.NoError(t, err)
+	assert.Equal(t, "updated", string(b))
+
+	// the existing file's mode is preserved
+	fi, err := hackpadfs.Stat(fsys, "out")
+	require.NoError(t, err)
+	assert.Equal(t, NormalizeFileMode(0o600), fi.Mode())
+
+	// aborting leaves the original untouched
+	w, err = CreateAtomic(fsys, "out", 0o644)
+	require.NoError(t, err)
+
+	_, err = w.Write([]byte("partial"))
+	require.NoError(t, err)
+
+	a, ok := w.(Aborter)
+	require.True(t, ok)
+	require.NoError(t, a.Abort())
+
+	b, err = fs.ReadFile(fsys, "out")
+	require.NoError(t, err)
+	assert.Equal(t, "updated", string(b))
+
+	// and no temp files are left behind
+	des, err := fs.ReadDir(fsys, ".")
+	require.NoError(t, err)
+	require.Len(t, des, 1)
+	assert.Equal(t, "out", des[0].Name())
+
+	// new files get the given mode
+	w, err = CreateAtomic(fsys, "new", 0o640)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	fi, err = hackpadfs.Stat(fsys, "new")
+	require.NoError(t, err)
+	assert.Equal(t, NormalizeFileMode(0o640), fi.Mode())
+}
+
+// linkFS is an os filesystem that can read symlinks
+type linkFS struct {
+	*osfs.FS
+}
+
+func (f linkFS) Readlink(name string) (string, error) {
+	p, err := f.ToOSPath(name)
+	if err != nil {
+		return "", err
+	}
+	return os.Readlink(p)
+}
+
+func TestCreateAtomic_Symlinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks need special permissions on Windows")
+	}
+
+	dir := t.TempDir()
+	fsys := linkFS{osfs.NewFS()}
+	root, err := fsys.FromOSPath(dir)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "target"), []byte("original"), 0o600))
+	require.NoError(t, os.Symlink("target", filepath.Join(dir, "link")))
+	require.NoError(t, os.Symlink("link", filepath.Join(dir, "link2")))
+	require.NoError(t, os.Symlink("missing", filepath.Join(dir, "dangling")))
+	require.NoError(t, os.Symlink("loop", filepath.Join(dir, "loop")))
+
+	write := func(name, content string) error {
+		t.Helper()
+
+		w, err := CreateAtomic(fsys, root+"/"+name, 0o644)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+		return w.Close()
+	}
+
+	// writing through a chain of links updates the target, and keeps the links
+	require.NoError(t, write("link2", "updated"))
+
+	b, err := os.ReadFile(filepath.Join(dir, "target"))
+	require.NoError(t, err)
+	assert.Equal(t, "updated", string(b))
+
+	for _, name := range []string{"link", "link2"} {
+		fi, err := os.Lstat(filepath.Join(dir, name))
+		require.NoError(t, err)
+		assert.NotZero(t, fi.Mode()&fs.ModeSymlink)
+	}
+
+	// the target's mode is kept
+	fi, err := os.Stat(filepath.Join(dir, "target"))
+	require.NoError(t, err)
+	assert.Equal(t, fs.FileMode(0o600), fi.Mode().Perm())
+
+	// a dangling link's target is created
+	require.NoError(t, write("dangling", "new"))
+
+	b, err = os.ReadFile(filepath.Join(dir, "missing"))
+	require.NoError(t, err)
+	assert.Equal(t, "new", string(b))
+
+	require.ErrorContains(t, write("loop", "x"), "too many levels of symbolic links")
+
+	// non-regular files are written to directly
+	devNull, err := fsys.FromOSPath(os.DevNull)
+	require.NoError(t, err)
+
+	w, err := CreateAtomic(fsys, devNull, 0o644)
+	require.NoError(t, err)
+	_, err = w.Write([]byte("discarded"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	fi, err = os.Stat(os.DevNull)
+	require.NoError(t, err)
+	assert.False(t, fi.Mode().IsRegular())
+}
+
+func TestGzipWriter(t *testing.T) {
+	w := newBufferCloser(&bytes.Buffer{})
+	g := GzipWriter(w)
+
+	_, err := g.Write([]byte("hello "))
+	require.NoError(t, err)
+	_, err = g.Write([]byte("world"))
+	require.NoError(t, err)
+	require.NoError(t, g.Close())
+	assert.True(t, w.closed)
+
+	zr, err := gzip.NewReader(bytes.NewReader(w.Bytes()))
+	require.NoError(t, err)
+	b, err := io.ReadAll(zr)
+	require.NoError(t, err)
+	assert.Equal(t, "hello world", string(b))
+
+	// the output is the same every time
+	w2 := newBufferCloser(&bytes.Buffer{})
+	g = GzipWriter(w2)
+	_, err = g.Write([]byte("hello world"))
+	require.NoError(t, err)
+	require.NoError(t, g.Close())
+	assert.Equal(t, w.Bytes(), w2.Bytes())
+
+	// aborting aborts the wrapped writer
+	fsys, _ := mem.NewFS()
+	aw, err := CreateAtomic(fsys, "out.gz", 0o644)
+	require.NoError(t, err)
+
+	g = GzipWriter(aw)
+	_, err = g.Write([]byte("partial"))
+	require.NoError(t, err)
+	require.NoError(t, Abort(g))
+
+	des, err := fs.ReadDir(fsys, ".")
+	require.NoError(t, err)
+	assert.Empty(t, des)
+}
+
+func TestAbort(t *testing.T) {
+	// writers that can't abort are closed
+	w := newBufferCloser(&bytes.Buffer{})
+	require.NoError(t, Abort(w))
+	assert.True(t, w.closed)
+
+	// a lazy writer that was never opened is never opened after aborting
+	opened := false
+	l := LazyWriteCloser(func() (io.WriteCloser, error) {
+		opened = true
+		return w, nil
+	})
+	require.NoError(t, Abort(l))
+	assert.False(t, opened)
+
+	// buffered content in a SameSkipper is discarded
+	w = newBufferCloser(&bytes.Buffer{})
+	s := SameSkipper(bytes.NewBufferString("foo"), func() (io.WriteCloser, error) {
+		return w, nil
+	})
+	_, err := s.Write([]byte("fo"))
+	require.NoError(t, err)
+	require.NoError(t, Abort(s))
+	assert.Empty(t, w.String())
+}
+
 func TestAssertPathInWD(t *testing.T) {
 	oldwd, _ := os.Getwd()
 	defer os.Chdir(oldwd)
@@ -5,13 +5,21 @@ import (
 	"bytes"
 	"encoding/csv"
 	"encoding/json"
+	"encoding/xml"
 	"fmt"
 	"io"
+	"math"
+	"mime"
 	"os"
+	"reflect"
+	"sort"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"cuelang.org/go/cue"
 	"cuelang.org/go/cue/cuecontext"
+	cueerrors "cuelang.org/go/cue/errors"
 	"cuelang.org/go/cue/format"
 	"github.com/Shopify/ejson"
 	ejsonJson "github.com/Shopify/ejson/json"
@@ -149,6 +157,37 @@ func YAMLArray(in string) ([]interface{}, error) {
 	return obj, err
 }
 
+// YAMLDocuments - Unmarshal a stream of YAML documents (separated by "---")
+// into a slice, with one element per document. Empty documents are skipped.
+func YAMLDocuments(in string) ([]interface{}, error) {
+	docs := []interface{}{}
+
+	d := yaml.NewDecoder(strings.NewReader(in))
+	for {
+		var doc interface{}
+
+		err := d.Decode(&doc)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to unmarshal YAML document %d: %w", len(docs)+1, err)
+		}
+
+		if doc == nil {
+			continue
+		}
+
+		if vv, replaced := stringifyMapKeys(doc); replaced {
+			doc = vv
+		}
+
+		docs = append(docs, doc)
+	}
+
+	return docs, nil
+}
+
 // stringifyYAMLArrayMapKeys recurses into the input array and changes all
 // non-string map keys to string map keys. Modifies the input array.
 func stringifyYAMLArrayMapKeys(in []interface{}) error {
@@ -208,7 +247,42 @@ func stringifyMapKeys(in interface{}) (interface{}, bool) {
 // TOML - Unmarshal a TOML Object
 func TOML(in string) (interface{}, error) {
 	obj := make(map[string]interface{})
-	return unmarshalObj(obj, in, toml.Unmarshal)
+	obj, err := unmarshalObj(obj, in, toml.Unmarshal)
+	if err != nil {
+		return nil, err
+	}
+
+	normalizeTOMLTables(obj)
+	return obj, nil
+}
+
+// normalizeTOMLTables recurses into in and changes all arrays of tables
+// ([]map[string]interface{}) to []interface{}, so that TOML data has the same
+// shape as data parsed from JSON or YAML. Modifies the input in place.
+func normalizeTOMLTables(in interface{}) (interface{}, bool) {
+	switch in := in.(type) {
+	case []interface{}:
+		for i, v := range in {
+			if vv, replaced := normalizeTOMLTables(v); replaced {
+				in[i] = vv
+			}
+		}
+	case map[string]interface{}:
+		for k, v := range in {
+			if vv, replaced := normalizeTOMLTables(v); replaced {
+				in[k] = vv
+			}
+		}
+	case []map[string]interface{}:
+		res := make([]interface{}, len(in))
+		for i, v := range in {
+			normalizeTOMLTables(v)
+			res[i] = v
+		}
+		return res, true
+	}
+
+	return nil, false
 }
 
 // DotEnv - Unmarshal a dotenv file
@@ -226,26 +300,43 @@ func DotEnv(in string) (interface{}, error) {
 
 func parseCSV(args ...string) ([][]string, []string, error) {
 	in, delim, hdr := csvParseArgs(args...)
+	comma, err := csvComma(delim)
+	if err != nil {
+		return nil, nil, err
+	}
 	c := csv.NewReader(strings.NewReader(in))
-	c.Comma = rune(delim[0])
+	c.Comma = comma
 	records, err := c.ReadAll()
 	if err != nil {
 		return nil, nil, err
 	}
 	if len(records) > 0 {
-		if hdr == nil {
+		switch {
+		case hdr == nil:
 			hdr = records[0]
 			records = records[1:]
-		} else if len(hdr) == 0 {
+		case len(hdr) == 0:
 			hdr = make([]string, len(records[0]))
 			for i := range hdr {
 				hdr[i] = autoIndex(i)
 			}
+		case len(hdr) != len(records[0]):
+			return nil, nil, fmt.Errorf("CSV header has %d fields, but the data's records have %d", len(hdr), len(records[0]))
 		}
 	}
 	return records, hdr, nil
 }
 
+// csvComma returns the delimiter as a rune, for csv.Reader. It must be a single
+// character, but that can be a multi-byte character like "¦".
+func csvComma(delim string) (rune, error) {
+	if utf8.RuneCountInString(delim) != 1 {
+		return 0, fmt.Errorf("invalid CSV delimiter %q: must be a single character", delim)
+	}
+	r, _ := utf8.DecodeRuneInString(delim)
+	return r, nil
+}
+
 func csvParseArgs(args ...string) (in, delim string, hdr []string) {
 	delim = ","
 	switch len(args) {
@@ -253,7 +344,7 @@ func csvParseArgs(args ...string) (in, delim string, hdr []string) {
 		in = args[0]
 	case 2:
 		in = args[1]
-		switch len(args[0]) {
+		switch utf8.RuneCountInString(args[0]) {
 		case 1:
 			delim = args[0]
 		case 0:
@@ -263,7 +354,10 @@ func csvParseArgs(args ...string) (in, delim string, hdr []string) {
 		}
 	case 3:
 		delim = args[0]
-		hdr = strings.Split(args[1], delim)
+		hdr = []string{}
+		if args[1] != "" {
+			hdr = strings.Split(args[1], delim)
+		}
 		in = args[2]
 	}
 	return in, delim, hdr
@@ -351,6 +445,56 @@ func CSVByColumn(args ...string) (cols map[string][]string, err error) {
 	return cols, nil
 }
 
+// csvWithParams - Unmarshal CSV, according to the optional "delim" and
+// "header" parameters of the given MIME type (e.g. `text/csv; delim=";"`).
+//
+// The delimiter must be a single character, and may be given as `\t` for tab.
+// When header is "true" (or "present"), rows are returned as maps (of type
+// map[string]interface{}) indexed by header name. When header is "false" (or
+// "absent"), all lines are returned as rows, with no header. When header is
+// omitted, the output is the same as [CSV].
+func csvWithParams(mimeType, in string) (interface{}, error) {
+	_, params, _ := mime.ParseMediaType(mimeType)
+
+	delim := ","
+	if d, ok := params["delim"]; ok {
+		if d == `\t` {
+			d = "\t"
+		}
+		delim = d
+	}
+
+	comma, err := csvComma(delim)
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(params["header"]) {
+	case "":
+		return CSV(delim, in)
+	case "true", "present":
+		rows, err := CSVByRow(delim, in)
+		if err != nil {
+			return nil, err
+		}
+
+		out := make([]map[string]interface{}, len(rows))
+		for i, row := range rows {
+			out[i] = make(map[string]interface{}, len(row))
+			for k, v := range row {
+				out[i][k] = v
+			}
+		}
+		return out, nil
+	case "false", "absent":
+		c := csv.NewReader(strings.NewReader(in))
+		c.Comma = comma
+		return c.ReadAll()
+	default:
+		return nil, fmt.Errorf("invalid CSV header parameter %q: must be true or false", params["header"])
+	}
+}
+
 // ToCSV -
 func ToCSV(args ...interface{}) (string, error) {
 	delim := ","
@@ -467,6 +611,241 @@ func ToTOML(in interface{}) (string, error) {
 	return buf.String(), nil
 }
 
+// ToHCL - Stringify a value as HCL (in the native syntax, as used by
+// Terraform).
+//
+// Maps become a body of attributes, one per key, sorted by name. Keys must be
+// valid HCL identifiers, since they're attribute names. Any other value is
+// written as a single expression. Nested maps are written as objects, and
+// lists as tuples, with one element per line.
+func ToHCL(in interface{}) (string, error) {
+	// some values can't be represented, but would be marshalled as null
+	if err := checkHCLValue(reflect.ValueOf(in)); err != nil {
+		return "", fmt.Errorf("unable to marshal to HCL: %w", err)
+	}
+
+	// round-trip through JSON so that any value that can be marshalled is
+	// normalized into maps, slices, and primitives
+	b, err := toJSONBytes(in)
+	if err != nil {
+		return "", err
+	}
+
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+
+	var v interface{}
+	if err = d.Decode(&v); err != nil {
+		return "", fmt.Errorf("unable to marshal %s: %w", in, err)
+	}
+
+	buf := &bytes.Buffer{}
+
+	if m, ok := v.(map[string]interface{}); ok {
+		for k := range m {
+			if !isHCLIdentifier(k) {
+				return "", fmt.Errorf("unable to marshal to HCL: %q is not a valid attribute name", k)
+			}
+		}
+
+		err = writeHCLAttrs(buf, m, "")
+		if err != nil {
+			return "", err
+		}
+
+		return buf.String(), nil
+	}
+
+	err = writeHCLValue(buf, v, "")
+	if err != nil {
+		return "", err
+	}
+
+	buf.WriteString("\n")
+
+	return buf.String(), nil
+}
+
+// writeHCLAttrs writes the map as attributes, one per line. As with
+// 'terraform fmt', the '=' signs are aligned, except across multi-line values.
+func writeHCLAttrs(buf *bytes.Buffer, m map[string]interface{}, indent string) error {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	names := make([]string, len(keys))
+	values := make([]string, len(keys))
+
+	for i, k := range keys {
+		names[i] = k
+		if !isHCLIdentifier(k) {
+			names[i] = hclQuote(k)
+		}
+
+		vbuf := &bytes.Buffer{}
+		if err := writeHCLValue(vbuf, m[k], indent); err != nil {
+			return err
+		}
+
+		values[i] = vbuf.String()
+	}
+
+	for start := 0; start < len(keys); {
+		// a run of attributes ends with the first multi-line value
+		end := start
+		for end < len(keys)-1 && !strings.Contains(values[end], "\n") {
+			end++
+		}
+
+		width := 0
+		for _, n := range names[start : end+1] {
+			width = max(width, utf8.RuneCountInString(n))
+		}
+
+		for i := start; i <= end; i++ {
+			fmt.Fprintf(buf, "%s%-*s = %s\n", indent, width, names[i], values[i])
+		}
+
+		start = end + 1
+	}
+
+	return nil
+}
+
+func writeHCLValue(buf *bytes.Buffer, v interface{}, indent string) error {
+	switch v := v.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		fmt.Fprintf(buf, "%t", v)
+	case json.Number:
+		buf.WriteString(v.String())
+	case string:
+		buf.WriteString(hclQuote(v))
+	case []interface{}:
+		if len(v) == 0 {
+			buf.WriteString("[]")
+			return nil
+		}
+
+		buf.WriteString("[\n")
+		for _, e := range v {
+			buf.WriteString(indent + "  ")
+			if err := writeHCLValue(buf, e, indent+"  "); err != nil {
+				return err
+			}
+			buf.WriteString(",\n")
+		}
+		buf.WriteString(indent + "]")
+	case map[string]interface{}:
+		if len(v) == 0 {
+			buf.WriteString("{}")
+			return nil
+		}
+
+		buf.WriteString("{\n")
+		if err := writeHCLAttrs(buf, v, indent+"  "); err != nil {
+			return err
+		}
+		buf.WriteString(indent + "}")
+	default:
+		return fmt.Errorf("unable to marshal to HCL: unsupported type %T", v)
+	}
+
+	return nil
+}
+
+// checkHCLValue returns an error if v is or contains a value that can't be
+// represented in HCL, like NaN, infinity, functions, or channels
+func checkHCLValue(v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		if f := v.Float(); math.IsNaN(f) || math.IsInf(f, 0) {
+			return fmt.Errorf("unsupported value %v", f)
+		}
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer, reflect.Complex64, reflect.Complex128:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	case reflect.Interface, reflect.Pointer:
+		if !v.IsNil() {
+			return checkHCLValue(v.Elem())
+		}
+	case reflect.Slice, reflect.Array:
+		for i := range v.Len() {
+			if err := checkHCLValue(v.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			if err := checkHCLValue(iter.Value()); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		for i := range v.NumField() {
+			if err := checkHCLValue(v.Field(i)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// isHCLIdentifier reports whether s can be used as an unquoted attribute name
+func isHCLIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for i, r := range s {
+		switch {
+		case r == '_' || unicode.IsLetter(r):
+		case i > 0 && (r == '-' || unicode.IsDigit(r)):
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// hclQuote returns s as a quoted HCL string. Template sequences ("${" and
+// "%{") are escaped, so that the string is read back literally.
+func hclQuote(s string) string {
+	sb := &strings.Builder{}
+	sb.WriteByte('"')
+
+	for i, r := range s {
+		switch {
+		case r == '"':
+			sb.WriteString(`\"`)
+		case r == '\\':
+			sb.WriteString(`\\`)
+		case r == '\n':
+			sb.WriteString(`\n`)
+		case r == '\r':
+			sb.WriteString(`\r`)
+		case r == '\t':
+			sb.WriteString(`\t`)
+		case (r == '$' || r == '%') && strings.HasPrefix(s[i+1:], "{"):
+			sb.WriteRune(r)
+			sb.WriteRune(r)
+		case !unicode.IsPrint(r):
+			fmt.Fprintf(sb, `\u%04x`, r)
+		default:
+			sb.WriteRune(r)
+		}
+	}
+
+	sb.WriteByte('"')
+
+	return sb.String()
+}
+
 // CUE - Unmarshal a CUE expression into the appropriate type
 func CUE(in string) (interface{}, error) {
 	cuectx := cuecontext.New()
@@ -476,6 +855,15 @@ func CUE(in string) (interface{}, error) {
 		return nil, fmt.Errorf("unable to process CUE: %w", val.Err())
 	}
 
+	if err := val.Validate(); err != nil {
+		return nil, fmt.Errorf("unable to process CUE: %w", err)
+	}
+
+	// only concrete values can be exported, so report every field that isn't
+	if err := val.Validate(cue.Concrete(true)); err != nil {
+		return nil, fmt.Errorf("CUE value is incomplete - these fields aren't concrete: %s", strings.Join(cueIncompletePaths(err), ", "))
+	}
+
 	switch val.Kind() {
 	case cue.StructKind:
 		out := map[string]interface{}{}
@@ -512,6 +900,27 @@ func CUE(in string) (interface{}, error) {
 	}
 }
 
+// cueIncompletePaths returns the paths of the fields reported in the given
+// validation error, without duplicates
+func cueIncompletePaths(err error) []string {
+	paths := []string{}
+	seen := map[string]bool{}
+
+	for _, e := range cueerrors.Errors(err) {
+		p := strings.Join(e.Path(), ".")
+		if p == "" {
+			p = "(root)"
+		}
+
+		if !seen[p] {
+			seen[p] = true
+			paths = append(paths, p)
+		}
+	}
+
+	return paths
+}
+
 func ToCUE(in interface{}) (string, error) {
 	cuectx := cuecontext.New()
 	v := cuectx.Encode(in)
@@ -526,3 +935,101 @@ func ToCUE(in interface{}) (string, error) {
 
 	return string(bs), nil
 }
+
+// XML - Unmarshal an XML document into a map, keyed by the root element's name.
+//
+// Elements become maps of their children, and repeated child elements become
+// slices, in document order. Attributes are keyed by their name prefixed with
+// "@". Namespace prefixes are kept as part of the names (e.g. "soap:Body").
+// Elements with only text become strings, while the text of elements that also
+// have attributes or children is keyed by "#text". Text is trimmed of leading
+// and trailing whitespace, and all text directly within an element (mixed
+// content) is concatenated.
+func XML(in string) (map[string]interface{}, error) {
+	d := xml.NewDecoder(strings.NewReader(in))
+
+	for {
+		// RawToken is used rather than Token, so that namespace prefixes are
+		// kept instead of being replaced by the namespace URL
+		tok, err := d.RawToken()
+		if err == io.EOF {
+			return nil, fmt.Errorf("unable to unmarshal XML: no root element")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to unmarshal XML: %w", err)
+		}
+
+		if start, ok := tok.(xml.StartElement); ok {
+			v, err := xmlElement(d, start)
+			if err != nil {
+				return nil, fmt.Errorf("unable to unmarshal XML: %w", err)
+			}
+
+			return map[string]interface{}{xmlName(start.Name): v}, nil
+		}
+	}
+}
+
+func xmlName(n xml.Name) string {
+	if n.Space == "" {
+		return n.Local
+	}
+
+	return n.Space + ":" + n.Local
+}
+
+// xmlElement decodes the content of the element, up to its end element, into
+// a string (for elements with only text) or a map
+func xmlElement(d *xml.Decoder, start xml.StartElement) (interface{}, error) {
+	m := map[string]interface{}{}
+	for _, a := range start.Attr {
+		m["@"+xmlName(a.Name)] = a.Value
+	}
+
+	text := &strings.Builder{}
+
+	for {
+		tok, err := d.RawToken()
+		if err == io.EOF {
+			return nil, fmt.Errorf("element <%s> is not closed", xmlName(start.Name))
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			v, err := xmlElement(d, t)
+			if err != nil {
+				return nil, err
+			}
+
+			name := xmlName(t.Name)
+			switch existing := m[name].(type) {
+			case nil:
+				m[name] = v
+			case []interface{}:
+				m[name] = append(existing, v)
+			default:
+				m[name] = []interface{}{existing, v}
+			}
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			if t.Name != start.Name {
+				return nil, fmt.Errorf("element <%s> closed by </%s>", xmlName(start.Name), xmlName(t.Name))
+			}
+
+			s := strings.TrimSpace(text.String())
+			if len(m) == 0 {
+				return s, nil
+			}
+
+			if s != "" {
+				m["#text"] = s
+			}
+
+			return m, nil
+		}
+	}
+}
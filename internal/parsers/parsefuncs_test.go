@@ -2,6 +2,7 @@ package parsers
 
 import (
 	"fmt"
+	"math"
 	"os"
 	"testing"
 	"time"
@@ -232,6 +233,38 @@ func TestToJSONPretty(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestJSONRoundTrip(t *testing.T) {
+	in := `{"name":"app","replicas":1,"env":{"B":"2","C":"3","A":"1"},"ports":[80,443]}`
+	expected := `{
+	"env": {
+		"A": "1",
+		"B": "2",
+		"C": "3"
+	},
+	"name": "app",
+	"ports": [
+		80,
+		443
+	],
+	"replicas": 1
+}`
+
+	// map iteration order is random, so make sure the output doesn't change
+	for range 10 {
+		obj, err := JSON(in)
+		require.NoError(t, err)
+
+		out, err := ToJSONPretty("\t", obj)
+		require.NoError(t, err)
+		assert.Equal(t, expected, out)
+	}
+
+	// maps with non-string keys (as parsed from YAML) are sorted too
+	out, err := ToJSONPretty(" ", map[interface{}]interface{}{"b": 2, "a": 1, "c": 3})
+	require.NoError(t, err)
+	assert.Equal(t, "{\n \"a\": 1,\n \"b\": 2,\n \"c\": 3\n}", out)
+}
+
 func TestToYAML(t *testing.T) {
 	expected := `d: 2006-01-02T15:04:05.999999999-07:00
 foo: bar
@@ -341,6 +374,14 @@ func TestCSVByColumn(t *testing.T) {
 			"B": {"2", "5"},
 			"C": {"3", "6"},
 		}, []string{"", "1,2,3\n4,5,6"}},
+		{map[string][]string{
+			"A": {"1", "4"},
+			"B": {"2", "5"},
+		}, []string{"|", "", "1|2\n4|5"}},
+		{map[string][]string{
+			"a": {"1"},
+			"b": {"2"},
+		}, []string{"¦", "a¦b\n1¦2"}},
 	}
 	for _, d := range testdata {
 		out, err := CSVByColumn(d.args...)
@@ -349,6 +390,65 @@ func TestCSVByColumn(t *testing.T) {
 	}
 }
 
+func TestCSV_Errors(t *testing.T) {
+	testdata := []struct {
+		msg  string
+		args []string
+	}{
+		{"invalid CSV delimiter", []string{"", "a", "1"}},
+		{"invalid CSV delimiter", []string{";;", "a;;b", "1;2"}},
+		{"CSV header has 2 fields, but the data's records have 3", []string{",", "a,b", "1,2,3"}},
+		{"CSV header has 1 fields, but the data's records have 2", []string{"ab", "1,2"}},
+	}
+
+	for _, d := range testdata {
+		_, err := CSVByRow(d.args...)
+		require.ErrorContains(t, err, d.msg)
+
+		_, err = CSVByColumn(d.args...)
+		require.ErrorContains(t, err, d.msg)
+
+		_, err = CSV(d.args...)
+		require.ErrorContains(t, err, d.msg)
+	}
+}
+
+func TestCSVWithParams(t *testing.T) {
+	out, err := csvWithParams("text/csv", "a,b\n1,2")
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"a", "b"}, {"1", "2"}}, out)
+
+	out, err = csvWithParams(`text/csv; delim=";"`, "a;b\n1;2")
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"a", "b"}, {"1", "2"}}, out)
+
+	out, err = csvWithParams(`text/csv; delim="\\t"; header=true`, "a\tb\n1\t2")
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]interface{}{{"a": "1", "b": "2"}}, out)
+
+	out, err = csvWithParams("text/csv; header=false", "a,b\n1,2")
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"a", "b"}, {"1", "2"}}, out)
+
+	// multi-byte delimiters are fine
+	out, err = csvWithParams(`text/csv; delim="¦"; header=true`, "a¦b\n1¦2")
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]interface{}{{"a": "1", "b": "2"}}, out)
+
+	out, err = csvWithParams(`text/csv; delim="¦"; header=false`, "a¦b\n1¦2")
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"a", "b"}, {"1", "2"}}, out)
+
+	_, err = csvWithParams(`text/csv; delim=""`, "a,b\n1,2")
+	require.ErrorContains(t, err, "single character")
+
+	_, err = csvWithParams("text/csv; delim=ab", "a,b\n1,2")
+	require.ErrorContains(t, err, "single character")
+
+	_, err = csvWithParams("text/csv; header=maybe", "a,b\n1,2")
+	require.Error(t, err)
+}
+
 func TestAutoIndex(t *testing.T) {
 	assert.Equal(t, "A", autoIndex(0))
 	assert.Equal(t, "B", autoIndex(1))
@@ -430,6 +530,16 @@ enabled = true
   ip = "10.0.0.2"
   dc = "eqdc10"
 
+[[products]]
+name = "Hammer"
+sku = 738594937
+
+[[products]]
+name = "Nail"
+sku = 284758393
+  [[products.variants]]
+  color = "gray"
+
 [clients]
 data = [ ["gamma", "delta"], [1, 2] ] # just an update to make sure parsers support it
 
@@ -463,6 +573,19 @@ hosts = [
 				"dc": "eqdc10",
 			},
 		},
+		"products": []interface{}{
+			map[string]interface{}{
+				"name": "Hammer",
+				"sku":  int64(738594937),
+			},
+			map[string]interface{}{
+				"name": "Nail",
+				"sku":  int64(284758393),
+				"variants": []interface{}{
+					map[string]interface{}{"color": "gray"},
+				},
+			},
+		},
 		"clients": map[string]interface{}{
 			"data": []interface{}{
 				[]interface{}{"gamma", "delta"},
@@ -504,6 +627,59 @@ true = true
 	assert.Equal(t, expected, out)
 }
 
+func TestToHCL(t *testing.T) {
+	expected := `count   = 3
+empty   = {}
+enabled = true
+none    = null
+region  = "us-east-1"
+tags    = {
+  Name     = "web"
+  "my key" = "x$${y} %%{z} \"q\"\n"
+}
+zones = [
+  "a",
+  1.5,
+  [],
+]
+`
+	in := map[string]interface{}{
+		"region":  "us-east-1",
+		"count":   3,
+		"enabled": true,
+		"none":    nil,
+		"empty":   map[string]interface{}{},
+		"tags": map[interface{}]interface{}{
+			"Name":   "web",
+			"my key": "x${y} %{z} \"q\"\n",
+		},
+		"zones": []interface{}{"a", 1.5, []string{}},
+	}
+	out, err := ToHCL(in)
+	require.NoError(t, err)
+	assert.Equal(t, expected, out)
+
+	// values that aren't maps are written as expressions
+	out, err = ToHCL([]string{"a", "b"})
+	require.NoError(t, err)
+	assert.Equal(t, "[\n  \"a\",\n  \"b\",\n]\n", out)
+
+	out, err = ToHCL(struct {
+		Name string `json:"name"`
+	}{"foo"})
+	require.NoError(t, err)
+	assert.Equal(t, "name = \"foo\"\n", out)
+
+	_, err = ToHCL(map[string]interface{}{"not valid": 1})
+	require.ErrorContains(t, err, `"not valid" is not a valid attribute name`)
+
+	_, err = ToHCL(map[string]interface{}{"a": []float64{1, math.Inf(1)}})
+	require.ErrorContains(t, err, "unsupported value +Inf")
+
+	_, err = ToHCL(map[string]interface{}{"a": func() {}})
+	require.ErrorContains(t, err, "unsupported type func()")
+}
+
 func TestDecryptEJSON(t *testing.T) {
 	privateKey := "e282d979654f88267f7e6c2d8268f1f4314b8673579205ed0029b76de9c8223f"
 	publicKey := "6e05ec625bcdca34864181cc43e6fcc20a57732a453bc2f4a2e117ffdf1a6762"
@@ -717,6 +893,20 @@ list: [ 1, 2, 3 ]
 
 	_, err = CUE(`>=0 & <=7 & >=3 & <=10`)
 	require.Error(t, err)
+
+	// defaults are used for concrete values
+	out, err = CUE(`a: *"x" | "y"`)
+	require.NoError(t, err)
+	assert.EqualValues(t, map[string]interface{}{"a": "x"}, out)
+
+	_, err = CUE(`a: 1 & 2`)
+	require.ErrorContains(t, err, "unable to process CUE: a: conflicting values")
+
+	_, err = CUE("a: int\nb: 1\nc: {d: string, e: [int, 2]}\nf: \"x\" | \"y\"")
+	require.EqualError(t, err, "CUE value is incomplete - these fields aren't concrete: a, c.d, c.e.0, f")
+
+	_, err = CUE(`int`)
+	require.EqualError(t, err, "CUE value is incomplete - these fields aren't concrete: (root)")
 }
 
 func TestToCUE(t *testing.T) {
@@ -776,3 +966,97 @@ func TestToCUE(t *testing.T) {
 	require.NoError(t, err)
 	assert.EqualValues(t, `{}`, out)
 }
+
+func TestXML(t *testing.T) {
+	in := `<?xml version="1.0" encoding="UTF-8"?>
+<!-- a comment -->
+<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">
+  <soap:Body>
+    <order id="42" status="open">
+      <item sku="a1">Widget</item>
+      <item sku="b2">Gadget</item>
+      <item>Gizmo</item>
+      <note>Handle <b>with</b> care</note>
+      <empty/>
+      <![CDATA[raw <text>]]>
+    </order>
+  </soap:Body>
+</soap:Envelope>`
+
+	expected := map[string]interface{}{
+		"soap:Envelope": map[string]interface{}{
+			"@xmlns:soap": "http://schemas.xmlsoap.org/soap/envelope/",
+			"soap:Body": map[string]interface{}{
+				"order": map[string]interface{}{
+					"@id":     "42",
+					"@status": "open",
+					"item": []interface{}{
+						map[string]interface{}{"@sku": "a1", "#text": "Widget"},
+						map[string]interface{}{"@sku": "b2", "#text": "Gadget"},
+						"Gizmo",
+					},
+					"note": map[string]interface{}{
+						"#text": "Handle  care",
+						"b":     "with",
+					},
+					"empty": "",
+					"#text": "raw <text>",
+				},
+			},
+		},
+	}
+
+	out, err := XML(in)
+	require.NoError(t, err)
+	assert.EqualValues(t, expected, out)
+
+	out, err = XML(`<a>hello</a>`)
+	require.NoError(t, err)
+	assert.EqualValues(t, map[string]interface{}{"a": "hello"}, out)
+
+	_, err = XML(``)
+	require.ErrorContains(t, err, "no root element")
+
+	_, err = XML(`<a><b></a>`)
+	require.ErrorContains(t, err, "element <b> closed by </a>")
+
+	_, err = XML(`<a><b>`)
+	require.ErrorContains(t, err, "element <b> is not closed")
+
+	_, err = XML(`<a x="1></a>`)
+	require.Error(t, err)
+}
+
+func TestYAMLDocuments(t *testing.T) {
+	out, err := YAMLDocuments(`---
+kind: Service
+metadata:
+  name: web
+---
+# an empty document
+---
+kind: Deployment
+spec:
+  1: one
+---
+- a
+- b
+`)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"kind": "Service", "metadata": map[string]interface{}{"name": "web"}},
+		map[string]interface{}{"kind": "Deployment", "spec": map[string]interface{}{"1": "one"}},
+		[]interface{}{"a", "b"},
+	}, out)
+
+	out, err = YAMLDocuments(`foo: bar`)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{map[string]interface{}{"foo": "bar"}}, out)
+
+	out, err = YAMLDocuments(``)
+	require.NoError(t, err)
+	assert.Empty(t, out)
+
+	_, err = YAMLDocuments("foo: bar\n---\nfoo: [\n")
+	require.ErrorContains(t, err, "YAML document 2")
+}
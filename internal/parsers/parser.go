@@ -17,13 +17,11 @@ func ParseData(mimeType, s string) (out any, err error) {
 	case iohelpers.JSONArrayMimetype:
 		out, err = JSONArray(s)
 	case iohelpers.YAMLMimetype:
-		out, err = YAML(s)
-		if err != nil {
-			// maybe it's a YAML array
-			out, err = YAMLArray(s)
-		}
+		out, err = yamlData(s)
+	case iohelpers.YAMLArrayMimetype:
+		out, err = YAMLDocuments(s)
 	case iohelpers.CSVMimetype:
-		out, err = CSV(s)
+		out, err = csvWithParams(mimeType, s)
 	case iohelpers.TOMLMimetype:
 		out, err = TOML(s)
 	case iohelpers.EnvMimetype:
@@ -32,8 +30,28 @@ func ParseData(mimeType, s string) (out any, err error) {
 		out = s
 	case iohelpers.CUEMimetype:
 		out, err = CUE(s)
+	case iohelpers.XMLMimetype:
+		out, err = XML(s)
 	default:
 		return nil, fmt.Errorf("data of type %q not yet supported", mimeType)
 	}
 	return out, err
 }
+
+// yamlData parses YAML, which may be a stream of multiple documents. A
+// single document is parsed as an object or an array, while multiple
+// documents are parsed into a slice with an element per document.
+func yamlData(s string) (out any, err error) {
+	docs, err := YAMLDocuments(s)
+	if err == nil && len(docs) > 1 {
+		return docs, nil
+	}
+
+	out, err = YAML(s)
+	if err != nil {
+		// maybe it's a YAML array
+		out, err = YAMLArray(s)
+	}
+
+	return out, err
+}
@@ -0,0 +1,33 @@
+package parsers
+
+import (
+	"testing"
+
+	"github.com/hairyhenderson/gomplate/v4/internal/iohelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseData_YAML(t *testing.T) {
+	// a single document is an object (or array), as always
+	out, err := ParseData(iohelpers.YAMLMimetype, "---\nfoo: bar\n")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"foo": "bar"}, out)
+
+	out, err = ParseData(iohelpers.YAMLMimetype, "- foo\n- bar\n")
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"foo", "bar"}, out)
+
+	// multiple documents are a slice of documents
+	out, err = ParseData(iohelpers.YAMLMimetype, "foo: bar\n---\nbaz: qux\n")
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"foo": "bar"},
+		map[string]interface{}{"baz": "qux"},
+	}, out)
+
+	// the array+yaml type always gives a slice of documents
+	out, err = ParseData(iohelpers.YAMLArrayMimetype, "foo: bar\n")
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{map[string]interface{}{"foo": "bar"}}, out)
+}
@@ -0,0 +1,14 @@
+package integration
+
+import (
+	"testing"
+)
+
+func TestBase32_Encode(t *testing.T) {
+	inOutTest(t, `{{ "foo" | base32.Encode }}`, "MZXW6===")
+	inOutTest(t, `{{ "foo" | base32.Encode (dict "padding" false) }}`, "MZXW6")
+}
+
+func TestBase32_Decode(t *testing.T) {
+	inOutTest(t, `{{ "mzxw6" | base32.Decode }}`, "foo")
+}
@@ -1,7 +1,12 @@
 package integration
 
 import (
+	"os"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tfs "gotest.tools/v3/fs"
 )
 
 func TestBase64_Encode(t *testing.T) {
@@ -11,3 +16,30 @@ func TestBase64_Encode(t *testing.T) {
 func TestBase64_Decode(t *testing.T) {
 	inOutTest(t, `{{ "Zm9v" | base64.Decode }}`, "foo")
 }
+
+func TestBase64_DecodeBinary(t *testing.T) {
+	tmpDir := tfs.NewDir(t, "gomplate-inttests")
+	t.Cleanup(tmpDir.Remove)
+
+	// not valid UTF-8
+	expected := []byte{0xff, 0x00, 0x80, 0xfe, 0xc3, 0x28}
+
+	o, e, err := cmd(t, "-i", `{{ base64.Decode "/wCA/sMo" }}`, "-o", "out").
+		withDir(tmpDir.Path()).run()
+	assertSuccess(t, o, e, err, "")
+
+	b, err := os.ReadFile(tmpDir.Join("out"))
+	require.NoError(t, err)
+	assert.Equal(t, expected, b)
+
+	o, e, err = cmd(t, "-i", `{{ base64.Decode "/wCA/sMo" | file.Write "a" }}`+
+		`{{ base64.DecodeBytes "/wCA/sMo" | file.Write "b" }}`).
+		withDir(tmpDir.Path()).run()
+	assertSuccess(t, o, e, err, "")
+
+	for _, f := range []string{"a", "b"} {
+		b, err = os.ReadFile(tmpDir.Join(f))
+		require.NoError(t, err)
+		assert.Equal(t, expected, b)
+	}
+}
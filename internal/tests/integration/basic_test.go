@@ -3,6 +3,7 @@ package integration
 import (
 	"io/fs"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/hairyhenderson/gomplate/v4/internal/iohelpers"
@@ -66,6 +67,30 @@ func TestBasic_ErrorsWithInputOutputImbalance(t *testing.T) {
 	assert.ErrorContains(t, err, "must provide same number of 'outputFiles' (1) as 'in' or 'inputFiles' (2) options")
 }
 
+func TestBasic_CombinesInputs(t *testing.T) {
+	tmpDir := tfs.NewDir(t, "gomplate-inttests",
+		tfs.WithFile("defs.tmpl", `{{ define "greeting" }}Hello, {{ . }}!{{ end }}{{ define "name" }}nobody{{ end }}`),
+		tfs.WithFile("more.tmpl", `{{ define "name" }}world{{ end }}`),
+		tfs.WithFile("main.tmpl", `{{ template "greeting" (tmpl.Exec "name") }}`),
+	)
+	t.Cleanup(tmpDir.Remove)
+
+	o, e, err := cmd(t, "--combine",
+		"-f", tmpDir.Join("defs.tmpl"),
+		"-f", tmpDir.Join("more.tmpl"),
+		"-f", tmpDir.Join("main.tmpl"),
+	).run()
+	assertSuccess(t, o, e, err, "Hello, world!")
+
+	_, _, err = cmd(t, "--combine",
+		"-f", tmpDir.Join("defs.tmpl"),
+		"-f", tmpDir.Join("main.tmpl"),
+		"-o", tmpDir.Join("a"),
+		"-o", tmpDir.Join("b"),
+	).run()
+	assert.ErrorContains(t, err, "must provide exactly one 'outputFiles' entry when using 'combine', got 2")
+}
+
 func TestBasic_RoutesInputsToProperOutputs(t *testing.T) {
 	tmpDir := setupBasicTest(t)
 	oneOut := tmpDir.Join("one.out")
@@ -185,6 +210,95 @@ func TestBasic_EmptyOutputSuppression(t *testing.T) {
 	require.ErrorIs(t, err, fs.ErrNotExist)
 }
 
+func TestBasic_FailedRenderLeavesOutputUntouched(t *testing.T) {
+	tmpDir := setupBasicTest(t)
+	out := tmpDir.Join("two")
+
+	_, _, err := cmd(t, "-i", `partial output{{ fail "oops" }}`, "-o", out).run()
+	assert.ErrorContains(t, err, "oops")
+
+	content, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.Equal(t, "hello\n", string(content))
+
+	// no temp files are left behind
+	entries, err := os.ReadDir(tmpDir.Path())
+	require.NoError(t, err)
+	names := []string{}
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	assert.DeepEqual(t, []string{"broken", "one", "subdir", "two"}, names)
+}
+
+func TestBasic_WritesThroughSymlinks(t *testing.T) {
+	if isWindows {
+		t.Skip("symlinks need special permissions on Windows")
+	}
+
+	tmpDir := setupBasicTest(t)
+	link := tmpDir.Join("link")
+	require.NoError(t, os.Symlink("two", link))
+
+	o, e, err := cmd(t, "-i", `updated`, "-o", link).run()
+	assertSuccess(t, o, e, err, "")
+
+	// the link is kept, and the file it points to is updated
+	fi, err := os.Lstat(link)
+	require.NoError(t, err)
+	assert.Assert(t, fi.Mode()&fs.ModeSymlink != 0)
+
+	content, err := os.ReadFile(tmpDir.Join("two"))
+	require.NoError(t, err)
+	assert.Equal(t, "updated", string(content))
+
+	// devices are written to directly
+	o, e, err = cmd(t, "-i", `discarded`, "-o", os.DevNull).run()
+	assertSuccess(t, o, e, err, "")
+}
+
+func TestBasic_ErrorFormatJSON(t *testing.T) {
+	_, e, err := cmd(t, "--error-format", "json", "-i", "hello\n{{ fail \"oops\" }}").run()
+	assert.ErrorContains(t, err, "oops")
+
+	// the error is only reported once, as a line of JSON
+	assert.Equal(t, 1, strings.Count(e, "\n"))
+	assert.Assert(t, cmp.Contains(e, `{"error":"renderTemplate: failed to render template <arg> (line 2, function \"fail\"): `))
+	assert.Assert(t, cmp.Contains(e, `"template":"<arg>","op":"render","function":"fail","line":2,"column":3}`))
+}
+
+func TestBasic_DryRun(t *testing.T) {
+	tmpDir := setupBasicTest(t)
+	changed := tmpDir.Join("two")
+	created := tmpDir.Join("sub", "new")
+
+	o, e, err := cmd(t,
+		"-f", tmpDir.Join("one"), "-o", changed,
+		"-f", tmpDir.Join("one"), "-o", created,
+		"--dry-run").run()
+	assert.NilError(t, err, e)
+	assert.Assert(t, cmp.Contains(o, "-hello\n+hi\n"))
+	assert.Assert(t, cmp.Contains(o, "dry run: 1 created, 1 changed, 0 unchanged\n"))
+
+	// nothing was written
+	content, err := os.ReadFile(changed)
+	require.NoError(t, err)
+	assert.Equal(t, "hello\n", string(content))
+
+	_, err = os.Stat(tmpDir.Join("sub"))
+	require.ErrorIs(t, err, fs.ErrNotExist)
+
+	_, _, err = cmd(t,
+		"-f", tmpDir.Join("one"), "-o", changed,
+		"--dry-run", "--fail-on-change").run()
+	assert.ErrorContains(t, err, "1 output file(s) would be created or changed")
+
+	o, e, err = cmd(t,
+		"-f", tmpDir.Join("two"), "-o", changed,
+		"--dry-run", "--fail-on-change").run()
+	assertSuccess(t, o, e, err, "unchanged "+changed+"\ndry run: 0 created, 0 changed, 1 unchanged\n")
+}
+
 func TestBasic_RoutesInputsToProperOutputsWithChmod(t *testing.T) {
 	tmpDir := setupBasicTest(t)
 	oneOut := tmpDir.Join("one.out")
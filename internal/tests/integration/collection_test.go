@@ -72,6 +72,22 @@ func TestColl_Sort(t *testing.T) {
 `)
 }
 
+func TestColl_KeysValuesHas(t *testing.T) {
+	tmpDir := setupCollTest(t)
+
+	// run a few times, to catch any dependence on map iteration order
+	for range 5 {
+		o, e, err := cmd(t, "-c", "config="+tmpDir.Join("config.json"),
+			"-i", `{{ $v := .config.values -}}
+{{ range coll.Keys $v }}{{ . }},{{ end }}
+{{ range coll.Values $v }}{{ . }},{{ end }}
+{{ coll.Has $v "three" }} {{ coll.Has $v "five" }}`).run()
+		assertSuccess(t, o, e, err, `four,one,three,
+map[a:eh?],uno,[5 6 7],
+true false`)
+	}
+}
+
 func TestColl_JSONPath(t *testing.T) {
 	tmpDir := setupCollTest(t)
 	o, e, err := cmd(t, "-c", "config="+tmpDir.Join("config.json"),
@@ -2,8 +2,10 @@ package integration
 
 import (
 	"bytes"
+	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/johannesboyne/gofakes3"
@@ -145,3 +147,71 @@ func TestDatasources_Blob_GCSDirectory(t *testing.T) {
 			os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")).run()
 	assertSuccess(t, o, e, err, "true")
 }
+
+func TestDatasources_Blob_S3Errors(t *testing.T) {
+	srv := setupDatasourcesBlobTest(t)
+
+	s3URL := func(key string) string {
+		return "s3://mybucket/" + key + "?region=us-east-1&disableSSL=true&s3ForcePathStyle=true&" +
+			"endpoint=" + srv.Listener.Addr().String()
+	}
+
+	// a missing object
+	_, _, err := cmd(t, "-d", "data="+s3URL("missing.json"), "-i", "{{ ds `data` }}").
+		withEnv("AWS_ACCESS_KEY_ID", "YOUR-ACCESSKEYID").
+		withEnv("AWS_SECRET_ACCESS_KEY", "YOUR-SECRETACCESSKEY").
+		run()
+	require.ErrorContains(t, err, `s3 object "missing.json" not found in bucket "mybucket"`)
+
+	// missing credentials
+	_, _, err = cmd(t, "-d", "data="+s3URL("foo.json"), "-i", "{{ ds `data` }}").
+		withEnv("AWS_ACCESS_KEY_ID", "").
+		withEnv("AWS_SECRET_ACCESS_KEY", "").
+		withEnv("AWS_PROFILE", "").
+		withEnv("AWS_SHARED_CREDENTIALS_FILE", os.DevNull).
+		withEnv("AWS_CONFIG_FILE", os.DevNull).
+		withEnv("AWS_EC2_METADATA_DISABLED", "true").
+		run()
+	require.ErrorContains(t, err, `no AWS credentials found to read s3 object "foo.json"`)
+}
+
+// setupFakeGCS starts a minimal fake of the GCS JSON API, with a bucket that
+// has no objects, and a bucket that no-one's allowed to read
+func setupFakeGCS(t *testing.T) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/storage/v1/b/denied/"):
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"error": {"code": 403, "message": "anonymous caller does not have storage.objects.get access"}}`))
+		case r.URL.Path == "/storage/v1/b/mybucket/o":
+			// listing the bucket finds nothing
+			_, _ = w.Write([]byte(`{"kind": "storage#objects"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error": {"code": 404, "message": "No such object"}}`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestDatasources_Blob_GCSErrors(t *testing.T) {
+	srv := setupFakeGCS(t)
+
+	// a missing object
+	_, _, err := cmd(t, "-d", "data=gs://mybucket/missing.json", "-i", "{{ ds `data` }}").
+		withEnv("STORAGE_EMULATOR_HOST", srv.Listener.Addr().String()).
+		withEnv("GOOGLE_ANON", "true").
+		run()
+	require.ErrorContains(t, err, `gs object "missing.json" not found in bucket "mybucket"`)
+
+	// an object the credentials can't read
+	_, _, err = cmd(t, "-d", "data=gs://denied/foo.json", "-i", "{{ ds `data` }}").
+		withEnv("STORAGE_EMULATOR_HOST", srv.Listener.Addr().String()).
+		withEnv("GOOGLE_ANON", "true").
+		run()
+	require.ErrorContains(t, err, `access to gs object "foo.json" in bucket "denied" denied`)
+}
@@ -32,4 +32,18 @@ func TestDatasources_Env(t *testing.T) {
 		withEnv("json_value", `{"value":"corge"}`).
 		run()
 	assertSuccess(t, o, e, err, "corge")
+
+	o, e, err = cmd(t, "-d", "e=env:?prefix=APP_&strip=true",
+		"-i", `{{ (ds "e").HOST }}:{{ (ds "e").PORT }}`).
+		withEnv("APP_HOST", "localhost").
+		withEnv("APP_PORT", "8080").
+		run()
+	assertSuccess(t, o, e, err, "localhost:8080")
+
+	o, e, err = cmd(t, "-d", "e=env:///?prefix=APP_",
+		"-i", `{{ range $k, $v := ds "e" }}{{ $k }}={{ $v }} {{ end }}`).
+		withEnv("APP_HOST", "localhost").
+		withEnv("APP_PORT", "8080").
+		run()
+	assertSuccess(t, o, e, err, "APP_HOST=localhost APP_PORT=8080 ")
 }
@@ -1,9 +1,13 @@
 package integration
 
 import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
 	"path/filepath"
 	"testing"
 
+	"gotest.tools/v3/assert"
 	"gotest.tools/v3/fs"
 )
 
@@ -106,6 +110,11 @@ func TestDatasources_File(t *testing.T) {
 		run()
 	assertSuccess(t, o, e, err, "foobarbaz")
 
+	o, e, err = cmd(t, "-i",
+		`{{defineDatasource "config" "config.json"}}{{defineDatasource "config" "config.yml"}}`).
+		withDir(tmpDir.Path()).run()
+	assertFailed(t, o, e, err, `datasource \"config\" is already defined`)
+
 	o, e, err = cmd(t, "-d", "config="+tmpDir.Join("config.yml"),
 		"-i", `{{(datasource "config").foo.bar}}`).run()
 	assertSuccess(t, o, e, err, "baz")
@@ -189,3 +198,41 @@ func TestDatasources_File_Directory(t *testing.T) {
 		withDir(tmpDir.Path()).run()
 	assertSuccess(t, o, e, err, "core.yaml root key: cloud")
 }
+
+func TestDatasources_Stdin(t *testing.T) {
+	o, e, err := cmd(t, "-c", "cfg=stdin:///cfg.yaml",
+		"-i", `{{ .cfg.key }} {{ (ds "cfg").key }}`).
+		withStdin("key: value\n").run()
+	assertSuccess(t, o, e, err, "value value")
+
+	_, _, err = cmd(t, "-d", "cfg=stdin:///cfg.yaml").
+		withStdin("key: value\n").run()
+	assert.ErrorContains(t, err, `datasource "cfg" can't both be read from stdin`)
+}
+
+func TestDatasources_File_Decode(t *testing.T) {
+	buf := &bytes.Buffer{}
+	zw := gzip.NewWriter(buf)
+	_, err := zw.Write([]byte(`{"foo": {"bar": "baz"}}`))
+	assert.NilError(t, err)
+	assert.NilError(t, zw.Close())
+
+	tmpDir := fs.NewDir(t, "gomplate-inttests",
+		fs.WithFile("config.json.gz", buf.String()),
+		fs.WithFile("config.b64", base64.StdEncoding.EncodeToString(buf.Bytes())),
+	)
+	t.Cleanup(tmpDir.Remove)
+
+	o, e, err := cmd(t, "-d", "config=config.json.gz?type=application/json&decode=gzip",
+		"-i", `{{ (ds "config").foo.bar }}`).withDir(tmpDir.Path()).run()
+	assertSuccess(t, o, e, err, "baz")
+
+	o, e, err = cmd(t, "-d", "config=config.b64?type=application/json&decode=base64+gzip",
+		"-i", `{{ (ds "config").foo.bar }}`).withDir(tmpDir.Path()).run()
+	assertSuccess(t, o, e, err, "baz")
+
+	_, _, err = cmd(t, "-d", "config=config.b64?type=application/json&decode=gzip",
+		"-i", `{{ (ds "config").foo.bar }}`).withDir(tmpDir.Path()).run()
+	assert.ErrorContains(t, err, "couldn't read datasource 'config'")
+	assert.ErrorContains(t, err, "gzip decode")
+}
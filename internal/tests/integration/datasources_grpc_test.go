@@ -0,0 +1,53 @@
+package integration
+
+import (
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"gotest.tools/v3/assert"
+)
+
+func setupDatasourcesGRPCTest(t *testing.T) string {
+	t.Helper()
+
+	srv := grpc.NewServer()
+
+	hs := health.NewServer()
+	hs.SetServingStatus("web", healthpb.HealthCheckResponse_SERVING)
+	hs.SetServingStatus("web,api", healthpb.HealthCheckResponse_NOT_SERVING)
+	healthpb.RegisterHealthServer(srv, hs)
+	reflection.Register(srv)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NilError(t, err)
+
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	return lis.Addr().String()
+}
+
+func TestDatasources_GRPC(t *testing.T) {
+	addr := setupDatasourcesGRPCTest(t)
+
+	// the request is percent-encoded, since --datasource values are split on
+	// commas and can't contain bare quotes
+	o, e, err := cmd(t,
+		"-d", "health=grpc://"+addr+"/grpc.health.v1.Health/Check#%7B%22service%22:%22web%22%7D",
+		"-i", `{{ (ds "health").status }}`).run()
+	assertSuccess(t, o, e, err, "SERVING")
+
+	o, e, err = cmd(t,
+		"-d", "health=grpc://"+addr+"/grpc.health.v1.Health/Check#%7B%22service%22:%22web%2Capi%22%7D",
+		"-i", `{{ (ds "health").status }}`).run()
+	assertSuccess(t, o, e, err, "NOT_SERVING")
+
+	// unencoded JSON is fine in a template
+	o, e, err = cmd(t,
+		"-i", `{{ (ds "grpc://`+addr+`/grpc.health.v1.Health/Check#{\"service\":\"web\"}").status }}`).run()
+	assertSuccess(t, o, e, err, "SERVING")
+}
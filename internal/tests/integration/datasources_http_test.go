@@ -1,9 +1,16 @@
 package integration
 
 import (
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync/atomic"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
+	tfs "gotest.tools/v3/fs"
 )
 
 func setupDatasourcesHTTPTest(t *testing.T) *httptest.Server {
@@ -16,6 +23,14 @@ func setupDatasourcesHTTPTest(t *testing.T) *httptest.Server {
 	mux.HandleFunc("/bogus.csv", typeHandler("text/plain", `{"value": "json"}`))
 	mux.HandleFunc("/list", typeHandler("application/array+json", `[1, 2, 3, 4, 5]`))
 	mux.HandleFunc("/params", paramHandler(t))
+	mux.HandleFunc("/template", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer letmein" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte(`{{ "hello" | strings.ToUpper }}`))
+	})
 
 	srv := httptest.NewServer(mux)
 	t.Cleanup(srv.Close)
@@ -42,6 +57,20 @@ func TestDatasources_HTTP(t *testing.T) {
 	assertSuccess(t, o, e, err, "gzip")
 }
 
+func TestDatasources_HTTP_InURL(t *testing.T) {
+	srv := setupDatasourcesHTTPTest(t)
+
+	o, e, err := cmd(t, "--in-url", srv.URL+"/template",
+		"-H", "in=Authorization: Bearer letmein").run()
+	assertSuccess(t, o, e, err, "HELLO")
+
+	_, _, err = cmd(t, "--in-url", srv.URL+"/template").run()
+	assert.ErrorContains(t, err, "failed to read input template from "+srv.URL+"/template")
+
+	_, _, err = cmd(t, "--in-url", srv.URL+"/template", "-f", "foo.tmpl").run()
+	assert.ErrorContains(t, err, "only one of these options is supported at a time: 'inURL', 'inputFiles'")
+}
+
 func TestDatasources_HTTP_TypeOverridePrecedence(t *testing.T) {
 	srv := setupDatasourcesHTTPTest(t)
 
@@ -85,3 +114,73 @@ func TestDatasources_HTTP_AppendQueryAfterSubPaths(t *testing.T) {
 		"-i", "{{ (ds `foo` `bogus.csv`).value }}").run()
 	assertSuccess(t, o, e, err, "json")
 }
+
+func TestDatasources_HTTP_ReadOncePerRun(t *testing.T) {
+	hits := atomic.Int32{}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			hits.Add(1)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"value": "json"}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	tmpDir := tfs.NewDir(t, "gomplate-inttests",
+		tfs.WithDir("in",
+			tfs.WithFile("a.tmpl", `{{ (ds "data").value }}`),
+			tfs.WithFile("b.tmpl", `{{ (ds "other").value }}`),
+			tfs.WithFile("c.tmpl", `{{ (ds "data").value }}{{ (ds "data").value }}`),
+		),
+	)
+	t.Cleanup(tmpDir.Remove)
+
+	o, e, err := cmd(t, "--input-dir", tmpDir.Join("in"), "--output-dir", tmpDir.Join("out"),
+		"-d", "data="+srv.URL+"/data?x=1",
+		"-d", "other="+srv.URL+"/data?x=1",
+	).run()
+	assertSuccess(t, o, e, err, "")
+	assert.Equal(t, int32(1), hits.Load())
+
+	hits.Store(0)
+	o, e, err = cmd(t, "--input-dir", tmpDir.Join("in"), "--output-dir", tmpDir.Join("out"),
+		"-d", "data="+srv.URL+"/data?x=1",
+		"-d", "other="+srv.URL+"/data?x=1",
+		"--no-cache",
+	).run()
+	assertSuccess(t, o, e, err, "")
+	assert.Equal(t, int32(4), hits.Load())
+}
+
+func TestDatasources_HTTP_Post(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		if r.Method != http.MethodPost || r.Header.Get("Authorization") != "Bearer s3cr3t" {
+			http.Error(w, "denied", http.StatusForbidden)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"data": {"query": %q}}`, strings.TrimSpace(string(b)))
+	}))
+	t.Cleanup(srv.Close)
+
+	tmpDir := tfs.NewDir(t, "gomplate-inttests",
+		tfs.WithFile("query.graphql", `{ viewer { login } }`+"\n"),
+	)
+	t.Cleanup(tmpDir.Remove)
+
+	o, e, err := cmd(t,
+		"-d", "api="+srv.URL+"/graphql?method=POST&body=@query.graphql",
+		"-H", "api=Authorization: Bearer s3cr3t",
+		"-i", `{{ (ds "api").data.query }}`,
+	).withDir(tmpDir.Path()).run()
+	assertSuccess(t, o, e, err, "{ viewer { login } }")
+
+	o, e, err = cmd(t,
+		"-d", "api="+srv.URL+"/graphql?method=POST&body=@query.graphql",
+		"-i", `{{ (ds "api").data.query }}`,
+	).withDir(tmpDir.Path()).run()
+	assertFailed(t, o, e, err, "http POST failed with status 403 Forbidden: denied")
+}
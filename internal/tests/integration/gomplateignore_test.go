@@ -286,6 +286,8 @@ func TestGomplateignore_WithExcludeProcessing(t *testing.T) {
 `, []string{
 		"--exclude-processing", "crash.bin",
 		"--exclude-processing", "log/*.zip",
+		// excluded files are never copied, even when they should be passed through
+		"--exclude-processing", "rules/fire.txt",
 		"--exclude", "rules/*.txt",
 		"--exclude", "sprites/*.ini",
 	},
@@ -310,3 +312,19 @@ func TestGomplateignore_WithExcludeProcessing(t *testing.T) {
 		"crash.bin", "logs/archive.zip", "manifest.json", "rules/index.csv",
 		"sprites/demon.xml", "sprites/human.csv"), files)
 }
+
+func TestGomplateignore_InvalidPattern(t *testing.T) {
+	tmpDir := setupGomplateignoreTest(t)(
+		tfs.WithFile(".gomplateignore", "*.log\n"),
+		tfs.WithDir("sub",
+			tfs.WithFile(".gomplateignore", "ok/\n\nfoo[\n"),
+			tfs.WithFile("a.txt", "a"),
+		),
+	)
+
+	_, _, err := cmd(t,
+		"--input-dir", tmpDir.Join("in"),
+		"--output-dir", tmpDir.Join("out"),
+	).run()
+	require.ErrorContains(t, err, filepath.Join("in", "sub", ".gomplateignore")+`:3: invalid pattern "foo["`)
+}
@@ -1,8 +1,14 @@
 package integration
 
 import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/hairyhenderson/gomplate/v4/internal/iohelpers"
 	tassert "github.com/stretchr/testify/assert"
@@ -114,6 +120,35 @@ func TestInputDir_InputDirWithModeOverride(t *testing.T) {
 	}
 }
 
+func TestInputDir_InputDirWithModeGlobs(t *testing.T) {
+	tmpDir := setupInputDirTest(t)
+	o, e, err := cmd(t,
+		"--input-dir", tmpDir.Join("in"),
+		"--output-dir", tmpDir.Join("out"),
+		"--chmod", "*.sh=0700",
+		"--chmod", "inner/*=0640",
+		"--chmod", "0604",
+		"-d", "config="+tmpDir.Join("config.yml"),
+	).run()
+	assertSuccess(t, o, e, err, "")
+
+	testdata := []struct {
+		path string
+		mode os.FileMode
+	}{
+		{tmpDir.Join("out", "eins.txt"), 0o604},
+		{tmpDir.Join("out", "inner", "deux.txt"), 0o640},
+		{tmpDir.Join("out", "drei.sh"), 0o700},
+		{tmpDir.Join("out", "vier.txt"), 0o604},
+	}
+	for _, v := range testdata {
+		info, err := os.Stat(v.path)
+		assert.NilError(t, err)
+		m := iohelpers.NormalizeFileMode(v.mode)
+		assert.Equal(t, m, info.Mode(), v.path)
+	}
+}
+
 func TestInputDir_OutputMapInline(t *testing.T) {
 	tmpDir := setupInputDirTest(t)
 	o, e, err := cmd(t,
@@ -225,6 +260,58 @@ func TestInputDir_DefaultOutputDir(t *testing.T) {
 	assert.Equal(t, `deux * deux`, string(content))
 }
 
+func TestInputDir_Compress(t *testing.T) {
+	tmpDir := setupInputDirTest(t)
+
+	render := func() {
+		t.Helper()
+
+		o, e, err := cmd(t,
+			"--input-dir", tmpDir.Join("in"),
+			"--output-dir", tmpDir.Join("out"),
+			"-d", "config="+tmpDir.Join("config.yml"),
+			"--compress", "gzip",
+		).run()
+		assertSuccess(t, o, e, err, "")
+	}
+
+	render()
+
+	files, err := os.ReadDir(tmpDir.Join("out"))
+	assert.NilError(t, err)
+	tassert.Len(t, files, 4)
+
+	readGzip := func(path string) string {
+		t.Helper()
+
+		f, err := os.Open(path)
+		assert.NilError(t, err)
+		defer f.Close()
+
+		zr, err := gzip.NewReader(f)
+		assert.NilError(t, err)
+
+		b, err := io.ReadAll(zr)
+		assert.NilError(t, err)
+
+		return string(b)
+	}
+
+	assert.Equal(t, "eins", readGzip(tmpDir.Join("out", "eins.txt.gz")))
+	assert.Equal(t, "deux", readGzip(tmpDir.Join("out", "inner", "deux.txt.gz")))
+	assert.Equal(t, "deux * deux", readGzip(tmpDir.Join("out", "vier.txt.gz")))
+
+	// unchanged files aren't rewritten
+	old := time.Now().Add(-time.Hour).Truncate(time.Second)
+	assert.NilError(t, os.Chtimes(tmpDir.Join("out", "eins.txt.gz"), old, old))
+
+	render()
+
+	fi, err := os.Stat(tmpDir.Join("out", "eins.txt.gz"))
+	assert.NilError(t, err)
+	assert.Equal(t, old, fi.ModTime())
+}
+
 func TestInputDir_ReportsFilenameWithBadInputFile(t *testing.T) {
 	tmpDir := setupInputDirTest(t)
 	o, _, err := cmd(t,
@@ -236,6 +323,49 @@ func TestInputDir_ReportsFilenameWithBadInputFile(t *testing.T) {
 	assert.Equal(t, "", o)
 }
 
+func TestInputDir_OutputMapWithOutputDir(t *testing.T) {
+	tmpDir := setupInputDirTest(t)
+	o, e, err := cmd(t,
+		"--input-dir", tmpDir.Join("in"),
+		"--output-dir", tmpDir.Join("out"),
+		"--output-map", `mapped/{{ .in | strings.ToUpper }}`,
+		"-d", "config.yml",
+	).withDir(tmpDir.Path()).run()
+	assertSuccess(t, o, e, err, "")
+
+	b, err := os.ReadFile(tmpDir.Join("out", "mapped", "EINS.TXT"))
+	assert.NilError(t, err)
+	assert.Equal(t, "eins", string(b))
+}
+
+func TestInputDir_OutputMapEscapes(t *testing.T) {
+	tmpDir := setupInputDirTest(t)
+	_, _, err := cmd(t,
+		"--input-dir", tmpDir.Join("in"),
+		"--output-dir", tmpDir.Join("out"),
+		"--output-map", `../escaped-{{ .in }}`,
+		"-d", "config.yml",
+	).withDir(tmpDir.Path()).run()
+	assert.ErrorContains(t, err, "the path must be relative, and within the output directory")
+
+	matches, err := filepath.Glob(tmpDir.Join("escaped-*"))
+	assert.NilError(t, err)
+	tassert.Empty(t, matches)
+
+	// without --output-dir, the map isn't restricted
+	o, e, err := cmd(t,
+		"--input-dir", tmpDir.Join("in"),
+		"--include", "eins.txt",
+		"--output-map", `../rendered/{{ .in }}`,
+		"-d", "config="+tmpDir.Join("config.yml"),
+	).withDir(tmpDir.Join("out")).run()
+	assertSuccess(t, o, e, err, "")
+
+	b, err := os.ReadFile(tmpDir.Join("rendered", "eins.txt"))
+	assert.NilError(t, err)
+	assert.Equal(t, "eins", string(b))
+}
+
 func TestInputDir_InputDirCwd(t *testing.T) {
 	tmpDir := setupInputDirTest(t)
 	o, e, err := cmd(t,
@@ -265,3 +395,52 @@ func TestInputDir_InputDirCwd(t *testing.T) {
 		assert.Equal(t, v.content, string(content))
 	}
 }
+
+func TestInputDir_Archive(t *testing.T) {
+	buf := &bytes.Buffer{}
+	zw := zip.NewWriter(buf)
+	for name, content := range map[string]string{
+		"templates/eins.txt":       `{{ (ds "config").one }}`,
+		"templates/inner/deux.txt": `{{ (ds "config").two }}`,
+		"data/config.yml":          "one: eins\ntwo: deux\n",
+	} {
+		w, err := zw.Create(name)
+		assert.NilError(t, err)
+		_, err = w.Write([]byte(content))
+		assert.NilError(t, err)
+	}
+	assert.NilError(t, zw.Close())
+
+	tmpDir := fs.NewDir(t, "gomplate-inttests",
+		fs.WithFile("bundle.zip", buf.String()),
+	)
+
+	// output paths are relative to the input directory in the archive
+	o, e, err := cmd(t,
+		"--input-dir", "archive://bundle.zip//templates",
+		"--output-dir", "out",
+		"-d", "config=archive://bundle.zip//data/config.yml",
+	).withDir(tmpDir.Path()).run()
+	assertSuccess(t, o, e, err, "")
+
+	b, err := os.ReadFile(tmpDir.Join("out", "eins.txt"))
+	assert.NilError(t, err)
+	assert.Equal(t, "eins", string(b))
+
+	b, err = os.ReadFile(tmpDir.Join("out", "inner", "deux.txt"))
+	assert.NilError(t, err)
+	assert.Equal(t, "deux", string(b))
+
+	o, e, err = cmd(t,
+		"-f", "archive://"+filepath.ToSlash(tmpDir.Join("bundle.zip"))+"//templates/inner/deux.txt",
+		"-d", "config=archive://bundle.zip//data/config.yml",
+	).withDir(tmpDir.Path()).run()
+	assertSuccess(t, o, e, err, "deux")
+
+	_, e, err = cmd(t,
+		"--input-dir", "archive://bundle.zip//missing",
+		"--output-dir", "out",
+	).withDir(tmpDir.Path()).run()
+	assert.ErrorContains(t, err, "")
+	tassert.Contains(t, e, "missing")
+}
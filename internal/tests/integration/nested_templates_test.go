@@ -12,6 +12,12 @@ func setupNestedTemplatesTest(t *testing.T) *fs.Dir {
 		fs.WithDir("templates",
 			fs.WithFile("one.t", `{{ . }}`),
 			fs.WithFile("two.t", `{{ range $n := (seq 2) }}{{ $n }}: {{ $ }} {{ end }}`),
+			fs.WithDir("sub",
+				fs.WithFile("three.t", `three {{ . }}`),
+				// a cycle, which must not be followed
+				fs.WithSymlink("loop", ".."),
+			),
+			fs.WithSymlink("four.t", "sub/three.t"),
 		),
 	)
 	t.Cleanup(tmpDir.Remove)
@@ -46,4 +52,10 @@ func TestNestedTemplates(t *testing.T) {
 {{ template "templates/two.t" "two"}}`).
 		withDir(tmpDir.Path()).run()
 	assertSuccess(t, o, e, err, "one\n1: two 2: two ")
+
+	// subdirectories are included, and symlinks to files are followed
+	o, e, err = cmd(t, "-t", "t=templates/",
+		"-i", `{{ template "t/sub/three.t" "x" }}, {{ template "t/four.t" "y" }}`).
+		withDir(tmpDir.Path()).run()
+	assertSuccess(t, o, e, err, "three x, three y")
 }
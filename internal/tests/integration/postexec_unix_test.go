@@ -0,0 +1,62 @@
+//go:build !windows
+// +build !windows
+
+package integration
+
+import (
+	"io/fs"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tfs "gotest.tools/v3/fs"
+)
+
+func TestPostExecEach(t *testing.T) {
+	tmpDir := tfs.NewDir(t, "gomplate-inttests",
+		tfs.WithDir("in",
+			tfs.WithFile("a", `{{ "a" }}`),
+			tfs.WithFile("b", `{{ "b" }}`),
+		),
+	)
+	t.Cleanup(tmpDir.Remove)
+
+	// the command sees the file once it's been moved into place
+	o, e, err := cmd(t, "-i", "hello", "-o", tmpDir.Join("out"),
+		"--post-exec-each", "cat {}").run()
+	assertSuccess(t, o, e, err, "hello")
+
+	// the file name is appended when there's no {}
+	o, e, err = cmd(t, "--input-dir", tmpDir.Join("in"), "--output-dir", tmpDir.Join("outdir"),
+		"--parallelism", "1", "--post-exec-each", "cat").run()
+	assertSuccess(t, o, e, err, "ab")
+
+	// files that are left alone because they didn't change don't run the
+	// command
+	o, e, err = cmd(t, "-i", "hello", "-o", tmpDir.Join("out"),
+		"--post-exec-each", "cat {}").run()
+	assertSuccess(t, o, e, err, "")
+
+	// arguments can be quoted
+	o, e, err = cmd(t, "-i", "hello, world", "-o", tmpDir.Join("out"),
+		"--post-exec-each", `sh -c 'echo "changed: $0"' {}`).run()
+	assertSuccess(t, o, e, err, "changed: "+tmpDir.Join("out")+"\n")
+
+	_, _, err = cmd(t, "-i", "hello", "-o", tmpDir.Join("out"),
+		"--post-exec-each", "false").run()
+	require.Error(t, err)
+	assert.ErrorContains(t, err, `post-exec command "false `+tmpDir.Join("out")+`" failed for `+tmpDir.Join("out"))
+
+	// failed renders don't run the command
+	_, _, err = cmd(t, "-i", `{{ fail "oops" }}`, "-o", tmpDir.Join("failed"),
+		"--post-exec-each", "touch {}.ran").run()
+	assert.ErrorContains(t, err, "oops")
+
+	_, err = os.Stat(tmpDir.Join("failed.ran"))
+	require.ErrorIs(t, err, fs.ErrNotExist)
+
+	_, _, err = cmd(t, "-i", "hello", "-o", tmpDir.Join("out"),
+		"--post-exec-each", `echo "unterminated`).run()
+	assert.ErrorContains(t, err, "invalid --post-exec-each command")
+}
@@ -1,6 +1,12 @@
 package gomplate
 
-import "time"
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
 
 // Metrics tracks interesting basic metrics around gomplate executions. Warning: experimental!
 // This may change in breaking ways without warning. This is not subject to any semantic versioning guarantees!
@@ -18,7 +24,13 @@ type MetricsType struct {
 
 	TemplatesGathered  int
 	TemplatesProcessed int
+	// the number of output files that weren't written because their content
+	// was unchanged
+	TemplatesUnchanged int
 	Errors             int
+
+	// guards fields updated while templates are rendered concurrently
+	mu sync.Mutex
 }
 
 func newMetrics() *MetricsType {
@@ -26,3 +38,74 @@ func newMetrics() *MetricsType {
 		RenderDuration: make(map[string]time.Duration),
 	}
 }
+
+// recordRender records the outcome of rendering a single template. Safe for
+// concurrent use.
+func (m *MetricsType) recordRender(name string, d time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.RenderDuration[name] = d
+	if err != nil {
+		m.Errors++
+	} else {
+		m.TemplatesProcessed++
+	}
+}
+
+// recordUnchanged records that an output file was left untouched because its
+// content didn't change. Safe for concurrent use.
+func (m *MetricsType) recordUnchanged() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.TemplatesUnchanged++
+}
+
+// metricsJSON is the JSON representation of the metrics, with durations in
+// milliseconds
+type metricsJSON struct {
+	RenderDurations     map[string]float64 `json:"renderDurationsMs"`
+	GatherDuration      float64            `json:"gatherDurationMs"`
+	TotalRenderDuration float64            `json:"totalRenderDurationMs"`
+	TemplatesGathered   int                `json:"templatesGathered"`
+	TemplatesProcessed  int                `json:"templatesProcessed"`
+	TemplatesUnchanged  int                `json:"templatesUnchanged"`
+	Errors              int                `json:"errors"`
+}
+
+// WriteJSON writes the metrics to w as a single line of JSON, with durations
+// in milliseconds. Warning: experimental! The format may change in breaking
+// ways without warning.
+func (m *MetricsType) WriteJSON(w io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ms := func(d time.Duration) float64 {
+		return float64(d) / float64(time.Millisecond)
+	}
+
+	out := metricsJSON{
+		RenderDurations:     make(map[string]float64, len(m.RenderDuration)),
+		GatherDuration:      ms(m.GatherDuration),
+		TotalRenderDuration: ms(m.TotalRenderDuration),
+		TemplatesGathered:   m.TemplatesGathered,
+		TemplatesProcessed:  m.TemplatesProcessed,
+		TemplatesUnchanged:  m.TemplatesUnchanged,
+		Errors:              m.Errors,
+	}
+
+	for name, d := range m.RenderDuration {
+		out.RenderDurations[name] = ms(d)
+	}
+
+	// json.Encoder terminates the output with a newline
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+
+	if err := enc.Encode(out); err != nil {
+		return fmt.Errorf("failed to write metrics: %w", err)
+	}
+
+	return nil
+}
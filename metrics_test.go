@@ -0,0 +1,66 @@
+package gomplate
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsWriteJSON(t *testing.T) {
+	m := newMetrics()
+	m.GatherDuration = 1500 * time.Microsecond
+	m.TotalRenderDuration = 3 * time.Millisecond
+	m.TemplatesGathered = 2
+	m.recordRender("a.tmpl", 2*time.Millisecond, nil)
+	m.recordRender("b.tmpl", time.Millisecond, errors.New("failed"))
+	m.recordUnchanged()
+
+	out := &bytes.Buffer{}
+	require.NoError(t, m.WriteJSON(out))
+
+	assert.JSONEq(t, `{
+		"renderDurationsMs": {"a.tmpl": 2, "b.tmpl": 1},
+		"gatherDurationMs": 1.5,
+		"totalRenderDurationMs": 3,
+		"templatesGathered": 2,
+		"templatesProcessed": 1,
+		"templatesUnchanged": 1,
+		"errors": 1
+	}`, out.String())
+
+	// a single line
+	assert.Equal(t, 1, strings.Count(out.String(), "\n"))
+	assert.True(t, strings.HasSuffix(out.String(), "\n"))
+}
+
+func TestRun_MetricsJSON(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+
+	cfg := &Config{
+		Input:       "hello",
+		Stdout:      stdout,
+		Stderr:      stderr,
+		MetricsJSON: true,
+	}
+	require.NoError(t, Run(context.Background(), cfg))
+	assert.Equal(t, "hello", stdout.String())
+	assert.Contains(t, stderr.String(), `"templatesProcessed":1`)
+
+	// metrics are written even when rendering fails
+	stderr.Reset()
+	cfg = &Config{
+		Input:       "{{ fail }}",
+		Stdout:      stdout,
+		Stderr:      stderr,
+		MetricsJSON: true,
+	}
+	require.Error(t, Run(context.Background(), cfg))
+	assert.Contains(t, stderr.String(), `"errors":1`)
+}
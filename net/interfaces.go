@@ -0,0 +1,142 @@
+package net
+
+import (
+	"net"
+	"net/netip"
+	"strings"
+)
+
+// Interface is a local network interface, with its addresses
+type Interface struct {
+	// Name - the interface's name, like "eth0"
+	Name string
+	// HardwareAddr - the interface's MAC address, if it has one
+	HardwareAddr string
+	// Flags - the interface's flags, like "up" and "loopback"
+	Flags []string
+	// Addrs - the interface's addresses, with their prefix lengths
+	Addrs []netip.Prefix
+	Index int
+	MTU   int
+}
+
+// systemInterfaces - overridable for testing
+//
+//nolint:gochecknoglobals
+var systemInterfaces = net.Interfaces
+
+// Interfaces returns the local network interfaces, and their addresses
+func Interfaces() ([]Interface, error) {
+	ifaces, err := systemInterfaces()
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]Interface, len(ifaces))
+	for i, iface := range ifaces {
+		addrs, err := iface.Addrs()
+		if err != nil {
+			return nil, err
+		}
+
+		out[i] = Interface{
+			Name:         iface.Name,
+			HardwareAddr: iface.HardwareAddr.String(),
+			Flags:        interfaceFlags(iface.Flags),
+			Addrs:        addrPrefixes(addrs),
+			Index:        iface.Index,
+			MTU:          iface.MTU,
+		}
+	}
+
+	return out, nil
+}
+
+func interfaceFlags(f net.Flags) []string {
+	if f == 0 {
+		return []string{}
+	}
+
+	return strings.Split(f.String(), "|")
+}
+
+// addrPrefixes converts interface addresses to prefixes - addresses that
+// aren't IP networks are skipped
+func addrPrefixes(addrs []net.Addr) []netip.Prefix {
+	out := make([]netip.Prefix, 0, len(addrs))
+	for _, a := range addrs {
+		n, ok := a.(*net.IPNet)
+		if !ok {
+			continue
+		}
+
+		ip, ok := netip.AddrFromSlice(n.IP)
+		if !ok {
+			continue
+		}
+
+		ones, _ := n.Mask.Size()
+		out = append(out, netip.PrefixFrom(ip.Unmap(), ones))
+	}
+
+	return out
+}
+
+// FirstPrivateIP returns the first private address (in the RFC 1918 ranges,
+// or an IPv6 unique local address) of an interface that's up, and isn't a
+// loopback interface. IPv4 addresses are preferred. An empty string is
+// returned when there's no such address.
+func FirstPrivateIP() (string, error) {
+	ifaces, err := Interfaces()
+	if err != nil {
+		return "", err
+	}
+
+	return firstIP(ifaces, netip.Addr.IsPrivate), nil
+}
+
+// FirstGlobalIP returns the first global unicast address that isn't private
+// (see FirstPrivateIP) of an interface that's up, and isn't a loopback
+// interface. IPv4 addresses are preferred. An empty string is returned when
+// there's no such address.
+func FirstGlobalIP() (string, error) {
+	ifaces, err := Interfaces()
+	if err != nil {
+		return "", err
+	}
+
+	return firstIP(ifaces, func(ip netip.Addr) bool {
+		return ip.IsGlobalUnicast() && !ip.IsPrivate()
+	}), nil
+}
+
+// firstIP returns the first address matching the selector, checking all IPv4
+// addresses before IPv6 addresses
+func firstIP(ifaces []Interface, match func(netip.Addr) bool) string {
+	for _, v4 := range []bool{true, false} {
+		for _, iface := range ifaces {
+			if !hasFlag(iface, "up") || hasFlag(iface, "loopback") {
+				continue
+			}
+
+			for _, p := range iface.Addrs {
+				ip := p.Addr()
+				if ip.Is4() == v4 && match(ip) {
+					return ip.String()
+				}
+			}
+		}
+	}
+
+	return ""
+}
+
+func hasFlag(iface Interface, flag string) bool {
+	for _, f := range iface.Flags {
+		if f == flag {
+			return true
+		}
+	}
+
+	return false
+}
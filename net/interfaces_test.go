@@ -0,0 +1,106 @@
+package net
+
+import (
+	"errors"
+	"net"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInterfaces(t *testing.T) {
+	ifaces, err := Interfaces()
+	require.NoError(t, err)
+
+	// every host has a loopback interface
+	found := false
+	for _, iface := range ifaces {
+		if hasFlag(iface, "loopback") {
+			found = true
+			assert.NotEmpty(t, iface.Name)
+		}
+	}
+	assert.True(t, found)
+
+	orig := systemInterfaces
+	t.Cleanup(func() { systemInterfaces = orig })
+
+	systemInterfaces = func() ([]net.Interface, error) {
+		return nil, errors.New("boom")
+	}
+
+	_, err = Interfaces()
+	require.Error(t, err)
+
+	_, err = FirstPrivateIP()
+	require.Error(t, err)
+
+	_, err = FirstGlobalIP()
+	require.Error(t, err)
+}
+
+func TestAddrPrefixes(t *testing.T) {
+	addrs := []net.Addr{
+		&net.IPNet{IP: net.ParseIP("10.0.0.5"), Mask: net.CIDRMask(8, 32)},
+		&net.IPNet{IP: net.ParseIP("fd00::1"), Mask: net.CIDRMask(64, 128)},
+		&net.IPAddr{IP: net.ParseIP("192.168.0.1")},
+	}
+
+	assert.Equal(t, []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.5/8"),
+		netip.MustParsePrefix("fd00::1/64"),
+	}, addrPrefixes(addrs))
+
+	assert.Equal(t, []string{}, interfaceFlags(0))
+	assert.Equal(t, []string{"up", "loopback"}, interfaceFlags(net.FlagUp|net.FlagLoopback))
+}
+
+func TestFirstIP(t *testing.T) {
+	ifaces := []Interface{
+		{
+			Name:  "lo",
+			Flags: []string{"up", "loopback"},
+			Addrs: []netip.Prefix{netip.MustParsePrefix("127.0.0.1/8")},
+		},
+		{
+			Name:  "eth1",
+			Flags: []string{"broadcast"},
+			Addrs: []netip.Prefix{netip.MustParsePrefix("192.168.1.5/24")},
+		},
+		{
+			Name:  "eth0",
+			Flags: []string{"up", "broadcast"},
+			Addrs: []netip.Prefix{
+				netip.MustParsePrefix("fe80::1/64"),
+				netip.MustParsePrefix("fd00::5/64"),
+				netip.MustParsePrefix("2001:db8::5/64"),
+				netip.MustParsePrefix("10.0.0.5/8"),
+			},
+		},
+		{
+			Name:  "eth2",
+			Flags: []string{"up"},
+			Addrs: []netip.Prefix{netip.MustParsePrefix("203.0.113.7/24")},
+		},
+	}
+
+	isPrivate := netip.Addr.IsPrivate
+	isGlobal := func(ip netip.Addr) bool {
+		return ip.IsGlobalUnicast() && !ip.IsPrivate()
+	}
+
+	// IPv4 addresses are preferred, and down interfaces are skipped
+	assert.Equal(t, "10.0.0.5", firstIP(ifaces, isPrivate))
+	assert.Equal(t, "203.0.113.7", firstIP(ifaces, isGlobal))
+
+	assert.Equal(t, "fd00::5", firstIP(ifaces[:3], func(ip netip.Addr) bool {
+		return ip.Is6() && ip.IsPrivate()
+	}))
+	assert.Equal(t, "2001:db8::5", firstIP(ifaces[:3], isGlobal))
+
+	// no match is not an error
+	assert.Equal(t, "", firstIP(ifaces[:2], isPrivate))
+	assert.Equal(t, "", firstIP(nil, isGlobal))
+}
@@ -2,12 +2,30 @@
 package net
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/hairyhenderson/gomplate/v4/env"
 )
 
+// defaultTimeout is the maximum time to wait for a DNS lookup, unless
+// overridden with the DNS_TIMEOUT environment variable (in milliseconds)
+const defaultTimeout = 5 * time.Second
+
 // LookupIP -
 func LookupIP(name string) (string, error) {
-	i, err := LookupIPs(name)
+	return LookupIPContext(context.Background(), name)
+}
+
+// LookupIPContext - like LookupIP, but the lookup is also cancelled when ctx
+// is done
+func LookupIPContext(ctx context.Context, name string) (string, error) {
+	i, err := LookupIPsContext(ctx, name)
 	if err != nil {
 		return "", err
 	}
@@ -19,7 +37,19 @@ func LookupIP(name string) (string, error) {
 
 // LookupIPs -
 func LookupIPs(name string) ([]string, error) {
-	srcIPs, err := net.LookupIP(name)
+	return LookupIPsContext(context.Background(), name)
+}
+
+// LookupIPsContext - like LookupIPs, but the lookup is also cancelled when ctx
+// is done
+func LookupIPsContext(ctx context.Context, name string) ([]string, error) {
+	ctx, cancel, err := lookupContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	srcIPs, err := net.DefaultResolver.LookupIP(ctx, "ip", name)
 	if err != nil {
 		return nil, err
 	}
@@ -49,28 +79,148 @@ func contains(a []string, s string) bool {
 
 // LookupCNAME -
 func LookupCNAME(name string) (string, error) {
-	return net.LookupCNAME(name)
+	return LookupCNAMEContext(context.Background(), name)
 }
 
-// LookupTXT -
+// LookupCNAMEContext - like LookupCNAME, but the lookup is also cancelled when
+// ctx is done
+func LookupCNAMEContext(ctx context.Context, name string) (string, error) {
+	ctx, cancel, err := lookupContext(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer cancel()
+
+	return net.DefaultResolver.LookupCNAME(ctx, name)
+}
+
+// LookupTXT - look up the TXT records for the given name. An empty slice is
+// returned if the name doesn't exist or has no TXT records (NXDOMAIN), and an
+// error is returned for other failures, such as timeouts.
 func LookupTXT(name string) ([]string, error) {
-	return net.LookupTXT(name)
+	return LookupTXTContext(context.Background(), name)
 }
 
-// LookupSRV -
+// LookupTXTContext - like LookupTXT, but the lookup is also cancelled when ctx
+// is done
+func LookupTXTContext(ctx context.Context, name string) ([]string, error) {
+	ctx, cancel, err := lookupContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	txts, err := net.DefaultResolver.LookupTXT(ctx, name)
+	if isNotFound(err) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("TXT lookup for %q failed: %w", name, err)
+	}
+	return txts, nil
+}
+
+// LookupSRV - look up the first SRV record for the given name (see
+// LookupSRVs). If the name doesn't exist or has no SRV records, the error is a
+// *net.DNSError with IsNotFound set.
 func LookupSRV(name string) (*net.SRV, error) {
-	srvs, err := LookupSRVs(name)
+	return LookupSRVContext(context.Background(), name)
+}
+
+// LookupSRVContext - like LookupSRV, but the lookup is also cancelled when ctx
+// is done
+func LookupSRVContext(ctx context.Context, name string) (*net.SRV, error) {
+	srvs, err := LookupSRVsContext(ctx, name)
 	if err != nil {
 		return nil, err
 	}
+	if len(srvs) == 0 {
+		return nil, fmt.Errorf("SRV lookup for %q failed: %w", name,
+			&net.DNSError{Err: "no SRV records found", Name: name, IsNotFound: true})
+	}
 	return srvs[0], nil
 }
 
-// LookupSRVs -
+// LookupSRVs - look up the SRV records for the given name. The records are
+// sorted by priority (lowest first), then by weight (highest first), then by
+// target and port, so that the order is stable between renders.
+//
+// An empty slice is returned if the name doesn't exist or has no SRV records
+// (NXDOMAIN), and an error is returned for other failures, such as timeouts.
 func LookupSRVs(name string) ([]*net.SRV, error) {
-	_, addrs, err := net.LookupSRV("", "", name)
+	return LookupSRVsContext(context.Background(), name)
+}
+
+// LookupSRVsContext - like LookupSRVs, but the lookup is also cancelled when
+// ctx is done
+func LookupSRVsContext(ctx context.Context, name string) ([]*net.SRV, error) {
+	ctx, cancel, err := lookupContext(ctx)
 	if err != nil {
 		return nil, err
 	}
+	defer cancel()
+
+	_, addrs, err := lookupSRV(ctx, "", "", name)
+	if isNotFound(err) {
+		return []*net.SRV{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("SRV lookup for %q failed: %w", name, err)
+	}
+
+	sortSRVs(addrs)
+
 	return addrs, nil
 }
+
+func sortSRVs(addrs []*net.SRV) {
+	sort.SliceStable(addrs, func(i, j int) bool {
+		a, b := addrs[i], addrs[j]
+		switch {
+		case a.Priority != b.Priority:
+			return a.Priority < b.Priority
+		case a.Weight != b.Weight:
+			return a.Weight > b.Weight
+		case a.Target != b.Target:
+			return a.Target < b.Target
+		default:
+			return a.Port < b.Port
+		}
+	})
+}
+
+// isNotFound returns true if the error indicates that the name doesn't exist
+// (or has no records of the requested type)
+func isNotFound(err error) bool {
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr) && dnsErr.IsNotFound
+}
+
+// lookupSRV is the resolver's SRV lookup, which can be replaced in tests
+var lookupSRV = net.DefaultResolver.LookupSRV
+
+// lookupContext returns a context derived from ctx that times out after the
+// configured DNS lookup timeout
+func lookupContext(ctx context.Context) (context.Context, context.CancelFunc, error) {
+	timeout, err := lookupTimeout()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	return ctx, cancel, nil
+}
+
+func lookupTimeout() (time.Duration, error) {
+	s := env.Getenv("DNS_TIMEOUT")
+	if s == "" {
+		return defaultTimeout, nil
+	}
+
+	t, err := strconv.Atoi(s)
+	if err != nil || t <= 0 {
+		return 0, fmt.Errorf("invalid DNS_TIMEOUT value %q - must be a positive integer (milliseconds)", s)
+	}
+
+	return time.Duration(t) * time.Millisecond, nil
+}
@@ -1,7 +1,12 @@
 package net
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -43,3 +48,98 @@ func TestLookupSRV(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, uint16(5060), srv.Port)
 }
+
+func TestLookupSRVContext(t *testing.T) {
+	orig := lookupSRV
+	t.Cleanup(func() { lookupSRV = orig })
+
+	var records []*net.SRV
+	var lookupErr error
+	lookupSRV = func(ctx context.Context, _, _, name string) (string, []*net.SRV, error) {
+		if err := ctx.Err(); err != nil {
+			return "", nil, &net.DNSError{Err: err.Error(), Name: name}
+		}
+		return "", records, lookupErr
+	}
+
+	ctx := context.Background()
+
+	records = []*net.SRV{{Target: "b.", Port: 80, Priority: 20}, {Target: "a.", Port: 81, Priority: 10}}
+	srv, err := LookupSRVContext(ctx, "_http._tcp.example.com")
+	require.NoError(t, err)
+	assert.Equal(t, &net.SRV{Target: "a.", Port: 81, Priority: 10}, srv)
+
+	// names that don't exist are empty for LookupSRVs, but an error for
+	// LookupSRV, since there's no record to return
+	records, lookupErr = nil, &net.DNSError{Err: "no such host", IsNotFound: true}
+	srvs, err := LookupSRVsContext(ctx, "_http._tcp.example.com")
+	require.NoError(t, err)
+	assert.Empty(t, srvs)
+
+	_, err = LookupSRVContext(ctx, "_http._tcp.example.com")
+	require.Error(t, err)
+	assert.True(t, isNotFound(err))
+
+	records, lookupErr = nil, nil
+	_, err = LookupSRVContext(ctx, "_http._tcp.example.com")
+	require.ErrorContains(t, err, "no SRV records found")
+	assert.True(t, isNotFound(err))
+
+	// the caller's context is used
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+
+	_, err = LookupSRVsContext(cctx, "_http._tcp.example.com")
+	require.ErrorContains(t, err, context.Canceled.Error())
+	assert.False(t, isNotFound(err))
+}
+
+func TestSortSRVs(t *testing.T) {
+	srvs := []*net.SRV{
+		{Target: "c.", Port: 80, Priority: 20, Weight: 5},
+		{Target: "b.", Port: 80, Priority: 10, Weight: 5},
+		{Target: "a.", Port: 81, Priority: 10, Weight: 5},
+		{Target: "a.", Port: 80, Priority: 10, Weight: 5},
+		{Target: "d.", Port: 80, Priority: 10, Weight: 50},
+	}
+
+	sortSRVs(srvs)
+
+	assert.Equal(t, []*net.SRV{
+		{Target: "d.", Port: 80, Priority: 10, Weight: 50},
+		{Target: "a.", Port: 80, Priority: 10, Weight: 5},
+		{Target: "a.", Port: 81, Priority: 10, Weight: 5},
+		{Target: "b.", Port: 80, Priority: 10, Weight: 5},
+		{Target: "c.", Port: 80, Priority: 20, Weight: 5},
+	}, srvs)
+}
+
+func TestIsNotFound(t *testing.T) {
+	assert.False(t, isNotFound(nil))
+	assert.False(t, isNotFound(errors.New("foo")))
+	assert.False(t, isNotFound(&net.DNSError{IsTimeout: true}))
+	assert.True(t, isNotFound(&net.DNSError{IsNotFound: true}))
+	assert.True(t, isNotFound(fmt.Errorf("wrapped: %w", &net.DNSError{IsNotFound: true})))
+}
+
+func TestLookupTimeout(t *testing.T) {
+	d, err := lookupTimeout()
+	require.NoError(t, err)
+	assert.Equal(t, defaultTimeout, d)
+
+	t.Setenv("DNS_TIMEOUT", "250")
+	d, err = lookupTimeout()
+	require.NoError(t, err)
+	assert.Equal(t, 250*time.Millisecond, d)
+
+	t.Setenv("DNS_TIMEOUT", "foo")
+	_, err = lookupTimeout()
+	require.ErrorContains(t, err, "invalid DNS_TIMEOUT")
+
+	t.Setenv("DNS_TIMEOUT", "-1")
+	_, err = lookupTimeout()
+	require.Error(t, err)
+
+	_, err = LookupTXT("example.com")
+	require.ErrorContains(t, err, "invalid DNS_TIMEOUT")
+}
@@ -0,0 +1,107 @@
+package gomplate
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os/exec"
+	"strings"
+
+	"github.com/hairyhenderson/gomplate/v4/internal/iohelpers"
+)
+
+// postExecEach runs a command after each output file is written
+type postExecEach struct {
+	stdout io.Writer
+	stderr io.Writer
+	args   []string
+}
+
+type postExecEachCtxKey struct{}
+
+func contextWithPostExecEach(ctx context.Context, p *postExecEach) context.Context {
+	return context.WithValue(ctx, postExecEachCtxKey{}, p)
+}
+
+// postExecEachFromContext returns the per-file command, or nil if none is set
+func postExecEachFromContext(ctx context.Context) *postExecEach {
+	p, _ := ctx.Value(postExecEachCtxKey{}).(*postExecEach)
+	return p
+}
+
+// commandFor returns the command's arguments for the given file. The name
+// replaces "{}" in any arguments, or is appended if none contain it.
+func (p *postExecEach) commandFor(filename string) []string {
+	args := make([]string, len(p.args))
+	found := false
+	for i, a := range p.args {
+		if strings.Contains(a, "{}") {
+			found = true
+		}
+		args[i] = strings.ReplaceAll(a, "{}", filename)
+	}
+
+	if !found {
+		args = append(args, filename)
+	}
+
+	return args
+}
+
+func (p *postExecEach) run(ctx context.Context, filename string) error {
+	args := p.commandFor(filename)
+
+	slog.DebugContext(ctx, "running post-exec command", "file", filename, "args", args)
+
+	c := exec.CommandContext(ctx, args[0], args[1:]...)
+	c.Stdout = p.stdout
+	c.Stderr = p.stderr
+
+	if err := c.Run(); err != nil {
+		return fmt.Errorf("post-exec command %q failed for %s: %w", strings.Join(args, " "), filename, err)
+	}
+
+	return nil
+}
+
+// withPostExecEach wraps the writer for the named output file so that the
+// per-file command (if any) runs once the file has been closed, and so moved
+// into place. Nothing is run in dry-run mode, since no files are written, or
+// for files left alone because their content didn't change.
+func withPostExecEach(ctx context.Context, filename string, w io.WriteCloser) io.WriteCloser {
+	p := postExecEachFromContext(ctx)
+	if p == nil || dryRunReportFromContext(ctx) != nil {
+		return w
+	}
+
+	return &postExecWriter{WriteCloser: w, ctx: ctx, p: p, name: filename}
+}
+
+type postExecWriter struct {
+	io.WriteCloser
+	ctx  context.Context
+	p    *postExecEach
+	name string
+}
+
+var _ iohelpers.Aborter = (*postExecWriter)(nil)
+
+func (w *postExecWriter) Close() error {
+	if err := w.WriteCloser.Close(); err != nil {
+		return err
+	}
+
+	if u, ok := w.WriteCloser.(*unchangedRecorder); ok && !u.written {
+		slog.DebugContext(w.ctx, "output unchanged, not running post-exec command", "file", w.name)
+		return nil
+	}
+
+	return w.p.run(w.ctx, w.name)
+}
+
+// Abort - implements iohelpers.Aborter. The command isn't run for failed
+// renders.
+func (w *postExecWriter) Abort() error {
+	return iohelpers.Abort(w.WriteCloser)
+}
@@ -0,0 +1,64 @@
+package gomplate
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostExecEach_CommandFor(t *testing.T) {
+	p := &postExecEach{args: []string{"gofmt", "-w", "{}"}}
+	assert.Equal(t, []string{"gofmt", "-w", "out.go"}, p.commandFor("out.go"))
+
+	// the original args aren't modified
+	assert.Equal(t, []string{"gofmt", "-w", "{}"}, p.args)
+
+	p = &postExecEach{args: []string{"check", "--file={}", "--backup={}.bak"}}
+	assert.Equal(t, []string{"check", "--file=a", "--backup=a.bak"}, p.commandFor("a"))
+
+	p = &postExecEach{args: []string{"yamllint", "-s"}}
+	assert.Equal(t, []string{"yamllint", "-s", "out.yaml"}, p.commandFor("out.yaml"))
+}
+
+type closeRecorder struct {
+	bytes.Buffer
+	closed, aborted bool
+}
+
+func (c *closeRecorder) Close() error {
+	c.closed = true
+	return nil
+}
+
+func (c *closeRecorder) Abort() error {
+	c.aborted = true
+	return nil
+}
+
+func TestWithPostExecEach(t *testing.T) {
+	ctx := context.Background()
+
+	w := &closeRecorder{}
+	assert.Same(t, w, withPostExecEach(ctx, "out", w))
+
+	p := &postExecEach{args: []string{"this-command-does-not-exist"}}
+	ctx = contextWithPostExecEach(ctx, p)
+
+	// nothing is run in dry-run mode
+	assert.Same(t, w, withPostExecEach(contextWithDryRunReport(ctx, newDryRunReport()), "out", w))
+
+	// the command isn't run when the output is aborted
+	wc := withPostExecEach(ctx, "out", w)
+	require.NoError(t, wc.(*postExecWriter).Abort())
+	assert.True(t, w.aborted)
+	assert.False(t, w.closed)
+
+	w = &closeRecorder{}
+	wc = withPostExecEach(ctx, "out", w)
+	err := wc.Close()
+	assert.True(t, w.closed)
+	require.ErrorContains(t, err, `post-exec command "this-command-does-not-exist out" failed for out`)
+}
@@ -2,8 +2,10 @@
 package random
 
 import (
+	crand "crypto/rand"
 	"fmt"
 	"math"
+	"math/big"
 	"math/rand"
 	"regexp"
 	"unicode"
@@ -12,15 +14,36 @@ import (
 // Default set, matches "[a-zA-Z0-9_.-]"
 const defaultSet = "-.0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ_abcdefghijklmnopqrstuvwxyz"
 
+// namedSets are the character sets that can be given to StringRE by name
+// instead of as a regular expression. None of the names are regular
+// expressions that match a single character, so they can't be confused.
+//
+//nolint:gochecknoglobals
+var namedSets = map[string]string{
+	"hex":          "0123456789abcdef",
+	"alphanumeric": "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz",
+	"lower":        "abcdefghijklmnopqrstuvwxyz",
+	"upper":        "ABCDEFGHIJKLMNOPQRSTUVWXYZ",
+	"ascii":        " !\"#$%&'()*+,-./0123456789:;<=>?@ABCDEFGHIJKLMNOPQRSTUVWXYZ[\\]^_`abcdefghijklmnopqrstuvwxyz{|}~",
+}
+
 // StringRE - Generate a random string that matches a given regular
-// expression. Defaults to "[a-zA-Z0-9_.-]"
+// expression. Defaults to "[a-zA-Z0-9_.-]". The name of a character set
+// (hex, alphanumeric, ascii, lower, or upper) can be given instead of a
+// regular expression.
 func StringRE(count int, match string) (r string, err error) {
 	chars := []rune(defaultSet)
-	if match != "" {
+	if set, ok := namedSets[match]; ok {
+		chars = []rune(set)
+	} else if match != "" {
 		chars, err = matchChars(match)
 		if err != nil {
 			return "", err
 		}
+
+		if len(chars) == 0 {
+			return "", fmt.Errorf("no characters match the regular expression %q", match)
+		}
 	}
 
 	return rndString(count, chars)
@@ -38,12 +61,27 @@ func StringBounds(count int, lower, upper rune) (r string, err error) {
 	return rndString(count, chars)
 }
 
-// produce a string containing a random selection of given characters
+// produce a string containing a random selection of given characters, using
+// a cryptographically secure random number generator
 func rndString(count int, chars []rune) (string, error) {
+	if count < 0 {
+		return "", fmt.Errorf("count must not be negative (was %d)", count)
+	}
+
+	if len(chars) == 0 {
+		return "", fmt.Errorf("no characters to choose from")
+	}
+
+	n := big.NewInt(int64(len(chars)))
+
 	s := make([]rune, count)
 	for i := range s {
-		//nolint:gosec
-		s[i] = chars[rand.Intn(len(chars))]
+		j, err := crand.Int(crand.Reader, n)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate random number: %w", err)
+		}
+
+		s[i] = chars[j.Int64()]
 	}
 	return string(s), nil
 }
@@ -73,8 +111,9 @@ func matchChars(match string) ([]rune, error) {
 	return out, nil
 }
 
-// Item -
-func Item(items []interface{}) (interface{}, error) {
+// Item returns an element of the given slice at random. If a seed is given,
+// the same element is always returned for the same seed and input.
+func Item(items []interface{}, seed ...int64) (interface{}, error) {
 	if len(items) == 0 {
 		return nil, fmt.Errorf("expected a non-empty array or slice")
 	}
@@ -82,11 +121,52 @@ func Item(items []interface{}) (interface{}, error) {
 		return items[0], nil
 	}
 
-	//nolint:gosec
-	n := rand.Intn(len(items))
+	n := rng(seed).Intn(len(items))
 	return items[n], nil
 }
 
+// Shuffle returns a copy of the given slice, with the elements in a random
+// order. If a seed is given, the order is always the same for the same seed
+// and input.
+func Shuffle(items []interface{}, seed ...int64) ([]interface{}, error) {
+	if len(items) == 0 {
+		return nil, fmt.Errorf("expected a non-empty array or slice")
+	}
+
+	out := make([]interface{}, len(items))
+	copy(out, items)
+
+	rng(seed).Shuffle(len(out), func(i, j int) {
+		out[i], out[j] = out[j], out[i]
+	})
+
+	return out, nil
+}
+
+// rng returns a generator seeded with the first of the given seeds, or the
+// global generator if there are none
+func rng(seed []int64) *rand.Rand {
+	if len(seed) == 0 {
+		return globalRand
+	}
+
+	//nolint:gosec
+	return rand.New(rand.NewSource(seed[0]))
+}
+
+// globalRand uses the top-level functions from math/rand, which are safe for
+// concurrent use and randomly seeded
+//
+//nolint:gochecknoglobals,gosec
+var globalRand = rand.New(globalSource{})
+
+type globalSource struct{}
+
+//nolint:gosec
+func (globalSource) Int63() int64 { return rand.Int63() }
+
+func (globalSource) Seed(int64) {}
+
 // Number -
 func Number(min, max int64) (int64, error) {
 	if min > max {
@@ -47,6 +47,42 @@ func TestStringRE(t *testing.T) {
 
 	_, err = StringRE(1, "[bogus")
 	require.Error(t, err)
+
+	_, err = StringRE(1, "abc")
+	require.EqualError(t, err, `no characters match the regular expression "abc"`)
+
+	_, err = StringRE(-1, "")
+	require.Error(t, err)
+
+	r, err = StringRE(0, "hex")
+	require.NoError(t, err)
+	assert.Empty(t, r)
+
+	testdata := map[string]string{
+		"hex":          "^[0-9a-f]{64}$",
+		"alphanumeric": "^[0-9A-Za-z]{64}$",
+		"lower":        "^[a-z]{64}$",
+		"upper":        "^[A-Z]{64}$",
+		"ascii":        "^[ -~]{64}$",
+	}
+	for name, re := range testdata {
+		r, err = StringRE(64, name)
+		require.NoError(t, err)
+		assert.Regexp(t, re, r, name)
+	}
+
+	// sets exactly match their regexp equivalents
+	for name, re := range map[string]string{
+		"alphanumeric": "[[:alnum:]]",
+		"lower":        "[[:lower:]]",
+		"upper":        "[[:upper:]]",
+		"ascii":        "[ -~]",
+		"hex":          "[0-9a-f]",
+	} {
+		chars, err := matchChars(re)
+		require.NoError(t, err)
+		assert.Equal(t, namedSets[name], string(chars), name)
+	}
 }
 
 func TestStringBounds(t *testing.T) {
@@ -145,3 +181,52 @@ func TestFloat(t *testing.T) {
 		assert.InDelta(t, d.expected, n, d.delta)
 	}
 }
+
+func TestItemSeed(t *testing.T) {
+	t.Parallel()
+
+	in := []interface{}{"a", "b", "c", "d", "e", "f", "g", "h"}
+
+	first, err := Item(in, 42)
+	require.NoError(t, err)
+
+	for j := 0; j < 10; j++ {
+		i, err := Item(in, 42)
+		require.NoError(t, err)
+		assert.Equal(t, first, i)
+	}
+}
+
+func TestShuffle(t *testing.T) {
+	t.Parallel()
+
+	_, err := Shuffle(nil)
+	require.Error(t, err)
+
+	_, err = Shuffle([]interface{}{}, 1)
+	require.Error(t, err)
+
+	out, err := Shuffle([]interface{}{"foo"})
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"foo"}, out)
+
+	in := []interface{}{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	orig := append([]interface{}{}, in...)
+
+	out, err = Shuffle(in)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, in, out)
+	assert.Equal(t, orig, in, "input must not be modified")
+
+	// the same seed always gives the same order
+	seeded, err := Shuffle(in, 42)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, in, seeded)
+	assert.NotEqual(t, in, seeded)
+
+	for j := 0; j < 10; j++ {
+		out, err = Shuffle(in, 42)
+		require.NoError(t, err)
+		assert.Equal(t, seeded, out)
+	}
+}
@@ -2,13 +2,50 @@
 package regexp
 
 import (
+	"errors"
 	"fmt"
 	stdre "regexp"
+	"regexp/syntax"
 )
 
+// compile compiles the expression, with an error that includes it
+func compile(expression string) (*stdre.Regexp, error) {
+	re, err := stdre.Compile(expression)
+	if err != nil {
+		return nil, &compileError{expr: expression, err: err}
+	}
+
+	return re, nil
+}
+
+// compileError is an error compiling an expression. The parser's own error
+// quotes only the part of the expression it failed at, which is the whole
+// expression in many cases, so that part is only repeated when it's different.
+type compileError struct {
+	err  error
+	expr string
+}
+
+func (e *compileError) Error() string {
+	var serr *syntax.Error
+	if !errors.As(e.err, &serr) {
+		return fmt.Sprintf("error compiling expression %q: %s", e.expr, e.err)
+	}
+
+	if serr.Expr == e.expr {
+		return fmt.Sprintf("error compiling expression %q: %s", e.expr, serr.Code)
+	}
+
+	return fmt.Sprintf("error compiling expression %q: %s at %q", e.expr, serr.Code, serr.Expr)
+}
+
+func (e *compileError) Unwrap() error {
+	return e.err
+}
+
 // Find -
 func Find(expression, input string) (string, error) {
-	re, err := stdre.Compile(expression)
+	re, err := compile(expression)
 	if err != nil {
 		return "", err
 	}
@@ -17,18 +54,43 @@ func Find(expression, input string) (string, error) {
 
 // FindAll -
 func FindAll(expression string, n int, input string) ([]string, error) {
-	re, err := stdre.Compile(expression)
+	re, err := compile(expression)
 	if err != nil {
 		return nil, err
 	}
 	return re.FindAllString(input, n), nil
 }
 
+// FindNamed - returns a map of the named capture groups in the first match
+// to the text they matched. Unnamed groups are ignored, and groups that didn't
+// participate in the match are empty. With no match, the map is empty.
+func FindNamed(expression, input string) (map[string]string, error) {
+	re, err := compile(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	out := map[string]string{}
+
+	m := re.FindStringSubmatch(input)
+	if m == nil {
+		return out, nil
+	}
+
+	for i, name := range re.SubexpNames() {
+		if name != "" {
+			out[name] = m[i]
+		}
+	}
+
+	return out, nil
+}
+
 // Match -
 func Match(expression, input string) (bool, error) {
-	re, err := stdre.Compile(expression)
+	re, err := compile(expression)
 	if err != nil {
-		return false, fmt.Errorf("error compiling expression: %w", err)
+		return false, err
 	}
 
 	return re.MatchString(input), nil
@@ -41,9 +103,9 @@ func QuoteMeta(input string) string {
 
 // Replace -
 func Replace(expression, replacement, input string) (string, error) {
-	re, err := stdre.Compile(expression)
+	re, err := compile(expression)
 	if err != nil {
-		return "", fmt.Errorf("error compiling expression: %w", err)
+		return "", err
 	}
 
 	return re.ReplaceAllString(input, replacement), nil
@@ -51,18 +113,18 @@ func Replace(expression, replacement, input string) (string, error) {
 
 // ReplaceLiteral -
 func ReplaceLiteral(expression, replacement, input string) (string, error) {
-	re, err := stdre.Compile(expression)
+	re, err := compile(expression)
 	if err != nil {
-		return "", fmt.Errorf("error compiling expression: %w", err)
+		return "", err
 	}
 	return re.ReplaceAllLiteralString(input, replacement), nil
 }
 
 // Split -
 func Split(expression string, n int, input string) ([]string, error) {
-	re, err := stdre.Compile(expression)
+	re, err := compile(expression)
 	if err != nil {
-		return nil, fmt.Errorf("error compiling expression: %w", err)
+		return nil, err
 	}
 
 	return re.Split(input, n), nil
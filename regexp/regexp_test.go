@@ -1,6 +1,7 @@
 package regexp
 
 import (
+	"regexp/syntax"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -13,7 +14,35 @@ func TestFind(t *testing.T) {
 	assert.Equal(t, "foo", f)
 
 	_, err = Find(`[a-`, "")
-	require.Error(t, err)
+	require.EqualError(t, err, `error compiling expression "[a-": missing closing ]`)
+
+	// the failing part is only added when it's not the whole expression
+	_, err = Find(`foo\q`, "")
+	require.EqualError(t, err, `error compiling expression "foo\\q": invalid escape sequence at "\\q"`)
+
+	var serr *syntax.Error
+	require.ErrorAs(t, err, &serr)
+	assert.Equal(t, syntax.ErrInvalidEscape, serr.Code)
+}
+
+func TestFindNamed(t *testing.T) {
+	re := `^v(?P<major>\d+)\.(?P<minor>\d+)(?:\.(?P<patch>\d+))?(-(.+))?$`
+
+	m, err := FindNamed(re, "v1.22.5-rc1")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"major": "1", "minor": "22", "patch": "5"}, m)
+
+	m, err = FindNamed(re, "v1.22")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"major": "1", "minor": "22", "patch": ""}, m)
+
+	m, err = FindNamed(re, "nope")
+	require.NoError(t, err)
+	assert.Empty(t, m)
+	assert.NotNil(t, m)
+
+	_, err = FindNamed(`(?P<x>`, "")
+	require.EqualError(t, err, `error compiling expression "(?P<x>": missing closing )`)
 }
 
 func TestFindAll(t *testing.T) {
@@ -7,6 +7,7 @@ import (
 	"io/fs"
 	"net/http"
 	"path"
+	"runtime"
 	"slices"
 	"strings"
 	"sync"
@@ -17,6 +18,7 @@ import (
 	"github.com/hairyhenderson/go-fsimpl/autofs"
 	"github.com/hairyhenderson/gomplate/v4/internal/datafs"
 	"github.com/hairyhenderson/gomplate/v4/internal/funcs"
+	"github.com/hairyhenderson/gomplate/v4/internal/iohelpers"
 )
 
 // RenderOptions - options for controlling how templates are rendered, and
@@ -48,8 +50,25 @@ type RenderOptions struct {
 
 	// MissingKey controls the behavior during execution if a map is indexed with a key that is not present in the map
 	MissingKey string
+
+	// Parallelism - the maximum number of templates to render concurrently.
+	// Templates are rendered sequentially when this is less than 2. Note
+	// that templates sharing a Writer must not be rendered concurrently.
+	Parallelism int
+
+	// TemplateTimeout - how long to wait for a remote (non-file) nested
+	// template to be read. Defaults to 30 seconds.
+	TemplateTimeout time.Duration
+
+	// NoCache - when true, datasources are read every time they're used.
+	// Otherwise each unique datasource URL is read at most once by the
+	// renderer, and the content is shared by all templates it renders.
+	NoCache bool
 }
 
+// defaultTemplateTimeout is used when RenderOptions.TemplateTimeout isn't set
+const defaultTemplateTimeout = 30 * time.Second
+
 // optionsFromConfig - translate the internal config struct to a RenderOptions.
 // Does not set the Funcs field.
 func optionsFromConfig(cfg *Config) RenderOptions {
@@ -61,12 +80,33 @@ func optionsFromConfig(cfg *Config) RenderOptions {
 		LDelim:       cfg.LDelim,
 		RDelim:       cfg.RDelim,
 		MissingKey:   cfg.MissingKey,
+		Parallelism:  cfg.Parallelism,
+
+		TemplateTimeout: cfg.TemplateTimeout,
+		NoCache:         cfg.NoCache,
+	}
+
+	if opts.Parallelism == 0 {
+		opts.Parallelism = runtime.GOMAXPROCS(0)
+	}
+
+	// templates written to stdout share a writer, and so can't be rendered
+	// concurrently without interleaving their output
+	stdoutCount := 0
+	for _, o := range cfg.OutputFiles {
+		if o == "-" {
+			stdoutCount++
+		}
+	}
+	if stdoutCount > 1 {
+		opts.Parallelism = 1
 	}
 
 	return opts
 }
 
 type renderer struct {
+	reg         datafs.Registry
 	sr          datafs.DataSourceReader
 	nested      map[string]DataSource
 	funcs       template.FuncMap
@@ -74,6 +114,19 @@ type renderer struct {
 	rDelim      string
 	missingKey  string
 	tctxAliases []string
+	parallelism int
+
+	// remote nested templates are only read once per renderer
+	remoteCache   map[string][]nestedTemplate
+	remoteTimeout time.Duration
+	remoteMu      sync.Mutex
+}
+
+// nestedTemplate is the source of a single nested template
+type nestedTemplate struct {
+	name  string
+	fname string
+	text  string
 }
 
 // Renderer provides gomplate's core template rendering functionality.
@@ -148,15 +201,26 @@ func newRenderer(opts RenderOptions) *renderer {
 	}
 
 	sr := datafs.NewSourceReader(reg)
+	if opts.NoCache {
+		sr = datafs.NewUncachedSourceReader(reg)
+	}
+
+	timeout := opts.TemplateTimeout
+	if timeout == 0 {
+		timeout = defaultTemplateTimeout
+	}
 
 	return &renderer{
-		nested:      opts.Templates,
-		sr:          sr,
-		funcs:       opts.Funcs,
-		tctxAliases: tctxAliases,
-		lDelim:      opts.LDelim,
-		rDelim:      opts.RDelim,
-		missingKey:  missingKey,
+		nested:        nested,
+		reg:           reg,
+		sr:            sr,
+		funcs:         opts.Funcs,
+		tctxAliases:   tctxAliases,
+		lDelim:        opts.LDelim,
+		rDelim:        opts.RDelim,
+		missingKey:    missingKey,
+		parallelism:   opts.Parallelism,
+		remoteTimeout: timeout,
 	}
 }
 
@@ -170,6 +234,20 @@ type Template struct {
 	Name string
 	// Text is the template text
 	Text string
+	// LDelim and RDelim override the renderer's action delimiters for this
+	// template, when set. Nested templates still use the renderer's
+	// delimiters.
+	LDelim string
+	RDelim string
+	// DataSources are extra datasources that are available only to this
+	// template, in addition to the renderer's datasources. These take
+	// precedence over the renderer's datasources with the same alias.
+	DataSources map[string]DataSource
+	// Associated are templates parsed into the same set as this one, in
+	// order and before it, so that the templates they define can be used
+	// here. Only their Name and Text are used, and they aren't rendered
+	// themselves. Later definitions replace earlier ones with the same name.
+	Associated []Template
 }
 
 func (r *renderer) RenderTemplates(ctx context.Context, templates []Template) error {
@@ -201,47 +279,151 @@ func (r *renderer) renderTemplatesWithData(ctx context.Context, templates []Temp
 	// track some metrics for debug output
 	start := time.Now()
 	defer func() { Metrics.TotalRenderDuration = time.Since(start) }()
-	for _, template := range templates {
-		err := r.renderTemplate(ctx, template, f, tmplctx)
+
+	if r.parallelism < 2 || len(templates) < 2 {
+		for _, template := range templates {
+			err := r.renderTemplate(ctx, template, f, tmplctx)
+			if err != nil {
+				return fmt.Errorf("renderTemplate: %w", err)
+			}
+		}
+		return nil
+	}
+
+	return r.renderTemplatesConcurrently(ctx, templates, f, tmplctx)
+}
+
+// renderTemplatesConcurrently renders the templates with a pool of up to
+// r.parallelism workers. Templates are started in order, and the first
+// failure cancels the rest: no new templates are started, and templates
+// already queued after the failed one are skipped. The error returned is from
+// the first failed template (in input order), so the result doesn't depend on
+// scheduling.
+func (r *renderer) renderTemplatesConcurrently(ctx context.Context, templates []Template, f template.FuncMap, tmplctx interface{}) error {
+	parent := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	errs := make([]error, len(templates))
+
+	// the index of the first failed template, so that earlier templates are
+	// still rendered, in case they fail too
+	mu := sync.Mutex{}
+	firstFailed := len(templates)
+	skip := func(i int) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return parent.Err() != nil || (ctx.Err() != nil && i > firstFailed)
+	}
+	fail := func(i int) {
+		mu.Lock()
+		defer mu.Unlock()
+		firstFailed = min(firstFailed, i)
+		cancel()
+	}
+
+	next := make(chan int)
+	wg := sync.WaitGroup{}
+	for range min(r.parallelism, len(templates)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				if skip(i) {
+					continue
+				}
+
+				errs[i] = r.renderTemplate(ctx, templates[i], f, tmplctx)
+				if errs[i] != nil {
+					fail(i)
+				}
+			}
+		}()
+	}
+
+dispatch:
+	for i := range templates {
+		select {
+		case <-ctx.Done():
+			break dispatch
+		case next <- i:
+		}
+	}
+	close(next)
+	wg.Wait()
+
+	for _, err := range errs {
 		if err != nil {
 			return fmt.Errorf("renderTemplate: %w", err)
 		}
 	}
-	return nil
+
+	return parent.Err()
 }
 
-func (r *renderer) renderTemplate(ctx context.Context, template Template, f template.FuncMap, tmplctx interface{}) error {
-	if template.Writer != nil {
-		if wr, ok := template.Writer.(io.Closer); ok {
-			defer wr.Close()
-		}
+func (r *renderer) renderTemplate(ctx context.Context, template Template, f template.FuncMap, tmplctx interface{}) (err error) {
+	if wr, ok := template.Writer.(io.Closer); ok {
+		defer func() {
+			// discard the output of failed renders, where the writer allows it
+			if err != nil {
+				_ = iohelpers.Abort(wr)
+				return
+			}
+
+			if cerr := wr.Close(); cerr != nil {
+				err = fmt.Errorf("failed to close output for template %s: %w", template.Name, cerr)
+			}
+		}()
+	}
+
+	if len(template.DataSources) > 0 {
+		f = r.templateDataSourceFuncs(ctx, template.DataSources, f)
 	}
 
 	tstart := time.Now()
-	tmpl, err := r.parseTemplate(ctx, template.Name, template.Text, f, tmplctx)
+	tmpl, err := r.parseTemplate(ctx, template, f, tmplctx)
 	if err != nil {
-		return fmt.Errorf("parse template %s: %w", template.Name, err)
+		return newTemplateError("parse", template.Name, err)
 	}
 
 	err = tmpl.Execute(template.Writer, tmplctx)
-	Metrics.RenderDuration[template.Name] = time.Since(tstart)
+	Metrics.recordRender(template.Name, time.Since(tstart), err)
 	if err != nil {
-		Metrics.Errors++
-		return fmt.Errorf("failed to render template %s: %w", template.Name, err)
+		return newTemplateError("render", template.Name, err)
 	}
-	Metrics.TemplatesProcessed++
 
 	return nil
 }
 
+// templateDataSourceFuncs returns a copy of f with datasource funcs that can
+// also read the given template-specific datasources. Datasources defined with
+// defineDatasource are still visible to all templates.
+func (r *renderer) templateDataSourceFuncs(ctx context.Context, dataSources map[string]DataSource, f template.FuncMap) template.FuncMap {
+	reg := datafs.NewOverlayRegistry(r.reg)
+	for alias, ds := range dataSources {
+		reg.Register(alias, ds)
+	}
+
+	f = copyFuncMap(f)
+	// the shared datasources are still only read once
+	addToMap(f, funcs.CreateTemplateDataSourceFuncs(ctx, datafs.SourceReaderWithRegistry(r.sr, reg), f))
+
+	// user-defined funcs still override the built-in funcs
+	addToMap(f, r.funcs)
+
+	return f
+}
+
 func (r *renderer) Render(ctx context.Context, name, text string, wr io.Writer) error {
 	return r.RenderTemplates(ctx, []Template{
 		{Name: name, Text: text, Writer: wr},
 	})
 }
 
-// parseTemplate - parses text as a Go template with the given name and options
-func (r *renderer) parseTemplate(ctx context.Context, name, text string, funcs template.FuncMap, tmplctx interface{}) (tmpl *template.Template, err error) {
+// parseTemplate - parses the template's text as a Go template with the given
+// options
+func (r *renderer) parseTemplate(ctx context.Context, t Template, funcs template.FuncMap, tmplctx interface{}) (tmpl *template.Template, err error) {
+	name := t.Name
 	tmpl = template.New(name)
 
 	missingKey := r.missingKey
@@ -261,8 +443,16 @@ func (r *renderer) parseTemplate(ctx context.Context, name, text string, funcs t
 	// the "tmpl" funcs get added here because they need access to the root template and context
 	addTmplFuncs(funcMap, tmpl, tmplctx, name)
 	tmpl.Funcs(funcMap)
-	tmpl.Delims(r.lDelim, r.rDelim)
-	_, err = tmpl.Parse(text)
+	tmpl.Delims(r.delims(t))
+
+	for _, a := range t.Associated {
+		_, err = tmpl.New(a.Name).Parse(a.Text)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = tmpl.Parse(t.Text)
 	if err != nil {
 		return nil, err
 	}
@@ -275,100 +465,190 @@ func (r *renderer) parseTemplate(ctx context.Context, name, text string, funcs t
 	return tmpl, nil
 }
 
-func (r *renderer) parseNestedTemplates(ctx context.Context, tmpl *template.Template) error {
-	fsp := datafs.FSProviderFromContext(ctx)
+// delims returns the action delimiters for the template - its own, where set,
+// or the renderer's
+func (r *renderer) delims(t Template) (string, string) {
+	l, rd := r.lDelim, r.rDelim
+	if t.LDelim != "" {
+		l = t.LDelim
+	}
+	if t.RDelim != "" {
+		rd = t.RDelim
+	}
+
+	return l, rd
+}
 
+func (r *renderer) parseNestedTemplates(ctx context.Context, tmpl *template.Template) error {
 	for alias, n := range r.nested {
-		u := *n.URL
+		tmpls, err := r.readNestedTemplates(ctx, alias, n)
+		if err != nil {
+			return fmt.Errorf("template %q (%s): %w", alias, n.URL, err)
+		}
 
-		fname := path.Base(u.Path)
-		if strings.HasSuffix(u.Path, "/") {
-			fname = "."
+		for _, t := range tmpls {
+			// nested templates always use the renderer's delimiters, even
+			// when the template they're nested in has its own
+			_, err = tmpl.New(t.name).Delims(r.lDelim, r.rDelim).Parse(t.text)
+			if err != nil {
+				return fmt.Errorf("parse nested template %q: %w", t.fname, err)
+			}
 		}
+	}
+
+	return nil
+}
+
+// readNestedTemplates reads the nested template(s) with the given alias.
+// Remote templates (anything not read from a file) are read once, with a
+// timeout, and cached for the renderer's lifetime - local templates are always
+// read fresh.
+func (r *renderer) readNestedTemplates(ctx context.Context, alias string, n DataSource) ([]nestedTemplate, error) {
+	if n.URL.Scheme == "" || n.URL.Scheme == "file" {
+		return readNestedTemplates(ctx, r.sr, alias, n)
+	}
+
+	// holding the lock while reading ensures concurrent renders don't read
+	// the same template more than once
+	r.remoteMu.Lock()
+	defer r.remoteMu.Unlock()
+
+	if tmpls, ok := r.remoteCache[alias]; ok {
+		return tmpls, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.remoteTimeout)
+	defer cancel()
+
+	tmpls, err := readNestedTemplates(ctx, r.sr, alias, n)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.remoteCache == nil {
+		r.remoteCache = map[string][]nestedTemplate{}
+	}
+	r.remoteCache[alias] = tmpls
+
+	return tmpls, nil
+}
+
+func readNestedTemplates(ctx context.Context, reg datafs.Registry, alias string, n DataSource) ([]nestedTemplate, error) {
+	fsp := datafs.FSProviderFromContext(ctx)
 
-		u.Path = path.Dir(u.Path)
+	u := *n.URL
+
+	fname := path.Base(u.Path)
+	if strings.HasSuffix(u.Path, "/") {
+		fname = "."
+	}
+
+	u.Path = path.Dir(u.Path)
+
+	fsys, err := fsp.New(&u)
+	if err != nil {
+		return nil, fmt.Errorf("filesystem provider for %q unavailable: %w", &u, err)
+	}
 
-		fsys, err := fsp.New(&u)
+	// TODO: maybe need to do something with root here?
+	_, reldir, err := datafs.ResolveLocalPath(fsys, u.Path)
+	if err != nil {
+		return nil, fmt.Errorf("resolveLocalPath: %w", err)
+	}
+
+	if reldir != "" && reldir != "." {
+		fsys, err = fs.Sub(fsys, reldir)
 		if err != nil {
-			return fmt.Errorf("filesystem provider for %q unavailable: %w", &u, err)
+			return nil, fmt.Errorf("sub filesystem for %q unavailable: %w", &u, err)
 		}
+	}
+
+	hdr, err := datafs.ExpandHeader(n.Header)
+	if err != nil {
+		return nil, err
+	}
 
-		// TODO: maybe need to do something with root here?
-		_, reldir, err := datafs.ResolveLocalPath(fsys, u.Path)
+	// inject context & header in case they're useful...
+	fsys = fsimpl.WithContextFS(ctx, fsys)
+	fsys = fsimpl.WithHeaderFS(hdr, fsys)
+	fsys = datafs.WithDataSourceRegistryFS(reg, fsys)
+
+	// valid fs.FS paths have no trailing slash
+	fname = strings.TrimRight(fname, "/")
+
+	// first determine if the template path is a directory, in which case we
+	// need to load all the files in the directory and its subdirectories
+	fi, err := fs.Stat(fsys, fname)
+	if err != nil {
+		return nil, fmt.Errorf("stat %q: %w", fname, err)
+	}
+
+	if fi.IsDir() {
+		return readNestedTemplateDir(fsys, alias, fname)
+	}
+
+	t, err := readNestedTemplate(fsys, alias, fname)
+	if err != nil {
+		return nil, err
+	}
+
+	return []nestedTemplate{t}, nil
+}
+
+// readNestedTemplateDir reads all the templates in the directory and its
+// subdirectories, named for their paths relative to the directory. Only
+// regular files are read - symlinks to files are followed, but symlinks to
+// directories are skipped so that cycles can't occur.
+func readNestedTemplateDir(fsys fs.FS, alias, fname string) ([]nestedTemplate, error) {
+	tmpls := []nestedTemplate{}
+
+	err := fs.WalkDir(fsys, fname, func(p string, d fs.DirEntry, err error) error {
 		if err != nil {
-			return fmt.Errorf("resolveLocalPath: %w", err)
+			return fmt.Errorf("readDir %q: %w", p, err)
 		}
 
-		if reldir != "" && reldir != "." {
-			fsys, err = fs.Sub(fsys, reldir)
+		typ := d.Type()
+		if typ&fs.ModeSymlink != 0 {
+			fi, err := fs.Stat(fsys, p)
 			if err != nil {
-				return fmt.Errorf("sub filesystem for %q unavailable: %w", &u, err)
+				return fmt.Errorf("stat %q: %w", p, err)
 			}
-		}
 
-		// inject context & header in case they're useful...
-		fsys = fsimpl.WithContextFS(ctx, fsys)
-		fsys = fsimpl.WithHeaderFS(n.Header, fsys)
-		fsys = datafs.WithDataSourceRegistryFS(r.sr, fsys)
-
-		// valid fs.FS paths have no trailing slash
-		fname = strings.TrimRight(fname, "/")
+			typ = fi.Mode().Type()
+		}
 
-		// first determine if the template path is a directory, in which case we
-		// need to load all the files in the directory (but not recursively)
-		fi, err := fs.Stat(fsys, fname)
-		if err != nil {
-			return fmt.Errorf("stat %q: %w", fname, err)
+		if !typ.IsRegular() {
+			return nil
 		}
 
-		if fi.IsDir() {
-			err = parseNestedTemplateDir(ctx, fsys, alias, fname, tmpl)
-		} else {
-			err = parseNestedTemplate(ctx, fsys, alias, fname, tmpl)
+		rel := p
+		if fname != "." {
+			rel = strings.TrimPrefix(p, fname+"/")
 		}
 
+		t, err := readNestedTemplate(fsys, path.Join(alias, rel), p)
 		if err != nil {
 			return err
 		}
-	}
 
-	return nil
-}
+		tmpls = append(tmpls, t)
 
-func parseNestedTemplateDir(ctx context.Context, fsys fs.FS, alias, fname string, tmpl *template.Template) error {
-	files, err := fs.ReadDir(fsys, fname)
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("readDir %q: %w", fname, err)
-	}
-
-	for _, f := range files {
-		if !f.IsDir() {
-			err = parseNestedTemplate(ctx, fsys,
-				path.Join(alias, f.Name()),
-				path.Join(fname, f.Name()),
-				tmpl,
-			)
-			if err != nil {
-				return err
-			}
-		}
+		return nil, err
 	}
 
-	return nil
+	return tmpls, nil
 }
 
-func parseNestedTemplate(_ context.Context, fsys fs.FS, alias, fname string, tmpl *template.Template) error {
+func readNestedTemplate(fsys fs.FS, alias, fname string) (nestedTemplate, error) {
 	b, err := fs.ReadFile(fsys, fname)
 	if err != nil {
-		return fmt.Errorf("readFile %q: %w", fname, err)
+		return nestedTemplate{}, fmt.Errorf("readFile %q: %w", fname, err)
 	}
 
-	_, err = tmpl.New(alias).Parse(string(b))
-	if err != nil {
-		return fmt.Errorf("parse nested template %q: %w", fname, err)
-	}
-
-	return nil
+	return nestedTemplate{name: alias, fname: fname, text: string(b)}, nil
 }
 
 // DefaultFSProvider is the default filesystem provider used by gomplate
@@ -386,6 +666,14 @@ var DefaultFSProvider = sync.OnceValue(
 		fsp.Add(datafs.EnvFS)
 		fsp.Add(datafs.StdinFS)
 		fsp.Add(datafs.MergeFS)
+		fsp.Add(datafs.RedisFS)
+		fsp.Add(datafs.SQLFS)
+		fsp.Add(datafs.BoltDBFS)
+		fsp.Add(datafs.ArchiveFS)
+		fsp.Add(datafs.SFTPFS)
+		fsp.Add(datafs.K8sFS)
+		fsp.Add(datafs.MongoFS)
+		fsp.Add(datafs.GRPCFS)
 
 		return fsp
 	})()
@@ -4,13 +4,19 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"os"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"testing/fstest"
+	"text/template"
+	"time"
 
 	"github.com/hairyhenderson/go-fsimpl"
+	"github.com/hairyhenderson/go-fsimpl/httpfs"
 	"github.com/hairyhenderson/gomplate/v4/internal/datafs"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -57,6 +63,23 @@ func TestRenderTemplate(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "HELLO WORLD", out.String())
 
+	// datasources are only read when referenced, so a broken one doesn't
+	// fail templates that don't use it
+	bu, _ := url.Parse("mem:///missing.json")
+	tr = NewRenderer(RenderOptions{
+		Datasources: map[string]DataSource{
+			"world":  {URL: wu},
+			"broken": {URL: bu},
+		},
+	})
+	out = &bytes.Buffer{}
+	err = tr.Render(ctx, "test", `{{ (ds "world") | toUpper }}`, out)
+	require.NoError(t, err)
+	assert.Equal(t, "WORLD", out.String())
+
+	err = tr.Render(ctx, "test", `{{ ds "broken" }}`, &bytes.Buffer{})
+	require.ErrorContains(t, err, "couldn't read datasource 'broken'")
+
 	// with a nested template
 	nu, _ := url.Parse("nested.tmpl")
 	fsys["nested.tmpl"] = &fstest.MapFile{Data: []byte(
@@ -78,6 +101,158 @@ func TestRenderTemplate(t *testing.T) {
 	tr = NewRenderer(RenderOptions{})
 	err = tr.Render(ctx, "foo", `{{ bogus }}`, &bytes.Buffer{})
 	assert.ErrorContains(t, err, "template: foo:")
+
+	// associated templates' definitions are visible, and later ones win
+	out = &bytes.Buffer{}
+	err = tr.RenderTemplates(ctx, []Template{{
+		Name:   "main",
+		Text:   `{{ template "a" }} {{ template "b" }}`,
+		Writer: out,
+		Associated: []Template{
+			{Name: "defs1", Text: `ignored{{ define "a" }}A{{ end }}{{ define "b" }}old{{ end }}`},
+			{Name: "defs2", Text: `{{ define "b" }}{{ template "a" }}B{{ end }}`},
+		},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, "A AB", out.String())
+
+	err = tr.RenderTemplates(ctx, []Template{{
+		Name:       "main",
+		Text:       `{{ template "a" }}`,
+		Writer:     &bytes.Buffer{},
+		Associated: []Template{{Name: "defs", Text: `{{ define "a" }}{{ bogus }}{{ end }}`}},
+	}})
+	assert.ErrorContains(t, err, "template: defs:")
+}
+
+func TestRenderRemoteNestedTemplate(t *testing.T) {
+	var hits atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/lib.tmpl":
+			if r.Method == http.MethodGet {
+				hits.Add(1)
+			}
+			fmt.Fprintf(w, `{{ define "greet" }}%s, {{ . }}{{ end }}`, r.Header.Get("X-Greeting"))
+		case "/slow.tmpl":
+			time.Sleep(200 * time.Millisecond)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	fsp := fsimpl.NewMux()
+	fsp.Add(httpfs.FS)
+	ctx := datafs.ContextWithFSProvider(context.Background(), fsp)
+
+	lu, _ := url.Parse(srv.URL + "/lib.tmpl")
+	tr := NewRenderer(RenderOptions{
+		Templates: map[string]DataSource{
+			"lib": {URL: lu, Header: http.Header{"X-Greeting": {"hello"}}},
+		},
+	})
+
+	// the template is only fetched once (not counting the HEAD request from
+	// stat), no matter how often it's used
+	for range 3 {
+		out := &bytes.Buffer{}
+		err := tr.Render(ctx, "test", `{{ template "greet" "world" }}`, out)
+		require.NoError(t, err)
+		assert.Equal(t, "hello, world", out.String())
+	}
+	assert.Equal(t, int32(1), hits.Load())
+
+	// errors name the alias and URL
+	mu, _ := url.Parse(srv.URL + "/missing.tmpl")
+	tr = NewRenderer(RenderOptions{
+		Templates: map[string]DataSource{"missing": {URL: mu}},
+	})
+	err := tr.Render(ctx, "test", `hi`, &bytes.Buffer{})
+	require.ErrorContains(t, err, fmt.Sprintf(`template "missing" (%s)`, mu))
+
+	su, _ := url.Parse(srv.URL + "/slow.tmpl")
+	tr = NewRenderer(RenderOptions{
+		Templates:       map[string]DataSource{"slow": {URL: su}},
+		TemplateTimeout: 50 * time.Millisecond,
+	})
+	err = tr.Render(ctx, "test", `hi`, &bytes.Buffer{})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestRenderTemplatesConcurrently(t *testing.T) {
+	ctx := datafs.ContextWithFSProvider(context.Background(), fsimpl.NewMux())
+
+	tr := NewRenderer(RenderOptions{Parallelism: 4})
+
+	outs := make([]*bytes.Buffer, 20)
+	templates := make([]Template, len(outs))
+	for i := range templates {
+		outs[i] = &bytes.Buffer{}
+		templates[i] = Template{
+			Name:   fmt.Sprintf("t%d", i),
+			Text:   fmt.Sprintf(`{{ "%d" | toUpper }}`, i),
+			Writer: outs[i],
+		}
+	}
+
+	err := tr.RenderTemplates(ctx, templates)
+	require.NoError(t, err)
+	for i, out := range outs {
+		assert.Equal(t, fmt.Sprintf("%d", i), out.String())
+	}
+
+	// the first failed template is always reported
+	templates[3].Text = `{{ fail "three" }}`
+	templates[7].Text = `{{ fail "seven" }}`
+	for range 10 {
+		err = tr.RenderTemplates(ctx, templates)
+		require.ErrorContains(t, err, "template t3")
+		assert.ErrorContains(t, err, "three")
+	}
+
+	// the first failure stops the rest from being rendered
+	tr = NewRenderer(RenderOptions{
+		Parallelism: 4,
+		Funcs: template.FuncMap{"slow": func(s string) string {
+			time.Sleep(10 * time.Millisecond)
+			return s
+		}},
+	})
+	for i := range templates {
+		outs[i].Reset()
+		templates[i].Text = fmt.Sprintf(`{{ slow "%d" }}`, i)
+	}
+	templates[0].Text = `{{ fail "zero" }}`
+
+	err = tr.RenderTemplates(ctx, templates)
+	require.ErrorContains(t, err, "template t0")
+	assert.Empty(t, outs[len(outs)-1].String())
+
+	// as does cancelling the context
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+
+	templates[0].Text = `{{ slow "0" }}`
+	err = tr.RenderTemplates(cctx, templates)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestOptionsFromConfig_Parallelism(t *testing.T) {
+	opts := optionsFromConfig(&Config{Parallelism: 3})
+	assert.Equal(t, 3, opts.Parallelism)
+
+	opts = optionsFromConfig(&Config{})
+	assert.Positive(t, opts.Parallelism)
+
+	// multiple stdout outputs must not be interleaved
+	opts = optionsFromConfig(&Config{
+		Parallelism: 3,
+		InputFiles:  []string{"a", "b"},
+		OutputFiles: []string{"-", "-"},
+	})
+	assert.Equal(t, 1, opts.Parallelism)
 }
 
 //// examples
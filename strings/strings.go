@@ -3,14 +3,12 @@ package strings
 
 import (
 	"fmt"
-	"regexp"
 	"sort"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/Masterminds/goutils"
-	"github.com/hairyhenderson/gomplate/v4/conv"
-	"golang.org/x/text/cases"
-	"golang.org/x/text/language"
 )
 
 // Indent - indent each line of the string with the given indent string.
@@ -69,40 +67,114 @@ func Sort(list []string) []string {
 	return sorted
 }
 
-var (
-	spaces      = regexp.MustCompile(`\s+`)
-	nonAlphaNum = regexp.MustCompile(`[^\pL\pN]+`)
-)
-
-// SnakeCase -
+// SnakeCase - converts the input to snake_case. The first letter keeps its
+// case, and the rest are lower-cased.
 func SnakeCase(in string) string {
-	s := casePrepare(in)
-	return spaces.ReplaceAllString(s, "_")
+	return joinWords(in, "_", strings.ToLower)
 }
 
-// KebabCase -
+// KebabCase - converts the input to kebab-case. The first letter keeps its
+// case, and the rest are lower-cased.
 func KebabCase(in string) string {
-	s := casePrepare(in)
-	return spaces.ReplaceAllString(s, "-")
+	return joinWords(in, "-", strings.ToLower)
 }
 
-func casePrepare(in string) string {
-	in = strings.TrimSpace(in)
-	s := strings.ToLower(in)
-	// make sure the first letter remains lower- or upper-cased
-	s = strings.Replace(s, string(s[0]), string(in[0]), 1)
-	s = nonAlphaNum.ReplaceAllString(s, " ")
-	return strings.TrimSpace(s)
+// ScreamingSnakeCase - converts the input to SCREAMING_SNAKE_CASE
+func ScreamingSnakeCase(in string) string {
+	return strings.Join(mapWords(splitWords(in), strings.ToUpper), "_")
 }
 
-// CamelCase -
+// CamelCase - converts the input to camelCase. The first letter keeps its
+// case, so "Foo bar" becomes "FooBar", and "foo bar" becomes "fooBar".
 func CamelCase(in string) string {
-	in = strings.TrimSpace(in)
-	tag := language.Und
-	s := cases.Title(tag).String(conv.ToString(in))
-	// make sure the first letter remains lower- or upper-cased
-	s = strings.Replace(s, string(s[0]), string(in[0]), 1)
-	return nonAlphaNum.ReplaceAllString(s, "")
+	return joinWords(in, "", titleWord)
+}
+
+// PascalCase - converts the input to PascalCase, which is CamelCase that
+// always starts with an upper-case letter
+func PascalCase(in string) string {
+	return strings.Join(mapWords(splitWords(in), titleWord), "")
+}
+
+// joinWords converts each word in the input with conv and joins them with
+// sep, keeping the case of the input's first letter
+func joinWords(in, sep string, conv func(string) string) string {
+	s := strings.Join(mapWords(splitWords(in), conv), sep)
+
+	r, size := utf8.DecodeRuneInString(s)
+	if r == utf8.RuneError {
+		return s
+	}
+
+	for _, first := range in {
+		switch {
+		case unicode.IsUpper(first):
+			r = unicode.ToUpper(r)
+		case unicode.IsLower(first):
+			r = unicode.ToLower(r)
+		default:
+			continue
+		}
+
+		return string(r) + s[size:]
+	}
+
+	return s
+}
+
+func mapWords(words []string, conv func(string) string) []string {
+	for i, w := range words {
+		words[i] = conv(w)
+	}
+
+	return words
+}
+
+// titleWord upper-cases the first letter of the word, and lower-cases the rest
+func titleWord(w string) string {
+	r, size := utf8.DecodeRuneInString(w)
+	return string(unicode.ToTitle(r)) + strings.ToLower(w[size:])
+}
+
+// splitWords splits the input into words. Words are separated by any
+// characters that aren't letters or digits, and a new word starts at each
+// upper-case letter following a lower-case letter or a digit. Runs of
+// upper-case letters are kept together as acronyms, except for the last
+// letter when it's followed by a lower-case letter, so "HTTPServer" is split
+// into "HTTP" and "Server". Digits belong to the word they follow.
+func splitWords(in string) []string {
+	words := []string{}
+	runes := []rune(in)
+
+	word := []rune{}
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if len(word) > 0 {
+				words = append(words, string(word))
+				word = []rune{}
+			}
+
+			continue
+		}
+
+		if len(word) > 0 && unicode.IsUpper(r) {
+			prev := word[len(word)-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				words = append(words, string(word))
+				word = []rune{}
+			}
+		}
+
+		word = append(word, r)
+	}
+
+	if len(word) > 0 {
+		words = append(words, string(word))
+	}
+
+	return words
 }
 
 // WordWrapOpts defines the options to apply to the WordWrap function
@@ -110,8 +182,16 @@ type WordWrapOpts struct {
 	// Line-break sequence to insert (defaults to "\n")
 	LBSeq string
 
+	// A prefix to insert at the start of each line (e.g. "# "). The prefix
+	// counts towards the line length.
+	Prefix string
+
 	// The desired maximum line length in characters (defaults to 80)
 	Width uint
+
+	// Break words that are longer than the width, instead of leaving them on
+	// their own over-long line
+	HardWrap bool
 }
 
 // applies default options
@@ -129,7 +209,18 @@ func wwDefaults(opts WordWrapOpts) WordWrapOpts {
 // width.
 func WordWrap(in string, opts WordWrapOpts) string {
 	opts = wwDefaults(opts)
-	return goutils.WrapCustom(in, int(opts.Width), opts.LBSeq, false)
+	if opts.Prefix == "" {
+		return goutils.WrapCustom(in, int(opts.Width), opts.LBSeq, opts.HardWrap)
+	}
+
+	width := int(opts.Width) - utf8.RuneCountInString(opts.Prefix)
+	if width < 1 {
+		width = 1
+	}
+
+	out := goutils.WrapCustom(in, width, opts.LBSeq, opts.HardWrap)
+
+	return opts.Prefix + strings.ReplaceAll(out, opts.LBSeq, opts.LBSeq+opts.Prefix)
 }
 
 // SkipLines - skip the given number of lines (ending with \n) from the string.
@@ -90,17 +90,63 @@ func TestSort(t *testing.T) {
 }
 
 func TestCaseFuncs(t *testing.T) {
-	testdata := []struct{ in, s, k, c string }{
-		{"  Foo bar ", "Foo_bar", "Foo-bar", "FooBar"},
-		{"foo  bar", "foo_bar", "foo-bar", "fooBar"},
-		{" baz\tqux  ", "baz_qux", "baz-qux", "bazQux"},
-		{"Hello, World!", "Hello_world", "Hello-world", "HelloWorld"},
-		{"grüne | Straße", "grüne_straße", "grüne-straße", "grüneStraße"},
+	testdata := []struct{ in, s, k, c, p, ss string }{
+		{"  Foo bar ", "Foo_bar", "Foo-bar", "FooBar", "FooBar", "FOO_BAR"},
+		{"foo  bar", "foo_bar", "foo-bar", "fooBar", "FooBar", "FOO_BAR"},
+		{" baz\tqux  ", "baz_qux", "baz-qux", "bazQux", "BazQux", "BAZ_QUX"},
+		{"Hello, World!", "Hello_world", "Hello-world", "HelloWorld", "HelloWorld", "HELLO_WORLD"},
+		{"grüne | Straße", "grüne_straße", "grüne-straße", "grüneStraße", "GrüneStraße", "GRÜNE_STRAßE"},
+		{"foo-bar_baz", "foo_bar_baz", "foo-bar-baz", "fooBarBaz", "FooBarBaz", "FOO_BAR_BAZ"},
+		{"fooBar", "foo_bar", "foo-bar", "fooBar", "FooBar", "FOO_BAR"},
+		{"FooBar", "Foo_bar", "Foo-bar", "FooBar", "FooBar", "FOO_BAR"},
+		{"foo_bar", "foo_bar", "foo-bar", "fooBar", "FooBar", "FOO_BAR"},
+		{"FOO_BAR", "Foo_bar", "Foo-bar", "FooBar", "FooBar", "FOO_BAR"},
+		{"HTTPServer", "Http_server", "Http-server", "HttpServer", "HttpServer", "HTTP_SERVER"},
+		{"httpServer", "http_server", "http-server", "httpServer", "HttpServer", "HTTP_SERVER"},
+		{"getHTTPResponseCode", "get_http_response_code", "get-http-response-code", "getHttpResponseCode", "GetHttpResponseCode", "GET_HTTP_RESPONSE_CODE"},
+		{"userID", "user_id", "user-id", "userId", "UserId", "USER_ID"},
+		{"HTTP2Server", "Http2_server", "Http2-server", "Http2Server", "Http2Server", "HTTP2_SERVER"},
+		{"version2Update", "version2_update", "version2-update", "version2Update", "Version2Update", "VERSION2_UPDATE"},
+		{"S3Bucket", "S3_bucket", "S3-bucket", "S3Bucket", "S3Bucket", "S3_BUCKET"},
+		{"v1.2.3", "v1_2_3", "v1-2-3", "v123", "V123", "V1_2_3"},
+		{"123 go", "123_go", "123-go", "123Go", "123Go", "123_GO"},
+		{"_private thing", "private_thing", "private-thing", "privateThing", "PrivateThing", "PRIVATE_THING"},
+		{"A", "A", "A", "A", "A", "A"},
+		{"a", "a", "a", "a", "A", "A"},
+		{"", "", "", "", "", ""},
+		{" - ", "", "", "", "", ""},
 	}
 	for _, d := range testdata {
-		assert.Equal(t, d.s, SnakeCase(d.in))
-		assert.Equal(t, d.k, KebabCase(d.in))
-		assert.Equal(t, d.c, CamelCase(d.in))
+		t.Run(d.in, func(t *testing.T) {
+			assert.Equal(t, d.s, SnakeCase(d.in), "SnakeCase")
+			assert.Equal(t, d.k, KebabCase(d.in), "KebabCase")
+			assert.Equal(t, d.c, CamelCase(d.in), "CamelCase")
+			assert.Equal(t, d.p, PascalCase(d.in), "PascalCase")
+			assert.Equal(t, d.ss, ScreamingSnakeCase(d.in), "ScreamingSnakeCase")
+		})
+	}
+}
+
+func TestSplitWords(t *testing.T) {
+	testdata := []struct {
+		in       string
+		expected []string
+	}{
+		{"", []string{}},
+		{"foo", []string{"foo"}},
+		{"foo bar\tbaz", []string{"foo", "bar", "baz"}},
+		{"fooBarBaz", []string{"foo", "Bar", "Baz"}},
+		{"HTTPServer", []string{"HTTP", "Server"}},
+		{"myHTTPServer", []string{"my", "HTTP", "Server"}},
+		{"ServeHTTP", []string{"Serve", "HTTP"}},
+		{"ipv4Addr", []string{"ipv4", "Addr"}},
+		// mixed-case acronyms can't be told apart from words
+		{"IPv4Addr", []string{"I", "Pv4", "Addr"}},
+		{"Größe--Ändern", []string{"Größe", "Ändern"}},
+		{"日本語 text", []string{"日本語", "text"}},
+	}
+	for _, d := range testdata {
+		assert.Equal(t, d.expected, splitWords(d.in), d.in)
 	}
 }
 
@@ -130,6 +176,29 @@ that has been set.`
 	in = strings.ReplaceAll(out, "\n", " ")
 	assert.Equal(t, out, WordWrap(in, WordWrapOpts{}))
 
+	in = "a short line that needs wrapping"
+	out = `# a short
+# line
+# that
+# needs
+# wrapping`
+	assert.Equal(t, out, WordWrap(in, WordWrapOpts{Width: 10, Prefix: "# "}))
+
+	in = "see https://example.com/long/url"
+	out = `see
+https://ex
+ample.com/
+long/url`
+	assert.Equal(t, out, WordWrap(in, WordWrapOpts{Width: 10, HardWrap: true}))
+
+	out = `// see
+// https:
+// //exam
+// ple.co
+// m/long
+// /url`
+	assert.Equal(t, out, WordWrap(in, WordWrapOpts{Width: 9, Prefix: "// ", HardWrap: true}))
+
 	// TODO: get these working - need to switch to a word-wrapping package that
 	// can handle multi-byte characters!
 	//
@@ -1,18 +1,24 @@
 package gomplate
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
 	"errors"
 	"fmt"
 	"io"
 	"io/fs"
+	"log/slog"
 	"os"
+	"path"
 	"path/filepath"
+	"strings"
 	"text/template"
 
 	"github.com/hack-pad/hackpadfs"
 	"github.com/hairyhenderson/gomplate/v4/internal/datafs"
 	"github.com/hairyhenderson/gomplate/v4/internal/iohelpers"
+	"github.com/hairyhenderson/gomplate/v4/internal/urlhelpers"
 	"github.com/hairyhenderson/gomplate/v4/tmpl"
 
 	// TODO: switch back if/when fs.FS support gets merged upstream
@@ -44,39 +50,61 @@ func copyFuncMap(funcMap template.FuncMap) template.FuncMap {
 }
 
 // gatherTemplates - gather and prepare templates for rendering
-func gatherTemplates(ctx context.Context, cfg *Config, outFileNamer outputNamer) ([]Template, error) {
-	mode, modeOverride, err := cfg.getMode()
-	if err != nil {
-		return nil, err
-	}
+func gatherTemplates(ctx context.Context, cfg *Config, outFileNamer outputNamer) (templates []Template, err error) {
+	switch {
+	case cfg.Input != "" || cfg.InputURL != "":
+		// the arg-provided input string gets a special name
+		name, text := "<arg>", cfg.Input
+		if cfg.InputURL != "" {
+			name = cfg.InputURL
+
+			text, err = readInputURL(ctx, cfg)
+			if err != nil {
+				return nil, err
+			}
+		}
 
-	var templates []Template
+		outFile := cfg.outputName(cfg.OutputFiles[0])
+		mode, modeOverride, merr := cfg.getMode(outFile)
+		if merr != nil {
+			return nil, merr
+		}
 
-	switch {
-	case cfg.Input != "":
 		// open the output file - no need to close it, as it will be closed by the
 		// caller later
-		target, oerr := openOutFile(ctx, cfg.OutputFiles[0], 0o755, mode, modeOverride, cfg.Stdout)
+		target, oerr := openOutFile(ctx, outFile, cfg.outFileOpts(mode, modeOverride))
 		if oerr != nil {
 			return nil, fmt.Errorf("openOutFile: %w", oerr)
 		}
 
 		templates = []Template{{
-			// the arg-provided input string gets a special name
-			Name:   "<arg>",
-			Text:   cfg.Input,
+			Name:   name,
+			Text:   text,
 			Writer: target,
 		}}
 	case cfg.InputDir != "":
 		// input dirs presume output dirs are set too
-		templates, err = walkDir(ctx, cfg, cfg.InputDir, outFileNamer, cfg.ExcludeGlob, cfg.ExcludeProcessingGlob, mode, modeOverride)
+		templates, err = walkDir(ctx, cfg, cfg.InputDir, outFileNamer, cfg.ExcludeGlob, cfg.ExcludeProcessingGlob)
 		if err != nil {
 			return nil, fmt.Errorf("walkDir: %w", err)
 		}
+	case len(cfg.InputFiles) > 0 && cfg.Combine:
+		tpl, cerr := combineInputFiles(ctx, cfg)
+		if cerr != nil {
+			return nil, cerr
+		}
+
+		templates = []Template{tpl}
 	case len(cfg.InputFiles) > 0:
 		templates = make([]Template, len(cfg.InputFiles))
 		for i, f := range cfg.InputFiles {
-			templates[i], err = fileToTemplate(ctx, cfg, f, cfg.OutputFiles[i], mode, modeOverride)
+			outFile := cfg.outputName(cfg.OutputFiles[i])
+			mode, modeOverride, merr := cfg.getMode(outFile)
+			if merr != nil {
+				return nil, merr
+			}
+
+			templates[i], _, err = fileToTemplate(ctx, cfg, f, outFile, mode, modeOverride)
 			if err != nil {
 				return nil, fmt.Errorf("fileToTemplate: %w", err)
 			}
@@ -86,23 +114,84 @@ func gatherTemplates(ctx context.Context, cfg *Config, outFileNamer outputNamer)
 	return templates, nil
 }
 
+// combineInputFiles reads the input files as a single template, rendered to
+// the (only) output file. The last file is the entrypoint, and the others are
+// associated with it, in order. Front matter is only read from the entrypoint.
+func combineInputFiles(ctx context.Context, cfg *Config) (Template, error) {
+	last := len(cfg.InputFiles) - 1
+
+	associated := make([]Template, 0, last)
+	for _, f := range cfg.InputFiles[:last] {
+		source, _, err := readInFile(ctx, f, 0)
+		if err != nil {
+			return Template{}, fmt.Errorf("readInFile: %w", err)
+		}
+
+		associated = append(associated, Template{Name: f, Text: source})
+	}
+
+	outFile := cfg.outputName(cfg.OutputFiles[0])
+	mode, modeOverride, err := cfg.getMode(outFile)
+	if err != nil {
+		return Template{}, err
+	}
+
+	tpl, _, err := fileToTemplate(ctx, cfg, cfg.InputFiles[last], outFile, mode, modeOverride)
+	if err != nil {
+		return Template{}, fmt.Errorf("fileToTemplate: %w", err)
+	}
+
+	tpl.Associated = associated
+
+	return tpl, nil
+}
+
+// inputURLAlias is the alias that headers for the input template URL are
+// given for (i.e. --datasource-header in=...)
+const inputURLAlias = "in"
+
+// readInputURL reads the input template from the datasource URL in
+// cfg.InputURL, with the same readers as datasources
+func readInputURL(ctx context.Context, cfg *Config) (string, error) {
+	u, err := urlhelpers.ParseSourceURL(cfg.InputURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid input template URL %q: %w", cfg.InputURL, err)
+	}
+
+	reg := datafs.NewRegistry()
+	reg.Register(inputURLAlias, DataSource{URL: u, Header: cfg.ExtraHeaders[inputURLAlias]})
+
+	_, b, err := datafs.NewSourceReader(reg).ReadSource(ctx, inputURLAlias)
+	if err != nil {
+		return "", fmt.Errorf("failed to read input template from %s: %w", u, err)
+	}
+
+	return string(b), nil
+}
+
 // walkDir - given an input dir `dir` and an output dir `outDir`, and a list
 // of .gomplateignore and exclude globs (if any), walk the input directory and create a list of
 // tplate objects, and an error, if any.
-func walkDir(ctx context.Context, cfg *Config, dir string, outFileNamer outputNamer, excludeGlob []string, excludeProcessingGlob []string, mode os.FileMode, modeOverride bool) ([]Template, error) {
-	dir = filepath.ToSlash(filepath.Clean(dir))
+func walkDir(ctx context.Context, cfg *Config, dir string, outFileNamer outputNamer, excludeGlob []string, excludeProcessingGlob []string) ([]Template, error) {
+	archive := isArchiveURL(dir)
+	if !archive {
+		dir = filepath.ToSlash(filepath.Clean(dir))
+	}
 
-	// get a filesystem rooted in the same volume as dir (or / on non-Windows)
-	fsys, err := datafs.FSysForPath(ctx, dir)
+	// get a filesystem rooted in the same volume as dir (or / on non-Windows),
+	// or at the root of the archive
+	fsys, resolvedDir, err := inputFS(ctx, dir)
 	if err != nil {
 		return nil, err
 	}
 
 	// we need dir to be relative to the root of fsys
 	// TODO: maybe need to do something with root here?
-	_, resolvedDir, err := datafs.ResolveLocalPath(fsys, dir)
-	if err != nil {
-		return nil, fmt.Errorf("resolveLocalPath: %w", err)
+	if !archive {
+		_, resolvedDir, err = datafs.ResolveLocalPath(fsys, dir)
+		if err != nil {
+			return nil, fmt.Errorf("resolveLocalPath: %w", err)
+		}
 	}
 
 	// we need to sub the filesystem to the dir
@@ -118,7 +207,12 @@ func walkDir(ctx context.Context, cfg *Config, dir string, outFileNamer outputNa
 	}
 	dirMode := dirStat.Mode()
 
-	templates := make([]Template, 0)
+	// archives don't always record directory modes (zip archives usually
+	// don't), so output directories are created with the default mode
+	if archive {
+		dirMode = 0o755
+	}
+
 	matcher := xignore.NewMatcher(subfsys)
 
 	excludeMatches, err := matcher.Matches(".", &xignore.MatchesOptions{
@@ -127,6 +221,10 @@ func walkDir(ctx context.Context, cfg *Config, dir string, outFileNamer outputNa
 		AfterPatterns: excludeGlob,
 	})
 	if err != nil {
+		// xignore doesn't say which file or line has a bad pattern
+		if perr := checkIgnorefiles(subfsys, dir); perr != nil {
+			return nil, perr
+		}
 		return nil, fmt.Errorf("ignore matching failed for %s: %w", dir, err)
 	}
 
@@ -147,11 +245,18 @@ func walkDir(ctx context.Context, cfg *Config, dir string, outFileNamer outputNa
 		passthroughFiles[file] = true
 	}
 
+	// all output paths are resolved and checked before anything is written,
+	// so that a collision doesn't leave some files copied or rendered
+	outputs := make(map[string]string)
+	planned := make([]plannedOutput, 0, len(excludeMatches.UnmatchedFiles))
+
 	// Unmatched ignorefile rules's files
 	for _, file := range excludeMatches.UnmatchedFiles {
 		// we want to pass an absolute (as much as possible) path to fileToTemplate
-		inPath := filepath.Join(dir, file)
-		inPath = filepath.ToSlash(inPath)
+		inPath := filepath.ToSlash(filepath.Join(dir, file))
+		if archive {
+			inPath = archiveJoin(dir, file)
+		}
 
 		// but outFileNamer expects only the filename itself
 		outFile, err := outFileNamer.Name(ctx, file)
@@ -159,9 +264,38 @@ func walkDir(ctx context.Context, cfg *Config, dir string, outFileNamer outputNa
 			return nil, fmt.Errorf("outFileNamer: %w", err)
 		}
 
-		_, ok := passthroughFiles[file]
-		if ok {
-			err = copyFileToOutDir(ctx, cfg, inPath, outFile, mode, modeOverride)
+		mode, modeOverride, err := cfg.getMode(outFile)
+		if err != nil {
+			return nil, err
+		}
+
+		p := plannedOutput{
+			inPath:       inPath,
+			outFile:      outFile,
+			mode:         mode,
+			modeOverride: modeOverride,
+			passthrough:  passthroughFiles[file],
+		}
+
+		// templates are read now, since front matter can change the output
+		if !p.passthrough {
+			p.tpl, p.outFile, p.mode, p.modeOverride, err = readTemplateFile(ctx, cfg, inPath, outFile, mode, modeOverride)
+			if err != nil {
+				return nil, fmt.Errorf("fileToTemplate: %w", err)
+			}
+		}
+
+		if err = checkOutput(outputs, inPath, p.outFile); err != nil {
+			return nil, err
+		}
+
+		planned = append(planned, p)
+	}
+
+	templates := make([]Template, 0, len(planned))
+	for _, p := range planned {
+		if p.passthrough {
+			err = copyFileToOutDir(ctx, cfg, p.inPath, p.outFile, p.mode, p.modeOverride)
 			if err != nil {
 				return nil, fmt.Errorf("copyFileToOutDir: %w", err)
 			}
@@ -169,26 +303,129 @@ func walkDir(ctx context.Context, cfg *Config, dir string, outFileNamer outputNa
 			continue
 		}
 
-		tpl, err := fileToTemplate(ctx, cfg, inPath, outFile, mode, modeOverride)
+		p.tpl.Writer, err = getOutfileHandler(ctx, cfg, p.outFile, p.mode, p.modeOverride)
 		if err != nil {
 			return nil, fmt.Errorf("fileToTemplate: %w", err)
 		}
 
+		// nothing is written in dry-run mode, so no directories are needed
+		if dryRunReportFromContext(ctx) != nil {
+			templates = append(templates, p.tpl)
+			continue
+		}
+
 		// Ensure file parent dirs - use separate fsys for output file
-		outfsys, err := datafs.FSysForPath(ctx, outFile)
+		outfsys, err := datafs.FSysForPath(ctx, p.outFile)
 		if err != nil {
 			return nil, fmt.Errorf("fsysForPath: %w", err)
 		}
-		if err = hackpadfs.MkdirAll(outfsys, filepath.Dir(outFile), dirMode); err != nil {
-			return nil, fmt.Errorf("mkdirAll %q: %w", outFile, err)
+		if err = hackpadfs.MkdirAll(outfsys, filepath.Dir(p.outFile), dirMode); err != nil {
+			return nil, fmt.Errorf("mkdirAll %q: %w", p.outFile, err)
 		}
 
-		templates = append(templates, tpl)
+		templates = append(templates, p.tpl)
 	}
 
 	return templates, nil
 }
 
+// plannedOutput is an input file found by walkDir, and where it's written.
+// Passthrough files are copied as-is, and have no template.
+type plannedOutput struct {
+	tpl          Template
+	inPath       string
+	outFile      string
+	mode         os.FileMode
+	modeOverride bool
+	passthrough  bool
+}
+
+// checkOutput records that inPath is written to outFile, and returns an error
+// if another input is already written there (e.g. from an output map that maps
+// different inputs to the same path)
+func checkOutput(outputs map[string]string, inPath, outFile string) error {
+	if prev, ok := outputs[outFile]; ok {
+		return fmt.Errorf("input files %q and %q would both be written to %q", prev, inPath, outFile)
+	}
+	outputs[outFile] = inPath
+
+	return nil
+}
+
+// checkIgnorefiles finds all .gomplateignore files in fsys, and looks for
+// invalid patterns, so the error can name the file and line. Each line is
+// parsed on its own by xignore, so patterns are read exactly as they are
+// when matching.
+func checkIgnorefiles(fsys fs.FS, dir string) error {
+	return fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() || d.Name() != gomplateignore {
+			return nil
+		}
+
+		b, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return fmt.Errorf("read %s: %w", filepath.Join(dir, p), err)
+		}
+
+		for i, line := range strings.Split(string(b), "\n") {
+			f := xignore.Ignorefile{}
+			if err := f.FromReader(strings.NewReader(line)); err != nil {
+				return fmt.Errorf("%s:%d: %w", filepath.Join(dir, p), i+1, err)
+			}
+
+			for _, pattern := range f.Patterns {
+				if err := xignore.NewPattern(pattern).Prepare(); err != nil {
+					return fmt.Errorf("%s:%d: invalid pattern %q: %w", filepath.Join(dir, p), i+1, strings.TrimSuffix(line, "\r"), err)
+				}
+			}
+		}
+
+		return nil
+	})
+}
+
+// inputFS returns a filesystem to read the input file (or directory) from,
+// and the input's name in it. Inputs in archives are given as archive URLs,
+// like archive:///tmp/bundle.zip//templates/foo.tmpl, and their names are
+// relative to the root of the archive.
+func inputFS(ctx context.Context, inPath string) (fs.FS, string, error) {
+	fsys, err := datafs.FSysForPath(ctx, inPath)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if !isArchiveURL(inPath) {
+		return fsys, inPath, nil
+	}
+
+	u, err := urlhelpers.ParseSourceURL(inPath)
+	if err != nil {
+		return nil, "", err
+	}
+
+	_, name := datafs.SplitFSMuxURL(u)
+
+	return fsys, name, nil
+}
+
+func isArchiveURL(p string) bool {
+	return strings.HasPrefix(p, "archive:")
+}
+
+// archiveJoin appends the file's path to the archive URL dir, adding the
+// double-slash separating the archive file from the path in the archive when
+// dir is the archive's root
+func archiveJoin(dir, file string) string {
+	scheme, rest, _ := strings.Cut(dir, "://")
+	archiveFile, inner, _ := strings.Cut(rest, "//")
+
+	return scheme + "://" + archiveFile + "//" + path.Join(inner, file)
+}
+
 func readInFile(ctx context.Context, inFile string, mode os.FileMode) (source string, newmode os.FileMode, err error) {
 	newmode = mode
 	var b []byte
@@ -205,12 +442,13 @@ func readInFile(ctx context.Context, inFile string, mode os.FileMode) (source st
 	} else {
 		var fsys fs.FS
 		var si fs.FileInfo
-		fsys, err = datafs.FSysForPath(ctx, inFile)
+		var name string
+		fsys, name, err = inputFS(ctx, inFile)
 		if err != nil {
 			return source, newmode, fmt.Errorf("fsysForPath: %w", err)
 		}
 
-		si, err = fs.Stat(fsys, inFile)
+		si, err = fs.Stat(fsys, name)
 		if err != nil {
 			return source, newmode, fmt.Errorf("stat %q: %w", inFile, err)
 		}
@@ -220,7 +458,7 @@ func readInFile(ctx context.Context, inFile string, mode os.FileMode) (source st
 
 		// we read the file and store in memory immediately, to prevent leaking
 		// file descriptors.
-		b, err = fs.ReadFile(fsys, inFile)
+		b, err = fs.ReadFile(fsys, name)
 		if err != nil {
 			return source, newmode, fmt.Errorf("readAll %q: %w", inFile, err)
 		}
@@ -233,7 +471,7 @@ func readInFile(ctx context.Context, inFile string, mode os.FileMode) (source st
 func getOutfileHandler(ctx context.Context, cfg *Config, outFile string, mode os.FileMode, modeOverride bool) (io.Writer, error) {
 	// open the output file - no need to close it, as it will be closed by the
 	// caller later
-	target, err := openOutFile(ctx, outFile, 0o755, mode, modeOverride, cfg.Stdout)
+	target, err := openOutFile(ctx, outFile, cfg.outFileOpts(mode, modeOverride))
 	if err != nil {
 		return nil, fmt.Errorf("openOutFile: %w", err)
 	}
@@ -241,7 +479,7 @@ func getOutfileHandler(ctx context.Context, cfg *Config, outFile string, mode os
 	return target, nil
 }
 
-func copyFileToOutDir(ctx context.Context, cfg *Config, inFile, outFile string, mode os.FileMode, modeOverride bool) error {
+func copyFileToOutDir(ctx context.Context, cfg *Config, inFile, outFile string, mode os.FileMode, modeOverride bool) (err error) {
 	sourceStr, newmode, err := readInFile(ctx, inFile, mode)
 	if err != nil {
 		return err
@@ -254,84 +492,264 @@ func copyFileToOutDir(ctx context.Context, cfg *Config, inFile, outFile string,
 
 	wr, ok := outFH.(io.Closer)
 	if ok && wr != os.Stdout {
-		defer wr.Close()
+		defer func() {
+			// closing moves the file into place (and runs any post-exec
+			// command), so errors must be reported
+			if cerr := wr.Close(); cerr != nil && err == nil {
+				err = cerr
+			}
+		}()
 	}
 
 	_, err = outFH.Write([]byte(sourceStr))
 	return err
 }
 
-func fileToTemplate(ctx context.Context, cfg *Config, inFile, outFile string, mode os.FileMode, modeOverride bool) (Template, error) {
-	source, newmode, err := readInFile(ctx, inFile, mode)
+// fileToTemplate reads the template file, and opens its output file. When
+// front matter is enabled, it can override the output file and mode, so the
+// output file actually used is also returned.
+func fileToTemplate(ctx context.Context, cfg *Config, inFile, outFile string, mode os.FileMode, modeOverride bool) (Template, string, error) {
+	tmpl, outFile, mode, modeOverride, err := readTemplateFile(ctx, cfg, inFile, outFile, mode, modeOverride)
 	if err != nil {
-		return Template{}, err
+		return Template{}, "", err
 	}
 
-	target, err := getOutfileHandler(ctx, cfg, outFile, newmode, modeOverride)
+	tmpl.Writer, err = getOutfileHandler(ctx, cfg, outFile, mode, modeOverride)
 	if err != nil {
-		return Template{}, err
+		return Template{}, "", err
+	}
+
+	return tmpl, outFile, nil
+}
+
+// readTemplateFile reads the template file, without opening its output file.
+// The output file and mode are returned, as overridden by front matter.
+func readTemplateFile(ctx context.Context, cfg *Config, inFile, outFile string, mode os.FileMode, modeOverride bool) (Template, string, os.FileMode, bool, error) {
+	// the input file's mode is used when no mode is set
+	source, inMode, err := readInFile(ctx, inFile, 0)
+	if err != nil {
+		return Template{}, "", 0, false, err
 	}
 
 	tmpl := Template{
-		Name:   inFile,
-		Text:   source,
-		Writer: target,
+		Name: inFile,
+		Text: source,
 	}
 
-	return tmpl, nil
+	if cfg.FrontMatter {
+		fm, text, err := parseFrontMatter(ctx, inFile, source, cfg.LDelim, cfg.RDelim)
+		if err != nil {
+			return Template{}, "", 0, false, err
+		}
+
+		if fm != nil {
+			tmpl.Text = text
+			tmpl.DataSources = fm.DataSources
+			tmpl.LDelim, tmpl.RDelim = fm.LDelim, fm.RDelim
+
+			outFile, mode, modeOverride, err = fm.apply(ctx, cfg, inFile, outFile, mode, modeOverride)
+			if err != nil {
+				return Template{}, "", 0, false, err
+			}
+		}
+	}
+
+	if mode == 0 {
+		mode = inMode
+	}
+
+	return tmpl, outFile, mode, modeOverride, nil
 }
 
+// outFileOpts are the options for opening an output file
+type outFileOpts struct {
+	// stdout is written to when the file is "-"
+	stdout io.Writer
+	// compress is the compression to use ("gzip"), if any
+	compress string
+	// mode is the file's mode - existing files are only chmodded when
+	// modeOverride is set
+	mode         os.FileMode
+	modeOverride bool
+	force        bool
+	stream       bool
+	deleteEmpty  bool
+}
+
+// outFileOpts returns the options for opening an output file with the given
+// mode (see getMode)
+func (c *Config) outFileOpts(mode os.FileMode, modeOverride bool) outFileOpts {
+	return outFileOpts{
+		stdout:       c.Stdout,
+		compress:     c.Compress,
+		mode:         mode,
+		modeOverride: modeOverride,
+		force:        c.Force,
+		stream:       c.Stream,
+		deleteEmpty:  c.DeleteEmpty,
+	}
+}
+
+// outDirMode is the mode of the output file's parent directories, when they
+// need to be created
+const outDirMode = 0o755
+
 // openOutFile returns a writer for the given file, creating the file if it
 // doesn't exist yet, and creating the parent directories if necessary. Will
 // defer actual opening until the first non-empty write. If the file already
-// exists, it will not be overwritten until the first difference is encountered.
+// exists, it will not be overwritten until the first difference is encountered,
+// unless opts.force is set.
 //
-// TODO: dirMode is always called with 0o755 - should either remove or make it configurable
+// When opts.stream is set, nothing is buffered: output to stdout isn't held
+// back until the first non-whitespace write, and files are written in place
+// rather than to a temporary file.
 //
-//nolint:unparam
-func openOutFile(ctx context.Context, filename string, dirMode, mode os.FileMode, modeOverride bool, stdout io.Writer) (out io.Writer, err error) {
+// When opts.deleteEmpty is set, an existing file is deleted if nothing but
+// whitespace was written by the time the writer is closed.
+//
+// When opts.compress is "gzip", the output is compressed, and the file's
+// current content is decompressed to compare it with the output. The filename
+// must already have the ".gz" extension (see Config.outputName).
+func openOutFile(ctx context.Context, filename string, opts outFileOpts) (out io.Writer, err error) {
+	create := func() (io.WriteCloser, error) {
+		w, err := createOutFile(ctx, filename, opts)
+		if err != nil {
+			return nil, err
+		}
+		// the post-exec command needs to know whether the file was written,
+		// so it wraps the file's writer directly, and runs before any chown
+		return withOutOwner(ctx, filename, withPostExecEach(ctx, filename, w)), nil
+	}
+
+	stdoutWriter := func() io.WriteCloser {
+		w := iohelpers.NopCloser(opts.stdout)
+		if opts.compress != "" {
+			w = iohelpers.GzipWriter(w)
+		}
+		return w
+	}
+
+	if opts.stream {
+		if filename == "-" {
+			return stdoutWriter(), nil
+		}
+		return create()
+	}
+
+	if opts.deleteEmpty && filename != "-" {
+		d := &emptyDeleter{ctx: ctx, name: filename}
+		d.WriteCloser = iohelpers.NewEmptySkipper(func() (io.Writer, error) {
+			d.opened = true
+			return create()
+		})
+		return d, nil
+	}
+
 	out = iohelpers.NewEmptySkipper(func() (io.Writer, error) {
 		if filename == "-" {
-			return iohelpers.NopCloser(stdout), nil
+			return stdoutWriter(), nil
 		}
-		return createOutFile(ctx, filename, dirMode, mode, modeOverride)
+		return create()
 	})
 	return out, nil
 }
 
-func createOutFile(ctx context.Context, filename string, dirMode, mode os.FileMode, modeOverride bool) (out io.WriteCloser, err error) {
+// emptyDeleter deletes the output file on Close when the wrapped writer was
+// never opened, i.e. when the template rendered to empty output
+type emptyDeleter struct {
+	io.WriteCloser
+	ctx    context.Context
+	name   string
+	opened bool
+}
+
+var _ iohelpers.Aborter = (*emptyDeleter)(nil)
+
+func (w *emptyDeleter) Close() error {
+	if err := w.WriteCloser.Close(); err != nil {
+		return err
+	}
+
+	// in dry-run mode, nothing is deleted
+	if w.opened || dryRunReportFromContext(w.ctx) != nil {
+		return nil
+	}
+
+	fsys, err := datafs.FSysForPath(w.ctx, w.name)
+	if err != nil {
+		return fmt.Errorf("fsysForPath: %w", err)
+	}
+
+	err = hackpadfs.Remove(fsys, w.name)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete empty output file %q: %w", w.name, err)
+	}
+
+	if err == nil {
+		slog.DebugContext(w.ctx, "deleted empty output file", "file", w.name)
+	}
+
+	return nil
+}
+
+// Abort - implements iohelpers.Aborter. Existing files are left untouched
+// when rendering fails.
+func (w *emptyDeleter) Abort() error {
+	return iohelpers.Abort(w.WriteCloser)
+}
+
+func createOutFile(ctx context.Context, filename string, opts outFileOpts) (out io.WriteCloser, err error) {
 	// we only support writing out to local files for now
 	fsys, err := datafs.FSysForPath(ctx, filename)
 	if err != nil {
 		return nil, fmt.Errorf("fsysForPath: %w", err)
 	}
 
-	mode = iohelpers.NormalizeFileMode(mode.Perm())
-	if modeOverride {
+	// in dry-run mode, nothing is written - the output is compared with the
+	// file's current content instead
+	if r := dryRunReportFromContext(ctx); r != nil {
+		return r.writer(fsys, filename, opts.compress != ""), nil
+	}
+
+	mode := iohelpers.NormalizeFileMode(opts.mode.Perm())
+
+	fi, statErr := hackpadfs.Stat(fsys, filename)
+
+	// only chmod when the mode actually changes, since even a no-op chmod
+	// updates the file's ctime
+	if opts.modeOverride && statErr == nil && (opts.force || fi.Mode().Perm() != mode) {
 		err = hackpadfs.Chmod(fsys, filename, mode)
-		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		if err != nil {
 			return nil, fmt.Errorf("failed to chmod output file %q with mode %q: %w", filename, mode, err)
 		}
 	}
 
 	open := func() (out io.WriteCloser, err error) {
 		// Ensure file parent dirs
-		if err = hackpadfs.MkdirAll(fsys, filepath.Dir(filename), dirMode); err != nil {
+		if err = hackpadfs.MkdirAll(fsys, filepath.Dir(filename), outDirMode); err != nil {
 			return nil, fmt.Errorf("mkdirAll %q: %w", filename, err)
 		}
 
-		f, err := hackpadfs.OpenFile(fsys, filename, os.O_RDWR|os.O_CREATE|os.O_TRUNC, mode)
-		if err != nil {
-			return out, fmt.Errorf("failed to open output file '%s' for writing: %w", filename, err)
+		if opts.stream {
+			out, err = createStreamFile(fsys, filename, mode)
+		} else {
+			// write to a temp file that's only moved into place once rendering
+			// succeeds, so a failed render never leaves a partial file behind
+			out, err = iohelpers.CreateAtomic(fsys, filename, mode)
+			if err != nil {
+				return out, fmt.Errorf("failed to open output file '%s' for writing: %w", filename, err)
+			}
+		}
+
+		if err == nil && opts.compress != "" {
+			out = iohelpers.GzipWriter(out)
 		}
-		out = f.(io.WriteCloser)
 
 		return out, err
 	}
 
 	// if the output file already exists, we'll use a SameSkipper
-	fi, err := hackpadfs.Stat(fsys, filename)
-	if err != nil {
+	if statErr != nil {
 		// likely means the file just doesn't exist - further errors will be more useful
 		return iohelpers.LazyWriteCloser(open), nil
 	}
@@ -340,9 +758,87 @@ func createOutFile(ctx context.Context, filename string, dirMode, mode os.FileMo
 		return nil, isDirError(fi.Name())
 	}
 
-	out = iohelpers.SameSkipper(iohelpers.LazyReadCloser(func() (io.ReadCloser, error) {
-		return hackpadfs.OpenFile(fsys, filename, os.O_RDONLY, mode)
-	}), open)
+	// when streaming, there's no comparing with the existing content, since
+	// that would mean holding back output until the first difference
+	if opts.force || opts.stream {
+		return iohelpers.LazyWriteCloser(open), nil
+	}
+
+	u := &unchangedRecorder{}
+	u.WriteCloser = iohelpers.SameSkipper(iohelpers.LazyReadCloser(func() (io.ReadCloser, error) {
+		f, err := hackpadfs.OpenFile(fsys, filename, os.O_RDONLY, mode)
+		if err != nil || opts.compress == "" {
+			return f, err
+		}
+		defer f.Close()
+
+		b, err := io.ReadAll(f)
+		if err != nil {
+			return nil, err
+		}
+
+		return io.NopCloser(bytes.NewReader(gunzipOrNil(b))), nil
+	}), func() (io.WriteCloser, error) {
+		u.written = true
+		return open()
+	})
+
+	return u, nil
+}
+
+// gunzipOrNil decompresses b, returning nil if it isn't valid gzip content -
+// so a file that isn't compressed yet compares as changed
+func gunzipOrNil(b []byte) []byte {
+	zr, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return nil
+	}
+	defer zr.Close()
+
+	out, err := io.ReadAll(zr)
+	if err != nil {
+		return nil
+	}
+
+	return out
+}
+
+// createStreamFile opens the output file for writing in place, truncating it
+// if it already exists
+func createStreamFile(fsys fs.FS, filename string, mode os.FileMode) (io.WriteCloser, error) {
+	f, err := hackpadfs.OpenFile(fsys, filename, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open output file '%s' for writing: %w", filename, err)
+	}
+
+	wc, ok := f.(io.WriteCloser)
+	if !ok {
+		_ = f.Close()
+		return nil, fmt.Errorf("output file '%s' is not writable", filename)
+	}
+
+	return wc, nil
+}
+
+// unchangedRecorder counts the output as unchanged in the metrics when it's
+// closed without the wrapped writer ever having opened the file for writing
+type unchangedRecorder struct {
+	io.WriteCloser
+	written bool
+}
+
+var _ iohelpers.Aborter = (*unchangedRecorder)(nil)
+
+func (u *unchangedRecorder) Close() error {
+	err := u.WriteCloser.Close()
+	if err == nil && !u.written && Metrics != nil {
+		Metrics.recordUnchanged()
+	}
+
+	return err
+}
 
-	return out, err
+// Abort - implements iohelpers.Aborter
+func (u *unchangedRecorder) Abort() error {
+	return iohelpers.Abort(u.WriteCloser)
 }
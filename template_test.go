@@ -2,11 +2,13 @@ package gomplate
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
 	"io"
 	"io/fs"
 	"net/url"
 	"os"
+	"path/filepath"
 	"testing"
 	"testing/fstest"
 	"text/template"
@@ -28,7 +30,7 @@ func TestOpenOutFile(t *testing.T) {
 
 	ctx := datafs.ContextWithFSProvider(context.Background(), datafs.WrappedFSProvider(fsys, "file"))
 
-	f, err := openOutFile(ctx, "/tmp/foo", 0o755, 0o644, false, nil)
+	f, err := openOutFile(ctx, "/tmp/foo", outFileOpts{mode: 0o644})
 	require.NoError(t, err)
 
 	_, err = f.Write([]byte("hello world"))
@@ -45,7 +47,7 @@ func TestOpenOutFile(t *testing.T) {
 
 	out := &bytes.Buffer{}
 
-	f, err = openOutFile(ctx, "-", 0o755, 0o644, false, out)
+	f, err = openOutFile(ctx, "-", outFileOpts{stdout: out, mode: 0o644})
 	require.NoError(t, err)
 
 	_, err = f.Write([]byte("hello world"))
@@ -53,6 +55,218 @@ func TestOpenOutFile(t *testing.T) {
 	assert.Equal(t, "hello world", out.String())
 }
 
+func TestOpenOutFile_Stream(t *testing.T) {
+	memfs, _ := mem.NewFS()
+	fsys := datafs.WrapWdFS(memfs)
+
+	_ = hackpadfs.Mkdir(fsys, "/tmp", 0o777)
+	_ = hackpadfs.WriteFullFile(fsys, "/tmp/foo", []byte("a much longer existing file"), 0o644)
+
+	ctx := datafs.ContextWithFSProvider(context.Background(), datafs.WrappedFSProvider(fsys, "file"))
+
+	// leading whitespace isn't held back
+	out := &bytes.Buffer{}
+
+	f, err := openOutFile(ctx, "-", outFileOpts{stdout: out, mode: 0o644, stream: true})
+	require.NoError(t, err)
+
+	_, err = f.Write([]byte("\n  "))
+	require.NoError(t, err)
+	assert.Equal(t, "\n  ", out.String())
+
+	// the file is written in place, truncating the existing content
+	f, err = openOutFile(ctx, "/tmp/foo", outFileOpts{mode: 0o644, stream: true})
+	require.NoError(t, err)
+
+	_, err = f.Write([]byte("hello"))
+	require.NoError(t, err)
+
+	b, err := fs.ReadFile(fsys, "/tmp/foo")
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(b))
+
+	_, err = f.Write([]byte(" world"))
+	require.NoError(t, err)
+
+	wc, ok := f.(io.WriteCloser)
+	assert.True(t, ok)
+	require.NoError(t, wc.Close())
+
+	b, err = fs.ReadFile(fsys, "/tmp/foo")
+	require.NoError(t, err)
+	assert.Equal(t, "hello world", string(b))
+
+	entries, err := fs.ReadDir(fsys, "/tmp")
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestOpenOutFile_DeleteEmpty(t *testing.T) {
+	memfs, _ := mem.NewFS()
+	fsys := datafs.WrapWdFS(memfs)
+
+	_ = hackpadfs.Mkdir(fsys, "/tmp", 0o777)
+	_ = hackpadfs.WriteFullFile(fsys, "/tmp/foo", []byte("hello"), 0o644)
+	_ = hackpadfs.WriteFullFile(fsys, "/tmp/bar", []byte("hello"), 0o644)
+
+	ctx := datafs.ContextWithFSProvider(context.Background(), datafs.WrappedFSProvider(fsys, "file"))
+
+	writeAndClose := func(t *testing.T, filename, content string, deleteEmpty bool) {
+		t.Helper()
+
+		f, err := openOutFile(ctx, filename, outFileOpts{mode: 0o644, deleteEmpty: deleteEmpty})
+		require.NoError(t, err)
+
+		_, err = f.Write([]byte(content))
+		require.NoError(t, err)
+
+		wc, ok := f.(io.WriteCloser)
+		require.True(t, ok)
+		require.NoError(t, wc.Close())
+	}
+
+	// existing files are left alone by default
+	writeAndClose(t, "/tmp/foo", " \n", false)
+
+	b, err := fs.ReadFile(fsys, "/tmp/foo")
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(b))
+
+	writeAndClose(t, "/tmp/foo", " \n", true)
+
+	_, err = fs.Stat(fsys, "/tmp/foo")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+
+	// missing files are fine
+	writeAndClose(t, "/tmp/missing", "", true)
+
+	// non-empty output is written as usual
+	writeAndClose(t, "/tmp/bar", "\nworld", true)
+
+	b, err = fs.ReadFile(fsys, "/tmp/bar")
+	require.NoError(t, err)
+	assert.Equal(t, "\nworld", string(b))
+
+	// failed renders don't delete anything
+	f, err := openOutFile(ctx, "/tmp/bar", outFileOpts{mode: 0o644, deleteEmpty: true})
+	require.NoError(t, err)
+
+	wc, ok := f.(io.WriteCloser)
+	require.True(t, ok)
+	require.NoError(t, iohelpers.Abort(wc))
+
+	_, err = fs.Stat(fsys, "/tmp/bar")
+	require.NoError(t, err)
+
+	// nothing is deleted in dry-run mode
+	dctx := contextWithDryRunReport(ctx, newDryRunReport())
+
+	f, err = openOutFile(dctx, "/tmp/bar", outFileOpts{mode: 0o644, deleteEmpty: true})
+	require.NoError(t, err)
+
+	wc, ok = f.(io.WriteCloser)
+	require.True(t, ok)
+	require.NoError(t, wc.Close())
+
+	_, err = fs.Stat(fsys, "/tmp/bar")
+	require.NoError(t, err)
+}
+
+func TestOpenOutFile_Compress(t *testing.T) {
+	memfs, _ := mem.NewFS()
+	fsys := datafs.WrapWdFS(memfs)
+
+	_ = hackpadfs.Mkdir(fsys, "/tmp", 0o777)
+
+	ctx := datafs.ContextWithFSProvider(context.Background(), datafs.WrappedFSProvider(fsys, "file"))
+
+	gunzip := func(t *testing.T, b []byte) string {
+		t.Helper()
+
+		zr, err := gzip.NewReader(bytes.NewReader(b))
+		require.NoError(t, err)
+
+		out, err := io.ReadAll(zr)
+		require.NoError(t, err)
+
+		return string(out)
+	}
+
+	cfg := &Config{Compress: "gzip"}
+	write := func(t *testing.T, ctx context.Context, filename, content string, stdout io.Writer) {
+		t.Helper()
+
+		f, err := openOutFile(ctx, cfg.outputName(filename), outFileOpts{stdout: stdout, compress: "gzip", mode: 0o644})
+		require.NoError(t, err)
+
+		_, err = f.Write([]byte(content))
+		require.NoError(t, err)
+
+		wc, ok := f.(io.WriteCloser)
+		require.True(t, ok)
+		require.NoError(t, wc.Close())
+	}
+
+	write(t, ctx, "/tmp/foo.json", "hello", nil)
+
+	b, err := fs.ReadFile(fsys, "/tmp/foo.json.gz")
+	require.NoError(t, err)
+	assert.Equal(t, "hello", gunzip(t, b))
+
+	_, err = fs.Stat(fsys, "/tmp/foo.json")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+
+	// names that already have the extension are left alone
+	assert.Equal(t, "/tmp/bar.gz", cfg.outputName("/tmp/bar.gz"))
+	assert.Equal(t, "-", cfg.outputName("-"))
+	write(t, ctx, "/tmp/bar.gz", "world", nil)
+
+	b, err = fs.ReadFile(fsys, "/tmp/bar.gz")
+	require.NoError(t, err)
+	assert.Equal(t, "world", gunzip(t, b))
+
+	// unchanged content isn't rewritten, even if it's compressed differently
+	var zb bytes.Buffer
+	zw, _ := gzip.NewWriterLevel(&zb, gzip.BestSpeed)
+	_, _ = zw.Write([]byte("hello"))
+	require.NoError(t, zw.Close())
+	require.NoError(t, hackpadfs.WriteFullFile(fsys, "/tmp/foo.json.gz", zb.Bytes(), 0o644))
+
+	write(t, ctx, "/tmp/foo.json", "hello", nil)
+
+	b, err = fs.ReadFile(fsys, "/tmp/foo.json.gz")
+	require.NoError(t, err)
+	assert.Equal(t, zb.Bytes(), b)
+
+	write(t, ctx, "/tmp/foo.json", "hello, world", nil)
+
+	b, err = fs.ReadFile(fsys, "/tmp/foo.json.gz")
+	require.NoError(t, err)
+	assert.Equal(t, "hello, world", gunzip(t, b))
+
+	// files that aren't valid gzip are replaced
+	require.NoError(t, hackpadfs.WriteFullFile(fsys, "/tmp/foo.json.gz", []byte("hello, world"), 0o644))
+
+	write(t, ctx, "/tmp/foo.json", "hello, world", nil)
+
+	b, err = fs.ReadFile(fsys, "/tmp/foo.json.gz")
+	require.NoError(t, err)
+	assert.Equal(t, "hello, world", gunzip(t, b))
+
+	// stdout is compressed too
+	out := &bytes.Buffer{}
+	write(t, ctx, "-", "to stdout", out)
+	assert.Equal(t, "to stdout", gunzip(t, out.Bytes()))
+
+	// dry runs compare the decompressed content
+	r := newDryRunReport()
+	write(t, contextWithDryRunReport(ctx, r), "/tmp/foo.json", "hello, world", nil)
+	write(t, contextWithDryRunReport(ctx, r), "/tmp/bar", "changed", nil)
+	assert.Equal(t, dryRunUnchanged, r.files["/tmp/foo.json.gz"].status)
+	assert.Equal(t, dryRunChanged, r.files["/tmp/bar.gz"].status)
+	assert.Contains(t, r.files["/tmp/bar.gz"].diff, "-world\n+changed\n")
+}
+
 func TestGatherTemplates(t *testing.T) {
 	// chdir to root so we can use relative paths
 	wd, _ := os.Getwd()
@@ -95,6 +309,26 @@ func TestGatherTemplates(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "hello world", buf.String())
 
+	buf = &bytes.Buffer{}
+	cfg = &Config{
+		InputURL: "foo",
+		Stdout:   buf,
+	}
+	cfg.applyDefaults()
+	templates, err = gatherTemplates(ctx, cfg, nil)
+	require.NoError(t, err)
+	require.Len(t, templates, 1)
+	assert.Equal(t, "bar", templates[0].Text)
+	assert.Equal(t, "foo", templates[0].Name)
+
+	cfg = &Config{
+		InputURL: "file:///missing",
+		Stdout:   buf,
+	}
+	cfg.applyDefaults()
+	_, err = gatherTemplates(ctx, cfg, nil)
+	require.ErrorContains(t, err, "failed to read input template from file:///missing")
+
 	templates, err = gatherTemplates(ctx, &Config{
 		Input:       "foo",
 		OutputFiles: []string{"out"},
@@ -109,6 +343,11 @@ func TestGatherTemplates(t *testing.T) {
 	_, err = templates[0].Writer.Write([]byte("hello world"))
 	require.NoError(t, err)
 
+	// output is only moved into place on close
+	_, err = hackpadfs.Stat(fsys, "out")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+	require.NoError(t, templates[0].Writer.(io.Closer).Close())
+
 	info, err := hackpadfs.Stat(fsys, "out")
 	require.NoError(t, err)
 	assert.Equal(t, iohelpers.NormalizeFileMode(0o644), info.Mode())
@@ -129,6 +368,7 @@ func TestGatherTemplates(t *testing.T) {
 	require.NoError(t, err)
 	// negative test - we should not be writing to stdout
 	assert.NotEqual(t, "hello world", buf.String())
+	require.NoError(t, templates[0].Writer.(io.Closer).Close())
 
 	info, err = hackpadfs.Stat(fsys, "out")
 	require.NoError(t, err)
@@ -151,12 +391,28 @@ func TestGatherTemplates(t *testing.T) {
 	require.NoError(t, err)
 	// negative test - we should not be writing to stdout
 	assert.NotEqual(t, "hello world", buf.String())
+	require.NoError(t, templates[0].Writer.(io.Closer).Close())
 
 	info, err = hackpadfs.Stat(fsys, "out")
 	require.NoError(t, err)
 	assert.Equal(t, iohelpers.NormalizeFileMode(0o755), info.Mode())
 	hackpadfs.Remove(fsys, "out")
 
+	templates, err = gatherTemplates(ctx, &Config{
+		InputFiles:  []string{"in/1", "in/2", "in/3"},
+		OutputFiles: []string{"-"},
+		Combine:     true,
+		Stdout:      &bytes.Buffer{},
+	}, nil)
+	require.NoError(t, err)
+	require.Len(t, templates, 1)
+	assert.Equal(t, "in/3", templates[0].Name)
+	assert.Equal(t, "baz", templates[0].Text)
+	assert.Equal(t, []Template{
+		{Name: "in/1", Text: "foo"},
+		{Name: "in/2", Text: "bar"},
+	}, templates[0].Associated)
+
 	templates, err = gatherTemplates(ctx, &Config{
 		InputDir:  "in",
 		OutputDir: "out",
@@ -173,11 +429,53 @@ func TestCreateOutFile(t *testing.T) {
 
 	ctx := datafs.ContextWithFSProvider(context.Background(), datafs.WrappedFSProvider(fsys, "file"))
 
-	_, err := createOutFile(ctx, "in", 0o755, 0o644, false)
+	_, err := createOutFile(ctx, "in", outFileOpts{mode: 0o644})
 	require.Error(t, err)
 	assert.IsType(t, &fs.PathError{}, err)
 }
 
+func TestCreateOutFile_Unchanged(t *testing.T) {
+	fsys, _ := mem.NewFS()
+	_ = hackpadfs.WriteFullFile(fsys, "out", []byte("hello"), 0o644)
+
+	ctx := datafs.ContextWithFSProvider(context.Background(), datafs.WrappedFSProvider(fsys, "file"))
+
+	Metrics = newMetrics()
+
+	write := func(content string, mode os.FileMode, modeOverride, force bool) {
+		t.Helper()
+
+		f, err := createOutFile(ctx, "out", outFileOpts{mode: mode, modeOverride: modeOverride, force: force})
+		require.NoError(t, err)
+
+		_, err = f.Write([]byte(content))
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+	}
+
+	write("hello", 0o644, false, false)
+	assert.Equal(t, 1, Metrics.TemplatesUnchanged)
+
+	// a new mode is still applied when the content is unchanged
+	write("hello", 0o600, true, false)
+	assert.Equal(t, 2, Metrics.TemplatesUnchanged)
+
+	fi, err := hackpadfs.Stat(fsys, "out")
+	require.NoError(t, err)
+	assert.Equal(t, iohelpers.NormalizeFileMode(0o600), fi.Mode().Perm())
+
+	// forced writes aren't counted as unchanged
+	write("hello", 0o644, false, true)
+	assert.Equal(t, 2, Metrics.TemplatesUnchanged)
+
+	write("goodbye", 0o644, false, false)
+	assert.Equal(t, 2, Metrics.TemplatesUnchanged)
+
+	b, err := fs.ReadFile(fsys, "out")
+	require.NoError(t, err)
+	assert.Equal(t, "goodbye", string(b))
+}
+
 func TestParseNestedTemplates(t *testing.T) {
 	wd, _ := os.Getwd()
 	t.Cleanup(func() {
@@ -209,7 +507,7 @@ func TestParseNestedTemplates(t *testing.T) {
 	assert.Equal(t, "hello world", out.String())
 
 	// test with directory of templates
-	fsys["dir/"] = &fstest.MapFile{Mode: 0o777 | os.ModeDir}
+	fsys["dir"] = &fstest.MapFile{Mode: 0o777 | os.ModeDir}
 	fsys["dir/foo.t"] = &fstest.MapFile{Data: []byte("foo"), Mode: 0o600}
 	fsys["dir/bar.t"] = &fstest.MapFile{Data: []byte("bar"), Mode: 0o600}
 
@@ -226,4 +524,40 @@ func TestParseNestedTemplates(t *testing.T) {
 	err = tmpl.Execute(&out, nil)
 	require.NoError(t, err)
 	assert.Equal(t, "foo bar", out.String())
+
+	// subdirectories are included too
+	fsys["dir/sub/baz.t"] = &fstest.MapFile{Data: []byte("baz"), Mode: 0o600}
+	fsys["dir/sub/deeper/qux.t"] = &fstest.MapFile{Data: []byte("qux"), Mode: 0o600}
+
+	tmpl, _ = template.New("root").Parse(`{{ template "dir/foo.t" }} {{ template "dir/sub/baz.t" }} {{ template "dir/sub/deeper/qux.t" }}`)
+
+	r = &renderer{nested: nested}
+	err = r.parseNestedTemplates(ctx, tmpl)
+	require.NoError(t, err)
+
+	out = bytes.Buffer{}
+	err = tmpl.Execute(&out, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "foo baz qux", out.String())
+}
+
+func TestCheckIgnorefiles(t *testing.T) {
+	fsys := fstest.MapFS{
+		".gomplateignore":     {Data: []byte("\xEF\xBB\xBF# comment\n*.log\n!keep.log\n")},
+		"sub/.gomplateignore": {Data: []byte("ok/\r\n\r\nfoo[\n")},
+		"sub/a.txt":           {Data: []byte("a")},
+	}
+
+	err := checkIgnorefiles(fsys, "in")
+	require.ErrorContains(t, err, filepath.Join("in", "sub", ".gomplateignore")+`:3: invalid pattern "foo["`)
+
+	fsys["sub/.gomplateignore"] = &fstest.MapFile{Data: []byte("ok/\n!foo\\[\n")}
+	require.NoError(t, checkIgnorefiles(fsys, "in"))
+}
+
+func TestArchiveJoin(t *testing.T) {
+	assert.Equal(t, "archive:///tmp/a.zip//foo.tmpl", archiveJoin("archive:///tmp/a.zip", "foo.tmpl"))
+	assert.Equal(t, "archive:///tmp/a.zip//foo.tmpl", archiveJoin("archive:///tmp/a.zip//", "foo.tmpl"))
+	assert.Equal(t, "archive:///tmp/a.zip//in/sub/foo.tmpl", archiveJoin("archive:///tmp/a.zip//in", "sub/foo.tmpl"))
+	assert.Equal(t, "archive://a.tar.gz//in/foo.tmpl", archiveJoin("archive://a.tar.gz//in/", "foo.tmpl"))
 }
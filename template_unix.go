@@ -4,7 +4,10 @@
 package gomplate
 
 import (
+	"errors"
+	"io/fs"
 	"os"
+	"syscall"
 
 	"golang.org/x/sys/unix"
 )
@@ -16,3 +19,22 @@ func isDirError(name string) *os.PathError {
 		Err:  unix.EISDIR,
 	}
 }
+
+// chownSupported - only root can change the ownership of output files
+func chownSupported() error {
+	if os.Geteuid() != 0 {
+		return errors.New("not running as root")
+	}
+
+	return nil
+}
+
+// fileOwner returns the file's uid and gid, when they're known
+func fileOwner(fi fs.FileInfo) (uid, gid int, ok bool) {
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, 0, false
+	}
+
+	return int(st.Uid), int(st.Gid), true
+}
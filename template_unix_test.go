@@ -4,6 +4,7 @@ package gomplate
 
 import (
 	"context"
+	"io/fs"
 	"testing"
 
 	"github.com/hack-pad/hackpadfs"
@@ -22,7 +23,7 @@ func TestWalkDir_UNIX(t *testing.T) {
 
 	cfg := &Config{}
 
-	_, err := walkDir(ctx, cfg, "/indir", simpleNamer("/outdir"), nil, nil, 0, false)
+	_, err := walkDir(ctx, cfg, "/indir", simpleNamer("/outdir"), nil, nil)
 	require.Error(t, err)
 
 	err = hackpadfs.MkdirAll(fsys, "/indir/one", 0o777)
@@ -36,7 +37,7 @@ func TestWalkDir_UNIX(t *testing.T) {
 	err = hackpadfs.WriteFullFile(fsys, "/indir/two/baz", []byte("baz"), 0o644)
 	require.NoError(t, err)
 
-	templates, err := walkDir(ctx, cfg, "/indir", simpleNamer("/outdir"), []string{"*/two"}, []string{}, 0, false)
+	templates, err := walkDir(ctx, cfg, "/indir", simpleNamer("/outdir"), []string{"*/two"}, []string{})
 	require.NoError(t, err)
 
 	expected := []Template{
@@ -54,4 +55,26 @@ func TestWalkDir_UNIX(t *testing.T) {
 		assert.Equal(t, expected[i].Name, tmpl.Name)
 		assert.Equal(t, expected[i].Text, tmpl.Text)
 	}
+
+	// two inputs mapped to the same output is an error
+	sameNamer := outputNamerFunc(func(context.Context, string) (string, error) {
+		return "/outdir/same", nil
+	})
+	_, err = walkDir(ctx, cfg, "/indir", sameNamer, nil, nil)
+	require.ErrorContains(t, err, `would both be written to "/outdir/same"`)
+
+	// collisions are caught before passthrough files are copied
+	_, err = walkDir(ctx, cfg, "/indir", sameNamer, nil, []string{"one/bar"})
+	require.ErrorContains(t, err, `would both be written to "/outdir/same"`)
+
+	_, err = hackpadfs.Stat(fsys, "/outdir/same")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+
+	// with compression, the names are compared after ".gz" is added
+	err = hackpadfs.WriteFullFile(fsys, "/indir/one/bar.gz", []byte("bar"), 0o644)
+	require.NoError(t, err)
+
+	cfg = &Config{OutputDir: "/outdir", Compress: "gzip"}
+	_, err = walkDir(ctx, cfg, "/indir", chooseNamer(cfg, nil), nil, nil)
+	require.ErrorContains(t, err, `input files "/indir/one/bar" and "/indir/one/bar.gz" would both be written to "/outdir/one/bar.gz"`)
 }
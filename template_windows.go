@@ -4,6 +4,8 @@
 package gomplate
 
 import (
+	"errors"
+	"io/fs"
 	"os"
 
 	"golang.org/x/sys/windows"
@@ -16,3 +18,13 @@ func isDirError(name string) *os.PathError {
 		Err:  windows.ERROR_INVALID_HANDLE,
 	}
 }
+
+// chownSupported - Windows doesn't have Unix-style file ownership
+func chownSupported() error {
+	return errors.New("not supported on Windows")
+}
+
+// fileOwner - file ownership is never known on Windows
+func fileOwner(_ fs.FileInfo) (uid, gid int, ok bool) {
+	return 0, 0, false
+}
@@ -3,21 +3,30 @@ package tmpl
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"path/filepath"
+	"sync/atomic"
 	"text/template"
 )
 
+// maxDepth is how deeply calls to Exec and Inline can be nested, so that
+// (probably accidental) unbounded recursion fails instead of crashing
+const maxDepth = 1000
+
 // Template -
 type Template struct {
 	root       *template.Template
 	defaultCtx interface{}
 	path       string
+
+	// depth is the number of Exec and Inline calls currently being rendered
+	depth atomic.Int32
 }
 
 // New -
 func New(root *template.Template, tctx interface{}, path string) *Template {
-	return &Template{root, tctx, path}
+	return &Template{root: root, defaultCtx: tctx, path: path}
 }
 
 // Path - returns the path to the current template if it came from a file.
@@ -56,7 +65,7 @@ func (t *Template) inline(name, in string, ctx interface{}) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	return render(tmpl, ctx)
+	return t.render(tmpl, ctx)
 }
 
 // Exec - execute (render) a template - this is the built-in `template` action, except with output...
@@ -69,18 +78,39 @@ func (t *Template) Exec(name string, tmplcontext ...interface{}) (string, error)
 	if tmpl == nil {
 		return "", fmt.Errorf(`template "%s" not defined`, name)
 	}
-	return render(tmpl, ctx)
+	return t.render(tmpl, ctx)
 }
 
-func render(tmpl *template.Template, ctx interface{}) (string, error) {
+func (t *Template) render(tmpl *template.Template, ctx interface{}) (string, error) {
+	if t.depth.Add(1) > maxDepth {
+		t.depth.Add(-1)
+		return "", &depthError{name: tmpl.Name()}
+	}
+	defer t.depth.Add(-1)
+
 	out := &bytes.Buffer{}
 	err := tmpl.Execute(out, ctx)
 	if err != nil {
+		// return the original error, rather than one wrapped once per level
+		var de *depthError
+		if errors.As(err, &de) {
+			return "", de
+		}
+
 		return "", err
 	}
 	return out.String(), nil
 }
 
+// depthError is returned when Exec or Inline calls are nested too deeply
+type depthError struct {
+	name string
+}
+
+func (e *depthError) Error() string {
+	return fmt.Sprintf("template %q: exceeded maximum nesting depth of %d", e.name, maxDepth)
+}
+
 func (t *Template) parseArgs(args ...interface{}) (name, in string, ctx interface{}, err error) {
 	name = "<inline>"
 	ctx = t.defaultCtx
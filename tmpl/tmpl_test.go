@@ -90,6 +90,37 @@ func TestExec(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestExecMaxDepth(t *testing.T) {
+	root := template.New("root")
+	tmpl := New(root, nil, "")
+	root.Funcs(template.FuncMap{
+		"tmpl": func() *Template { return tmpl },
+		"dec":  func(i int) int { return i - 1 },
+	})
+
+	// recursion is allowed, up to a point
+	_, err := root.New("countdown").Parse(`{{ if gt . 0 }}{{ tmpl.Exec "countdown" (dec .) }}{{ else }}done{{ end }}`)
+	require.NoError(t, err)
+
+	out, err := tmpl.Exec("countdown", 50)
+	require.NoError(t, err)
+	assert.Equal(t, "done", out)
+
+	_, err = root.New("forever").Parse(`{{ tmpl.Exec "forever" }}`)
+	require.NoError(t, err)
+
+	_, err = tmpl.Exec("forever")
+	require.EqualError(t, err, `template "forever": exceeded maximum nesting depth of 1000`)
+
+	_, err = tmpl.Inline(`{{ tmpl.Inline "forever-inline" "{{ tmpl.Exec \"forever\" }}" }}`)
+	require.ErrorContains(t, err, "exceeded maximum nesting depth")
+
+	// the depth is reset afterwards
+	out, err = tmpl.Exec("countdown", 3)
+	require.NoError(t, err)
+	assert.Equal(t, "done", out)
+}
+
 func TestPath(t *testing.T) {
 	tmpl := New(nil, nil, "")
 
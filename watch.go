@@ -0,0 +1,320 @@
+package gomplate
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/hairyhenderson/gomplate/v4/internal/urlhelpers"
+)
+
+// watchDebounce is how long to wait for further changes before re-rendering,
+// so that a burst of writes (as many editors do when saving) only triggers a
+// single render
+var watchDebounce = 100 * time.Millisecond
+
+// watch calls render with the (absolute) paths of the changed files whenever
+// any of the local files referenced by cfg change, until the context is
+// cancelled or the process is interrupted. Render errors
+// are logged, but don't stop the watcher. Changes to the outputs are ignored,
+// so that rendering into a watched directory doesn't trigger another render.
+func watch(ctx context.Context, cfg *Config, render func(ctx context.Context, changed []string) error) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create watcher: %w", err)
+	}
+	defer w.Close()
+
+	targets, err := watchTargetsFromConfig(cfg)
+	if err != nil {
+		return err
+	}
+
+	err = targets.addTo(w)
+	if err != nil {
+		return err
+	}
+
+	slog.InfoContext(ctx, "watching for changes",
+		slog.Any("files", targets.files), slog.Any("dirs", targets.dirs),
+		slog.Any("ignored", targets.outputs))
+
+	var debounce <-chan time.Time
+
+	// the files changed since the last render
+	changed := []string{}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err, ok := <-w.Errors:
+			if !ok {
+				return nil
+			}
+			slog.ErrorContext(ctx, "watch error", slog.Any("err", err))
+		case ev, ok := <-w.Events:
+			if !ok {
+				return nil
+			}
+
+			if !targets.matches(ev.Name) {
+				continue
+			}
+
+			// new directories in a watched tree need to be watched too
+			if ev.Has(fsnotify.Create) {
+				if fi, err := os.Stat(ev.Name); err == nil && fi.IsDir() {
+					if err := targets.addTree(w, ev.Name); err != nil {
+						slog.ErrorContext(ctx, "watch error", slog.Any("err", err))
+					}
+				}
+			}
+
+			slog.DebugContext(ctx, "change detected", slog.String("event", ev.String()))
+			if p, err := filepath.Abs(ev.Name); err == nil && !slices.Contains(changed, p) {
+				changed = append(changed, p)
+			}
+			debounce = time.After(watchDebounce)
+		case <-debounce:
+			debounce = nil
+
+			slog.InfoContext(ctx, "re-rendering templates", slog.Any("changed", changed))
+			err := render(ctx, changed)
+			changed = []string{}
+			if err != nil {
+				slog.ErrorContext(ctx, "failed to render templates", slog.Any("err", err))
+			}
+		}
+	}
+}
+
+// watchTargets is the set of local paths to watch for changes
+type watchTargets struct {
+	// files - individual files
+	files []string
+	// dirs - directories, which are watched recursively
+	dirs []string
+	// outputs - output files and directories, which are never watched
+	outputs []string
+}
+
+// watchTargetsFromConfig finds all local files and directories referenced by
+// the config's inputs, datasources, context, and nested templates
+func watchTargetsFromConfig(cfg *Config) (*watchTargets, error) {
+	t := &watchTargets{}
+
+	for _, f := range cfg.InputFiles {
+		if f == "-" {
+			continue
+		}
+
+		if err := t.addInput(f, false); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.InputDir != "" {
+		if err := t.addInput(cfg.InputDir, true); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, m := range []map[string]DataSource{cfg.DataSources, cfg.Context, cfg.Templates} {
+		for _, ds := range m {
+			if ds.URL != nil && ds.URL.Scheme == "archive" {
+				if err := t.add(archiveFile(ds.URL), false); err != nil {
+					return nil, err
+				}
+
+				continue
+			}
+
+			if ds.URL == nil || (ds.URL.Scheme != "" && ds.URL.Scheme != "file") {
+				continue
+			}
+
+			p := ds.URL.Path
+			if p == "" {
+				p = ds.URL.Opaque
+			}
+
+			if err := t.add(filepath.FromSlash(p), strings.HasSuffix(p, "/")); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	outputs := slices.Clone(cfg.OutputFiles)
+	if cfg.OutputDir != "" {
+		outputs = append(outputs, cfg.OutputDir)
+	}
+
+	for _, o := range outputs {
+		if o == "-" {
+			continue
+		}
+
+		if err := t.addOutput(o); err != nil {
+			return nil, err
+		}
+	}
+
+	return t, nil
+}
+
+// addInput adds an input file or directory - for inputs in archives, the
+// archive file itself is watched
+func (t *watchTargets) addInput(name string, dir bool) error {
+	if !isArchiveURL(name) {
+		return t.add(name, dir)
+	}
+
+	u, err := urlhelpers.ParseSourceURL(name)
+	if err != nil {
+		return fmt.Errorf("failed to parse %q: %w", name, err)
+	}
+
+	return t.add(archiveFile(u), false)
+}
+
+// archiveFile returns the local path of the archive file in an archive URL
+func archiveFile(u *url.URL) string {
+	p, _, _ := strings.Cut(u.Host+u.Path, "//")
+	return filepath.FromSlash(p)
+}
+
+func (t *watchTargets) add(name string, dir bool) error {
+	p, err := filepath.Abs(name)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %q: %w", name, err)
+	}
+
+	if dir {
+		t.dirs = append(t.dirs, p)
+	} else {
+		t.files = append(t.files, p)
+	}
+
+	return nil
+}
+
+// addOutput adds an output file or directory to be ignored - unless it
+// contains one of the targets, as the default output directory (".") often
+// does, since ignoring it would ignore the targets too
+func (t *watchTargets) addOutput(name string) error {
+	p, err := filepath.Abs(name)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %q: %w", name, err)
+	}
+
+	for _, target := range slices.Concat(t.files, t.dirs) {
+		if within(target, p) {
+			return nil
+		}
+	}
+
+	t.outputs = append(t.outputs, p)
+
+	return nil
+}
+
+// matches returns true if the named file is one of the targets, or is within
+// one of the target directories - but not if it's one of the outputs
+func (t *watchTargets) matches(name string) bool {
+	p, err := filepath.Abs(name)
+	if err != nil {
+		return false
+	}
+
+	if t.isOutput(p) {
+		return false
+	}
+
+	for _, f := range t.files {
+		if p == f {
+			return true
+		}
+	}
+
+	for _, d := range t.dirs {
+		if within(p, d) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isOutput returns true if the (absolute) path is an output file, or is within
+// an output directory
+func (t *watchTargets) isOutput(p string) bool {
+	for _, o := range t.outputs {
+		if within(p, o) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// within returns true if p is dir, or is a path within dir
+func within(p, dir string) bool {
+	return p == dir || strings.HasPrefix(p, dir+string(filepath.Separator))
+}
+
+// addTo adds the targets to the watcher. Files are watched through their
+// parent directories, since many editors save by replacing the file, which
+// would otherwise end the watch.
+func (t *watchTargets) addTo(w *fsnotify.Watcher) error {
+	for _, f := range t.files {
+		d := filepath.Dir(f)
+		if err := w.Add(d); err != nil {
+			return fmt.Errorf("failed to watch %q: %w", d, err)
+		}
+	}
+
+	for _, d := range t.dirs {
+		if err := t.addTree(w, d); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// addTree adds the directory and all subdirectories to the watcher, except
+// for output directories
+func (t *watchTargets) addTree(w *fsnotify.Watcher, root string) error {
+	return filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return fmt.Errorf("failed to walk %q: %w", p, err)
+		}
+
+		if !d.IsDir() {
+			return nil
+		}
+
+		if abs, err := filepath.Abs(p); err == nil && t.isOutput(abs) {
+			return fs.SkipDir
+		}
+
+		if err := w.Add(p); err != nil {
+			return fmt.Errorf("failed to watch %q: %w", p, err)
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,237 @@
+package gomplate
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchTargetsFromConfig(t *testing.T) {
+	abs := func(s string) string {
+		p, err := filepath.Abs(filepath.FromSlash(s))
+		require.NoError(t, err)
+		return p
+	}
+
+	mustParse := func(s string) *url.URL {
+		u, err := url.Parse(s)
+		require.NoError(t, err)
+		return u
+	}
+
+	cfg := &Config{
+		InputFiles: []string{"-", "in.tmpl", "/abs/in.tmpl"},
+		DataSources: map[string]DataSource{
+			"rel":  {URL: mustParse("data.json")},
+			"abs":  {URL: mustParse("file:///etc/data.yaml")},
+			"dir":  {URL: mustParse("file:///etc/data/")},
+			"http": {URL: mustParse("https://example.com/data.json")},
+			"env":  {URL: mustParse("env:FOO")},
+		},
+		Templates: map[string]DataSource{
+			"t": {URL: mustParse("templates/")},
+		},
+	}
+
+	targets, err := watchTargetsFromConfig(cfg)
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t, []string{
+		abs("in.tmpl"),
+		abs("/abs/in.tmpl"),
+		abs("data.json"),
+		abs("/etc/data.yaml"),
+	}, targets.files)
+	assert.ElementsMatch(t, []string{
+		abs("/etc/data"),
+		abs("templates"),
+	}, targets.dirs)
+
+	assert.True(t, targets.matches("in.tmpl"))
+	assert.True(t, targets.matches("/etc/data/sub/foo.json"))
+	assert.True(t, targets.matches("/etc/data"))
+	assert.False(t, targets.matches("/etc/database"))
+	assert.False(t, targets.matches("out.txt"))
+}
+
+func TestWatchTargetsFromConfig_Outputs(t *testing.T) {
+	abs := func(s string) string {
+		p, err := filepath.Abs(filepath.FromSlash(s))
+		require.NoError(t, err)
+		return p
+	}
+
+	// an output directory within the input directory is ignored
+	cfg := &Config{InputDir: "tmpl", OutputDir: "tmpl/out"}
+
+	targets, err := watchTargetsFromConfig(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, []string{abs("tmpl/out")}, targets.outputs)
+
+	assert.True(t, targets.matches("tmpl/foo.tmpl"))
+	assert.False(t, targets.matches("tmpl/out"))
+	assert.False(t, targets.matches("tmpl/out/foo.tmpl"))
+	assert.True(t, targets.matches("tmpl/outside.tmpl"))
+
+	// the default output directory contains the input directory, so it can't
+	// be ignored
+	cfg = &Config{InputDir: "tmpl", OutputDir: "."}
+
+	targets, err = watchTargetsFromConfig(cfg)
+	require.NoError(t, err)
+	assert.Empty(t, targets.outputs)
+	assert.True(t, targets.matches("tmpl/foo.tmpl"))
+
+	cfg = &Config{
+		InputFiles:  []string{"in.tmpl"},
+		OutputFiles: []string{"data/out.json"},
+		DataSources: map[string]DataSource{"data": {URL: &url.URL{Path: "data/"}}},
+	}
+
+	targets, err = watchTargetsFromConfig(cfg)
+	require.NoError(t, err)
+	assert.True(t, targets.matches("data/in.json"))
+	assert.False(t, targets.matches("data/out.json"))
+}
+
+func TestWatchTargetsFromConfig_Archive(t *testing.T) {
+	abs := func(s string) string {
+		p, err := filepath.Abs(filepath.FromSlash(s))
+		require.NoError(t, err)
+		return p
+	}
+
+	u, err := url.Parse("archive:///tmp/data.tar.gz//config.yaml")
+	require.NoError(t, err)
+
+	// the archive files themselves are watched
+	cfg := &Config{
+		InputDir:    "archive://bundle.zip//templates",
+		DataSources: map[string]DataSource{"data": {URL: u}},
+	}
+
+	targets, err := watchTargetsFromConfig(cfg)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{abs("bundle.zip"), abs("/tmp/data.tar.gz")}, targets.files)
+	assert.Empty(t, targets.dirs)
+
+	cfg = &Config{InputFiles: []string{"archive:///tmp/bundle.zip//foo.tmpl"}}
+	targets, err = watchTargetsFromConfig(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, []string{abs("/tmp/bundle.zip")}, targets.files)
+}
+
+func TestWatch(t *testing.T) {
+	watchDebounce = 10 * time.Millisecond
+	t.Cleanup(func() { watchDebounce = 100 * time.Millisecond })
+
+	tmpDir := t.TempDir()
+	in := filepath.Join(tmpDir, "in.tmpl")
+	require.NoError(t, os.WriteFile(in, []byte("hello"), 0o600))
+
+	cfg := &Config{InputFiles: []string{in}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	renders := make(chan int)
+	n := 0
+	render := func(_ context.Context, changed []string) error {
+		assert.Equal(t, []string{in}, changed)
+
+		n++
+		renders <- n
+
+		// errors must not stop the watcher
+		return fmt.Errorf("render %d failed", n)
+	}
+
+	done := make(chan error)
+	go func() {
+		done <- watch(ctx, cfg, render)
+	}()
+
+	// give the watcher time to start
+	time.Sleep(50 * time.Millisecond)
+
+	for i := 1; i <= 2; i++ {
+		// several writes in quick succession should only trigger one render
+		for j := 0; j < 3; j++ {
+			require.NoError(t, os.WriteFile(in, []byte(fmt.Sprintf("hello %d", j)), 0o600))
+		}
+
+		select {
+		case got := <-renders:
+			assert.Equal(t, i, got)
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for render")
+		}
+	}
+
+	// changes to unrelated files are ignored
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "out.txt"), []byte("x"), 0o600))
+
+	select {
+	case got := <-renders:
+		t.Fatalf("unexpected render %d", got)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	cancel()
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for watch to stop")
+	}
+}
+
+func TestRun_WatchAfterFailedRender(t *testing.T) {
+	watchDebounce = 10 * time.Millisecond
+	t.Cleanup(func() { watchDebounce = 100 * time.Millisecond })
+
+	tmpDir := t.TempDir()
+	in := filepath.Join(tmpDir, "in.tmpl")
+	out := filepath.Join(tmpDir, "out.txt")
+	require.NoError(t, os.WriteFile(in, []byte("{{ oops"), 0o600))
+
+	cfg := &Config{
+		InputFiles:  []string{in},
+		OutputFiles: []string{out},
+		Watch:       true,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan error)
+	go func() {
+		done <- Run(ctx, cfg)
+	}()
+
+	// the first render fails, but the watch continues
+	time.Sleep(100 * time.Millisecond)
+	require.NoError(t, os.WriteFile(in, []byte("fixed"), 0o600))
+
+	assert.Eventually(t, func() bool {
+		b, err := os.ReadFile(out)
+		return err == nil && string(b) == "fixed"
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for watch to stop")
+	}
+}